@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/bundle"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// bundlePassphraseEnv holds the shared bundle passphrase for scripted use
+const bundlePassphraseEnv = "CLOUDBENCH_BUNDLE_PASSPHRASE"
+
+var (
+	bundleKinds      []string
+	bundleIDs        []string
+	bundleEncrypt    bool
+	bundleOnConflict string
+	bundleFormat     string
+)
+
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export and import connection bundles",
+	Long: `A bundle is a portable JSON or YAML file holding GeoServer, S3 and GeoNode connections,
+pg_service entries, sync configurations and saved queries, e.g. to onboard a
+teammate. Secrets are redacted unless --encrypt is given, in which case they
+are encrypted with a shared passphrase (or ` + bundlePassphraseEnv + `).`,
+}
+
+var bundleExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export connections and settings to a bundle (stdout if no file)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		format := bundle.FormatJSON
+		if bundleFormat != "" {
+			if format, err = bundle.ParseFormat(bundleFormat); err != nil {
+				return err
+			}
+		} else if len(args) == 1 && bundle.FormatOf(args[0]) != "" {
+			format = bundle.FormatOf(args[0])
+		}
+
+		opts := bundle.ExportOptions{Kinds: bundleKinds, IDs: bundleIDs}
+		if bundleEncrypt {
+			if opts.Passphrase = os.Getenv(bundlePassphraseEnv); opts.Passphrase == "" {
+				if opts.Passphrase, err = readNewPassphrase(); err != nil {
+					return err
+				}
+			}
+		}
+
+		b, err := bundle.Export(cfg, opts)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer = os.Stdout
+		if len(args) == 1 {
+			f, err := os.OpenFile(args[0], os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create bundle: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+		if err := b.Write(out, format); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+
+		if len(args) == 1 {
+			fmt.Fprintf(os.Stderr, "Exported %d objects to %s (secrets %s)\n", b.Count(), args[0], b.SecretsMode)
+		}
+		return nil
+	},
+}
+
+var bundleImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import connections and settings from a bundle",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open bundle: %w", err)
+		}
+		defer f.Close()
+
+		b, err := bundle.Read(f, bundle.FormatOf(args[0]))
+		if err != nil {
+			return err
+		}
+
+		opts := bundle.ImportOptions{OnConflict: bundleOnConflict, Actor: audit.NewActor(audit.LocalUser, "cli", "")}
+		if b.SecretsMode == bundle.SecretsEncrypted {
+			if opts.Passphrase = os.Getenv(bundlePassphraseEnv); opts.Passphrase == "" {
+				if opts.Passphrase, err = secrets.PromptPassphrase("Bundle passphrase: "); err != nil {
+					return err
+				}
+			}
+		}
+
+		result, err := bundle.Import(cfg, b, opts)
+		if err != nil {
+			return fmt.Errorf("failed to import bundle: %w", err)
+		}
+		if err := cfg.Save(); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+
+		printImportResult(result)
+		return nil
+	},
+}
+
+func printImportResult(result *bundle.ImportResult) {
+	for _, label := range result.Added {
+		fmt.Printf("  added     %s\n", label)
+	}
+	for _, label := range result.Replaced {
+		fmt.Printf("  replaced  %s\n", label)
+	}
+	for _, label := range result.Skipped {
+		fmt.Printf("  skipped   %s (already exists)\n", label)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+	fmt.Printf("%d added, %d replaced, %d skipped\n", len(result.Added), len(result.Replaced), len(result.Skipped))
+}
+
+func init() {
+	bundleExportCmd.Flags().StringSliceVar(&bundleKinds, "kinds", nil, "kinds to export: geoserver, s3, geonode, pgservice, sync, query (default all)")
+	bundleExportCmd.Flags().StringSliceVar(&bundleIDs, "ids", nil, "only export these connection/config IDs, pg service names or service/query names")
+	bundleExportCmd.Flags().BoolVar(&bundleEncrypt, "encrypt", false, "encrypt secrets with a shared passphrase instead of redacting them")
+	bundleExportCmd.Flags().StringVar(&bundleFormat, "format", "", "bundle format: json or yaml (default from the file extension, else json)")
+	bundleImportCmd.Flags().StringVar(&bundleOnConflict, "on-conflict", bundle.ConflictSkip, "what to do with existing objects: skip, replace or duplicate")
+
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+	rootCmd.AddCommand(bundleCmd)
+}
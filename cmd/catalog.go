@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Export a GeoServer catalog to files, and import it elsewhere",
+	Long: `Export writes the whole catalog of a GeoServer to a directory tree with one
+YAML file per workspace, store, layer and layer group and one SLD file per
+style, in a stable order and without volatile fields, so that committing it
+to git gives meaningful diffs. Import recreates the catalog from such a tree
+on another server.
+
+Data store passwords and other credentials are not exported. They are
+replaced by ${secret:geoserver/<workspace>/<store>/<parameter>} references,
+which import reads from the secrets backend; edit a reference to
+${env:NAME} to take it from the environment instead.`,
+}
+
+var catalogExportCmd = &cobra.Command{
+	Use:   "export <dir>",
+	Short: "Write a server's catalog to a directory tree",
+	Long: `Write a server's catalog to a directory tree. An existing tree is updated in
+place: files for objects that no longer exist are removed, and other files
+in the directory are left alone.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		client, _, err := connect(manifestConn)
+		if err != nil {
+			return err
+		}
+		m, err := manifest.Export(client)
+		if err != nil {
+			return err
+		}
+		if err := m.WriteTree(args[0]); err != nil {
+			return fmt.Errorf("failed to write catalog: %w", err)
+		}
+
+		stores, layers, groups, styles := 0, 0, 0, len(m.Styles)
+		for _, ws := range m.Workspaces {
+			stores += len(ws.DataStores) + len(ws.CoverageStores)
+			layers += len(ws.Layers)
+			groups += len(ws.LayerGroups)
+			styles += len(ws.Styles)
+		}
+		printDone("Exported %d workspaces, %d stores, %d layers, %d layer groups and %d styles to %s",
+			len(m.Workspaces), stores, layers, groups, styles, args[0])
+		return nil
+	},
+}
+
+var catalogImportCmd = &cobra.Command{
+	Use:   "import <dir>",
+	Short: "Make a server's catalog match an exported tree",
+	Long: `Make a server's catalog match an exported tree, as apply does for a manifest:
+the changes are listed and made after confirmation. With --prune, objects in
+the tree's workspaces that the tree does not have are deleted.`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		m, err := manifest.LoadTree(args[0])
+		if err != nil {
+			return withExitCode(ExitConfig, err)
+		}
+		return applyManifest(cmd, m)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{catalogExportCmd, catalogImportCmd} {
+		c.Flags().StringVarP(&manifestConn, "conn", "c", "", "GeoServer connection name or ID (default $"+connectionEnv+" or the active connection)")
+	}
+	catalogImportCmd.Flags().BoolVar(&manifestPrune, "prune", false, "delete objects in the tree's workspaces that it does not have")
+	catalogImportCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "import without asking for confirmation")
+
+	catalogCmd.AddCommand(catalogExportCmd, catalogImportCmd)
+	rootCmd.AddCommand(catalogCmd)
+}
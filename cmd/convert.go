@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/cloudnative"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+	"github.com/kartoza/kartoza-cloudbench/internal/s3client"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	convertParallel  int
+	convertOutputDir string
+	convertForce     bool
+	convertS3        string
+	convertBucket    string
+	convertPrefix    string
+	convertReport    string
+	convertOutput    string
+)
+
+var convertCmd = &cobra.Command{
+	Use:   "convert <file|dir|glob>...",
+	Short: "Convert files to cloud-native formats",
+	Long: `Convert rasters to Cloud Optimized GeoTIFF, point clouds to COPC and vector
+data to GeoParquet, choosing the format from each file's type. Directories
+are searched recursively; quote globs such as 'data/*.tif' to let convert
+expand them.
+
+Each output is written next to its source (or to --output-dir) and
+validated. The checksums of sources and outputs are recorded in a
+.cloudbench-convert.json file in the output directory, so running convert
+again skips files that have not changed since; --force converts them anyway.
+
+With --s3 and --bucket, valid outputs are uploaded under --prefix to a saved
+S3 connection. --report writes a JSON summary of every file. The exit code
+is 1 when any file failed to convert, validate or upload.`,
+	Args: usageArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(convertOutput); err != nil {
+			return err
+		}
+		if convertParallel < 1 {
+			return withExitCode(ExitUsage, fmt.Errorf("--parallel must be at least 1"))
+		}
+		if (convertS3 == "") != (convertBucket == "") {
+			return withExitCode(ExitUsage, fmt.Errorf("--s3 and --bucket must be given together"))
+		}
+		cmd.SilenceUsage = true
+
+		sources, err := cloudnative.ExpandSources(args)
+		if err != nil {
+			return withExitCode(ExitNotFound, err)
+		}
+		if len(sources) == 0 {
+			return notFound("no convertible files found")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		opts := cloudnative.BatchOptions{
+			Parallel:  convertParallel,
+			Options:   cloudnative.DefaultConversionOptions(),
+			OutputDir: convertOutputDir,
+			Force:     convertForce,
+			Done: func(r cloudnative.BatchFileResult) {
+				if r.Reason != "" {
+					printDone("%s %s: %s", r.Status, r.Source, r.Reason)
+				} else {
+					printDone("%s %s -> %s", r.Status, r.Source, r.Output)
+				}
+			},
+		}
+		if convertS3 != "" {
+			if err := setConvertUpload(&opts); err != nil {
+				return err
+			}
+		}
+
+		report := cloudnative.RunBatch(ctx, sources, opts)
+		if convertReport != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(convertReport, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("failed to write report: %w", err)
+			}
+		}
+
+		files := report.Files
+		err = printOutput(os.Stdout, convertOutput, report, []string{"SOURCE", "TARGET", "STATUS", "OUTPUT", "DETAIL"}, func(i int) []string {
+			f := files[i]
+			detail := f.Reason
+			if detail == "" {
+				detail = f.Uploaded
+			}
+			return []string{f.Source, string(f.Target), f.Status, f.Output, detail}
+		}, len(files))
+		if err != nil {
+			return err
+		}
+		printDone("Converted %d, skipped %d, failed %d", report.Converted, report.Skipped, report.Failed)
+		if report.Failed > 0 {
+			return withExitCode(ExitFailure, fmt.Errorf("%d of %d files failed", report.Failed, len(files)))
+		}
+		return nil
+	},
+}
+
+// setConvertUpload sets a batch to upload its outputs to the S3 connection
+// and bucket given by --s3 and --bucket
+func setConvertUpload(opts *cloudnative.BatchOptions) error {
+	cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+	conn, err := findS3Connection(cfg, convertS3)
+	if err != nil {
+		return withExitCode(ExitConfig, err)
+	}
+	client, err := s3client.NewClient(conn)
+	if err != nil {
+		return withExitCode(ExitConfig, fmt.Errorf("%s: %w", conn.Name, err))
+	}
+	client = client.WithAudit(audit.NewActor(audit.LocalUser, "cli", conn.ID))
+
+	exists, err := client.BucketExists(context.Background(), convertBucket)
+	if err != nil {
+		return withExitCode(ExitUnreachable, fmt.Errorf("%s (%s): %w", conn.Name, conn.Endpoint, err))
+	}
+	if !exists {
+		return notFound("bucket %s does not exist on %s", convertBucket, conn.Name)
+	}
+
+	prefix := strings.Trim(convertPrefix, "/")
+	opts.Destination = fmt.Sprintf("s3://%s/%s/%s", conn.ID, convertBucket, prefix)
+	opts.Upload = func(ctx context.Context, outputPath string) (string, error) {
+		key := path.Join(prefix, filepath.Base(outputPath))
+		f, err := os.Open(outputPath)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			return "", err
+		}
+		putOpts := s3client.PutOptions{ContentType: outputContentType(outputPath)}
+		if err := client.PutObject(ctx, convertBucket, key, f, info.Size(), putOpts); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("s3://%s/%s", convertBucket, key), nil
+	}
+	return nil
+}
+
+// findS3Connection returns the S3 connection with the given name or ID
+func findS3Connection(cfg *config.Config, ref string) (*config.S3Connection, error) {
+	if conn := cfg.GetS3Connection(ref); conn != nil {
+		return conn, nil
+	}
+	var match *config.S3Connection
+	for i := range cfg.S3Connections {
+		if strings.EqualFold(cfg.S3Connections[i].Name, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("several S3 connections are named %q; use its ID", ref)
+			}
+			match = &cfg.S3Connections[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no S3 connection named %q", ref)
+	}
+	return match, nil
+}
+
+// outputContentType returns the MIME type of a converted file
+func outputContentType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".tif", ".tiff":
+		return "image/tiff"
+	case ".parquet":
+		return "application/vnd.apache.parquet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+func init() {
+	convertCmd.Flags().IntVarP(&convertParallel, "parallel", "j", jobs.DefaultLimits[jobs.KindConversion], "number of conversions to run at once")
+	convertCmd.Flags().StringVar(&convertOutputDir, "output-dir", "", "directory for the outputs (default next to each source)")
+	convertCmd.Flags().BoolVar(&convertForce, "force", false, "convert files whose output is up to date")
+	convertCmd.Flags().StringVar(&convertS3, "s3", "", "S3 connection name or ID to upload outputs to")
+	convertCmd.Flags().StringVar(&convertBucket, "bucket", "", "bucket to upload outputs to")
+	convertCmd.Flags().StringVar(&convertPrefix, "prefix", "", "key prefix for uploaded outputs")
+	convertCmd.Flags().StringVar(&convertReport, "report", "", "write a JSON report of the batch to this file")
+	convertCmd.Flags().StringVarP(&convertOutput, "output", "o", outputTable, "output format: table or json")
+	rootCmd.AddCommand(convertCmd)
+}
@@ -0,0 +1,320 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+// connectionEnv selects the GeoServer connection when --connection is not given
+const connectionEnv = "CLOUDBENCH_CONNECTION"
+
+var (
+	gsConnection string
+	gsOutput     string
+	gsWorkspace  string
+)
+
+var gsCmd = &cobra.Command{
+	Use:   "gs",
+	Short: "Script GeoServer catalog operations",
+	Long: `Work with the catalog of a saved GeoServer connection from the shell, e.g. in
+pipelines and CI. The connection is chosen by name or ID with --connection or
+` + connectionEnv + `, and defaults to the active connection.
+
+Listings print a table, or JSON with --output json. Messages about changes go
+to stderr. Exit codes: 0 success, 1 operation failed, 2 usage error,
+3 config error or unknown connection, 4 GeoServer unreachable or credentials
+refused, 5 resource not found.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(gsOutput); err != nil {
+			return err
+		}
+		// Arguments are valid, so failures from here on are not usage errors
+		cmd.SilenceUsage = true
+		return nil
+	},
+}
+
+// gsConnect returns an audited client for the connection selected with
+// --connection, once it has answered
+func gsConnect() (*api.Client, error) {
+	client, _, err := connect(gsConnection)
+	return client, err
+}
+
+// connect loads the config and returns an audited client for the named
+// connection, once it has answered, along with the config
+func connect(ref string) (*api.Client, *config.Config, error) {
+	cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+	if err != nil {
+		return nil, nil, withExitCode(ExitConfig, fmt.Errorf("failed to load config: %w", err))
+	}
+
+	conn, err := findConnection(cfg, ref)
+	if err != nil {
+		return nil, nil, withExitCode(ExitConfig, err)
+	}
+
+	client := api.NewClient(conn)
+	if err := client.TestConnection(); err != nil {
+		return nil, nil, withExitCode(ExitUnreachable, fmt.Errorf("%s (%s): %w", conn.Name, conn.URL, err))
+	}
+	return client.WithAudit(audit.NewActor(audit.LocalUser, "cli", conn.ID)), cfg, nil
+}
+
+// findConnection returns the connection with the given name or ID, falling
+// back to $CLOUDBENCH_CONNECTION and then the active connection
+func findConnection(cfg *config.Config, ref string) (*config.Connection, error) {
+	if ref == "" {
+		ref = os.Getenv(connectionEnv)
+	}
+	if ref == "" {
+		if conn := cfg.GetActiveConnection(); conn != nil {
+			return conn, nil
+		}
+		if len(cfg.Connections) == 1 {
+			return &cfg.Connections[0], nil
+		}
+		return nil, fmt.Errorf("no connection selected; use --connection or set %s", connectionEnv)
+	}
+
+	if conn := cfg.GetConnection(ref); conn != nil {
+		return conn, nil
+	}
+	var match *config.Connection
+	for i := range cfg.Connections {
+		if strings.EqualFold(cfg.Connections[i].Name, ref) {
+			if match != nil {
+				return nil, fmt.Errorf("several connections are named %q; use its ID", ref)
+			}
+			match = &cfg.Connections[i]
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no GeoServer connection named %q", ref)
+	}
+	return match, nil
+}
+
+// qualifiedName splits a workspace:name reference, taking the workspace from
+// --workspace when the reference has none
+func qualifiedName(ref string) (workspace, name string, err error) {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		workspace, name = ref[:i], ref[i+1:]
+	} else {
+		workspace, name = gsWorkspace, ref
+	}
+	if workspace == "" || name == "" {
+		return "", "", withExitCode(ExitUsage, fmt.Errorf("%q needs a workspace: use workspace:name or --workspace", ref))
+	}
+	return workspace, name, nil
+}
+
+// gsWorkspaces returns the workspace given with --workspace after checking it
+// exists, or every workspace
+func gsWorkspaces(client *api.Client) ([]string, error) {
+	workspaces, err := client.GetWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ws := range workspaces {
+		if gsWorkspace == "" || ws.Name == gsWorkspace {
+			names = append(names, ws.Name)
+		}
+	}
+	if gsWorkspace != "" && len(names) == 0 {
+		return nil, notFound("workspace %q not found", gsWorkspace)
+	}
+	return names, nil
+}
+
+// requireWorkspace fails with ExitNotFound unless the workspace exists
+func requireWorkspace(client *api.Client, name string) error {
+	workspaces, err := client.GetWorkspaces()
+	if err != nil {
+		return err
+	}
+	for _, ws := range workspaces {
+		if ws.Name == name {
+			return nil
+		}
+	}
+	return notFound("workspace %q not found", name)
+}
+
+// Workspaces
+
+type workspaceRow struct {
+	Name     string `json:"name"`
+	Isolated bool   `json:"isolated"`
+}
+
+var (
+	wsIsolated bool
+	wsDefault  bool
+	wsRecurse  bool
+)
+
+var gsWorkspacesCmd = &cobra.Command{
+	Use:     "workspaces",
+	Aliases: []string{"workspace", "ws"},
+	Short:   "List, create and delete workspaces",
+}
+
+var gsWorkspacesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List workspaces",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		workspaces, err := client.GetWorkspaces()
+		if err != nil {
+			return err
+		}
+
+		rows := make([]workspaceRow, len(workspaces))
+		for i, ws := range workspaces {
+			rows[i] = workspaceRow{Name: ws.Name, Isolated: ws.Isolated}
+		}
+		return printOutput(cmd.OutOrStdout(), gsOutput, rows, []string{"NAME", "ISOLATED"}, func(i int) []string {
+			return []string{rows[i].Name, strconv.FormatBool(rows[i].Isolated)}
+		}, len(rows))
+	},
+}
+
+var gsWorkspacesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a workspace",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := client.CreateWorkspaceWithConfig(models.WorkspaceConfig{Name: args[0], Isolated: wsIsolated, Default: wsDefault}); err != nil {
+			return err
+		}
+		printDone("Created workspace %s", args[0])
+		return nil
+	},
+}
+
+var gsWorkspacesDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a workspace",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := requireWorkspace(client, args[0]); err != nil {
+			return err
+		}
+		if err := client.DeleteWorkspace(args[0], wsRecurse); err != nil {
+			return err
+		}
+		printDone("Deleted workspace %s", args[0])
+		return nil
+	},
+}
+
+// Stores
+
+type storeRow struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind"` // datastore or coveragestore
+	Type      string `json:"type,omitempty"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// Store kinds
+const (
+	kindDataStore     = "datastore"
+	kindCoverageStore = "coveragestore"
+)
+
+var gsStoresCmd = &cobra.Command{
+	Use:     "stores",
+	Aliases: []string{"store"},
+	Short:   "List data and coverage stores",
+}
+
+var gsStoresListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the stores of a workspace (--workspace) or of all workspaces",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		workspaces, err := gsWorkspaces(client)
+		if err != nil {
+			return err
+		}
+
+		rows := []storeRow{}
+		for _, ws := range workspaces {
+			stores, err := listStores(client, ws)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, stores...)
+		}
+		return printOutput(cmd.OutOrStdout(), gsOutput, rows, []string{"WORKSPACE", "NAME", "KIND", "TYPE", "ENABLED"}, func(i int) []string {
+			r := rows[i]
+			return []string{r.Workspace, r.Name, r.Kind, r.Type, strconv.FormatBool(r.Enabled)}
+		}, len(rows))
+	},
+}
+
+// listStores returns the data and coverage stores of a workspace
+func listStores(client *api.Client, workspace string) ([]storeRow, error) {
+	dataStores, err := client.GetDataStores(workspace)
+	if err != nil {
+		return nil, err
+	}
+	coverageStores, err := client.GetCoverageStores(workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []storeRow
+	for _, s := range dataStores {
+		rows = append(rows, storeRow{Workspace: workspace, Name: s.Name, Kind: kindDataStore, Type: s.Type, Enabled: s.Enabled})
+	}
+	for _, s := range coverageStores {
+		rows = append(rows, storeRow{Workspace: workspace, Name: s.Name, Kind: kindCoverageStore, Type: s.Type, Enabled: s.Enabled})
+	}
+	return rows, nil
+}
+
+func init() {
+	gsCmd.PersistentFlags().StringVarP(&gsConnection, "connection", "c", "", "GeoServer connection name or ID (default $"+connectionEnv+" or the active connection)")
+	gsCmd.PersistentFlags().StringVarP(&gsOutput, "output", "o", outputTable, "output format: table or json")
+	gsCmd.PersistentFlags().StringVarP(&gsWorkspace, "workspace", "w", "", "workspace to work in")
+
+	gsWorkspacesCreateCmd.Flags().BoolVar(&wsIsolated, "isolated", false, "create an isolated workspace")
+	gsWorkspacesCreateCmd.Flags().BoolVar(&wsDefault, "default", false, "make it the default workspace")
+	gsWorkspacesDeleteCmd.Flags().BoolVarP(&wsRecurse, "recurse", "r", false, "also delete the workspace's stores, layers and styles")
+
+	gsWorkspacesCmd.AddCommand(gsWorkspacesListCmd, gsWorkspacesCreateCmd, gsWorkspacesDeleteCmd)
+	gsStoresCmd.AddCommand(gsStoresListCmd)
+	gsCmd.AddCommand(gsWorkspacesCmd, gsStoresCmd)
+	rootCmd.AddCommand(gsCmd)
+}
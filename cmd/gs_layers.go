@@ -0,0 +1,355 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// Layers
+
+type layerRow struct {
+	Workspace string `json:"workspace"`
+	Name      string `json:"name"`
+}
+
+var (
+	layerStore string
+	lgTitle    string
+	lgMode     string
+	lgLayers   []string
+)
+
+var gsLayersCmd = &cobra.Command{
+	Use:     "layers",
+	Aliases: []string{"layer"},
+	Short:   "List, publish, enable, disable and delete layers",
+	Long:    `Layers are named workspace:layer, or just layer with --workspace.`,
+}
+
+var gsLayersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the layers of a workspace (--workspace) or of all workspaces",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		workspaces, err := gsWorkspaces(client)
+		if err != nil {
+			return err
+		}
+
+		rows := []layerRow{}
+		for _, ws := range workspaces {
+			layers, err := client.GetLayers(ws)
+			if err != nil {
+				return err
+			}
+			for _, l := range layers {
+				rows = append(rows, layerRow{Workspace: ws, Name: l.Name})
+			}
+		}
+		return printOutput(cmd.OutOrStdout(), gsOutput, rows, []string{"WORKSPACE", "NAME"}, func(i int) []string {
+			return []string{rows[i].Workspace, rows[i].Name}
+		}, len(rows))
+	},
+}
+
+var gsLayersPublishCmd = &cobra.Command{
+	Use:   "publish <layer> --store <store>",
+	Short: "Publish a feature type or coverage from a store as a layer",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name, err := qualifiedName(args[0])
+		if err != nil {
+			return err
+		}
+		if layerStore == "" {
+			return withExitCode(ExitUsage, fmt.Errorf("--store is required"))
+		}
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := requireWorkspace(client, workspace); err != nil {
+			return err
+		}
+		stores, err := listStores(client, workspace)
+		if err != nil {
+			return err
+		}
+
+		for _, store := range stores {
+			if store.Name != layerStore {
+				continue
+			}
+			if store.Kind == kindCoverageStore {
+				err = client.PublishCoverage(workspace, store.Name, name)
+			} else {
+				err = client.PublishFeatureType(workspace, store.Name, name)
+			}
+			if err != nil {
+				return err
+			}
+			printDone("Published %s:%s from %s", workspace, name, store.Name)
+			return nil
+		}
+		return notFound("store %q not found in workspace %q", layerStore, workspace)
+	},
+}
+
+// setLayerEnabledCmd builds the enable and disable commands
+func setLayerEnabledCmd(enabled bool) *cobra.Command {
+	verb := "Enable"
+	if !enabled {
+		verb = "Disable"
+	}
+	return &cobra.Command{
+		Use:   strings.ToLower(verb) + " <layer>...",
+		Short: verb + " layers",
+		Args:  usageArgs(cobra.MinimumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return forEachLayer(args, func(client *api.Client, workspace, name string) error {
+				if err := client.EnableLayer(workspace, name, enabled); err != nil {
+					return err
+				}
+				printDone("%sd %s:%s", verb, workspace, name)
+				return nil
+			})
+		},
+	}
+}
+
+var gsLayersDeleteCmd = &cobra.Command{
+	Use:   "delete <layer>...",
+	Short: "Delete layers and their cached tiles",
+	Args:  usageArgs(cobra.MinimumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return forEachLayer(args, func(client *api.Client, workspace, name string) error {
+			if err := client.DeleteLayerWithCleanup(workspace, name); err != nil {
+				return err
+			}
+			printDone("Deleted %s:%s", workspace, name)
+			return nil
+		})
+	},
+}
+
+// forEachLayer checks that every named layer exists, then applies fn to each
+func forEachLayer(refs []string, fn func(client *api.Client, workspace, name string) error) error {
+	type ref struct{ workspace, name string }
+	var layers []ref
+	for _, arg := range refs {
+		workspace, name, err := qualifiedName(arg)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, ref{workspace, name})
+	}
+
+	client, err := gsConnect()
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]map[string]bool)
+	for _, l := range layers {
+		if existing[l.workspace] == nil {
+			if err := requireWorkspace(client, l.workspace); err != nil {
+				return err
+			}
+			found, err := client.GetLayers(l.workspace)
+			if err != nil {
+				return err
+			}
+			existing[l.workspace] = make(map[string]bool)
+			for _, f := range found {
+				existing[l.workspace][f.Name] = true
+			}
+		}
+		if !existing[l.workspace][l.name] {
+			return notFound("layer %s:%s not found", l.workspace, l.name)
+		}
+	}
+
+	for _, l := range layers {
+		if err := fn(client, l.workspace, l.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Layer groups
+
+type layerGroupRow struct {
+	Workspace string `json:"workspace,omitempty"`
+	Name      string `json:"name"`
+	Mode      string `json:"mode,omitempty"`
+}
+
+var gsLayerGroupsCmd = &cobra.Command{
+	Use:     "layergroups",
+	Aliases: []string{"layergroup", "lg"},
+	Short:   "List, show, create and delete layer groups",
+	Long: `Layer groups are named workspace:group, or group with --workspace. Groups
+without a workspace are global.`,
+}
+
+var gsLayerGroupsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the layer groups of a workspace (--workspace) or the global ones",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if gsWorkspace != "" {
+			if err := requireWorkspace(client, gsWorkspace); err != nil {
+				return err
+			}
+		}
+		groups, err := client.GetLayerGroups(gsWorkspace)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]layerGroupRow, len(groups))
+		for i, g := range groups {
+			rows[i] = layerGroupRow{Workspace: gsWorkspace, Name: g.Name, Mode: g.Mode}
+		}
+		return printOutput(cmd.OutOrStdout(), gsOutput, rows, []string{"WORKSPACE", "NAME", "MODE"}, func(i int) []string {
+			return []string{rows[i].Workspace, rows[i].Name, rows[i].Mode}
+		}, len(rows))
+	},
+}
+
+var gsLayerGroupsGetCmd = &cobra.Command{
+	Use:   "get <group>",
+	Short: "Show a layer group and its layers",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name := optionalWorkspace(args[0])
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := requireLayerGroup(client, workspace, name); err != nil {
+			return err
+		}
+		group, err := client.GetLayerGroup(workspace, name)
+		if err != nil {
+			return err
+		}
+
+		if gsOutput == outputJSON {
+			return printOutput(cmd.OutOrStdout(), gsOutput, group, nil, nil, 0)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Name:  %s\nMode:  %s\nTitle: %s\n\n", group.Name, group.Mode, group.Title)
+		return printOutput(cmd.OutOrStdout(), gsOutput, group.Layers, []string{"TYPE", "NAME", "STYLE"}, func(i int) []string {
+			l := group.Layers[i]
+			return []string{l.Type, l.Name, l.StyleName}
+		}, len(group.Layers))
+	},
+}
+
+var gsLayerGroupsCreateCmd = &cobra.Command{
+	Use:   "create <group> --layers <layer>,...",
+	Short: "Create a layer group in a workspace",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name, err := qualifiedName(args[0])
+		if err != nil {
+			return err
+		}
+		if len(lgLayers) == 0 {
+			return withExitCode(ExitUsage, fmt.Errorf("--layers is required"))
+		}
+		layers := make([]string, len(lgLayers))
+		for i, l := range lgLayers {
+			if !strings.Contains(l, ":") {
+				l = workspace + ":" + l
+			}
+			layers[i] = l
+		}
+
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := requireWorkspace(client, workspace); err != nil {
+			return err
+		}
+		create := models.LayerGroupCreate{Name: name, Title: lgTitle, Mode: strings.ToUpper(lgMode), Layers: layers}
+		if err := client.CreateLayerGroup(workspace, create); err != nil {
+			return err
+		}
+		printDone("Created layer group %s:%s with %d layers", workspace, name, len(layers))
+		return nil
+	},
+}
+
+var gsLayerGroupsDeleteCmd = &cobra.Command{
+	Use:   "delete <group>",
+	Short: "Delete a layer group",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name := optionalWorkspace(args[0])
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if err := requireLayerGroup(client, workspace, name); err != nil {
+			return err
+		}
+		if err := client.DeleteLayerGroup(workspace, name); err != nil {
+			return err
+		}
+		printDone("Deleted layer group %s", args[0])
+		return nil
+	},
+}
+
+// optionalWorkspace splits a [workspace:]name reference for resources that
+// may also be global
+func optionalWorkspace(ref string) (workspace, name string) {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return gsWorkspace, ref
+}
+
+// requireLayerGroup fails with ExitNotFound unless the layer group exists
+func requireLayerGroup(client *api.Client, workspace, name string) error {
+	if workspace != "" {
+		if err := requireWorkspace(client, workspace); err != nil {
+			return err
+		}
+	}
+	groups, err := client.GetLayerGroups(workspace)
+	if err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if g.Name == name {
+			return nil
+		}
+	}
+	return notFound("layer group %q not found", name)
+}
+
+func init() {
+	gsLayersPublishCmd.Flags().StringVarP(&layerStore, "store", "s", "", "data or coverage store holding the data")
+	gsLayerGroupsCreateCmd.Flags().StringSliceVarP(&lgLayers, "layers", "l", nil, "layers in drawing order, as workspace:layer or layer")
+	gsLayerGroupsCreateCmd.Flags().StringVar(&lgTitle, "title", "", "title of the group")
+	gsLayerGroupsCreateCmd.Flags().StringVar(&lgMode, "mode", "single", "mode: single, named, container or eo")
+
+	gsLayersCmd.AddCommand(gsLayersListCmd, gsLayersPublishCmd, setLayerEnabledCmd(true), setLayerEnabledCmd(false), gsLayersDeleteCmd)
+	gsLayerGroupsCmd.AddCommand(gsLayerGroupsListCmd, gsLayerGroupsGetCmd, gsLayerGroupsCreateCmd, gsLayerGroupsDeleteCmd)
+	gsCmd.AddCommand(gsLayersCmd, gsLayerGroupsCmd)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+	"github.com/spf13/cobra"
+)
+
+type styleRow struct {
+	Workspace string `json:"workspace,omitempty"`
+	Name      string `json:"name"`
+	Format    string `json:"format,omitempty"`
+}
+
+var styleFormat string
+
+var gsStylesCmd = &cobra.Command{
+	Use:     "styles",
+	Aliases: []string{"style"},
+	Short:   "List, get and put styles",
+	Long: `Styles are named workspace:style, or style with --workspace. Styles without
+a workspace are global. Formats are sld, css and mbstyle.`,
+}
+
+var gsStylesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the styles of a workspace (--workspace) or the global ones",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if gsWorkspace != "" {
+			if err := requireWorkspace(client, gsWorkspace); err != nil {
+				return err
+			}
+		}
+		styles, err := client.GetStyles(gsWorkspace)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]styleRow, len(styles))
+		for i, s := range styles {
+			rows[i] = styleRow{Workspace: gsWorkspace, Name: s.Name, Format: s.Format}
+		}
+		return printOutput(cmd.OutOrStdout(), gsOutput, rows, []string{"WORKSPACE", "NAME", "FORMAT"}, func(i int) []string {
+			return []string{rows[i].Workspace, rows[i].Name, rows[i].Format}
+		}, len(rows))
+	},
+}
+
+var gsStylesGetCmd = &cobra.Command{
+	Use:   "get <style>",
+	Short: "Print a style's body to stdout",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name := optionalWorkspace(args[0])
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		style, err := findStyle(client, workspace, name)
+		if err != nil {
+			return err
+		}
+		if style == nil {
+			return notFound("style %q not found", args[0])
+		}
+
+		format := styleFormat
+		if format == "" {
+			format = style.Format
+		}
+		content, err := client.GetStyleContent(workspace, name, format)
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprint(out, content)
+		if !strings.HasSuffix(content, "\n") {
+			fmt.Fprintln(out)
+		}
+		return nil
+	},
+}
+
+var gsStylesPutCmd = &cobra.Command{
+	Use:   "put <style> <file>",
+	Short: "Create or replace a style from a file, or from stdin with -",
+	Long: `Create or replace a style from a file, or from stdin with -. The format is
+taken from --format, or else from the file extension (.css, .json for
+mbstyle, otherwise sld).`,
+	Args: usageArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace, name := optionalWorkspace(args[0])
+		format := styleFormat
+		if format == "" {
+			format = styleFormatOf(args[1])
+		}
+
+		var content []byte
+		var err error
+		if args[1] == "-" {
+			content, err = io.ReadAll(os.Stdin)
+		} else {
+			content, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read style: %w", err)
+		}
+
+		client, err := gsConnect()
+		if err != nil {
+			return err
+		}
+		if workspace != "" {
+			if err := requireWorkspace(client, workspace); err != nil {
+				return err
+			}
+		}
+		existing, err := findStyle(client, workspace, name)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil {
+			err = client.UpdateStyleContent(workspace, name, string(content), format)
+		} else {
+			err = client.CreateStyle(workspace, name, string(content), format)
+		}
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			printDone("Updated style %s", args[0])
+		} else {
+			printDone("Created style %s", args[0])
+		}
+		return nil
+	},
+}
+
+// findStyle returns the named style, or nil if there is none
+func findStyle(client *api.Client, workspace, name string) (*models.Style, error) {
+	styles, err := client.GetStyles(workspace)
+	if err != nil {
+		return nil, err
+	}
+	for i := range styles {
+		if styles[i].Name == name {
+			return &styles[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// styleFormatOf guesses a style format from a file name
+func styleFormatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".css":
+		return "css"
+	case ".json":
+		return "mbstyle"
+	}
+	return "sld"
+}
+
+func init() {
+	gsStylesGetCmd.Flags().StringVarP(&styleFormat, "format", "f", "", "format to fetch: sld, css or mbstyle (default the style's own)")
+	gsStylesPutCmd.Flags().StringVarP(&styleFormat, "format", "f", "", "format of the file: sld, css or mbstyle")
+
+	gsStylesCmd.AddCommand(gsStylesListCmd, gsStylesGetCmd, gsStylesPutCmd)
+	gsCmd.AddCommand(gsStylesCmd)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeoServerCommands(t *testing.T) {
+	geoserver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/about/version":
+			w.Write([]byte(`{"about":{"resource":[]}}`))
+		case "/rest/workspaces":
+			w.Write([]byte(`{"workspaces":{"workspace":[{"name":"topp"},{"name":"tiger"}]}}`))
+		case "/rest/workspaces/topp/layers":
+			w.Write([]byte(`{"layers":{"layer":[{"name":"states"}]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer geoserver.Close()
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv(connectionEnv, "")
+	configFile := filepath.Join(configHome, "kartoza-cloudbench", "config.json")
+	os.MkdirAll(filepath.Dir(configFile), 0700)
+	os.WriteFile(configFile, []byte(`{"connections":[{"id":"c1","name":"Local","url":"`+geoserver.URL+`","username":"admin","password":"geoserver"}]}`), 0600)
+
+	run := func(args ...string) (string, int) {
+		gsConnection, gsOutput, gsWorkspace = "", outputTable, ""
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(&out)
+		rootCmd.SetArgs(args)
+		err := rootCmd.Execute()
+		return out.String(), ExitCode(err)
+	}
+
+	out, code := run("gs", "-c", "local", "workspaces", "list", "-o", "json")
+	var workspaces []workspaceRow
+	if code != ExitOK || json.Unmarshal([]byte(out), &workspaces) != nil || len(workspaces) != 2 {
+		t.Fatalf("unexpected workspaces list (exit %d): %s", code, out)
+	}
+
+	out, code = run("gs", "layers", "list", "-w", "topp")
+	if code != ExitOK || !strings.Contains(out, "topp       states") {
+		t.Errorf("unexpected layers table (exit %d): %s", code, out)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"gs", "layers", "delete", "topp:rivers"}, ExitNotFound},
+		{[]string{"gs", "layers", "enable", "states"}, ExitUsage},
+		{[]string{"gs", "workspaces", "list", "-o", "yaml"}, ExitUsage},
+		{[]string{"gs", "-c", "missing", "workspaces", "list"}, ExitConfig},
+	} {
+		if _, code := run(tc.args...); code != tc.want {
+			t.Errorf("%v: expected exit code %d, got %d", tc.args, tc.want, code)
+		}
+	}
+
+	geoserver.Close()
+	if _, code := run("gs", "workspaces", "list"); code != ExitUnreachable {
+		t.Errorf("expected exit code %d for an unreachable server, got %d", ExitUnreachable, code)
+	}
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	manifestFile     string
+	manifestConn     string
+	manifestPrune    bool
+	manifestOutput   string
+	detailedExitCode bool
+	applyYes         bool
+)
+
+var planCmd = &cobra.Command{
+	Use:   "plan -f <manifest>",
+	Short: "Show the changes that would make a GeoServer match a manifest",
+	Long: `Compare a YAML manifest of workspaces, stores, styles, layers, layer groups
+and tile layers with a live GeoServer, and list what apply would create (+),
+update (~) and delete (-). Nothing is changed.
+
+Only settings the manifest states are compared. With --prune, stores, styles,
+layers and layer groups in the manifest's workspaces that it does not list
+are deleted; other workspaces and global styles are left alone.
+
+Data store parameters may reference ${secret:name} from the secrets backend
+or ${env:NAME}. Parameters holding secrets are not compared, and are sent
+whenever their store is created or updated.
+
+With --detailed-exitcode, plan exits with 6 when there are changes to make.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkOutputFormat(manifestOutput); err != nil {
+			return err
+		}
+		m, err := loadManifest(cmd)
+		if err != nil {
+			return err
+		}
+		plan, _, err := planManifest(m)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if manifestOutput == outputJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(plan); err != nil {
+				return err
+			}
+		} else {
+			plan.Write(out)
+		}
+		if detailedExitCode && len(plan.Changes) > 0 {
+			return withExitCode(ExitChanges, errChangesPending)
+		}
+		return nil
+	},
+}
+
+var applyCmd = &cobra.Command{
+	Use:   "apply -f <manifest>",
+	Short: "Make a GeoServer match a manifest",
+	Long: `Plan the changes that make a GeoServer match a YAML manifest, as plan does,
+and make them after confirmation. Use --yes to skip the confirmation, e.g.
+in CI. Changes are made in dependency order and stop at the first failure;
+running apply again picks up where it stopped.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m, err := loadManifest(cmd)
+		if err != nil {
+			return err
+		}
+		return applyManifest(cmd, m)
+	},
+}
+
+var pastTense = map[manifest.Action]string{
+	manifest.ActionCreate: "Created",
+	manifest.ActionUpdate: "Updated",
+	manifest.ActionDelete: "Deleted",
+}
+
+// errChangesPending is reported by plan --detailed-exitcode
+var errChangesPending = fmt.Errorf("the server does not match the manifest")
+
+// loadManifest loads the manifest given with --file
+func loadManifest(cmd *cobra.Command) (*manifest.Manifest, error) {
+	if manifestFile == "" {
+		return nil, withExitCode(ExitUsage, fmt.Errorf("a manifest is required: use --file"))
+	}
+	// Arguments are valid, so failures from here on are not usage errors
+	cmd.SilenceUsage = true
+
+	m, err := manifest.Load(manifestFile)
+	if err != nil {
+		return nil, withExitCode(ExitConfig, err)
+	}
+	return m, nil
+}
+
+// planManifest plans a manifest against the connection given with --conn
+func planManifest(m *manifest.Manifest) (*manifest.Plan, manifest.Catalog, error) {
+	client, cfg, err := connect(manifestConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := m.Plan(client, manifest.Options{Prune: manifestPrune, Secret: secretResolver(cfg)})
+	if err != nil {
+		return nil, nil, err
+	}
+	return plan, client, nil
+}
+
+// applyManifest plans a manifest, shows the plan and makes the changes once
+// confirmed
+func applyManifest(cmd *cobra.Command, m *manifest.Manifest) error {
+	plan, client, err := planManifest(m)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	plan.Write(out)
+	if len(plan.Changes) == 0 {
+		return nil
+	}
+	if !applyYes {
+		ok, err := confirm(cmd, "Apply these changes?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Fprintln(out, "Apply cancelled.")
+			return nil
+		}
+	}
+
+	err = plan.Apply(client, func(c manifest.Change) {
+		printDone("%s %s %s", pastTense[c.Action], c.Kind, c.Ref())
+	})
+	if err != nil {
+		return err
+	}
+	create, update, remove := plan.Counts()
+	fmt.Fprintf(out, "Apply complete: %d created, %d updated, %d deleted.\n", create, update, remove)
+	return nil
+}
+
+// secretResolver reads secrets from the configured backend, opening it on
+// first use so manifests without secrets work without one
+func secretResolver(cfg *config.Config) func(name string) (string, error) {
+	return func(name string) (string, error) {
+		backend, err := cfg.OpenSecrets()
+		if err != nil {
+			return "", err
+		}
+		return backend.Get(name)
+	}
+}
+
+// confirm asks a yes/no question on the terminal
+func confirm(cmd *cobra.Command, question string) (bool, error) {
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return false, withExitCode(ExitUsage, fmt.Errorf("stdin is not a terminal: use --yes to apply without confirmation"))
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func init() {
+	for _, c := range []*cobra.Command{planCmd, applyCmd} {
+		c.Flags().StringVarP(&manifestFile, "file", "f", "", "manifest file (YAML)")
+		c.Flags().StringVarP(&manifestConn, "conn", "c", "", "GeoServer connection name or ID (default $"+connectionEnv+" or the active connection)")
+		c.Flags().BoolVar(&manifestPrune, "prune", false, "delete objects in the manifest's workspaces that it does not list")
+	}
+	planCmd.Flags().StringVarP(&manifestOutput, "output", "o", outputTable, "output format: table or json")
+	planCmd.Flags().BoolVar(&detailedExitCode, "detailed-exitcode", false, "exit with 6 when there are changes to make")
+	applyCmd.Flags().BoolVarP(&applyYes, "yes", "y", false, "apply without asking for confirmation")
+
+	rootCmd.AddCommand(planCmd, applyCmd)
+}
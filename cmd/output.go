@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes returned by the command line, so scripts can tell failures apart
+const (
+	ExitOK          = 0 // Success
+	ExitFailure     = 1 // The operation failed, e.g. GeoServer rejected it
+	ExitUsage       = 2 // Bad arguments or flags
+	ExitConfig      = 3 // The config could not be loaded or has no such connection
+	ExitUnreachable = 4 // The server could not be reached or refused the credentials
+	ExitNotFound    = 5 // The named resource does not exist
+	ExitChanges     = 6 // plan --detailed-exitcode found changes to make
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// exitError is an error that ends the program with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode gives err an exit code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// notFound reports a missing resource
+func notFound(format string, args ...interface{}) error {
+	return withExitCode(ExitNotFound, fmt.Errorf(format, args...))
+}
+
+// ExitCode returns the exit code for an error returned by Execute
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return ExitFailure
+}
+
+// usageArgs marks errors from an argument validator as usage errors
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		return withExitCode(ExitUsage, validate(cmd, args))
+	}
+}
+
+// checkOutputFormat validates an --output flag value
+func checkOutputFormat(format string) error {
+	if format != outputTable && format != outputJSON {
+		return withExitCode(ExitUsage, fmt.Errorf("unknown output format %q (use %s or %s)", format, outputTable, outputJSON))
+	}
+	return nil
+}
+
+// printOutput writes rows as indented JSON, or as a table with one line per
+// row under the given column headers
+func printOutput(w io.Writer, format string, rows interface{}, headers []string, cells func(i int) []string, count int) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for i := 0; i < count; i++ {
+		fmt.Fprintln(tw, strings.Join(cells(i), "\t"))
+	}
+	return tw.Flush()
+}
+
+// printDone reports a completed change on stderr, keeping stdout for data
+func printDone(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
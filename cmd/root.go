@@ -5,6 +5,7 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
 	"github.com/kartoza/kartoza-cloudbench/internal/tui"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,8 @@ for browsing local geospatial files and managing GeoServer instances.
 
 Upload layers and styles to GeoServer and publish them as services.`,
 		RunE: runTUI,
+		// main prints the error, with an exit code from ExitCode
+		SilenceErrors: true,
 	}
 )
 
@@ -29,6 +32,9 @@ func Execute(version string) error {
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(ExitUsage, err)
+	})
 }
 
 var versionCmd = &cobra.Command{
@@ -40,7 +46,7 @@ var versionCmd = &cobra.Command{
 }
 
 func runTUI(cmd *cobra.Command, args []string) error {
-	cfg, err := config.Load()
+	cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
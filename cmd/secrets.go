@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var secretsBackend string
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage encrypted storage of connection credentials",
+	Long: `Connection passwords, S3 secret keys and GeoNode tokens can be stored in an
+encrypted vault (unlocked with a master passphrase) or in the desktop keyring
+instead of in plaintext in config.json.
+
+For headless use, set ` + secrets.PassphraseEnv + ` to the vault passphrase.`,
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where credentials are stored",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if cfg.SecretsBackend == "" {
+			fmt.Println("Backend: none (credentials are stored in plaintext in config.json)")
+			fmt.Println("Run 'secrets init' to encrypt them.")
+			return nil
+		}
+
+		fmt.Printf("Backend: %s\n", cfg.SecretsBackend)
+		if cfg.SecretsBackend == secrets.BackendVault {
+			vaultPath, err := config.SecretsVaultPath()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Vault:   %s\n", vaultPath)
+		}
+
+		backend, err := cfg.OpenSecrets()
+		if err != nil {
+			return err
+		}
+		if lister, ok := backend.(secrets.Lister); ok {
+			names, err := lister.List()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Secrets: %d\n", len(names))
+		}
+		return nil
+	},
+}
+
+var secretsInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Move plaintext credentials from config.json into a secrets backend",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if secretsBackend != secrets.BackendVault && secretsBackend != secrets.BackendKeyring {
+			return fmt.Errorf("unknown backend %q (use %s or %s)", secretsBackend, secrets.BackendVault, secrets.BackendKeyring)
+		}
+
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.SecretsBackend == secretsBackend {
+			return fmt.Errorf("credentials are already stored in the %s", secretsBackend)
+		}
+
+		var passphrase string
+		if secretsBackend == secrets.BackendVault {
+			vaultPath, err := config.SecretsVaultPath()
+			if err != nil {
+				return err
+			}
+			if secrets.Exists(vaultPath) {
+				return fmt.Errorf("a vault already exists at %s; remove it or unlock it with %s", vaultPath, secrets.PassphraseEnv)
+			}
+			if passphrase = os.Getenv(secrets.PassphraseEnv); passphrase == "" {
+				if passphrase, err = readNewPassphrase(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := cfg.EnableSecrets(secretsBackend, passphrase); err != nil {
+			return fmt.Errorf("failed to migrate credentials: %w", err)
+		}
+		fmt.Printf("Credentials moved to the %s\n", secretsBackend)
+		return nil
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Change the vault passphrase",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.SecretsBackend != secrets.BackendVault {
+			return fmt.Errorf("credentials are not stored in a vault")
+		}
+
+		backend, err := cfg.OpenSecrets()
+		if err != nil {
+			return err
+		}
+		vault, ok := backend.(*secrets.Vault)
+		if !ok {
+			return fmt.Errorf("credentials are not stored in a vault")
+		}
+
+		passphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+		if err := vault.Rotate(passphrase); err != nil {
+			return fmt.Errorf("failed to rotate passphrase: %w", err)
+		}
+		secrets.SetPassphrase(passphrase)
+		fmt.Println("Passphrase changed")
+		if os.Getenv(secrets.PassphraseEnv) != "" {
+			fmt.Printf("Remember to update %s\n", secrets.PassphraseEnv)
+		}
+		return nil
+	},
+}
+
+var secretsDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Write credentials back into config.json in plaintext",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		if cfg.SecretsBackend == "" {
+			return fmt.Errorf("credentials are already stored in config.json")
+		}
+		if err := cfg.DisableSecrets(); err != nil {
+			return err
+		}
+		fmt.Println("Credentials written to config.json")
+		return nil
+	},
+}
+
+func init() {
+	secretsInitCmd.Flags().StringVar(&secretsBackend, "backend", secrets.BackendVault, "secrets backend: vault or keyring")
+
+	secretsCmd.AddCommand(secretsStatusCmd)
+	secretsCmd.AddCommand(secretsInitCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+	secretsCmd.AddCommand(secretsDisableCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
+
+// readNewPassphrase asks for a new passphrase twice
+func readNewPassphrase() (string, error) {
+	passphrase, err := secrets.PromptPassphrase("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	confirm, err := secrets.PromptPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
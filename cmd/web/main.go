@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
 	"github.com/kartoza/kartoza-cloudbench/internal/webserver"
 )
 
@@ -14,10 +22,21 @@ var (
 	version = "dev"
 )
 
+// adminPasswordEnv holds the bootstrap admin password for headless setup
+const adminPasswordEnv = "CLOUDBENCH_ADMIN_PASSWORD"
+
 func main() {
 	// Parse command line flags
 	addr := flag.String("addr", ":8080", "HTTP server address")
 	showVersion := flag.Bool("version", false, "Show version information")
+	bootstrapAdmin := flag.String("bootstrap-admin", "", "Create the first admin user with this username (password from "+adminPasswordEnv+" or prompted)")
+	noAuth := flag.Bool("no-auth", false, "Disable authentication (only for local, single-user use)")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file (PEM); serves HTTPS with -tls-key")
+	tlsKey := flag.String("tls-key", "", "TLS private key file (PEM)")
+	selfSigned := flag.Bool("self-signed", false, "Serve HTTPS with a generated self-signed certificate (local use only)")
+	basePath := flag.String("base-path", "", "URL path prefix to serve under, e.g. /cloudbench behind a reverse proxy")
+	trustProxy := flag.Bool("trust-proxy", false, "Honour X-Forwarded-For/-Proto/-Host/-Prefix headers from a reverse proxy")
+	shutdownTimeout := flag.Duration("shutdown-timeout", 30*time.Second, "How long to wait for requests and running jobs on SIGTERM before cancelling them")
 	flag.Parse()
 
 	if *showVersion {
@@ -25,8 +44,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Load configuration
-	cfg, err := config.Load()
+	// Load configuration, unlocking the secrets vault if needed
+	cfg, err := config.LoadWithPrompt(secrets.PromptPassphrase)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
@@ -34,11 +53,120 @@ func main() {
 	// Create and start web server
 	server := webserver.New(cfg)
 
+	if *noAuth {
+		log.Println("WARNING: authentication is disabled; anyone who can reach the server has full access")
+	} else {
+		store, err := openUserStore(*bootstrapAdmin)
+		if err != nil {
+			log.Fatalf("Authentication setup failed: %v", err)
+		}
+		server.EnableAuth(store)
+	}
+
+	scheme := "http"
+	switch {
+	case *selfSigned && (*tlsCert != "" || *tlsKey != ""):
+		log.Fatal("-self-signed cannot be combined with -tls-cert and -tls-key")
+	case *selfSigned:
+		dir, err := config.TLSDir()
+		if err != nil {
+			log.Fatalf("Failed to find a directory for the certificate: %v", err)
+		}
+		certFile, keyFile, err := webserver.SelfSignedCertificate(dir, listenHosts(*addr))
+		if err != nil {
+			log.Fatalf("Failed to create a self-signed certificate: %v", err)
+		}
+		server.SetTLS(certFile, keyFile)
+		scheme = "https"
+	case *tlsCert != "" && *tlsKey != "":
+		server.SetTLS(*tlsCert, *tlsKey)
+		scheme = "https"
+	case *tlsCert != "" || *tlsKey != "":
+		log.Fatal("-tls-cert and -tls-key must be given together")
+	}
+	server.SetBasePath(*basePath)
+	server.SetTrustProxy(*trustProxy)
+
+	// Stop gracefully on SIGINT or SIGTERM, letting running jobs finish
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		signal.Stop(signals) // A second signal exits immediately
+		fmt.Printf("Shutting down, waiting up to %s for requests and running jobs...\n", *shutdownTimeout)
+		ctx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown: %v; running jobs were cancelled", err)
+		}
+		close(stopped)
+	}()
+
 	fmt.Printf("Starting Kartoza CloudBench %s\n", version)
-	fmt.Printf("Server listening on http://localhost%s\n", *addr)
+	prefix := strings.Trim(*basePath, "/")
+	if prefix != "" {
+		prefix = "/" + prefix
+	}
+	host := *addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	}
+	fmt.Printf("Server listening on %s://%s%s/\n", scheme, host, prefix)
 	fmt.Println("Press Ctrl+C to stop")
 
 	if err := server.Start(*addr); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
+	<-stopped
+}
+
+// listenHosts returns the host of a listen address, for the self-signed
+// certificate, when it names one
+func listenHosts(addr string) []string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil || host == "" || host == "0.0.0.0" || host == "::" {
+		return nil
+	}
+	return []string{host}
+}
+
+// openUserStore opens the user accounts, creating the first admin when asked
+func openUserStore(bootstrapAdmin string) (*auth.Store, error) {
+	path, err := config.UsersPath()
+	if err != nil {
+		return nil, err
+	}
+	store, err := auth.OpenStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if bootstrapAdmin != "" {
+		if store.HasUsers() {
+			return nil, fmt.Errorf("users already exist in %s; -bootstrap-admin only works on first run", path)
+		}
+		password := os.Getenv(adminPasswordEnv)
+		if password == "" {
+			if password, err = secrets.PromptPassphrase("Password for " + bootstrapAdmin + ": "); err != nil {
+				return nil, err
+			}
+			confirm, err := secrets.PromptPassphrase("Repeat password: ")
+			if err != nil {
+				return nil, err
+			}
+			if confirm != password {
+				return nil, fmt.Errorf("passwords do not match")
+			}
+		}
+		if _, err := store.CreateUser(bootstrapAdmin, password, auth.RoleAdmin, nil); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Created admin user %s\n", bootstrapAdmin)
+	}
+
+	if !store.HasUsers() {
+		return nil, fmt.Errorf("no user accounts exist; create the first one with -bootstrap-admin <username>, or use -no-auth for local single-user use")
+	}
+	return store, nil
 }
@@ -562,7 +562,7 @@
             web-dev() {
               echo "Starting web development servers..."
               echo "Frontend: cd web && npm run dev"
-              echo "Backend:  go run ./cmd/web"
+              echo "Backend:  go run ./cmd/web -no-auth"
             }
             export -f web-dev
 
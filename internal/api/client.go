@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 )
 
@@ -28,6 +30,8 @@ type Client struct {
 	username   string
 	password   string
 	httpClient *http.Client
+	offline    *OfflineStore  // Last-fetched catalog and queued changes; may be nil
+	cache      *ResponseCache // Recent GET responses; may be nil
 }
 
 // NewClient creates a new GeoServer API client from a Connection
@@ -59,10 +63,58 @@ func (c *Client) BaseURL() string {
 	return c.baseURL
 }
 
+// WrapTransport wraps the underlying HTTP transport, e.g. to rate limit requests.
+// All requests made by the client, including uploads and downloads, pass through it.
+func (c *Client) WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.httpClient.Transport = wrap(base)
+}
+
+// WithAudit returns a copy of the client that records every change it makes
+// as actor. The original client is unchanged, so a shared client can be
+// audited per user.
+func (c *Client) WithAudit(actor *audit.Actor) *Client {
+	if actor == nil {
+		return c
+	}
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	prefix := ""
+	if u, err := url.Parse(c.baseURL); err == nil {
+		prefix = u.Path
+	}
+
+	audited := *c
+	httpClient := *c.httpClient
+	httpClient.Transport = &audit.Transport{Base: base, Actor: actor, Service: audit.ServiceGeoServer, Prefix: prefix}
+	audited.httpClient = &httpClient
+	return &audited
+}
+
 // doRequest performs an HTTP request with authentication
 func (c *Client) doRequest(method, path string, body io.Reader, contentType string) (*http.Response, error) {
 	url := c.baseURL + "/rest" + path
 
+	server := c.cacheServer()
+	var generation uint64
+	if c.cache != nil && method == http.MethodGet {
+		if cached := c.cache.get(server, path); cached != nil {
+			return cached.response(), nil
+		}
+		generation = c.cache.generation(server)
+	}
+
+	var queued []byte
+	queueable := false
+	if c.offline != nil && method != http.MethodGet {
+		body, queued, queueable = bufferBody(body)
+	}
+
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -75,7 +127,14 @@ func (c *Client) doRequest(method, path string, body io.Reader, contentType stri
 	}
 	req.Header.Set("Accept", "application/json")
 
-	return c.httpClient.Do(req)
+	resp, err := c.httpClient.Do(req)
+	if c.offline != nil {
+		resp, err = c.offline.handle(method, path, contentType, queued, queueable, resp, err)
+	}
+	if c.cache != nil && method == http.MethodGet && err == nil {
+		resp = c.cache.keep(server, path, generation, resp)
+	}
+	return resp, err
 }
 
 // doJSONRequest performs a JSON request
@@ -192,17 +192,7 @@ func (c *Client) GetCoverageStoreDetails(workspace, storeName string) (*Coverage
 }
 
 func (c *Client) CreateCoverageStoreFromDetails(workspace string, details *CoverageStoreDetails) error {
-	body := map[string]interface{}{
-		"coverageStore": map[string]interface{}{
-			"name":        details.Name,
-			"description": details.Description,
-			"type":        details.Type,
-			"enabled":     details.Enabled,
-			"url":         details.URL,
-		},
-	}
-
-	resp, err := c.doJSONRequest("POST", fmt.Sprintf("/workspaces/%s/coveragestores", workspace), body)
+	resp, err := c.doJSONRequest("POST", fmt.Sprintf("/workspaces/%s/coveragestores", workspace), coverageStoreBody(details))
 	if err != nil {
 		return err
 	}
@@ -216,6 +206,36 @@ func (c *Client) CreateCoverageStoreFromDetails(workspace string, details *Cover
 	return nil
 }
 
+// UpdateCoverageStoreFromDetails replaces a coverage store's description,
+// enabled flag and URL
+func (c *Client) UpdateCoverageStoreFromDetails(workspace string, details *CoverageStoreDetails) error {
+	resp, err := c.doJSONRequest("PUT", fmt.Sprintf("/workspaces/%s/coveragestores/%s", workspace, details.Name), coverageStoreBody(details))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update coverage store: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
+// coverageStoreBody builds the REST representation of a coverage store
+func coverageStoreBody(details *CoverageStoreDetails) map[string]interface{} {
+	return map[string]interface{}{
+		"coverageStore": map[string]interface{}{
+			"name":        details.Name,
+			"description": details.Description,
+			"type":        details.Type,
+			"enabled":     details.Enabled,
+			"url":         details.URL,
+		},
+	}
+}
+
 func (c *Client) GetCoverageStoreConfig(workspace, storeName string) (*models.CoverageStoreConfig, error) {
 	config := &models.CoverageStoreConfig{
 		Name:      storeName,
@@ -256,6 +256,39 @@ func (c *Client) GetDataStoreDetails(workspace, storeName string) (*DataStoreDet
 }
 
 func (c *Client) CreateDataStoreFromDetails(workspace string, details *DataStoreDetails) error {
+	resp, err := c.doJSONRequest("POST", fmt.Sprintf("/workspaces/%s/datastores", workspace), dataStoreBody(details))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to create data store: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
+// UpdateDataStoreFromDetails replaces a data store's description, enabled
+// flag and connection parameters
+func (c *Client) UpdateDataStoreFromDetails(workspace string, details *DataStoreDetails) error {
+	resp, err := c.doJSONRequest("PUT", fmt.Sprintf("/workspaces/%s/datastores/%s", workspace, details.Name), dataStoreBody(details))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update data store: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
+// dataStoreBody builds the REST representation of a data store
+func dataStoreBody(details *DataStoreDetails) map[string]interface{} {
 	// Convert connection parameters map back to entry array format
 	entries := make([]map[string]string, 0, len(details.ConnectionParameters))
 	for key, value := range details.ConnectionParameters {
@@ -265,7 +298,7 @@ func (c *Client) CreateDataStoreFromDetails(workspace string, details *DataStore
 		})
 	}
 
-	body := map[string]interface{}{
+	return map[string]interface{}{
 		"dataStore": map[string]interface{}{
 			"name":        details.Name,
 			"description": details.Description,
@@ -276,19 +309,6 @@ func (c *Client) CreateDataStoreFromDetails(workspace string, details *DataStore
 			},
 		},
 	}
-
-	resp, err := c.doJSONRequest("POST", fmt.Sprintf("/workspaces/%s/datastores", workspace), body)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to create data store: %s", string(bodyBytes))
-	}
-
-	return nil
 }
 
 func (c *Client) GetLayersForDataStore(workspace, storeName string) ([]string, error) {
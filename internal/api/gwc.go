@@ -88,8 +88,14 @@ func (c *Client) GetGWCLayer(layerName string) (*models.GWCLayer, error) {
 			Enabled    bool   `json:"enabled"`
 			GridSubsets []struct {
 				GridSetName string `json:"gridSetName"`
+				ZoomStart   *int   `json:"zoomStart"`
+				ZoomStop    *int   `json:"zoomStop"`
 			} `json:"gridSubsets"`
-			MimeFormats []string `json:"mimeFormats"`
+			MimeFormats     []string `json:"mimeFormats"`
+			MetaWidthHeight []int    `json:"metaWidthHeight"`
+			Gutter          int      `json:"gutter"`
+			ExpireCache     int      `json:"expireCache"`
+			ExpireClients   int      `json:"expireClients"`
 		} `json:"GeoServerLayer"`
 	}
 
@@ -98,19 +104,80 @@ func (c *Client) GetGWCLayer(layerName string) (*models.GWCLayer, error) {
 	}
 
 	layer := &models.GWCLayer{
-		Name:        result.GeoServerLayer.Name,
-		Enabled:     result.GeoServerLayer.Enabled,
-		MimeFormats: result.GeoServerLayer.MimeFormats,
+		Name:          result.GeoServerLayer.Name,
+		Enabled:       result.GeoServerLayer.Enabled,
+		MimeFormats:   result.GeoServerLayer.MimeFormats,
+		Gutter:        result.GeoServerLayer.Gutter,
+		ExpireCache:   result.GeoServerLayer.ExpireCache,
+		ExpireClients: result.GeoServerLayer.ExpireClients,
 	}
 
-	// Extract grid set names
+	if len(result.GeoServerLayer.MetaWidthHeight) >= 2 {
+		layer.MetaWidth = result.GeoServerLayer.MetaWidthHeight[0]
+		layer.MetaHeight = result.GeoServerLayer.MetaWidthHeight[1]
+	}
+
+	// Extract grid set names and zoom ranges
 	for _, gs := range result.GeoServerLayer.GridSubsets {
 		layer.GridSubsets = append(layer.GridSubsets, gs.GridSetName)
+		layer.GridSubsetLevels = append(layer.GridSubsetLevels, models.GWCGridSubset{
+			GridSetName: gs.GridSetName,
+			ZoomStart:   gs.ZoomStart,
+			ZoomStop:    gs.ZoomStop,
+		})
 	}
 
 	return layer, nil
 }
 
+// UpdateGWCLayer creates or replaces the tile caching configuration of a layer
+func (c *Client) UpdateGWCLayer(layer models.GWCLayer) error {
+	gridSubsets := make([]map[string]interface{}, 0, len(layer.GridSubsets))
+	if len(layer.GridSubsetLevels) > 0 {
+		for _, gs := range layer.GridSubsetLevels {
+			subset := map[string]interface{}{"gridSetName": gs.GridSetName}
+			if gs.ZoomStart != nil {
+				subset["zoomStart"] = *gs.ZoomStart
+			}
+			if gs.ZoomStop != nil {
+				subset["zoomStop"] = *gs.ZoomStop
+			}
+			gridSubsets = append(gridSubsets, subset)
+		}
+	} else {
+		for _, name := range layer.GridSubsets {
+			gridSubsets = append(gridSubsets, map[string]interface{}{"gridSetName": name})
+		}
+	}
+
+	config := map[string]interface{}{
+		"name":          layer.Name,
+		"enabled":       layer.Enabled,
+		"mimeFormats":   layer.MimeFormats,
+		"gridSubsets":   gridSubsets,
+		"gutter":        layer.Gutter,
+		"expireCache":   layer.ExpireCache,
+		"expireClients": layer.ExpireClients,
+	}
+	if layer.MetaWidth > 0 && layer.MetaHeight > 0 {
+		config["metaWidthHeight"] = []int{layer.MetaWidth, layer.MetaHeight}
+	}
+
+	body := map[string]interface{}{"GeoServerLayer": config}
+	resp, err := c.doGWCJSONRequest("PUT", fmt.Sprintf("/layers/%s.json", layer.Name), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update GWC layer: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
 func (c *Client) SeedLayer(layerName string, request models.GWCSeedRequest) error {
 	// Build the seed request body in GWC format
 	body := map[string]interface{}{
@@ -305,6 +372,17 @@ func (c *Client) GetGWCGridSet(name string) (*models.GWCGridSet, error) {
 					Double []float64 `json:"double"`
 				} `json:"coords"`
 			} `json:"extent"`
+			Description  string `json:"description"`
+			AlignTopLeft bool   `json:"alignTopLeft"`
+			Resolutions  struct {
+				Double []float64 `json:"double"`
+			} `json:"resolutions"`
+			ScaleDenominators struct {
+				Double []float64 `json:"double"`
+			} `json:"scaleDenominators"`
+			MetersPerUnit    float64 `json:"metersPerUnit"`
+			PixelSize        float64 `json:"pixelSize"`
+			YCoordinateFirst bool    `json:"yCoordinateFirst"`
 		} `json:"gridSet"`
 	}
 
@@ -313,10 +391,17 @@ func (c *Client) GetGWCGridSet(name string) (*models.GWCGridSet, error) {
 	}
 
 	gridSet := &models.GWCGridSet{
-		Name:       result.GridSet.Name,
-		SRS:        fmt.Sprintf("EPSG:%d", result.GridSet.SRS.Number),
-		TileWidth:  result.GridSet.TileWidth,
-		TileHeight: result.GridSet.TileHeight,
+		Name:              result.GridSet.Name,
+		SRS:               fmt.Sprintf("EPSG:%d", result.GridSet.SRS.Number),
+		TileWidth:         result.GridSet.TileWidth,
+		TileHeight:        result.GridSet.TileHeight,
+		Description:       result.GridSet.Description,
+		AlignTopLeft:      result.GridSet.AlignTopLeft,
+		Resolutions:       result.GridSet.Resolutions.Double,
+		ScaleDenominators: result.GridSet.ScaleDenominators.Double,
+		MetersPerUnit:     result.GridSet.MetersPerUnit,
+		PixelSize:         result.GridSet.PixelSize,
+		YCoordinateFirst:  result.GridSet.YCoordinateFirst,
 	}
 
 	if len(result.GridSet.Extent.Coords.Double) >= 4 {
@@ -329,6 +414,57 @@ func (c *Client) GetGWCGridSet(name string) (*models.GWCGridSet, error) {
 	return gridSet, nil
 }
 
+// CreateOrUpdateGWCGridSet creates a grid set, or replaces it if it already exists
+func (c *Client) CreateOrUpdateGWCGridSet(gridSet models.GWCGridSet) error {
+	var srsNumber int
+	if _, err := fmt.Sscanf(gridSet.SRS, "EPSG:%d", &srsNumber); err != nil {
+		return fmt.Errorf("unsupported grid set SRS %q", gridSet.SRS)
+	}
+
+	config := map[string]interface{}{
+		"name":         gridSet.Name,
+		"srs":          map[string]interface{}{"number": srsNumber},
+		"tileWidth":    gridSet.TileWidth,
+		"tileHeight":   gridSet.TileHeight,
+		"alignTopLeft": gridSet.AlignTopLeft,
+		"extent": map[string]interface{}{
+			"coords": map[string]interface{}{
+				"double": []float64{gridSet.MinX, gridSet.MinY, gridSet.MaxX, gridSet.MaxY},
+			},
+		},
+		"yCoordinateFirst": gridSet.YCoordinateFirst,
+	}
+	if gridSet.Description != "" {
+		config["description"] = gridSet.Description
+	}
+	// A grid set is defined either by resolutions or by scale denominators
+	if len(gridSet.Resolutions) > 0 {
+		config["resolutions"] = map[string]interface{}{"double": gridSet.Resolutions}
+	} else {
+		config["scaleDenominators"] = map[string]interface{}{"double": gridSet.ScaleDenominators}
+	}
+	if gridSet.MetersPerUnit > 0 {
+		config["metersPerUnit"] = gridSet.MetersPerUnit
+	}
+	if gridSet.PixelSize > 0 {
+		config["pixelSize"] = gridSet.PixelSize
+	}
+
+	body := map[string]interface{}{"gridSet": config}
+	resp, err := c.doGWCJSONRequest("PUT", fmt.Sprintf("/gridsets/%s.json", gridSet.Name), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update grid set: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
 func (c *Client) GetGWCDiskQuota() (*models.GWCDiskQuota, error) {
 	resp, err := c.doGWCRequest("GET", "/diskquota.json", nil, "")
 	if err != nil {
@@ -382,6 +518,15 @@ func (c *Client) UpdateGWCDiskQuota(quota models.GWCDiskQuota) error {
 		},
 	}
 
+	// Global quota is formatted as "<value> <units>", e.g. "500 MiB"
+	var value, units string
+	if n, _ := fmt.Sscanf(quota.GlobalQuota, "%s %s", &value, &units); n == 2 {
+		body["gwcQuotaConfiguration"].(map[string]interface{})["globalQuota"] = map[string]interface{}{
+			"value": value,
+			"units": units,
+		}
+	}
+
 	resp, err := c.doGWCJSONRequest("PUT", "/diskquota.json", body)
 	if err != nil {
 		return err
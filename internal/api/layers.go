@@ -125,6 +125,50 @@ func (c *Client) GetCoverages(workspace, coveragestore string) ([]models.Coverag
 	return result.Coverages.Coverage, nil
 }
 
+// RenameFeatureType renames a feature type, and with it the layer publishing it
+func (c *Client) RenameFeatureType(workspace, datastore, oldName, newName string) error {
+	body := map[string]interface{}{
+		"featureType": map[string]interface{}{
+			"name": newName,
+		},
+	}
+
+	resp, err := c.doJSONRequest("PUT", fmt.Sprintf("/workspaces/%s/datastores/%s/featuretypes/%s", workspace, datastore, oldName), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to rename feature type: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
+// RenameCoverage renames a coverage, and with it the layer publishing it
+func (c *Client) RenameCoverage(workspace, coveragestore, oldName, newName string) error {
+	body := map[string]interface{}{
+		"coverage": map[string]interface{}{
+			"name": newName,
+		},
+	}
+
+	resp, err := c.doJSONRequest("PUT", fmt.Sprintf("/workspaces/%s/coveragestores/%s/coverages/%s", workspace, coveragestore, oldName), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to rename coverage: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
 func (c *Client) PublishFeatureType(workspace, dataStore, featureTypeName string) error {
 	body := map[string]interface{}{
 		"featureType": map[string]interface{}{
@@ -0,0 +1,463 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// maxOfflineBody caps the size of the responses kept for offline browsing
+// and of the changes queued while offline
+const maxOfflineBody = 4 << 20
+
+// Kept responses older than maxOfflineAge are pruned, as are the oldest ones
+// once a server's responses take more than maxOfflineSize. Pruning runs at
+// most every offlinePruneInterval.
+const (
+	maxOfflineAge        = 30 * 24 * time.Hour
+	maxOfflineSize       = 64 << 20
+	offlinePruneInterval = time.Hour
+)
+
+// offlineCatalogPaths are the top-level REST paths the catalog tree, its
+// info dialogs and downloads read. Only their responses are kept.
+var offlineCatalogPaths = map[string]bool{
+	"workspaces":  true,
+	"namespaces":  true,
+	"styles":      true,
+	"layers":      true,
+	"layergroups": true,
+}
+
+// CachedAtHeader is set on responses served from the offline store to when
+// the server last returned them (RFC 3339)
+const CachedAtHeader = "X-Cloudbench-Cached-At"
+
+// ErrQueuedOffline is returned for changes made while the server is
+// unreachable. The change is kept and can be replayed once it is back.
+var ErrQueuedOffline = errors.New("server unreachable, change queued to replay when it is back")
+
+// QueuedChange is a change made while the server was unreachable
+type QueuedChange struct {
+	Method      string    `json:"method"`
+	Path        string    `json:"path"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	QueuedAt    time.Time `json:"queued_at"`
+}
+
+// OfflineStatus describes whether a server is reachable and what is waiting
+// for it
+type OfflineStatus struct {
+	Offline  bool
+	Since    time.Time // When requests started failing
+	CachedAt time.Time // When the oldest response served while offline was fetched
+	Queued   int       // Changes waiting to be replayed
+}
+
+// storedResponse is a REST response kept for offline browsing
+type storedResponse struct {
+	Path        string    `json:"path"`
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	Fetched     time.Time `json:"fetched"`
+}
+
+// OfflineStore keeps the last successful REST reads of one server, so the
+// catalog can still be browsed when it is unreachable, and the changes made
+// meanwhile. Copies of a client share their store.
+type OfflineStore struct {
+	dir string
+
+	mu       sync.Mutex
+	since    time.Time
+	cachedAt time.Time
+	queued   int // Length of the queue, once read
+	counted  bool
+	pruned   time.Time
+
+	replayMu sync.Mutex
+}
+
+// NewOfflineStore returns a store kept in dir
+func NewOfflineStore(dir string) *OfflineStore {
+	return &OfflineStore{dir: dir}
+}
+
+// OpenOfflineStore returns the store of a connection in config.OfflineDir
+func OpenOfflineStore(connID string) (*OfflineStore, error) {
+	dir, err := config.OfflineDir()
+	if err != nil {
+		return nil, err
+	}
+	return NewOfflineStore(filepath.Join(dir, connID)), nil
+}
+
+// SetOffline makes the client keep its REST reads in store, answer reads
+// from it while the server is unreachable, and queue changes made meanwhile
+func (c *Client) SetOffline(store *OfflineStore) {
+	c.offline = store
+}
+
+// Offline returns the client's offline store, or nil
+func (c *Client) Offline() *OfflineStore {
+	return c.offline
+}
+
+// Status reports whether the server is unreachable and how many changes
+// are queued for it
+func (s *OfflineStore) Status() OfflineStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.counted {
+		s.readQueue()
+	}
+	return OfflineStatus{Offline: !s.since.IsZero(), Since: s.since, CachedAt: s.cachedAt, Queued: s.queued}
+}
+
+// Queued returns the changes waiting to be replayed, oldest first
+func (s *OfflineStore) Queued() ([]QueuedChange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readQueue()
+}
+
+// DiscardQueued drops the queued changes without replaying them
+func (s *OfflineStore) DiscardQueued() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.writeQueue(nil)
+}
+
+// Remove deletes everything kept for the server
+func (s *OfflineStore) Remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued, s.counted = 0, true
+	return os.RemoveAll(s.dir)
+}
+
+// handle records the outcome of a REST request. Successful reads of the
+// catalog are kept; when the server cannot be reached, reads are answered
+// from what was kept and changes are queued. Changes are only queued when
+// the connection could not be made, since after any later failure the
+// server may have applied them. Version and status probes are never
+// answered from the store, so they still tell whether the server is back.
+func (s *OfflineStore) handle(method, path, contentType string, body []byte, queueable bool, resp *http.Response, err error) (*http.Response, error) {
+	probe := strings.HasPrefix(path, "/about/")
+	if err == nil {
+		s.setOnline()
+		if method == http.MethodGet && resp.StatusCode == http.StatusOK && !probe && isCatalogPath(path) {
+			return s.keep(path, resp), nil
+		}
+		return resp, nil
+	}
+
+	s.setOffline()
+	switch {
+	case probe:
+		return nil, err
+	case method == http.MethodGet:
+		stored := s.load(path)
+		if stored == nil {
+			return nil, err
+		}
+		s.mu.Lock()
+		if s.cachedAt.IsZero() || stored.Fetched.Before(s.cachedAt) {
+			s.cachedAt = stored.Fetched
+		}
+		s.mu.Unlock()
+		return stored.response(), nil
+	case !queueable || !isDialError(err):
+		return nil, err
+	}
+
+	change := QueuedChange{Method: method, Path: path, ContentType: contentType, Body: body, QueuedAt: time.Now()}
+	if qerr := s.enqueue(change); qerr != nil {
+		return nil, fmt.Errorf("%w (could not queue the change: %v)", err, qerr)
+	}
+	return nil, fmt.Errorf("%w (%s %s)", ErrQueuedOffline, method, path)
+}
+
+func (s *OfflineStore) setOnline() {
+	s.mu.Lock()
+	s.since, s.cachedAt = time.Time{}, time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *OfflineStore) setOffline() {
+	s.mu.Lock()
+	if s.since.IsZero() {
+		s.since = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+// keep stores a successful read and returns the response with its body
+// intact. Bodies too large to keep are passed through unstored.
+func (s *OfflineStore) keep(path string, resp *http.Response) *http.Response {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxOfflineBody+1))
+	if err != nil || len(data) > maxOfflineBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	stored := storedResponse{Path: path, ContentType: resp.Header.Get("Content-Type"), Body: data, Fetched: time.Now()}
+	if encoded, err := json.Marshal(stored); err == nil {
+		writeFileAtomic(s.responseFile(path), encoded)
+	}
+
+	s.mu.Lock()
+	due := time.Since(s.pruned) > offlinePruneInterval
+	if due {
+		s.pruned = time.Now()
+	}
+	s.mu.Unlock()
+	if due {
+		s.prune()
+	}
+	return resp
+}
+
+// prune removes kept responses older than maxOfflineAge, then the oldest
+// ones until the rest fit in maxOfflineSize
+func (s *OfflineStore) prune() {
+	dir := filepath.Join(s.dir, "responses")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var files []os.FileInfo
+	var size int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		if time.Since(info.ModTime()) > maxOfflineAge {
+			os.Remove(filepath.Join(dir, info.Name()))
+			continue
+		}
+		files = append(files, info)
+		size += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+	for _, info := range files {
+		if size <= maxOfflineSize {
+			break
+		}
+		os.Remove(filepath.Join(dir, info.Name()))
+		size -= info.Size()
+	}
+}
+
+// isCatalogPath reports whether a REST path is under one of
+// offlineCatalogPaths
+func isCatalogPath(path string) bool {
+	top := strings.TrimPrefix(path, "/")
+	if i := strings.IndexAny(top, "/.?"); i >= 0 {
+		top = top[:i]
+	}
+	return offlineCatalogPaths[top]
+}
+
+// isDialError reports whether a request failed before reaching the server,
+// so it cannot have been applied
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// load returns the response kept for path, or nil
+func (s *OfflineStore) load(path string) *storedResponse {
+	data, err := os.ReadFile(s.responseFile(path))
+	if err != nil {
+		return nil
+	}
+	var stored storedResponse
+	if json.Unmarshal(data, &stored) != nil || stored.Path != path {
+		return nil
+	}
+	return &stored
+}
+
+// response rebuilds the HTTP response that was kept
+func (r *storedResponse) response() *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", r.ContentType)
+	header.Set(CachedAtHeader, r.Fetched.Format(time.RFC3339))
+	return newResponse(header, r.Body)
+}
+
+func (s *OfflineStore) responseFile(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(s.dir, "responses", hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *OfflineStore) queueFile() string {
+	return filepath.Join(s.dir, "queue.json")
+}
+
+// readQueue reads the queued changes; the caller holds s.mu
+func (s *OfflineStore) readQueue() ([]QueuedChange, error) {
+	data, err := os.ReadFile(s.queueFile())
+	if os.IsNotExist(err) {
+		s.queued, s.counted = 0, true
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var queue []QueuedChange
+	if err := json.Unmarshal(data, &queue); err != nil {
+		return nil, fmt.Errorf("failed to read offline queue: %w", err)
+	}
+	s.queued, s.counted = len(queue), true
+	return queue, nil
+}
+
+// writeQueue replaces the queued changes; the caller holds s.mu
+func (s *OfflineStore) writeQueue(queue []QueuedChange) error {
+	if len(queue) == 0 {
+		if err := os.Remove(s.queueFile()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.queued, s.counted = 0, true
+		return nil
+	}
+	data, err := json.MarshalIndent(queue, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.queueFile(), data); err != nil {
+		return err
+	}
+	s.queued, s.counted = len(queue), true
+	return nil
+}
+
+func (s *OfflineStore) enqueue(change QueuedChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.readQueue()
+	if err != nil {
+		return err
+	}
+	return s.writeQueue(append(queue, change))
+}
+
+// dropFirst removes the oldest queued change once it has been replayed
+func (s *OfflineStore) dropFirst() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, err := s.readQueue()
+	if err != nil || len(queue) == 0 {
+		return err
+	}
+	return s.writeQueue(queue[1:])
+}
+
+// writeFileAtomic writes data to a temporary file and renames it into place
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp" + strconv.Itoa(os.Getpid())
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// bufferBody reads a change's body so it can be queued if the server turns
+// out to be unreachable. Bodies too large to queue are passed through.
+func bufferBody(body io.Reader) (io.Reader, []byte, bool) {
+	if body == nil {
+		return nil, nil, true
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxOfflineBody+1))
+	if err != nil || len(data) > maxOfflineBody {
+		return io.MultiReader(bytes.NewReader(data), body), nil, false
+	}
+	return bytes.NewReader(data), data, true
+}
+
+// ReplayQueued sends the changes queued while the server was unreachable,
+// oldest first. It stops at the first change that fails, leaving it and
+// the rest queued, and returns how many were replayed.
+func (c *Client) ReplayQueued() (int, error) {
+	if c.offline == nil {
+		return 0, nil
+	}
+	c.offline.replayMu.Lock()
+	defer c.offline.replayMu.Unlock()
+
+	queue, err := c.offline.Queued()
+	if err != nil {
+		return 0, err
+	}
+	for i, change := range queue {
+		if err := c.replay(change); err != nil {
+			return i, fmt.Errorf("failed to replay %s %s: %w", change.Method, change.Path, err)
+		}
+		if err := c.offline.dropFirst(); err != nil {
+			return i + 1, err
+		}
+	}
+	return len(queue), nil
+}
+
+// replay sends one queued change, bypassing the offline store so a failure
+// is not queued again
+func (c *Client) replay(change QueuedChange) error {
+	var body io.Reader
+	if change.Body != nil {
+		body = bytes.NewReader(change.Body)
+	}
+	req, err := http.NewRequest(change.Method, c.baseURL+"/rest"+change.Path, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	if change.ContentType != "" {
+		req.Header.Set("Content-Type", change.ContentType)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		c.offline.setOffline()
+		return err
+	}
+	defer resp.Body.Close()
+	c.offline.setOnline()
+	if resp.StatusCode >= 400 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
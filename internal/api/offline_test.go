@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestOfflineStore(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/workspaces":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"workspaces": {"workspace": [{"name": "topp"}]}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/about/version":
+			w.WriteHeader(http.StatusOK)
+		case r.Method == http.MethodPost:
+			body, _ := io.ReadAll(r.Body)
+			mu.Lock()
+			received = append(received, r.URL.Path+" "+string(body))
+			mu.Unlock()
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	addr := server.Listener.Addr().String()
+
+	client := NewClientDirect(server.URL, "admin", "geoserver")
+	store := NewOfflineStore(t.TempDir())
+	client.SetOffline(store)
+
+	if _, err := client.GetWorkspaces(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	// Reads are answered from the store, changes are queued
+	workspaces, err := client.GetWorkspaces()
+	if err != nil || len(workspaces) != 1 || workspaces[0].Name != "topp" {
+		t.Fatalf("offline GetWorkspaces() = %v, %v", workspaces, err)
+	}
+	if err := client.TestConnection(); err == nil {
+		t.Error("TestConnection() succeeded offline")
+	}
+	err = client.CreateWorkspace("new")
+	if !errors.Is(err, ErrQueuedOffline) {
+		t.Fatalf("offline CreateWorkspace() error = %v, want ErrQueuedOffline", err)
+	}
+	if status := store.Status(); !status.Offline || status.Queued != 1 || status.CachedAt.IsZero() {
+		t.Errorf("Status() = %+v", status)
+	}
+	if _, err := client.GetLayers("topp"); err == nil {
+		t.Error("GetLayers() succeeded offline without a stored response")
+	}
+
+	// Bring the server back on the same address and replay
+	restarted := httptest.NewUnstartedServer(server.Config.Handler)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	restarted.Listener = listener
+	restarted.Start()
+	defer restarted.Close()
+
+	if err := client.TestConnection(); err != nil {
+		t.Fatal(err)
+	}
+	if store.Status().Offline {
+		t.Error("still offline after a successful request")
+	}
+	replayed, err := client.ReplayQueued()
+	if err != nil || replayed != 1 {
+		t.Fatalf("ReplayQueued() = %d, %v", replayed, err)
+	}
+	if len(received) != 1 || received[0] != `/rest/workspaces {"workspace":{"name":"new"}}` {
+		t.Errorf("server received %q", received)
+	}
+	if queued, _ := store.Queued(); len(queued) != 0 {
+		t.Errorf("%d changes still queued after replay", len(queued))
+	}
+}
+
+// TestOfflineStoreKeepsOnlyWhatIsSafe checks that only catalog reads are
+// kept and that a change the server may have received is not queued
+func TestOfflineStoreKeepsOnlyWhatIsSafe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			// The server reads the change, then the connection drops
+			io.ReadAll(r.Body)
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := NewClientDirect(server.URL, "admin", "geoserver")
+	store := NewOfflineStore(t.TempDir())
+	client.SetOffline(store)
+
+	for _, path := range []string{"/settings", "/workspaces/topp.json"} {
+		resp, err := client.doRequest(http.MethodGet, path, nil, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if store.load("/settings") != nil || store.load("/workspaces/topp.json") == nil {
+		t.Error("kept responses other than the catalog's")
+	}
+
+	err := client.CreateWorkspace("new")
+	if err == nil || errors.Is(err, ErrQueuedOffline) {
+		t.Fatalf("CreateWorkspace() with a dropped connection error = %v, want the failure itself", err)
+	}
+	if queued, _ := store.Queued(); len(queued) != 0 {
+		t.Errorf("queued %d changes the server may have applied", len(queued))
+	}
+}
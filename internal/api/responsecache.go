@@ -0,0 +1,403 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// CacheResources are the REST collections the response cache can be given
+// separate TTLs for, under rest_cache_ttls in the config
+var CacheResources = []string{
+	"about", "coverages", "coveragestores", "datastores", "featuretypes", "layergroups",
+	"layers", "services", "settings", "styles", "workspaces",
+}
+
+// maxCachedBody caps the size of a cached response
+const maxCachedBody = 1 << 20
+
+// SharedCache is the response cache of every client the TUI and the web
+// server create. It caches nothing until configured.
+var SharedCache = NewResponseCache()
+
+// ResponseCache keeps REST GET responses in memory for a time that depends
+// on the resource. Changes made through a client drop the responses they
+// affect.
+type ResponseCache struct {
+	mu            sync.Mutex
+	ttl           time.Duration            // For resources without their own TTL
+	ttls          map[string]time.Duration // By resource, see CacheResources
+	entries       map[string]*cachedResponse
+	sweepAt       int               // Number of entries at which expired ones are removed
+	generations   map[string]uint64 // By server, advanced by every invalidation
+	cleared       uint64            // Advanced by every full clear
+	hits          uint64
+	misses        uint64
+	invalidations uint64
+}
+
+// cachedResponse is one REST response kept in memory
+type cachedResponse struct {
+	server      string // User and base URL
+	path        string // Without query or format extension, for invalidation
+	contentType string
+	body        []byte
+	expires     time.Time
+}
+
+// CacheStats counts how a response cache was used
+type CacheStats struct {
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	Invalidations uint64  `json:"invalidations"` // Responses dropped because of a change
+	Entries       int     `json:"entries"`
+	HitRate       float64 `json:"hit_rate"` // Hits per lookup, 0 before any lookup
+}
+
+// SetResponseCache makes the client answer GETs from cache while they are
+// fresh, and drop the cached responses its changes affect. Changes are
+// caught at the transport, so uploads invalidate too.
+func (c *Client) SetResponseCache(cache *ResponseCache) {
+	c.cache = cache
+	prefix := "/rest"
+	if u, err := url.Parse(c.baseURL); err == nil {
+		prefix = u.Path + "/rest"
+	}
+	server := c.cacheServer()
+	c.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &invalidator{base: base, cache: cache, server: server, prefix: prefix}
+	})
+}
+
+// invalidator drops the cached responses a request changes
+type invalidator struct {
+	base   http.RoundTripper
+	cache  *ResponseCache
+	server string
+	prefix string // URL path of the REST API, e.g. /geoserver/rest
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *invalidator) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		if path, ok := strings.CutPrefix(req.URL.Path, t.prefix); ok {
+			t.cache.invalidate(t.server, path)
+		}
+	}
+	return resp, err
+}
+
+// ClearCache drops the responses cached for the client's server, so the
+// next reads fetch the current catalog
+func (c *Client) ClearCache() {
+	if c.cache != nil {
+		c.cache.clearServer(c.cacheServer())
+	}
+}
+
+// Uncached returns a copy of the client that reads from the server itself,
+// past the response cache and the offline store. Its changes still
+// invalidate the cache.
+func (c *Client) Uncached() *Client {
+	uncached := *c
+	uncached.cache, uncached.offline = nil, nil
+	return &uncached
+}
+
+// cacheServer identifies the client's server and user in the cache, since
+// users can see different catalogs
+func (c *Client) cacheServer() string {
+	return c.username + "@" + c.baseURL
+}
+
+// NewResponseCache returns an empty cache that caches nothing until
+// configured
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{entries: make(map[string]*cachedResponse), sweepAt: 1024, generations: make(map[string]uint64)}
+}
+
+// SetTTLs sets how long responses are kept: ttl by default, and ttls by
+// resource. A TTL of 0 turns caching off for the resources it applies to.
+// Cached responses are dropped.
+func (rc *ResponseCache) SetTTLs(ttl time.Duration, ttls map[string]time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.ttl, rc.ttls = ttl, ttls
+	rc.entries = make(map[string]*cachedResponse)
+	rc.cleared++
+}
+
+// Configure sets the TTLs from rest_cache_ttl_secs and rest_cache_ttls
+func (rc *ResponseCache) Configure(cfg *config.Config) {
+	ttls := make(map[string]time.Duration, len(cfg.RESTCacheTTLs))
+	for resource, secs := range cfg.RESTCacheTTLs {
+		ttls[resource] = time.Duration(secs) * time.Second
+	}
+	rc.SetTTLs(time.Duration(cfg.RESTCacheTTLSecs)*time.Second, ttls)
+}
+
+// Enabled tells whether any resource is cached
+func (rc *ResponseCache) Enabled() bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.ttl > 0 {
+		return true
+	}
+	for _, ttl := range rc.ttls {
+		if ttl > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Stats returns the cache's counters
+func (rc *ResponseCache) Stats() CacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	stats := CacheStats{Hits: rc.hits, Misses: rc.misses, Invalidations: rc.invalidations, Entries: len(rc.entries)}
+	if lookups := rc.hits + rc.misses; lookups > 0 {
+		stats.HitRate = float64(rc.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// Clear drops every cached response
+func (rc *ResponseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.entries = make(map[string]*cachedResponse)
+	rc.cleared++
+}
+
+// generation returns a number that grows whenever responses of a server are
+// dropped. A GET response is only kept if it has not changed since the GET
+// was sent, so a read that raced a change cannot cache what it replaced.
+func (rc *ResponseCache) generation(server string) uint64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.cleared + rc.generations[server]
+}
+
+// ttlFor returns how long a response for path is kept. Version and status
+// probes are never cached, so they tell whether a server is up and how it is
+// doing.
+func (rc *ResponseCache) ttlFor(path string) time.Duration {
+	norm := normalizePath(path)
+	switch norm {
+	case "/about/version", "/about/status", "/about/system-status":
+		return 0
+	}
+	if ttl, ok := rc.ttls[resourceOf(norm)]; ok {
+		return ttl
+	}
+	return rc.ttl
+}
+
+// get returns the fresh response cached for a GET of path, or nil
+func (rc *ResponseCache) get(server, path string) *cachedResponse {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.ttlFor(path) <= 0 {
+		return nil
+	}
+	entry := rc.entries[cacheKey(server, path)]
+	if entry == nil || time.Now().After(entry.expires) {
+		rc.misses++
+		return nil
+	}
+	rc.hits++
+	return entry
+}
+
+// keep caches a successful GET response, sent at generation gen, and returns
+// it with its body intact. Responses served from the offline store, large
+// bodies and responses to GETs that raced a change are not cached.
+func (rc *ResponseCache) keep(server, path string, gen uint64, resp *http.Response) *http.Response {
+	rc.mu.Lock()
+	ttl := rc.ttlFor(path)
+	rc.mu.Unlock()
+	if ttl <= 0 || resp.StatusCode != http.StatusOK || resp.Header.Get(CachedAtHeader) != "" {
+		return resp
+	}
+	if resp.ContentLength > maxCachedBody {
+		return resp
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil || len(data) > maxCachedBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+		return resp
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.cleared+rc.generations[server] != gen {
+		return resp
+	}
+	if len(rc.entries) >= rc.sweepAt {
+		rc.sweep()
+	}
+	rc.entries[cacheKey(server, path)] = &cachedResponse{
+		server:      server,
+		path:        normalizePath(path),
+		contentType: resp.Header.Get("Content-Type"),
+		body:        data,
+		expires:     time.Now().Add(ttl),
+	}
+	return resp
+}
+
+// sweep removes expired entries; the caller holds rc.mu
+func (rc *ResponseCache) sweep() {
+	now := time.Now()
+	for key, entry := range rc.entries {
+		if now.After(entry.expires) {
+			delete(rc.entries, key)
+		}
+	}
+	rc.sweepAt = max(1024, 2*len(rc.entries))
+}
+
+// invalidate drops the responses a change to path may have made wrong: the
+// path itself, the collections above it, the resources below it, and the
+// layer lists a change in a workspace can affect
+func (rc *ResponseCache) invalidate(server, path string) {
+	changed := normalizePath(path)
+	related := relatedPaths(changed)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.generations[server]++
+	for key, entry := range rc.entries {
+		if entry.server != server {
+			continue
+		}
+		drop := within(entry.path, changed) || within(changed, entry.path)
+		for _, p := range related {
+			drop = drop || within(entry.path, p)
+		}
+		if drop {
+			delete(rc.entries, key)
+			rc.invalidations++
+		}
+	}
+}
+
+// clearServer drops every response cached for a server
+func (rc *ResponseCache) clearServer(server string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.generations[server]++
+	for key, entry := range rc.entries {
+		if entry.server == server {
+			delete(rc.entries, key)
+		}
+	}
+}
+
+// response rebuilds the cached HTTP response
+func (e *cachedResponse) response() *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", e.contentType)
+	return newResponse(header, e.body)
+}
+
+// newResponse builds a 200 response with body
+func newResponse(header http.Header, body []byte) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+	}
+}
+
+func cacheKey(server, path string) string {
+	return http.MethodGet + " " + server + path
+}
+
+// uploadPrefixes start the last path segment of GeoServer's upload endpoints
+var uploadPrefixes = []string{"file.", "external.", "url."}
+
+// normalizePath strips the query and format extension from a REST path, so
+// /workspaces/topp/styles/roads.sld and /workspaces/topp/styles/roads match.
+// An upload, e.g. /workspaces/topp/datastores/roads/file.shp, is a change to
+// the store it writes to, and the feature types or coverages it creates.
+func normalizePath(path string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if i := strings.LastIndexByte(path, '/'); i >= 0 {
+		for _, prefix := range uploadPrefixes {
+			if strings.HasPrefix(path[i+1:], prefix) {
+				return path[:i]
+			}
+		}
+	}
+	for _, ext := range []string{".json", ".xml", ".sld", ".html", ".zip"} {
+		path = strings.TrimSuffix(path, ext)
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+// within tells whether path is base or below it
+func within(path, base string) bool {
+	return path == base || strings.HasPrefix(path, base+"/")
+}
+
+// resourceOf returns the innermost REST collection of a path, e.g. layers
+// for /workspaces/topp/layers/roads
+func resourceOf(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		for _, r := range CacheResources {
+			if segments[i] == r {
+				return r
+			}
+		}
+	}
+	return ""
+}
+
+// relatedPaths returns the cached paths a change in a workspace can affect
+// besides its own: layers are published from feature types and coverages,
+// and are listed both globally and per workspace
+func relatedPaths(path string) []string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	var workspace, layer string
+	switch {
+	case len(segments) >= 2 && segments[0] == "workspaces":
+		workspace = segments[1]
+		if len(segments) >= 4 && segments[2] == "layers" {
+			layer = segments[3]
+		}
+	case len(segments) >= 2 && segments[0] == "layers":
+		if ws, name, ok := strings.Cut(segments[1], ":"); ok {
+			workspace, layer = ws, name
+		}
+	}
+	if workspace == "" {
+		return nil
+	}
+	related := []string{"/layers", "/workspaces/" + workspace + "/layers"}
+	if layer != "" {
+		related = append(related, "/layers/"+workspace+":"+layer, "/workspaces/"+workspace+"/layers/"+layer)
+	}
+	return related
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			return
+		}
+		gets.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/geoserver/rest/workspaces":
+			io.WriteString(w, `{"workspaces": {"workspace": [{"name": "topp"}]}}`)
+		case "/geoserver/rest/workspaces/topp/layers":
+			io.WriteString(w, `{"layers": {"layer": [{"name": "roads"}]}}`)
+		case "/geoserver/rest/workspaces/topp/datastores/roads/featuretypes":
+			io.WriteString(w, `{"featureTypes": {"featureType": [{"name": "roads"}]}}`)
+		case "/geoserver/rest/about/version":
+			io.WriteString(w, `{}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rc := NewResponseCache()
+	rc.SetTTLs(time.Minute, map[string]time.Duration{"workspaces": 0})
+	client := NewClientDirect(server.URL+"/geoserver", "admin", "geoserver")
+	client.SetResponseCache(rc)
+
+	fetch := func(name string, get func() error, wantGets int32) {
+		t.Helper()
+		before := gets.Load()
+		if err := get(); err != nil {
+			t.Fatal(err)
+		}
+		if got := gets.Load() - before; got != wantGets {
+			t.Errorf("%s made %d requests, want %d", name, got, wantGets)
+		}
+	}
+	layers := func() error { _, err := client.GetLayers("topp"); return err }
+	workspaces := func() error { _, err := client.GetWorkspaces(); return err }
+
+	fetch("first GetLayers", layers, 1)
+	fetch("second GetLayers", layers, 0)
+	fetch("GetWorkspaces with a TTL of 0", workspaces, 1)
+	fetch("GetWorkspaces again", workspaces, 1)
+	fetch("TestConnection", client.TestConnection, 1)
+	fetch("TestConnection again", client.TestConnection, 1)
+
+	// Updating a layer invalidates its workspace's layer list
+	if err := client.EnableLayer("topp", "roads", false); err != nil {
+		t.Fatal(err)
+	}
+	fetch("GetLayers after an update", layers, 1)
+
+	stats := rc.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Invalidations != 1 || stats.Entries != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+	if stats.HitRate < 0.33 || stats.HitRate > 0.34 {
+		t.Errorf("HitRate = %v, want 1/3", stats.HitRate)
+	}
+
+	// An upload into a store invalidates the store's feature types and the
+	// workspace's layer list
+	featureTypes := func() error { _, err := client.GetFeatureTypes("topp", "roads"); return err }
+	fetch("GetFeatureTypes", featureTypes, 1)
+	fetch("GetFeatureTypes again", featureTypes, 0)
+	fetch("GetLayers before an upload", layers, 0)
+	if err := client.UploadShapefileData("topp", "roads", []byte("PK")); err != nil {
+		t.Fatal(err)
+	}
+	fetch("GetFeatureTypes after an upload", featureTypes, 1)
+	fetch("GetLayers after an upload", layers, 1)
+
+	uncached := func() error { _, err := client.Uncached().GetLayers("topp"); return err }
+	fetch("GetLayers past the cache", uncached, 1)
+
+	// A read sent before a change and answered after it is not kept
+	cacheServer := client.cacheServer()
+	rc.Clear()
+	gen := rc.generation(cacheServer)
+	rc.invalidate(cacheServer, "/workspaces/topp/layers/roads")
+	stale := newResponse(http.Header{}, []byte(`{"layers": ""}`))
+	rc.keep(cacheServer, "/workspaces/topp/layers", gen, stale)
+	if rc.get(cacheServer, "/workspaces/topp/layers") != nil {
+		t.Error("kept a read that raced a change")
+	}
+}
@@ -278,6 +278,29 @@ func (c *Client) UpdateWorkspaceWithConfig(oldName string, config models.Workspa
 	return nil
 }
 
+// SetWorkspaceIsolated changes whether a workspace is isolated
+func (c *Client) SetWorkspaceIsolated(name string, isolated bool) error {
+	body := map[string]interface{}{
+		"workspace": map[string]interface{}{
+			"name":     name,
+			"isolated": isolated,
+		},
+	}
+
+	resp, err := c.doJSONRequest("PUT", fmt.Sprintf("/workspaces/%s", name), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to update workspace: %s", string(bodyBytes))
+	}
+
+	return nil
+}
+
 // DownloadWorkspace downloads a workspace configuration as JSON
 func (c *Client) DownloadWorkspace(name string) ([]byte, error) {
 	resp, err := c.doRequest("GET", fmt.Sprintf("/workspaces/%s", name), nil, "")
@@ -0,0 +1,99 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSummary is the longest before/after summary kept in a record
+const maxSummary = 500
+
+// Actor is who makes changes on one connection, and where records go. A nil
+// Actor records nothing, so clients without auditing need no special case.
+type Actor struct {
+	Log        *Log
+	User       string
+	Via        string
+	Connection string
+}
+
+// NewActor returns an actor recording to the default log
+func NewActor(user, via, connID string) *Actor {
+	return &Actor{Log: Default, User: user, Via: via, Connection: connID}
+}
+
+// Local returns the actor for changes made in the TUI
+func Local(connID string) *Actor {
+	return NewActor(LocalUser, "tui", connID)
+}
+
+// ForConnection returns a copy of the actor acting on another connection
+func (a *Actor) ForConnection(connID string) *Actor {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.Connection = connID
+	return &c
+}
+
+// Record appends a change to the log. err is the outcome of the change.
+func (a *Actor) Record(service, action, resource, before, after string, err error) {
+	if a == nil || a.Log == nil {
+		return
+	}
+	r := Record{
+		User:       a.User,
+		Via:        a.Via,
+		Service:    service,
+		Action:     action,
+		Connection: a.Connection,
+		Resource:   resource,
+		Before:     before,
+		After:      after,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	if appendErr := a.Log.Append(r); appendErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", appendErr)
+	}
+}
+
+// Summarize shortens a request or response body for a record. Text is kept,
+// with secrets redacted and whitespace collapsed; binary content is
+// described by its size.
+func Summarize(data []byte, total int64, contentType string) string {
+	if total == 0 {
+		return ""
+	}
+	if !isText(contentType) || !utf8.Valid(data) {
+		if contentType == "" {
+			contentType = "binary"
+		}
+		return fmt.Sprintf("%d bytes (%s)", total, contentType)
+	}
+	s := strings.Join(strings.Fields(Redact(string(data))), " ")
+	if len(s) > maxSummary || int64(len(data)) < total {
+		if len(s) > maxSummary {
+			s = s[:maxSummary]
+			for !utf8.ValidString(s) {
+				s = s[:len(s)-1]
+			}
+		}
+		s += fmt.Sprintf("… (%d bytes)", total)
+	}
+	return s
+}
+
+func isText(contentType string) bool {
+	ct := strings.ToLower(contentType)
+	for _, t := range []string{"json", "xml", "text/", "sld", "x-www-form-urlencoded"} {
+		if strings.Contains(ct, t) {
+			return true
+		}
+	}
+	return false
+}
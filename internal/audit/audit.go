@@ -0,0 +1,173 @@
+// Package audit records who created, changed or deleted what, across
+// GeoServer, S3, GeoNode, PostgreSQL services and sync, in an append-only
+// JSON-lines log.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// Actions recorded in the log
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Services whose changes are recorded
+const (
+	ServiceGeoServer = "geoserver"
+	ServiceS3        = "s3"
+	ServiceGeoNode   = "geonode"
+	ServicePostgres  = "postgres"
+)
+
+// LocalUser is the user recorded for changes made in the TUI
+const LocalUser = "tui-local"
+
+// Record is one audited change
+type Record struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user"`
+	Via        string    `json:"via"`     // web, tui or sync
+	Service    string    `json:"service"` // geoserver, s3, geonode or postgres
+	Action     string    `json:"action"`  // create, update or delete
+	Connection string    `json:"connection"`
+	Resource   string    `json:"resource"` // Path of the changed resource, e.g. /rest/workspaces/topp
+	Before     string    `json:"before,omitempty"`
+	After      string    `json:"after,omitempty"`
+	Error      string    `json:"error,omitempty"` // Set when the change failed
+}
+
+// Filter selects records from the log. Empty fields match everything.
+type Filter struct {
+	User       string
+	Via        string
+	Service    string
+	Action     string
+	Connection string
+	Resource   string // Substring of the resource path
+	Since      time.Time
+	Until      time.Time
+	Limit      int // Most recent records to return, 0 for all
+}
+
+// matches reports whether a record passes the filter
+func (f Filter) matches(r *Record) bool {
+	switch {
+	case f.User != "" && r.User != f.User,
+		f.Via != "" && r.Via != f.Via,
+		f.Service != "" && r.Service != f.Service,
+		f.Action != "" && r.Action != f.Action,
+		f.Connection != "" && r.Connection != f.Connection,
+		f.Resource != "" && !strings.Contains(r.Resource, f.Resource),
+		!f.Since.IsZero() && r.Time.Before(f.Since),
+		!f.Until.IsZero() && r.Time.After(f.Until):
+		return false
+	}
+	return true
+}
+
+// Log is an append-only JSON-lines audit log
+type Log struct {
+	mu   sync.Mutex
+	path string
+}
+
+// Open returns the log at path. The file is created on the first append.
+func Open(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the location of the log file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes a record to the end of the log
+func (l *Log) Append(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now().UTC()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit record: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create audit directory: %w", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// Query returns the records matching a filter, most recent first
+func (l *Log) Query(f Filter) ([]Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	records := []Record{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue // Skip a line torn by a crash rather than losing the whole log
+		}
+		if f.matches(&r) {
+			records = append(records, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.After(records[j].Time)
+	})
+	if f.Limit > 0 && len(records) > f.Limit {
+		records = records[:f.Limit]
+	}
+	return records, nil
+}
+
+// Default is the audit log next to the config file, shared by the TUI, the
+// web server and sync
+var Default *Log
+
+func init() {
+	path, err := config.AuditPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: audit log unavailable: %v\n", err)
+		return
+	}
+	Default = Open(path)
+}
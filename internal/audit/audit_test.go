@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransportRecordsChanges(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"layer": {"name": "roads"}}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	log := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	actor := &Actor{Log: log, User: "alice", Via: "web", Connection: "prod"}
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Actor: actor, Service: ServiceGeoServer}}
+
+	// Reads are not recorded
+	resp, err := client.Get(server.URL + "/rest/layers/roads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	req, _ := http.NewRequest(http.MethodDelete, server.URL+"/rest/layers/roads?recurse=true", nil)
+	if resp, err = client.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = client.Post(server.URL+"/rest/workspaces", "application/json", strings.NewReader(`{"workspace": {"name": "x"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	records, err := log.Query(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	deletes, _ := log.Query(Filter{Action: ActionDelete, User: "alice"})
+	if len(deletes) != 1 {
+		t.Fatalf("expected 1 delete, got %d", len(deletes))
+	}
+	d := deletes[0]
+	if d.Resource != "/rest/layers/roads" || d.Connection != "prod" || !strings.Contains(d.Before, "roads") || d.Error != "" {
+		t.Errorf("unexpected delete record: %+v", d)
+	}
+
+	creates, _ := log.Query(Filter{Action: ActionCreate})
+	if len(creates) != 1 || creates[0].Error != "HTTP 404" || !strings.Contains(creates[0].After, `"name": "x"`) {
+		t.Errorf("unexpected create record: %+v", creates)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, records); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 3 {
+		t.Errorf("expected a header and 2 CSV rows, got %d lines", lines)
+	}
+}
+
+func TestTransportRedactsSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<dataStore><connectionParameters><entry key="passwd">crypt1:a2V5</entry></connectionParameters></dataStore>`))
+		}
+	}))
+	defer server.Close()
+
+	log := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	actor := &Actor{Log: log, User: "alice", Via: "web", Connection: "prod"}
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Actor: actor, Service: ServiceGeoServer}}
+
+	body := `{"dataStore": {"name": "pg", "connectionParameters": {"entry": [
+		{"@key": "host", "$": "db.example.com"}, {"@key": "passwd", "$": "hunter2"}]}, "password": "s3cret"}}`
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/rest/workspaces/topp/datastores/pg", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	records, err := log.Query(Filter{})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected 1 record, got %d (%v)", len(records), err)
+	}
+	r := records[0]
+	for _, secret := range []string{"hunter2", "s3cret", "crypt1:a2V5"} {
+		if strings.Contains(r.Before+r.After, secret) {
+			t.Errorf("record keeps %q: before %q, after %q", secret, r.Before, r.After)
+		}
+	}
+	if !strings.Contains(r.After, "db.example.com") || !strings.Contains(r.After, `"$": "***"`) {
+		t.Errorf("unexpected after: %q", r.After)
+	}
+}
+
+func TestTransportFetchesBeforeForCatalogObjectsOnly(t *testing.T) {
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			fetched = append(fetched, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"name": "roads"}`))
+		}
+	}))
+	defer server.Close()
+
+	log := Open(filepath.Join(t.TempDir(), "audit.jsonl"))
+	actor := &Actor{Log: log, User: "alice", Via: "web", Connection: "prod"}
+	client := &http.Client{Transport: &Transport{Base: http.DefaultTransport, Actor: actor, Service: ServiceGeoServer}}
+
+	requests := []struct{ method, path, contentType string }{
+		{http.MethodPut, "/rest/workspaces/topp/datastores/roads/file.shp", "application/zip"},
+		{http.MethodPut, "/rest/workspaces/topp/coveragestores/dem/external.geotiff", "text/plain"},
+		{http.MethodPut, "/rest/styles/roads", "application/zip"},
+		{http.MethodDelete, "/rest/resource/data/dem.tif", ""},
+		{http.MethodPut, "/rest/layers/topp:roads", "application/json"},
+		{http.MethodDelete, "/gwc/rest/layers/topp:roads.json", ""},
+	}
+	for _, r := range requests {
+		req, _ := http.NewRequest(r.method, server.URL+r.path, strings.NewReader("data"))
+		if r.contentType != "" {
+			req.Header.Set("Content-Type", r.contentType)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	want := []string{"/rest/layers/topp:roads", "/gwc/rest/layers/topp:roads.json"}
+	if strings.Join(fetched, ",") != strings.Join(want, ",") {
+		t.Errorf("fetched %v before changing, want %v", fetched, want)
+	}
+	if records, _ := log.Query(Filter{}); len(records) != len(requests) {
+		t.Errorf("expected %d records, got %d", len(requests), len(records))
+	}
+}
@@ -0,0 +1,37 @@
+package audit
+
+import (
+	"encoding/csv"
+	"io"
+	"time"
+)
+
+// csvHeader names the columns written by WriteCSV
+var csvHeader = []string{"time", "user", "via", "service", "action", "connection", "resource", "before", "after", "error"}
+
+// WriteCSV writes records as CSV with a header row
+func WriteCSV(w io.Writer, records []Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.Time.Format(time.RFC3339),
+			r.User,
+			r.Via,
+			r.Service,
+			r.Action,
+			r.Connection,
+			r.Resource,
+			r.Before,
+			r.After,
+			r.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redacted replaces secret values in summaries
+const redacted = "***"
+
+// IsSecret reports whether a parameter holds a credential, by its key or by
+// GeoServer's encrypted value prefix
+func IsSecret(key, value string) bool {
+	key = strings.ToLower(key)
+	for _, word := range []string{"passw", "secret", "token", "credential"} {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return strings.HasPrefix(value, "crypt1:") || strings.HasPrefix(value, "crypt2:")
+}
+
+// Key and value forms that can carry a secret, with the indexes of the key
+// and value groups. Values may be cut off where a captured body ends.
+var secretForms = []struct {
+	re         *regexp.Regexp
+	key, value int
+}{
+	// GeoServer connection parameters: {"@key": "passwd", "$": "..."}, either way round
+	{regexp.MustCompile(`"@key"\s*:\s*"([^"\\]*)"\s*,\s*"\$"\s*:\s*"((?:[^"\\]|\\.)*)`), 1, 2},
+	{regexp.MustCompile(`"\$"\s*:\s*"((?:[^"\\]|\\.)*)"\s*,\s*"@key"\s*:\s*"([^"\\]*)"`), 2, 1},
+	// JSON members: "password": "..."
+	{regexp.MustCompile(`"([^"\\]*)"\s*:\s*"((?:[^"\\]|\\.)*)`), 1, 2},
+	// XML connection parameters and elements: <entry key="passwd">...</entry>, <password>...</password>
+	{regexp.MustCompile(`<entry key="([^"]*)">([^<]*)`), 1, 2},
+	{regexp.MustCompile(`<([\w:.-]+)>([^<]*)`), 1, 2},
+	// Form fields: password=...
+	{regexp.MustCompile(`(?:^|&)([^=&\s]*)=([^&]*)`), 1, 2},
+}
+
+// Redact replaces the values of secret parameters in a JSON, XML or form
+// body, so credentials sent to a server are not kept in the audit log
+func Redact(s string) string {
+	for _, form := range secretForms {
+		s = redactForm(s, form.re, form.key, form.value)
+	}
+	return s
+}
+
+func redactForm(s string, re *regexp.Regexp, key, value int) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(s, -1) {
+		vStart, vEnd := m[2*value], m[2*value+1]
+		v := s[vStart:vEnd]
+		if v == "" || v == redacted || !IsSecret(s[m[2*key]:m[2*key+1]], v) {
+			continue
+		}
+		b.WriteString(s[last:vStart])
+		b.WriteString(redacted)
+		last = vEnd
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Transport records the changes an HTTP API client makes. Reads pass
+// through untouched; for updates and deletes of catalog objects the object
+// is fetched first to summarize what it looked like before.
+type Transport struct {
+	Base    http.RoundTripper
+	Actor   *Actor
+	Service string
+	Prefix  string // Trimmed from URL paths to give the recorded resource
+}
+
+// action maps a request method to the recorded action, or "" for reads
+func action(method string) string {
+	switch method {
+	case http.MethodPost:
+		return ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return ActionUpdate
+	case http.MethodDelete:
+		return ActionDelete
+	}
+	return ""
+}
+
+// uploadPrefixes start the last path segment of GeoServer's upload endpoints,
+// e.g. datastores/roads/file.shp
+var uploadPrefixes = []string{"file.", "external.", "url."}
+
+// catalogObject reports whether a request changes a catalog object, such as a
+// layer or a style, whose JSON or XML representation can be fetched first.
+// Objects are named by the last path segment, optionally with a .json or .xml
+// suffix. Uploads, the resource store and other binary content are not.
+func catalogObject(req *http.Request) bool {
+	if req.Method != http.MethodDelete {
+		contentType := req.Header.Get("Content-Type")
+		if contentType != "" && !strings.Contains(contentType, "json") && !strings.Contains(contentType, "xml") {
+			return false
+		}
+	}
+
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for _, segment := range segments {
+		if segment == "resource" {
+			return false
+		}
+	}
+	name := segments[len(segments)-1]
+	for _, prefix := range uploadPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	switch path.Ext(name) {
+	case "", ".json", ".xml":
+		return true
+	}
+	return false
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	act := action(req.Method)
+	if act == "" || t.Actor == nil {
+		return t.Base.RoundTrip(req)
+	}
+
+	var before string
+	if act != ActionCreate && catalogObject(req) {
+		before = t.fetchBefore(req)
+	}
+
+	body := &capture{}
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		body.ReadCloser = req.Body
+		req.Body = body
+	}
+
+	resp, err := t.Base.RoundTrip(req)
+	if err == nil && resp.StatusCode >= 400 {
+		err = fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	after := Summarize(body.data, body.total, req.Header.Get("Content-Type"))
+	t.Actor.Record(t.Service, act, strings.TrimPrefix(req.URL.Path, t.Prefix), before, after, err)
+	if resp != nil && resp.StatusCode >= 400 {
+		err = nil // The caller handles error statuses itself
+	}
+	return resp, err
+}
+
+// fetchBefore summarizes the current state of the resource a request changes
+func (t *Transport) fetchBefore(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	get, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return ""
+	}
+	for _, h := range []string{"Authorization", "Cookie"} {
+		if v := req.Header.Get(h); v != "" {
+			get.Header.Set(h, v)
+		}
+	}
+	get.Header.Set("Accept", "application/json")
+
+	resp, err := t.Base.RoundTrip(get)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxSummary*2))
+	total := resp.ContentLength
+	if total < int64(len(data)) {
+		total = int64(len(data))
+	}
+	return Summarize(data, total, resp.Header.Get("Content-Type"))
+}
+
+// capture keeps the start of a request body and counts its size as it is sent
+type capture struct {
+	io.ReadCloser
+	data  []byte
+	total int64
+}
+
+func (c *capture) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if room := maxSummary*2 - len(c.data); room > 0 {
+		c.data = append(c.data, p[:min(n, room)]...)
+	}
+	c.total += int64(n)
+	return n, err
+}
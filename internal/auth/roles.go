@@ -0,0 +1,85 @@
+package auth
+
+import "fmt"
+
+// Role is what a user may do. Each role includes the powers of the ones
+// below it.
+type Role string
+
+const (
+	// RoleViewer can browse and preview
+	RoleViewer Role = "viewer"
+	// RoleEditor can also change layers, styles and other catalog content
+	RoleEditor Role = "editor"
+	// RoleAdmin can also manage connections, sync, deletes and users
+	RoleAdmin Role = "admin"
+)
+
+// Roles lists the roles from least to most powerful
+var Roles = []Role{RoleViewer, RoleEditor, RoleAdmin}
+
+func (r Role) rank() int {
+	for i, role := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return r.rank() >= 0
+}
+
+// Includes reports whether r has at least the powers of other
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && r.rank() >= other.rank()
+}
+
+// Grant raises a user's role on one connection, or one workspace of it
+type Grant struct {
+	Connection string `json:"connection"`
+	Workspace  string `json:"workspace,omitempty"` // Empty grants the role on the whole connection
+	Role       Role   `json:"role"`
+}
+
+// RoleFor returns the user's role for a connection and workspace: the
+// highest of the global role and any matching grants
+func (u *User) RoleFor(connID, workspace string) Role {
+	role := u.Role
+	if connID == "" {
+		return role
+	}
+	for _, g := range u.Grants {
+		if g.Connection != connID || (g.Workspace != "" && g.Workspace != workspace) {
+			continue
+		}
+		if g.Role.Includes(role) {
+			role = g.Role
+		}
+	}
+	return role
+}
+
+// Can reports whether the user has at least the needed role for a
+// connection and workspace. Either may be empty for global actions.
+func (u *User) Can(need Role, connID, workspace string) bool {
+	return u.RoleFor(connID, workspace).Includes(need)
+}
+
+// validateAccess checks a role and grants before they are stored
+func validateAccess(role Role, grants []Grant) error {
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	for _, g := range grants {
+		if g.Connection == "" {
+			return fmt.Errorf("grant needs a connection")
+		}
+		if !g.Role.Valid() {
+			return fmt.Errorf("unknown role %q in grant", g.Role)
+		}
+	}
+	return nil
+}
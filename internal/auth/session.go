@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"sync"
+	"time"
+)
+
+// SessionIdleTimeout ends sessions that have not been used for this long
+const SessionIdleTimeout = 12 * time.Hour
+
+// Session is a logged-in browser session
+type Session struct {
+	ID        string
+	UserID    string
+	CSRFToken string // Must accompany every state-changing request
+	LastSeen  time.Time
+}
+
+// ValidCSRF checks a request's CSRF token against the session's
+func (s *Session) ValidCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.CSRFToken)) == 1
+}
+
+// Sessions keeps browser sessions in memory. Restarting the server logs
+// everyone out.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// NewSessions creates an empty session set
+func NewSessions() *Sessions {
+	return &Sessions{sessions: make(map[string]*Session)}
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Create starts a session for a user
+func (s *Sessions) Create(userID string) (*Session, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	csrf, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{ID: id, UserID: userID, CSRFToken: csrf, LastSeen: time.Now()}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expire()
+	s.sessions[id] = session
+	return session, nil
+}
+
+// Get returns a live session and marks it as used
+func (s *Sessions) Get(id string) *Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	if time.Since(session.LastSeen) > SessionIdleTimeout {
+		delete(s.sessions, id)
+		return nil
+	}
+	session.LastSeen = time.Now()
+	copied := *session
+	return &copied
+}
+
+// Delete ends a session
+func (s *Sessions) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// DeleteUser ends all sessions of a user, e.g. after a password change
+func (s *Sessions) DeleteUser(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, session := range s.sessions {
+		if session.UserID == userID {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// expire drops idle sessions. The caller holds the lock.
+func (s *Sessions) expire() {
+	for id, session := range s.sessions {
+		if time.Since(session.LastSeen) > SessionIdleTimeout {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+type contextKey struct{}
+
+// WithUser returns a context carrying the authenticated user
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user, or nil
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(contextKey{}).(*User)
+	return user
+}
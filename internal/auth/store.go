@@ -0,0 +1,393 @@
+// Package auth provides local user accounts, browser sessions and personal
+// API tokens for the web server.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenPrefix starts every personal API token, so leaked tokens are easy to spot
+const TokenPrefix = "cbt_"
+
+// MinPasswordLength is the shortest accepted password
+const MinPasswordLength = 8
+
+var (
+	// ErrInvalidCredentials is returned for an unknown user, wrong password or unknown token
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUserExists is returned when creating a user whose name is taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrUserNotFound is returned for an unknown user ID or name
+	ErrUserNotFound = errors.New("user not found")
+	// ErrTokenNotFound is returned for an unknown token ID
+	ErrTokenNotFound = errors.New("token not found")
+	// ErrLastAdmin is returned when removing or demoting the only admin
+	ErrLastAdmin = errors.New("at least one admin is required")
+)
+
+// User is a local web server account
+type User struct {
+	ID           string     `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"password_hash"`
+	Role         Role       `json:"role"`
+	Grants       []Grant    `json:"grants,omitempty"` // Higher roles on specific connections or workspaces
+	CreatedAt    time.Time  `json:"created_at"`
+	Tokens       []APIToken `json:"tokens,omitempty"`
+}
+
+// APIToken is a personal token for scripted API access. Only a hash of the
+// token is stored; the token itself is shown once when it is created.
+type APIToken struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"` // First characters of the token, to tell tokens apart
+	Hash      string    `json:"hash"`   // SHA-256 of the token
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// storeFile is the on-disk format of the store
+type storeFile struct {
+	Users []User `json:"users"`
+}
+
+// Store holds the user accounts, persisted as a JSON file readable only by
+// the owner
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	users []User
+
+	dummyHash []byte // Compared against for unknown users, so timing does not reveal them
+}
+
+// OpenStore loads the user store at path. A missing file is an empty store.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read users: %w", err)
+	}
+	if err == nil {
+		var f storeFile
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("failed to parse users: %w", err)
+		}
+		s.users = f.Users
+	}
+
+	// Accounts created before roles existed were all admins
+	for i := range s.users {
+		if s.users[i].Role == "" {
+			s.users[i].Role = RoleAdmin
+		}
+	}
+
+	s.dummyHash, err = bcrypt.GenerateFromPassword([]byte("cloudbench"), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// save writes users as the store atomically and, once they are saved, makes
+// them the store's accounts. Callers change a copy of the accounts, see
+// copyUsers, so a failed save leaves the store as it was. The caller holds
+// the write lock.
+func (s *Store) save(users []User) error {
+	data, err := json.MarshalIndent(storeFile{Users: users}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal users: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create users directory: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write users: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save users: %w", err)
+	}
+	s.users = users
+	return nil
+}
+
+// copyUsers returns a copy of the accounts to change and save. The caller
+// holds the lock.
+func (s *Store) copyUsers() []User {
+	return append([]User(nil), s.users...)
+}
+
+// HasUsers reports whether any account exists
+func (s *Store) HasUsers() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.users) > 0
+}
+
+// Users returns a copy of all accounts
+func (s *Store) Users() []User {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	users := make([]User, len(s.users))
+	copy(users, s.users)
+	return users
+}
+
+// User returns the account with the given ID
+func (s *Store) User(id string) (*User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+func (s *Store) findByName(username string) int {
+	for i, u := range s.users {
+		if strings.EqualFold(u.Username, username) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Store) findByID(id string) int {
+	for i, u := range s.users {
+		if u.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CreateUser adds an account
+func (s *Store) CreateUser(username, password string, role Role, grants []Grant) (*User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("username is required")
+	}
+	if err := validateAccess(role, grants); err != nil {
+		return nil, err
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findByName(username) >= 0 {
+		return nil, ErrUserExists
+	}
+
+	user := User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		PasswordHash: hash,
+		Role:         role,
+		Grants:       grants,
+		CreatedAt:    time.Now().UTC(),
+	}
+	if err := s.save(append(s.copyUsers(), user)); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser removes an account and its tokens. The last admin cannot be
+// removed.
+func (s *Store) DeleteUser(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findByID(id)
+	if i < 0 {
+		return ErrUserNotFound
+	}
+	if s.users[i].Role == RoleAdmin && s.adminCount() == 1 {
+		return ErrLastAdmin
+	}
+	users := s.copyUsers()
+	return s.save(append(users[:i], users[i+1:]...))
+}
+
+// SetAccess changes an account's role and grants. The last admin cannot be
+// demoted.
+func (s *Store) SetAccess(id string, role Role, grants []Grant) (*User, error) {
+	if err := validateAccess(role, grants); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findByID(id)
+	if i < 0 {
+		return nil, ErrUserNotFound
+	}
+	if s.users[i].Role == RoleAdmin && role != RoleAdmin && s.adminCount() == 1 {
+		return nil, ErrLastAdmin
+	}
+	users := s.copyUsers()
+	users[i].Role = role
+	users[i].Grants = grants
+	if err := s.save(users); err != nil {
+		return nil, err
+	}
+	user := users[i]
+	return &user, nil
+}
+
+// adminCount returns the number of admins. The caller holds the lock.
+func (s *Store) adminCount() int {
+	n := 0
+	for _, u := range s.users {
+		if u.Role == RoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+// SetPassword replaces an account's password
+func (s *Store) SetPassword(id, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findByID(id)
+	if i < 0 {
+		return ErrUserNotFound
+	}
+	users := s.copyUsers()
+	users[i].PasswordHash = hash
+	return s.save(users)
+}
+
+// Authenticate checks a username and password
+func (s *Store) Authenticate(username, password string) (*User, error) {
+	s.mu.RLock()
+	i := s.findByName(username)
+	var user User
+	if i >= 0 {
+		user = s.users[i]
+	}
+	s.mu.RUnlock()
+
+	if i < 0 {
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateToken issues a personal API token for a user. The returned token
+// string cannot be retrieved again.
+func (s *Store) CreateToken(userID, name string) (string, *APIToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := TokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findByID(userID)
+	if i < 0 {
+		return "", nil, ErrUserNotFound
+	}
+
+	apiToken := APIToken{
+		ID:        uuid.New().String(),
+		Name:      strings.TrimSpace(name),
+		Prefix:    token[:len(TokenPrefix)+6],
+		Hash:      hashToken(token),
+		CreatedAt: time.Now().UTC(),
+	}
+	users := s.copyUsers()
+	users[i].Tokens = append(slices.Clip(users[i].Tokens), apiToken)
+	if err := s.save(users); err != nil {
+		return "", nil, err
+	}
+	return token, &apiToken, nil
+}
+
+// RevokeToken deletes one of a user's tokens
+func (s *Store) RevokeToken(userID, tokenID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.findByID(userID)
+	if i < 0 {
+		return ErrUserNotFound
+	}
+	for j, t := range s.users[i].Tokens {
+		if t.ID == tokenID {
+			users := s.copyUsers()
+			users[i].Tokens = slices.Delete(slices.Clone(users[i].Tokens), j, j+1)
+			return s.save(users)
+		}
+	}
+	return ErrTokenNotFound
+}
+
+// AuthenticateToken returns the owner of a personal API token
+func (s *Store) AuthenticateToken(token string) (*User, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, ErrInvalidCredentials
+	}
+	hash := hashToken(token)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, u := range s.users {
+		for _, t := range u.Tokens {
+			if subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hash)) == 1 {
+				return &u, nil
+			}
+		}
+	}
+	return nil, ErrInvalidCredentials
+}
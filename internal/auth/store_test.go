@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreUsersAndTokens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	store, err := OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.CreateUser("admin", "short", RoleAdmin, nil); err == nil {
+		t.Fatal("expected a short password to be rejected")
+	}
+	user, err := store.CreateUser("admin", "correct horse", RoleAdmin, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateUser("Admin", "correct horse", RoleViewer, nil); !errors.Is(err, ErrUserExists) {
+		t.Fatalf("expected ErrUserExists, got %v", err)
+	}
+
+	// Reopen to check the store was persisted
+	store, err = OpenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Authenticate("admin", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("expected ErrInvalidCredentials, got %v", err)
+	}
+	if _, err := store.Authenticate("admin", "correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, info, err := store.CreateToken(user.ID, "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := store.AuthenticateToken(token); err != nil || owner.ID != user.ID {
+		t.Fatalf("token did not authenticate its owner: %v", err)
+	}
+	if err := store.RevokeToken(user.ID, info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AuthenticateToken(token); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("revoked token still works: %v", err)
+	}
+}
+
+func TestStoreKeepsAccountsWhenSaveFails(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenStore(filepath.Join(dir, "users.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateUser("admin", "correct horse", RoleAdmin, nil); err != nil {
+		t.Fatal(err)
+	}
+	viewer, err := store.CreateUser("viewer", "correct horse", RoleViewer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, info, err := store.CreateToken(viewer.ID, "ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Saving fails once the store's directory is a file
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	store.path = filepath.Join(blocker, "users.json")
+
+	if err := store.DeleteUser(viewer.ID); err == nil {
+		t.Fatal("expected DeleteUser to fail")
+	}
+	if err := store.RevokeToken(viewer.ID, info.ID); err == nil {
+		t.Fatal("expected RevokeToken to fail")
+	}
+	if err := store.SetPassword(viewer.ID, "battery staple"); err == nil {
+		t.Fatal("expected SetPassword to fail")
+	}
+	if _, err := store.CreateUser("editor", "correct horse", RoleEditor, nil); err == nil {
+		t.Fatal("expected CreateUser to fail")
+	}
+
+	if users := store.Users(); len(users) != 2 {
+		t.Fatalf("failed changes altered the accounts: %+v", users)
+	}
+	if _, err := store.Authenticate("viewer", "correct horse"); err != nil {
+		t.Errorf("failed SetPassword changed the password: %v", err)
+	}
+	if _, err := store.AuthenticateToken(token); err != nil {
+		t.Errorf("failed RevokeToken revoked the token: %v", err)
+	}
+}
+
+func TestRoleForGrants(t *testing.T) {
+	user := &User{
+		Role: RoleViewer,
+		Grants: []Grant{
+			{Connection: "prod", Workspace: "roads", Role: RoleEditor},
+			{Connection: "staging", Role: RoleAdmin},
+		},
+	}
+
+	cases := []struct {
+		need            Role
+		conn, workspace string
+		want            bool
+	}{
+		{RoleViewer, "", "", true},
+		{RoleEditor, "", "", false},
+		{RoleEditor, "prod", "roads", true},
+		{RoleEditor, "prod", "rivers", false},
+		{RoleAdmin, "staging", "anything", true},
+	}
+	for _, c := range cases {
+		if got := user.Can(c.need, c.conn, c.workspace); got != c.want {
+			t.Errorf("Can(%s, %q, %q) = %v, want %v", c.need, c.conn, c.workspace, got, c.want)
+		}
+	}
+}
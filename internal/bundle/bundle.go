@@ -0,0 +1,350 @@
+// Package bundle exports and imports portable sets of CloudBench connections
+// and related settings, e.g. to onboard a teammate.
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/postgres"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// bundleVersion is the current bundle format version
+const bundleVersion = 1
+
+// Object kinds that can be exported
+const (
+	KindGeoServer = "geoserver"
+	KindS3        = "s3"
+	KindGeoNode   = "geonode"
+	KindPGService = "pgservice"
+	KindSync      = "sync"
+	KindQuery     = "query"
+)
+
+// AllKinds lists every exportable kind
+var AllKinds = []string{KindGeoServer, KindS3, KindGeoNode, KindPGService, KindSync, KindQuery}
+
+// Secret handling modes recorded in a bundle
+const (
+	SecretsRedacted  = "redacted"
+	SecretsEncrypted = "encrypted"
+)
+
+// Bundle is a portable export of connections and related settings. Secret
+// values are either removed or replaced by references into the encrypted
+// Secrets blob.
+type Bundle struct {
+	Version            int                        `json:"version"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	SecretsMode        string                     `json:"secrets_mode"`
+	Secrets            *secrets.Sealed            `json:"secrets,omitempty"`
+	Connections        []config.Connection        `json:"connections,omitempty"`
+	S3Connections      []config.S3Connection      `json:"s3_connections,omitempty"`
+	GeoNodeConnections []config.GeoNodeConnection `json:"geonode_connections,omitempty"`
+	PGServices         []PGService                `json:"pg_services,omitempty"`
+	SyncConfigs        []config.SyncConfiguration `json:"sync_configs,omitempty"`
+	SavedQueries       []config.SavedQuery        `json:"saved_queries,omitempty"`
+}
+
+// PGService is a pg_service.conf entry
+type PGService struct {
+	Name     string            `json:"name"`
+	Host     string            `json:"host,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	DBName   string            `json:"dbname,omitempty"`
+	User     string            `json:"user,omitempty"`
+	Password string            `json:"password,omitempty"`
+	SSLMode  string            `json:"sslmode,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// ExportOptions chooses what to export and how to protect secrets
+type ExportOptions struct {
+	Kinds      []string `json:"kinds,omitempty"`      // Kinds to export; empty exports all
+	IDs        []string `json:"ids,omitempty"`        // Only these objects (IDs, service names or "service/query"); empty exports all of the kinds
+	Passphrase string   `json:"passphrase,omitempty"` // Encrypts secrets; without it they are redacted
+}
+
+func (o ExportOptions) includes(kind, id string) bool {
+	if len(o.Kinds) > 0 && !slices.Contains(o.Kinds, kind) {
+		return false
+	}
+	return len(o.IDs) == 0 || slices.Contains(o.IDs, id)
+}
+
+// Export builds a bundle from the configuration. Sync configurations bring the
+// GeoServer connections they use, and saved queries their pg services.
+func Export(cfg *config.Config, opts ExportOptions) (*Bundle, error) {
+	for _, kind := range opts.Kinds {
+		if !slices.Contains(AllKinds, kind) {
+			return nil, fmt.Errorf("unknown kind: %s", kind)
+		}
+	}
+
+	b := &Bundle{Version: bundleVersion, CreatedAt: time.Now().UTC()}
+
+	for _, sc := range cfg.SyncConfigs {
+		if opts.includes(KindSync, sc.ID) {
+			b.SyncConfigs = append(b.SyncConfigs, sc)
+		}
+	}
+	for _, q := range cfg.SavedQueries {
+		if opts.includes(KindQuery, q.ServiceName+"/"+q.Name) {
+			b.SavedQueries = append(b.SavedQueries, q)
+		}
+	}
+
+	usedConnections := make(map[string]bool)
+	for _, sc := range b.SyncConfigs {
+		usedConnections[sc.SourceID] = true
+		for _, id := range sc.DestIDs {
+			usedConnections[id] = true
+		}
+	}
+	for _, conn := range cfg.Connections {
+		if opts.includes(KindGeoServer, conn.ID) || usedConnections[conn.ID] {
+			conn.IsActive = false
+			b.Connections = append(b.Connections, conn)
+		}
+	}
+	for _, conn := range cfg.S3Connections {
+		if opts.includes(KindS3, conn.ID) {
+			conn.IsActive = false
+			b.S3Connections = append(b.S3Connections, conn)
+		}
+	}
+	for _, conn := range cfg.GeoNodeConnections {
+		if opts.includes(KindGeoNode, conn.ID) {
+			conn.IsActive = false
+			b.GeoNodeConnections = append(b.GeoNodeConnections, conn)
+		}
+	}
+
+	usedServices := make(map[string]bool)
+	for _, q := range b.SavedQueries {
+		usedServices[q.ServiceName] = true
+	}
+	if services, err := postgres.ParsePGServiceFile(); err == nil {
+		for _, s := range services {
+			if opts.includes(KindPGService, s.Name) || usedServices[s.Name] {
+				b.PGServices = append(b.PGServices, PGService{
+					Name:     s.Name,
+					Host:     s.Host,
+					Port:     s.Port,
+					DBName:   s.DBName,
+					User:     s.User,
+					Password: s.Password,
+					SSLMode:  s.SSLMode,
+					Options:  s.Options,
+				})
+			}
+		}
+	}
+
+	if err := b.protectSecrets(opts.Passphrase); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Format is the file format of a bundle
+type Format string
+
+// Bundle formats. YAML bundles use the same field names as JSON ones.
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// FormatOf returns the format a file name's extension implies, or "" if it
+// implies none
+func FormatOf(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return ""
+}
+
+// ParseFormat checks a format given by name, e.g. on the command line
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatJSON, FormatYAML:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown bundle format %q (use json or yaml)", name)
+}
+
+// Read parses a bundle in the given format, or in the format its content
+// shows if format is empty: JSON bundles start with a brace
+func Read(r io.Reader, format Format) (*Bundle, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if format == "" {
+		format = FormatYAML
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = FormatJSON
+		}
+	}
+
+	if format == FormatYAML {
+		// Convert to JSON, so the bundle's JSON field names apply
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("failed to parse bundle: %w", err)
+		}
+	}
+
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if b.Version < 1 || b.Version > bundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", b.Version)
+	}
+	return &b, nil
+}
+
+// Write writes the bundle as indented JSON or as YAML
+func (b *Bundle) Write(w io.Writer, format Format) error {
+	if format != FormatYAML {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(b)
+	}
+
+	// YAML is a superset of JSON, so parsing the JSON keeps its field names
+	// and order. Dropping the JSON styles writes it as block YAML.
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	clearStyle(&doc)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// clearStyle resets the style of a YAML node and its children to the default
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// Count returns the number of objects in the bundle
+func (b *Bundle) Count() int {
+	return len(b.Connections) + len(b.S3Connections) + len(b.GeoNodeConnections) +
+		len(b.PGServices) + len(b.SyncConfigs) + len(b.SavedQueries)
+}
+
+// secretField is a secret value in the bundle and the name it is sealed under
+type secretField struct {
+	name  string
+	value *string
+}
+
+func (b *Bundle) secretFields() []secretField {
+	var fields []secretField
+	for i := range b.Connections {
+		conn := &b.Connections[i]
+		fields = append(fields, secretField{"geoserver/" + conn.ID + "/password", &conn.Password})
+	}
+	for i := range b.S3Connections {
+		conn := &b.S3Connections[i]
+		fields = append(fields, secretField{"s3/" + conn.ID + "/secret_key", &conn.SecretKey})
+	}
+	for i := range b.GeoNodeConnections {
+		conn := &b.GeoNodeConnections[i]
+		fields = append(fields,
+			secretField{"geonode/" + conn.ID + "/password", &conn.Password},
+			secretField{"geonode/" + conn.ID + "/token", &conn.Token},
+		)
+	}
+	for i := range b.PGServices {
+		svc := &b.PGServices[i]
+		fields = append(fields, secretField{"pgservice/" + svc.Name + "/password", &svc.Password})
+	}
+	return fields
+}
+
+// protectSecrets seals the secret values with passphrase, leaving references
+// in their place, or removes them when no passphrase is given
+func (b *Bundle) protectSecrets(passphrase string) error {
+	values := make(map[string]string)
+	for _, field := range b.secretFields() {
+		if *field.value == "" {
+			continue
+		}
+		if passphrase != "" {
+			values[field.name] = *field.value
+			*field.value = secrets.Ref(field.name)
+		} else {
+			*field.value = ""
+		}
+	}
+
+	if passphrase == "" {
+		b.SecretsMode = SecretsRedacted
+		return nil
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+	sealed, err := secrets.Seal(passphrase, data)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secrets: %w", err)
+	}
+	b.SecretsMode = SecretsEncrypted
+	b.Secrets = sealed
+	return nil
+}
+
+// revealSecrets replaces secret references with the sealed values. Without a
+// passphrase the references are cleared.
+func (b *Bundle) revealSecrets(passphrase string) error {
+	values := make(map[string]string)
+	if b.Secrets != nil && passphrase != "" {
+		data, err := b.Secrets.Open(passphrase)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return fmt.Errorf("failed to parse bundle secrets: %w", err)
+		}
+	}
+
+	for _, field := range b.secretFields() {
+		if secrets.IsRef(*field.value) {
+			*field.value = values[secrets.RefName(*field.value)]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Connections: []config.Connection{
+			{ID: "src", Name: "Staging", URL: "http://staging/geoserver", Username: "admin", Password: "s3cret", IsActive: true},
+			{ID: "dst", Name: "Production", URL: "http://prod/geoserver", Username: "admin", Password: "pr0d"},
+		},
+		SyncConfigs: []config.SyncConfiguration{
+			{ID: "sync", Name: "Promote", SourceID: "src", DestIDs: []string{"dst"}},
+		},
+	}
+}
+
+func roundTrip(t *testing.T, b *Bundle, format Format) *Bundle {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := b.Write(&buf, format); err != nil {
+		t.Fatal(err)
+	}
+	read, err := Read(&buf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return read
+}
+
+func TestExportRedactsSecrets(t *testing.T) {
+	b, err := Export(testConfig(), ExportOptions{Kinds: []string{KindSync}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sync configuration brings both of its connections
+	if len(b.SyncConfigs) != 1 || len(b.Connections) != 2 {
+		t.Fatalf("unexpected bundle contents: %+v", b)
+	}
+	for _, conn := range b.Connections {
+		if conn.Password != "" || conn.IsActive {
+			t.Fatalf("connection not sanitized: %+v", conn)
+		}
+	}
+}
+
+func TestImportEncryptedDuplicate(t *testing.T) {
+	b, err := Export(testConfig(), ExportOptions{Kinds: []string{KindGeoServer, KindSync}, Passphrase: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b = roundTrip(t, b, FormatJSON)
+
+	if _, err := Import(testConfig(), b, ImportOptions{Passphrase: "wrong"}); !errors.Is(err, secrets.ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	cfg := testConfig()
+	result, err := Import(cfg, b, ImportOptions{Passphrase: "shared", OnConflict: ConflictDuplicate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Added) != 3 || len(cfg.Connections) != 4 || len(cfg.SyncConfigs) != 2 {
+		t.Fatalf("unexpected import: %+v", result)
+	}
+
+	copied := cfg.SyncConfigs[1]
+	source := cfg.GetConnection(copied.SourceID)
+	if copied.SourceID == "src" || source == nil || source.Password != "s3cret" {
+		t.Fatalf("sync configuration not remapped to the imported connection: %+v", copied)
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	b, err := Export(testConfig(), ExportOptions{Passphrase: "shared"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := roundTrip(t, b, FormatJSON)
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "secrets_mode: encrypted\n") || !strings.Contains(out, "\nconnections:\n") || strings.HasPrefix(out, "{") {
+		t.Fatalf("not a block YAML bundle:\n%s", out)
+	}
+
+	for _, format := range []Format{FormatYAML, ""} {
+		read, err := Read(strings.NewReader(out), format)
+		if err != nil {
+			t.Fatalf("Read(%q): %v", format, err)
+		}
+		if !reflect.DeepEqual(read, want) {
+			t.Errorf("Read(%q) = %+v, want %+v", format, read, want)
+		}
+	}
+
+	if _, err := Import(testConfig(), roundTrip(t, b, FormatYAML), ImportOptions{Passphrase: "shared"}); err != nil {
+		t.Fatal(err)
+	}
+	if FormatOf("team.YML") != FormatYAML || FormatOf("team.json") != FormatJSON || FormatOf("team.bundle") != "" {
+		t.Error("FormatOf does not follow the file extension")
+	}
+}
@@ -0,0 +1,282 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/postgres"
+)
+
+// Conflict strategies for objects that already exist
+const (
+	ConflictSkip      = "skip"      // Keep the existing object
+	ConflictReplace   = "replace"   // Overwrite it, keeping existing secrets the bundle lacks
+	ConflictDuplicate = "duplicate" // Import under a new ID or name
+)
+
+// ImportOptions controls how a bundle is merged into the configuration
+type ImportOptions struct {
+	Passphrase string       `json:"passphrase,omitempty"`  // Decrypts the bundle's secrets
+	OnConflict string       `json:"on_conflict,omitempty"` // skip (default), replace or duplicate
+	Actor      *audit.Actor `json:"-"`                     // Recorded in the audit log for pg service changes
+}
+
+// ImportResult describes what an import changed
+type ImportResult struct {
+	Added    []string `json:"added"`
+	Replaced []string `json:"replaced"`
+	Skipped  []string `json:"skipped"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// importer holds the state of one import
+type importer struct {
+	cfg        *config.Config
+	onConflict string
+	actor      *audit.Actor
+	result     *ImportResult
+
+	connectionIDs map[string]string // Bundle GeoServer connection ID -> imported ID
+	serviceNames  map[string]string // Bundle pg service name -> imported name
+}
+
+// Import merges a bundle into the configuration. pg services are written to
+// pg_service.conf directly; the caller saves the configuration.
+func Import(cfg *config.Config, b *Bundle, opts ImportOptions) (*ImportResult, error) {
+	onConflict := opts.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictSkip
+	}
+	if onConflict != ConflictSkip && onConflict != ConflictReplace && onConflict != ConflictDuplicate {
+		return nil, fmt.Errorf("unknown conflict strategy: %s", onConflict)
+	}
+
+	if err := b.revealSecrets(opts.Passphrase); err != nil {
+		return nil, err
+	}
+
+	im := &importer{
+		cfg:           cfg,
+		onConflict:    onConflict,
+		actor:         opts.Actor,
+		result:        &ImportResult{Added: []string{}, Replaced: []string{}, Skipped: []string{}},
+		connectionIDs: make(map[string]string),
+		serviceNames:  make(map[string]string),
+	}
+	if b.SecretsMode == SecretsEncrypted && opts.Passphrase == "" {
+		im.warn("Bundle secrets are encrypted and no passphrase was given; credentials were not imported")
+	} else if b.SecretsMode == SecretsRedacted {
+		im.warn("Bundle secrets are redacted; enter credentials for new connections before using them")
+	}
+
+	im.importConnections(b.Connections)
+	im.importS3Connections(b.S3Connections)
+	im.importGeoNodeConnections(b.GeoNodeConnections)
+	im.importPGServices(b.PGServices)
+	im.importSyncConfigs(b.SyncConfigs)
+	im.importSavedQueries(b.SavedQueries)
+
+	return im.result, nil
+}
+
+func (im *importer) warn(format string, args ...interface{}) {
+	im.result.Warnings = append(im.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// resolve decides what to do with an object and records the outcome. It
+// returns the action taken: add, replace, skip or duplicate.
+func (im *importer) resolve(label string, exists bool) string {
+	if !exists {
+		im.result.Added = append(im.result.Added, label)
+		return "add"
+	}
+	switch im.onConflict {
+	case ConflictReplace:
+		im.result.Replaced = append(im.result.Replaced, label)
+		return ConflictReplace
+	case ConflictDuplicate:
+		im.result.Added = append(im.result.Added, label+" (copy)")
+		return ConflictDuplicate
+	default:
+		im.result.Skipped = append(im.result.Skipped, label)
+		return ConflictSkip
+	}
+}
+
+// keepSecret returns the existing value when the incoming one was redacted
+func keepSecret(incoming, existing string) string {
+	if incoming == "" {
+		return existing
+	}
+	return incoming
+}
+
+func (im *importer) importConnections(conns []config.Connection) {
+	for _, conn := range conns {
+		existing := im.cfg.GetConnection(conn.ID)
+		im.connectionIDs[conn.ID] = conn.ID
+
+		switch im.resolve(fmt.Sprintf("GeoServer connection '%s'", conn.Name), existing != nil) {
+		case "add":
+			im.cfg.AddConnection(conn)
+		case ConflictReplace:
+			conn.Password = keepSecret(conn.Password, existing.Password)
+			conn.IsActive = existing.IsActive
+			*existing = conn
+		case ConflictDuplicate:
+			conn.ID = uuid.New().String()
+			conn.Name += " (imported)"
+			im.connectionIDs[existing.ID] = conn.ID
+			im.cfg.AddConnection(conn)
+		}
+	}
+}
+
+func (im *importer) importS3Connections(conns []config.S3Connection) {
+	for _, conn := range conns {
+		existing := im.cfg.GetS3Connection(conn.ID)
+
+		switch im.resolve(fmt.Sprintf("S3 connection '%s'", conn.Name), existing != nil) {
+		case "add":
+			im.cfg.AddS3Connection(conn)
+		case ConflictReplace:
+			conn.SecretKey = keepSecret(conn.SecretKey, existing.SecretKey)
+			conn.IsActive = existing.IsActive
+			*existing = conn
+		case ConflictDuplicate:
+			conn.ID = uuid.New().String()
+			conn.Name += " (imported)"
+			im.cfg.AddS3Connection(conn)
+		}
+	}
+}
+
+func (im *importer) importGeoNodeConnections(conns []config.GeoNodeConnection) {
+	for _, conn := range conns {
+		existing := im.cfg.GetGeoNodeConnection(conn.ID)
+
+		switch im.resolve(fmt.Sprintf("GeoNode connection '%s'", conn.Name), existing != nil) {
+		case "add":
+			im.cfg.AddGeoNodeConnection(conn)
+		case ConflictReplace:
+			conn.Password = keepSecret(conn.Password, existing.Password)
+			conn.Token = keepSecret(conn.Token, existing.Token)
+			conn.IsActive = existing.IsActive
+			*existing = conn
+		case ConflictDuplicate:
+			conn.ID = uuid.New().String()
+			conn.Name += " (imported)"
+			im.cfg.AddGeoNodeConnection(conn)
+		}
+	}
+}
+
+func (im *importer) importPGServices(services []PGService) {
+	if len(services) == 0 {
+		return
+	}
+
+	existing := make(map[string]postgres.ServiceEntry)
+	if entries, err := postgres.ParsePGServiceFile(); err == nil {
+		for _, e := range entries {
+			existing[e.Name] = e
+		}
+	}
+
+	for _, svc := range services {
+		current, exists := existing[svc.Name]
+		im.serviceNames[svc.Name] = svc.Name
+
+		entry := postgres.ServiceEntry{
+			Name:     svc.Name,
+			Host:     svc.Host,
+			Port:     svc.Port,
+			DBName:   svc.DBName,
+			User:     svc.User,
+			Password: svc.Password,
+			SSLMode:  svc.SSLMode,
+			Options:  svc.Options,
+		}
+
+		switch im.resolve(fmt.Sprintf("pg service '%s'", svc.Name), exists) {
+		case ConflictSkip:
+			continue
+		case ConflictReplace:
+			entry.Password = keepSecret(entry.Password, current.Password)
+			entry.Hidden = current.Hidden
+		case ConflictDuplicate:
+			entry.Name += "_imported"
+			im.serviceNames[svc.Name] = entry.Name
+		}
+
+		if err := postgres.SaveServiceEntry(entry, im.actor); err != nil {
+			im.warn("Failed to write pg service '%s': %v", entry.Name, err)
+		}
+	}
+}
+
+func (im *importer) importSyncConfigs(configs []config.SyncConfiguration) {
+	for _, sc := range configs {
+		// Point the configuration at the imported connections
+		sc.SourceID = im.connectionID(sc.SourceID)
+		destIDs := make([]string, len(sc.DestIDs))
+		for i, id := range sc.DestIDs {
+			destIDs[i] = im.connectionID(id)
+		}
+		sc.DestIDs = destIDs
+		if sc.Mappings != nil && len(sc.Mappings.DataStoreOverrides) > 0 {
+			mappings := *sc.Mappings
+			mappings.DataStoreOverrides = make(map[string]config.DataStoreParamOverride)
+			for id, o := range sc.Mappings.DataStoreOverrides {
+				mappings.DataStoreOverrides[im.connectionID(id)] = o
+			}
+			sc.Mappings = &mappings
+		}
+		sc.LastSyncedAt = ""
+
+		existing := im.cfg.GetSyncConfig(sc.ID)
+		switch im.resolve(fmt.Sprintf("sync configuration '%s'", sc.Name), existing != nil) {
+		case "add":
+			im.cfg.AddSyncConfig(sc)
+		case ConflictReplace:
+			im.cfg.UpdateSyncConfig(sc)
+		case ConflictDuplicate:
+			sc.ID = uuid.New().String()
+			sc.Name += " (imported)"
+			im.cfg.AddSyncConfig(sc)
+		}
+
+		if im.cfg.GetConnection(sc.SourceID) == nil {
+			im.warn("Sync configuration '%s' refers to a source connection that does not exist", sc.Name)
+		}
+	}
+}
+
+func (im *importer) importSavedQueries(queries []config.SavedQuery) {
+	for _, q := range queries {
+		if name, ok := im.serviceNames[q.ServiceName]; ok {
+			q.ServiceName = name
+		}
+
+		existing := im.cfg.GetQuery(q.ServiceName, q.Name)
+		switch im.resolve(fmt.Sprintf("saved query '%s' (%s)", q.Name, q.ServiceName), existing != nil) {
+		case "add":
+			im.cfg.SavedQueries = append(im.cfg.SavedQueries, q)
+		case ConflictReplace:
+			*existing = q
+		case ConflictDuplicate:
+			q.Name += " (imported)"
+			im.cfg.SavedQueries = append(im.cfg.SavedQueries, q)
+		}
+	}
+}
+
+// connectionID maps a bundle GeoServer connection ID to its imported ID
+func (im *importer) connectionID(id string) string {
+	if mapped, ok := im.connectionIDs[id]; ok {
+		return mapped
+	}
+	return id
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
@@ -40,11 +41,14 @@ type CacheEntry struct {
 	MetadataFile  string       `json:"metadata_file"`
 	DataFile      string       `json:"data_file,omitempty"`
 	StyleFormat   string       `json:"style_format,omitempty"`
+	Source        *SourceState `json:"source,omitempty"` // State of the resource on the source when cached
 }
 
 // Manager manages the local cache directory
 type Manager struct {
 	cacheDir string
+	mu       sync.Mutex
+	ttl      time.Duration
 }
 
 // NewManager creates a new cache manager
@@ -99,6 +103,7 @@ func (m *Manager) CacheWorkspace(client *api.Client, serverID, workspace string)
 		return nil, err
 	}
 
+	source := newSourceState(client, ResourceTypeWorkspace, workspace, workspace, data)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeWorkspace,
 		Workspace:    workspace,
@@ -107,6 +112,7 @@ func (m *Manager) CacheWorkspace(client *api.Client, serverID, workspace string)
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(data),
 		MetadataFile: metaFile,
+		Source:       source,
 	}
 
 	return entry, m.saveEntry(path, entry)
@@ -130,6 +136,7 @@ func (m *Manager) CacheDataStore(client *api.Client, serverID, workspace, storeN
 		return nil, err
 	}
 
+	source := newSourceState(client, ResourceTypeDataStore, workspace, storeName, data)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeDataStore,
 		Workspace:    workspace,
@@ -139,6 +146,7 @@ func (m *Manager) CacheDataStore(client *api.Client, serverID, workspace, storeN
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(data),
 		MetadataFile: metaFile,
+		Source:       source,
 	}
 
 	return entry, m.saveEntry(path, entry)
@@ -161,6 +169,7 @@ func (m *Manager) CacheFeatureType(client *api.Client, serverID, workspace, stor
 	if err := os.WriteFile(metaFile, metaData, 0644); err != nil {
 		return nil, err
 	}
+	source := newSourceState(client, ResourceTypeFeatureType, workspace, featureTypeName, metaData)
 
 	// Download actual data via WFS as shapefile
 	shapeData, err := client.DownloadLayerAsShapefile(workspace, featureTypeName)
@@ -175,6 +184,7 @@ func (m *Manager) CacheFeatureType(client *api.Client, serverID, workspace, stor
 			CachedAt:     time.Now(),
 			Checksum:     computeChecksum(metaData),
 			MetadataFile: metaFile,
+			Source:       source,
 		}
 		return entry, m.saveEntry(path, entry)
 	}
@@ -195,6 +205,7 @@ func (m *Manager) CacheFeatureType(client *api.Client, serverID, workspace, stor
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(combined),
 		MetadataFile: metaFile,
+		Source:       source,
 		DataFile:     dataFile,
 	}
 
@@ -219,6 +230,7 @@ func (m *Manager) CacheCoverageStore(client *api.Client, serverID, workspace, st
 		return nil, err
 	}
 
+	source := newSourceState(client, ResourceTypeCoverageStore, workspace, storeName, data)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeCoverageStore,
 		Workspace:    workspace,
@@ -228,6 +240,7 @@ func (m *Manager) CacheCoverageStore(client *api.Client, serverID, workspace, st
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(data),
 		MetadataFile: metaFile,
+		Source:       source,
 	}
 
 	return entry, m.saveEntry(path, entry)
@@ -250,6 +263,7 @@ func (m *Manager) CacheCoverage(client *api.Client, serverID, workspace, storeNa
 	if err := os.WriteFile(metaFile, metaData, 0644); err != nil {
 		return nil, err
 	}
+	source := newSourceState(client, ResourceTypeCoverage, workspace, coverageName, metaData)
 
 	// Download actual data via WCS as GeoTIFF
 	tiffData, err := client.DownloadCoverageAsGeoTIFF(workspace, coverageName)
@@ -264,6 +278,7 @@ func (m *Manager) CacheCoverage(client *api.Client, serverID, workspace, storeNa
 			CachedAt:     time.Now(),
 			Checksum:     computeChecksum(metaData),
 			MetadataFile: metaFile,
+			Source:       source,
 		}
 		return entry, m.saveEntry(path, entry)
 	}
@@ -284,6 +299,7 @@ func (m *Manager) CacheCoverage(client *api.Client, serverID, workspace, storeNa
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(combined),
 		MetadataFile: metaFile,
+		Source:       source,
 		DataFile:     dataFile,
 	}
 
@@ -320,6 +336,7 @@ func (m *Manager) CacheStyle(client *api.Client, serverID, workspace, styleName
 	// Note: style metadata is in the style info endpoint
 	metaFile := filepath.Join(path, "style-info.json")
 
+	source := newSourceState(client, ResourceTypeStyle, workspace, styleName, styleData)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeStyle,
 		Workspace:    workspace,
@@ -328,6 +345,7 @@ func (m *Manager) CacheStyle(client *api.Client, serverID, workspace, styleName
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(styleData),
 		MetadataFile: metaFile,
+		Source:       source,
 		DataFile:     dataFile,
 		StyleFormat:  format,
 	}
@@ -353,6 +371,7 @@ func (m *Manager) CacheLayerGroup(client *api.Client, serverID, workspace, group
 		return nil, err
 	}
 
+	source := newSourceState(client, ResourceTypeLayerGroup, workspace, groupName, data)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeLayerGroup,
 		Workspace:    workspace,
@@ -361,6 +380,7 @@ func (m *Manager) CacheLayerGroup(client *api.Client, serverID, workspace, group
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(data),
 		MetadataFile: metaFile,
+		Source:       source,
 	}
 
 	return entry, m.saveEntry(path, entry)
@@ -384,6 +404,7 @@ func (m *Manager) CacheLayer(client *api.Client, serverID, workspace, layerName
 		return nil, err
 	}
 
+	source := newSourceState(client, ResourceTypeLayer, workspace, layerName, data)
 	entry := &CacheEntry{
 		ResourceType: ResourceTypeLayer,
 		Workspace:    workspace,
@@ -392,6 +413,7 @@ func (m *Manager) CacheLayer(client *api.Client, serverID, workspace, layerName
 		CachedAt:     time.Now(),
 		Checksum:     computeChecksum(data),
 		MetadataFile: metaFile,
+		Source:       source,
 	}
 
 	return entry, m.saveEntry(path, entry)
@@ -400,9 +422,7 @@ func (m *Manager) CacheLayer(client *api.Client, serverID, workspace, layerName
 // GetCacheEntry retrieves a cache entry if it exists
 func (m *Manager) GetCacheEntry(serverID, workspace string, resType ResourceType, storeName, resourceName string) (*CacheEntry, error) {
 	path := m.resourcePath(serverID, workspace, resType, storeName, resourceName)
-	entryFile := filepath.Join(path, "cache-entry.json")
-
-	data, err := os.ReadFile(entryFile)
+	data, err := os.ReadFile(filepath.Join(path, entryFile))
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil // Not cached
@@ -418,29 +438,11 @@ func (m *Manager) GetCacheEntry(serverID, workspace string, resType ResourceType
 	return &entry, nil
 }
 
-// IsCacheValid checks if the cached resource matches the source
+// IsCacheValid checks if the cached resource matches the source; see
+// StaleReason for why it may not
 func (m *Manager) IsCacheValid(client *api.Client, entry *CacheEntry) (bool, error) {
-	if entry == nil {
-		return false, nil
-	}
-
-	// Read the cached metadata file and compute checksum
-	data, err := os.ReadFile(entry.MetadataFile)
-	if err != nil {
-		return false, nil // File missing, cache invalid
-	}
-
-	// If there's a data file, include it in checksum
-	if entry.DataFile != "" {
-		dataContent, err := os.ReadFile(entry.DataFile)
-		if err != nil {
-			return false, nil
-		}
-		data = append(data, dataContent...)
-	}
-
-	currentChecksum := computeChecksum(data)
-	return currentChecksum == entry.Checksum, nil
+	reason, err := m.StaleReason(client, entry)
+	return err == nil && reason == "", err
 }
 
 // ReadCachedData reads the cached data file
@@ -448,6 +450,7 @@ func (m *Manager) ReadCachedData(entry *CacheEntry) ([]byte, error) {
 	if entry.DataFile == "" {
 		return nil, fmt.Errorf("no data file in cache entry")
 	}
+	m.touch(entry)
 	return os.ReadFile(entry.DataFile)
 }
 
@@ -473,7 +476,7 @@ func (m *Manager) saveEntry(path string, entry *CacheEntry) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(filepath.Join(path, "cache-entry.json"), data, 0644)
+	return os.WriteFile(filepath.Join(path, entryFile), data, 0644)
 }
 
 // computeChecksum computes SHA256 checksum of data
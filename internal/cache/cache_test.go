@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+func TestStaleReason(t *testing.T) {
+	modified, maxx, count := "2024-01-01 10:00:00.0 UTC", 10, 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/workspaces/topp/datastores/db/featuretypes/roads":
+			fmt.Fprintf(w, `{"featureType": {"name": "roads", "dateModified": %q,
+				"nativeBoundingBox": {"minx": 0, "miny": 0, "maxx": %d, "maxy": 10}}}`, modified, maxx)
+		case "/topp/wfs":
+			fmt.Fprintf(w, `<wfs:FeatureCollection numberMatched="%d" numberReturned="0"/>`, count)
+		case "/wfs":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Write([]byte("PK\x03\x04shapefile"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := api.NewClient(&config.Connection{URL: server.URL})
+	m := &Manager{cacheDir: t.TempDir()}
+	entry, err := m.CacheFeatureType(client, "src", "topp", "db", "roads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Source == nil || entry.Source.FeatureCount != 5 || entry.Source.BBox != "0,0,10,10" {
+		t.Fatalf("source state = %+v", entry.Source)
+	}
+
+	check := func(want string) {
+		t.Helper()
+		reason, err := m.StaleReason(client, entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(reason, want) || (want == "") != (reason == "") {
+			t.Errorf("StaleReason() = %q, want %q", reason, want)
+		}
+	}
+	check("")
+
+	count = 6
+	check("feature count changed from 5 to 6")
+	count = 5
+
+	maxx = 20
+	check("bounding box changed")
+	maxx = 10
+
+	modified = "2024-02-01 10:00:00.0 UTC"
+	check("modified on the source")
+	modified = "2024-01-01 10:00:00.0 UTC"
+
+	m.SetTTL(time.Hour)
+	entry.CachedAt = time.Now().Add(-2 * time.Hour)
+	check("TTL")
+	entry.CachedAt = time.Now()
+
+	if err := os.WriteFile(entry.DataFile, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	check("cached files were modified")
+}
+
+func TestEvict(t *testing.T) {
+	m := &Manager{cacheDir: t.TempDir()}
+	add := func(server, name string, size int, cachedAgo, usedAgo time.Duration) *CacheEntry {
+		t.Helper()
+		path := m.resourcePath(server, "topp", ResourceTypeCoverage, "dem", name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		dataFile := path + "/data.tif"
+		if err := os.WriteFile(dataFile, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		entry := &CacheEntry{ResourceType: ResourceTypeCoverage, Workspace: "topp", StoreName: "dem", ResourceName: name,
+			SourceServer: server, CachedAt: time.Now().Add(-cachedAgo), MetadataFile: path + "/coverage.json", DataFile: dataFile}
+		if err := m.saveEntry(path, entry); err != nil {
+			t.Fatal(err)
+		}
+		used := time.Now().Add(-usedAgo)
+		os.Chtimes(path+"/"+entryFile, used, used)
+		return entry
+	}
+	add("a", "old", 1000, 40*24*time.Hour, time.Hour)
+	add("a", "lru", 1000, time.Hour, 3*time.Hour)
+	recent := add("a", "recent", 1000, time.Hour, 2*time.Hour)
+	add("b", "pinned", 1000, 40*24*time.Hour, 5*time.Hour)
+
+	// Reading an entry makes it the most recently used
+	if _, err := m.ReadCachedData(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		CacheMaxSizeMB:  1,
+		CacheMaxAgeDays: 30,
+		SyncConfigs:     []config.SyncConfiguration{{SourceID: "b"}},
+	}
+	policy := ConfigPolicy(cfg)
+	policy.MaxSize = 3000 // Room for the pinned entry and one other
+
+	result, err := m.Evict(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Removed != 2 || result.Freed < 2000 {
+		t.Errorf("Evict() = %+v, want the old and least recently used entries removed", result)
+	}
+	for name, want := range map[string]bool{"old": false, "lru": false, "recent": true} {
+		entry, _ := m.GetCacheEntry("a", "topp", ResourceTypeCoverage, "dem", name)
+		if (entry != nil) != want {
+			t.Errorf("entry %s kept = %v, want %v", name, entry != nil, want)
+		}
+	}
+
+	usage, err := m.Usage(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 2 || usage[1].ServerID != "b" || usage[1].Pinned != 1 || usage[0].Entries != 1 {
+		t.Errorf("Usage() = %+v", usage)
+	}
+}
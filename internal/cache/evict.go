@@ -0,0 +1,243 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// JanitorInterval is how often RunJanitor enforces the cache limits
+const JanitorInterval = time.Hour
+
+// entryFile is the name of the file describing each cached resource. Its
+// modification time is when the resource was last used.
+const entryFile = "cache-entry.json"
+
+// Policy limits what the cache keeps
+type Policy struct {
+	MaxSize int64                        // Total bytes; 0 for no limit
+	MaxAge  time.Duration                // Since an entry was cached; 0 for no limit
+	Pinned  func(entry *CacheEntry) bool // Entries that are never evicted; may be nil
+}
+
+// ConfigPolicy returns the policy set by the config: cache_max_size_mb,
+// cache_max_age_days, and pins on what saved sync configurations read
+func ConfigPolicy(cfg *config.Config) Policy {
+	syncs := cfg.SyncConfigs
+	return Policy{
+		MaxSize: int64(cfg.CacheMaxSizeMB) << 20,
+		MaxAge:  time.Duration(cfg.CacheMaxAgeDays) * 24 * time.Hour,
+		Pinned: func(entry *CacheEntry) bool {
+			for _, sc := range syncs {
+				if sc.SourceID != entry.SourceServer {
+					continue
+				}
+				filter := sc.SyncOptions.WorkspaceFilter
+				if len(filter) == 0 || slices.Contains(filter, entry.Workspace) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// ServerUsage is how much of the cache holds one server's resources
+type ServerUsage struct {
+	ServerID string `json:"server_id"`
+	Size     int64  `json:"size"`
+	Entries  int    `json:"entries"`
+	Pinned   int    `json:"pinned"` // Entries kept regardless of the limits
+}
+
+// EvictResult reports what an eviction removed
+type EvictResult struct {
+	Removed int   `json:"removed"`
+	Freed   int64 `json:"freed"`
+}
+
+// storedEntry is a cached resource found on disk
+type storedEntry struct {
+	entry    CacheEntry
+	dir      string
+	size     int64
+	lastUsed time.Time
+	pinned   bool
+}
+
+// entries lists every cached resource with its size and last use
+func (m *Manager) entries(pinned func(*CacheEntry) bool) ([]storedEntry, error) {
+	var found []storedEntry
+	err := filepath.WalkDir(m.cacheDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || d.Name() != entryFile {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		e := storedEntry{dir: filepath.Dir(path), lastUsed: info.ModTime()}
+		if json.Unmarshal(data, &e.entry) != nil {
+			return nil
+		}
+		e.size = dirSize(e.dir)
+		e.pinned = pinned != nil && pinned(&e.entry)
+		found = append(found, e)
+		return nil
+	})
+	return found, err
+}
+
+// dirSize returns the size of the files directly in dir. Each resource has
+// its own directory, so nested resources are not counted twice.
+func dirSize(dir string) int64 {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return 0
+	}
+	var size int64
+	for _, f := range files {
+		if info, err := f.Info(); err == nil && !f.IsDir() {
+			size += info.Size()
+		}
+	}
+	return size
+}
+
+// Usage returns the cache's size per source server, sorted by server ID
+func (m *Manager) Usage(p Policy) ([]ServerUsage, error) {
+	found, err := m.entries(p.Pinned)
+	if err != nil {
+		return nil, err
+	}
+	byServer := make(map[string]*ServerUsage)
+	for _, e := range found {
+		u := byServer[e.entry.SourceServer]
+		if u == nil {
+			u = &ServerUsage{ServerID: e.entry.SourceServer}
+			byServer[e.entry.SourceServer] = u
+		}
+		u.Size += e.size
+		u.Entries++
+		if e.pinned {
+			u.Pinned++
+		}
+	}
+	usage := make([]ServerUsage, 0, len(byServer))
+	for _, u := range byServer {
+		usage = append(usage, *u)
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].ServerID < usage[j].ServerID })
+	return usage, nil
+}
+
+// Evict removes entries older than the policy's MaxAge, then the least
+// recently used entries until the cache fits in MaxSize. Pinned entries are
+// kept even if the cache stays over the limit.
+func (m *Manager) Evict(p Policy) (EvictResult, error) {
+	var result EvictResult
+	if p.MaxSize <= 0 && p.MaxAge <= 0 {
+		return result, nil
+	}
+	found, err := m.entries(p.Pinned)
+	if err != nil {
+		return result, err
+	}
+
+	var total int64
+	for _, e := range found {
+		total += e.size
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].lastUsed.Before(found[j].lastUsed) })
+
+	for _, e := range found {
+		expired := p.MaxAge > 0 && time.Since(e.entry.CachedAt) > p.MaxAge
+		overSize := p.MaxSize > 0 && total > p.MaxSize
+		if e.pinned || (!expired && !overSize) {
+			continue
+		}
+		if err := removeFiles(e.dir); err != nil {
+			return result, err
+		}
+		total -= e.size
+		result.Removed++
+		result.Freed += e.size
+		m.removeEmptyParents(e.dir)
+	}
+	return result, nil
+}
+
+// removeFiles removes the files of one cached resource, leaving any nested
+// resource directories alone
+func removeFiles(dir string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if !f.IsDir() {
+			if err := os.Remove(filepath.Join(dir, f.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// removeEmptyParents removes dir and its parents up to the cache directory
+// while they are empty
+func (m *Manager) removeEmptyParents(dir string) {
+	for dir != m.cacheDir && len(dir) > len(m.cacheDir) {
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// touch records that an entry was used, for least recently used eviction
+func (m *Manager) touch(entry *CacheEntry) {
+	now := time.Now()
+	os.Chtimes(filepath.Join(filepath.Dir(entry.MetadataFile), entryFile), now, now)
+}
+
+// RunJanitor enforces the cache limits now and then every interval until
+// stop is closed, reporting evictions and failures to logf unless it is nil.
+// The config is loaded each time, so changed limits and sync configurations
+// apply without a restart.
+func (m *Manager) RunJanitor(interval time.Duration, load func() (*config.Config, error), logf func(format string, args ...interface{}), stop <-chan struct{}) {
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if cfg, err := load(); err == nil {
+			if result, err := m.Evict(ConfigPolicy(cfg)); err != nil {
+				logf("Cache eviction failed: %v", err)
+			} else if result.Removed > 0 {
+				logf("Evicted %d cached resources (%d bytes)", result.Removed, result.Freed)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
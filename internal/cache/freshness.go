@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+)
+
+// SourceState is what the source server said about a resource when it was
+// cached, to tell later whether the cached copy is still current
+type SourceState struct {
+	MetadataChecksum string `json:"metadata_checksum"`
+	FeatureCount     int64  `json:"feature_count"`      // -1 when not a feature type or not counted
+	BBox             string `json:"bbox,omitempty"`     // Native bounding box as minx,miny,maxx,maxy
+	Modified         string `json:"modified,omitempty"` // dateModified from the REST metadata
+}
+
+// restInfo holds the change indicators of a REST resource description such
+// as {"featureType": {...}}
+type restInfo struct {
+	NativeBoundingBox *struct {
+		MinX float64 `json:"minx"`
+		MinY float64 `json:"miny"`
+		MaxX float64 `json:"maxx"`
+		MaxY float64 `json:"maxy"`
+	} `json:"nativeBoundingBox"`
+	DateModified string `json:"dateModified"`
+}
+
+// newSourceState records the state of a resource from its REST metadata.
+// Feature types are also counted through WFS.
+func newSourceState(client *api.Client, resType ResourceType, workspace, name string, metadata []byte) *SourceState {
+	state := &SourceState{MetadataChecksum: computeChecksum(metadata), FeatureCount: -1}
+
+	var wrapper map[string]json.RawMessage
+	if json.Unmarshal(metadata, &wrapper) == nil && len(wrapper) == 1 {
+		for _, raw := range wrapper {
+			var info restInfo
+			if json.Unmarshal(raw, &info) == nil {
+				state.Modified = info.DateModified
+				if b := info.NativeBoundingBox; b != nil {
+					state.BBox = fmt.Sprintf("%g,%g,%g,%g", b.MinX, b.MinY, b.MaxX, b.MaxY)
+				}
+			}
+		}
+	}
+
+	if resType == ResourceTypeFeatureType {
+		if count, err := client.GetFeatureCount(workspace, name); err == nil {
+			state.FeatureCount = count
+		}
+	}
+	return state
+}
+
+// fetchMetadata downloads the current REST metadata of a cached resource
+func fetchMetadata(client *api.Client, entry *CacheEntry) ([]byte, error) {
+	ws, store, name := entry.Workspace, entry.StoreName, entry.ResourceName
+	switch entry.ResourceType {
+	case ResourceTypeWorkspace:
+		return client.DownloadWorkspace(ws)
+	case ResourceTypeDataStore:
+		return client.DownloadDataStore(ws, name)
+	case ResourceTypeCoverageStore:
+		return client.DownloadCoverageStore(ws, name)
+	case ResourceTypeFeatureType:
+		return client.DownloadFeatureType(ws, store, name)
+	case ResourceTypeCoverage:
+		return client.DownloadCoverage(ws, store, name)
+	case ResourceTypeStyle:
+		data, _, err := client.DownloadStyle(ws, name)
+		return data, err
+	case ResourceTypeLayerGroup:
+		return client.DownloadLayerGroup(ws, name)
+	case ResourceTypeLayer:
+		return client.DownloadLayer(ws, name)
+	}
+	return nil, fmt.Errorf("unknown resource type %q", entry.ResourceType)
+}
+
+// SetTTL sets how long cached resources are used before they are
+// downloaded again, even if the source seems unchanged. Zero, the default,
+// keeps them until the source changes.
+func (m *Manager) SetTTL(ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ttl = ttl
+}
+
+// StaleReason returns why a cache entry no longer matches its source, or ""
+// when it can still be used. The entry is stale when it is older than the
+// TTL, when its files changed on disk, or when the source's REST metadata,
+// modification date, bounding box or feature count differ from when it was
+// cached. A nil client only checks the TTL and the local files.
+func (m *Manager) StaleReason(client *api.Client, entry *CacheEntry) (string, error) {
+	if entry == nil {
+		return "not cached", nil
+	}
+
+	m.mu.Lock()
+	ttl := m.ttl
+	m.mu.Unlock()
+	if age := time.Since(entry.CachedAt); ttl > 0 && age > ttl {
+		return fmt.Sprintf("cached %s ago, longer than the %s TTL", age.Round(time.Minute), ttl), nil
+	}
+
+	// Read the cached files and compare with the checksum taken when cached
+	data, err := os.ReadFile(entry.MetadataFile)
+	if err != nil {
+		return "cached metadata is missing", nil
+	}
+	if entry.DataFile != "" {
+		dataContent, err := os.ReadFile(entry.DataFile)
+		if err != nil {
+			return "cached data is missing", nil
+		}
+		data = append(data, dataContent...)
+	}
+	if computeChecksum(data) != entry.Checksum {
+		return "cached files were modified", nil
+	}
+
+	if client == nil {
+		return "", nil
+	}
+	if entry.Source == nil {
+		return "cached without source metadata to compare", nil
+	}
+	metadata, err := fetchMetadata(client, entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to check %s %s on the source: %w", entry.ResourceType, entry.ResourceName, err)
+	}
+	current := newSourceState(client, entry.ResourceType, entry.Workspace, entry.ResourceName, metadata)
+	cached := entry.Source
+
+	switch {
+	case current.Modified != cached.Modified && current.Modified != "":
+		return fmt.Sprintf("modified on the source at %s", current.Modified), nil
+	case current.BBox != cached.BBox:
+		return fmt.Sprintf("bounding box changed from %s to %s", cached.BBox, current.BBox), nil
+	case current.FeatureCount != cached.FeatureCount && current.FeatureCount >= 0:
+		return fmt.Sprintf("feature count changed from %d to %d", cached.FeatureCount, current.FeatureCount), nil
+	case current.MetadataChecksum != cached.MetadataChecksum:
+		return "REST metadata changed on the source", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,394 @@
+package cloudnative
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stateFile records, in each output directory, the checksums of the
+// conversions made there so unchanged sources are not converted again
+const stateFile = ".cloudbench-convert.json"
+
+// Outcomes of converting one file in a batch
+const (
+	BatchConverted = "converted"
+	BatchSkipped   = "skipped"
+	BatchFailed    = "failed"
+)
+
+// Converters and validators by target format. They are variables so tests
+// can run batches without GDAL, PDAL and ogr2ogr.
+var (
+	converters = map[ConversionType]func(ctx context.Context, inputPath, outputPath string, opts ConversionOptions, progress ProgressCallback) error{
+		ConversionCOG:        ConvertToCOG,
+		ConversionCOPC:       ConvertToCOPC,
+		ConversionGeoParquet: ConvertToGeoParquet,
+	}
+	validators = map[ConversionType]func(ctx context.Context, filePath string) (bool, string, error){
+		ConversionCOG:        ValidateCOG,
+		ConversionCOPC:       ValidateCOPC,
+		ConversionGeoParquet: ValidateGeoParquet,
+	}
+)
+
+// BatchOptions configures a batch conversion
+type BatchOptions struct {
+	Parallel  int // Conversions run at once; 1 when zero
+	Options   ConversionOptions
+	OutputDir string // Where outputs are written; next to each source when empty
+	Force     bool   // Convert even when the output is up to date
+	// Upload, when set, copies each valid output somewhere, e.g. to S3, and
+	// returns where it went. Outputs already uploaded there are not uploaded
+	// again.
+	Upload      func(ctx context.Context, outputPath string) (string, error)
+	Destination string // Where Upload copies outputs, to tell whether an output was already uploaded
+	// Done is called as each file finishes
+	Done func(result BatchFileResult)
+}
+
+// BatchFileResult is the outcome of converting one file
+type BatchFileResult struct {
+	Source       string         `json:"source"`
+	Output       string         `json:"output,omitempty"`
+	Target       ConversionType `json:"target,omitempty"`
+	Status       string         `json:"status"`           // converted, skipped or failed
+	Reason       string         `json:"reason,omitempty"` // Why the file was skipped or failed
+	Validation   string         `json:"validation,omitempty"`
+	InputSize    int64          `json:"input_size"`
+	OutputSize   int64          `json:"output_size,omitempty"`
+	SourceSHA256 string         `json:"source_sha256,omitempty"`
+	OutputSHA256 string         `json:"output_sha256,omitempty"`
+	Uploaded     string         `json:"uploaded,omitempty"`
+	Seconds      float64        `json:"seconds"`
+}
+
+// BatchReport summarises a batch conversion
+type BatchReport struct {
+	Started   time.Time         `json:"started"`
+	Finished  time.Time         `json:"finished"`
+	Converted int               `json:"converted"`
+	Skipped   int               `json:"skipped"`
+	Failed    int               `json:"failed"`
+	Files     []BatchFileResult `json:"files"`
+}
+
+// convertedFile is what the state file records about an output
+type convertedFile struct {
+	Source       string         `json:"source"`
+	Target       ConversionType `json:"target"`
+	SourceSHA256 string         `json:"source_sha256"`
+	OutputSHA256 string         `json:"output_sha256"`
+	Uploaded     string         `json:"uploaded,omitempty"`
+}
+
+// ExpandSources turns file, directory and glob arguments into the files to
+// convert. Directories are searched recursively for files that have a
+// cloud-native conversion; named files are kept as they are, so the batch
+// can report why they were not converted.
+func ExpandSources(args []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+	addPath := func(path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			add(path)
+			return nil
+		}
+		return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != path {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if _, ok := DetectRecommendedConversion(p); ok && !d.IsDir() {
+				add(p)
+			}
+			return nil
+		})
+	}
+
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			if err := addPath(arg); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		for _, m := range matches {
+			if err := addPath(m); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// RunBatch converts files to their recommended cloud-native formats,
+// Parallel at a time, and validates each output. Sources whose output was
+// made from the same content, and is itself unchanged, are skipped. Results
+// are in the order of sources.
+func RunBatch(ctx context.Context, sources []string, opts BatchOptions) *BatchReport {
+	report := &BatchReport{Started: time.Now(), Files: make([]BatchFileResult, len(sources))}
+	b := &batch{opts: opts, states: make(map[string]map[string]convertedFile), outputs: make(map[string]string)}
+
+	// Outputs are assigned up front so two sources never write the same file
+	plans := make([]BatchFileResult, len(sources))
+	for i, source := range sources {
+		plans[i] = b.plan(source)
+	}
+
+	parallel := max(opts.Parallel, 1)
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				result := plans[i]
+				if result.Status == "" {
+					start := time.Now()
+					b.convert(ctx, &result)
+					result.Seconds = time.Since(start).Seconds()
+				}
+				report.Files[i] = result
+				if opts.Done != nil {
+					opts.Done(result)
+				}
+			}
+		}()
+	}
+	for i := range sources {
+		if ctx.Err() != nil {
+			report.Files[i] = plans[i]
+			if report.Files[i].Status == "" {
+				report.Files[i].Status, report.Files[i].Reason = BatchFailed, "cancelled"
+			}
+			continue
+		}
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if err := b.saveStates(); err != nil {
+		// The outputs are fine; they will only be converted again next time
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	for _, f := range report.Files {
+		switch f.Status {
+		case BatchConverted:
+			report.Converted++
+		case BatchSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	report.Finished = time.Now()
+	return report
+}
+
+// batch holds the state shared by the conversions of a batch
+type batch struct {
+	opts    BatchOptions
+	mu      sync.Mutex
+	states  map[string]map[string]convertedFile // Output directory -> output name -> record
+	outputs map[string]string                   // Output path -> source writing it
+}
+
+// plan picks a source's target and output. Sources that cannot be converted
+// come back with their status set.
+func (b *batch) plan(source string) BatchFileResult {
+	result := BatchFileResult{Source: source}
+	if info, err := os.Stat(source); err == nil {
+		result.InputSize = info.Size()
+	}
+	if IsCloudNative(source) {
+		result.Status, result.Reason = BatchSkipped, "already cloud-native"
+		return result
+	}
+	target, ok := DetectRecommendedConversion(source)
+	if !ok {
+		result.Status, result.Reason = BatchSkipped, "no cloud-native conversion for "+GetSourceFormat(source)
+		return result
+	}
+	result.Target = target
+	result.Output = GenerateOutputPath(source, target)
+	if b.opts.OutputDir != "" {
+		result.Output = filepath.Join(b.opts.OutputDir, filepath.Base(result.Output))
+	}
+	if other, taken := b.outputs[result.Output]; taken {
+		result.Status, result.Reason = BatchFailed, fmt.Sprintf("%s is also the output of %s", result.Output, other)
+		return result
+	}
+	b.outputs[result.Output] = source
+	return result
+}
+
+// convert converts, validates and uploads one planned file
+func (b *batch) convert(ctx context.Context, result *BatchFileResult) {
+	fail := func(format string, args ...interface{}) {
+		result.Status, result.Reason = BatchFailed, fmt.Sprintf(format, args...)
+	}
+
+	sum, err := fileSHA256(result.Source)
+	if err != nil {
+		fail("%v", err)
+		return
+	}
+	result.SourceSHA256 = sum
+
+	dir, name := filepath.Dir(result.Output), filepath.Base(result.Output)
+	record, known := b.record(dir, name)
+	upToDate := false
+	if known && !b.opts.Force && record.SourceSHA256 == sum && record.Target == result.Target {
+		if outSum, err := fileSHA256(result.Output); err == nil && outSum == record.OutputSHA256 {
+			upToDate = true
+			result.OutputSHA256 = outSum
+		}
+	}
+
+	if upToDate {
+		result.Status, result.Reason = BatchSkipped, "output is up to date"
+	} else {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			fail("%v", err)
+			return
+		}
+		if err := converters[result.Target](ctx, result.Source, result.Output, b.opts.Options, nil); err != nil {
+			if ctx.Err() != nil {
+				fail("cancelled")
+			} else {
+				fail("%v", err)
+			}
+			return
+		}
+		valid, message, err := validators[result.Target](ctx, result.Output)
+		result.Validation = message
+		if err != nil {
+			fail("validation failed: %v", err)
+			return
+		}
+		if !valid {
+			fail("invalid output: %s", message)
+			return
+		}
+		if result.OutputSHA256, err = fileSHA256(result.Output); err != nil {
+			fail("%v", err)
+			return
+		}
+		result.Status = BatchConverted
+		record = convertedFile{Source: result.Source, Target: result.Target, SourceSHA256: sum, OutputSHA256: result.OutputSHA256}
+	}
+	if info, err := os.Stat(result.Output); err == nil {
+		result.OutputSize = info.Size()
+	}
+
+	if b.opts.Upload != nil {
+		if upToDate && record.Uploaded != "" && record.Uploaded == b.opts.Destination {
+			result.Uploaded = record.Uploaded
+		} else {
+			location, err := b.opts.Upload(ctx, result.Output)
+			if err != nil {
+				fail("upload failed: %v", err)
+				b.setRecord(dir, name, record)
+				return
+			}
+			result.Uploaded = location
+			record.Uploaded = b.opts.Destination
+		}
+	}
+	b.setRecord(dir, name, record)
+}
+
+// record returns what the state file of dir says about an output
+func (b *batch) record(dir, name string) (convertedFile, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	state, ok := b.states[dir]
+	if !ok {
+		state = make(map[string]convertedFile)
+		if data, err := os.ReadFile(filepath.Join(dir, stateFile)); err == nil {
+			json.Unmarshal(data, &state) // An unreadable state converts everything again
+		}
+		b.states[dir] = state
+	}
+	record, ok := state[name]
+	return record, ok
+}
+
+func (b *batch) setRecord(dir, name string, record convertedFile) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.states[dir] == nil {
+		b.states[dir] = make(map[string]convertedFile)
+	}
+	b.states[dir][name] = record
+}
+
+// saveStates writes the state file of every output directory
+func (b *batch) saveStates() error {
+	dirs := make([]string, 0, len(b.states))
+	for dir := range b.states {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if len(b.states[dir]) == 0 {
+			continue
+		}
+		data, err := json.MarshalIndent(b.states[dir], "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, stateFile), data, 0644); err != nil {
+			return fmt.Errorf("failed to save conversion checksums: %w", err)
+		}
+	}
+	return nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
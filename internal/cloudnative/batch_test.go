@@ -0,0 +1,102 @@
+package cloudnative
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	saved, savedValidators := converters, validators
+	t.Cleanup(func() { converters, validators = saved, savedValidators })
+
+	var conversions atomic.Int32
+	converters = map[ConversionType]func(context.Context, string, string, ConversionOptions, ProgressCallback) error{
+		ConversionCOG: func(ctx context.Context, in, out string, opts ConversionOptions, progress ProgressCallback) error {
+			conversions.Add(1)
+			data, err := os.ReadFile(in)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(out, append([]byte("cog:"), data...), 0644)
+		},
+	}
+	validators = map[ConversionType]func(context.Context, string) (bool, string, error){
+		ConversionCOG: func(ctx context.Context, path string) (bool, string, error) {
+			data, _ := os.ReadFile(path)
+			if strings.Contains(string(data), "broken") {
+				return false, "not tiled", nil
+			}
+			return true, "valid COG", nil
+		},
+	}
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.tif", "a")
+	write("sub/b.tif", "b")
+	write("sub/notes.txt", "not convertible")
+	write(".hidden/c.tif", "c")
+
+	sources, err := ExpandSources([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 {
+		t.Fatalf("ExpandSources found %v, want a.tif and sub/b.tif", sources)
+	}
+	if _, err := ExpandSources([]string{filepath.Join(dir, "*.gpkg")}); err == nil {
+		t.Error("a glob matching nothing should be an error")
+	}
+
+	var uploads atomic.Int32
+	opts := BatchOptions{
+		Parallel:    2,
+		Destination: "s3://test/bucket",
+		Upload: func(ctx context.Context, output string) (string, error) {
+			uploads.Add(1)
+			return "s3://bucket/" + filepath.Base(output), nil
+		},
+	}
+	report := RunBatch(context.Background(), sources, opts)
+	if report.Converted != 2 || report.Failed != 0 {
+		t.Fatalf("first run: %+v", report)
+	}
+	if report.Files[0].Uploaded == "" || report.Files[0].OutputSHA256 == "" {
+		t.Errorf("first file was not uploaded or checksummed: %+v", report.Files[0])
+	}
+
+	// Nothing changed, so nothing is converted or uploaded again
+	report = RunBatch(context.Background(), sources, opts)
+	if report.Skipped != 2 || conversions.Load() != 2 || uploads.Load() != 2 {
+		t.Fatalf("rerun: %+v, %d conversions, %d uploads", report, conversions.Load(), uploads.Load())
+	}
+
+	// A changed source is converted again, and an invalid output fails
+	write("a.tif", "broken")
+	report = RunBatch(context.Background(), sources, opts)
+	if report.Failed != 1 || report.Skipped != 1 || report.Files[0].Status != BatchFailed {
+		t.Fatalf("after change: %+v", report)
+	}
+	if !strings.Contains(report.Files[0].Reason, "not tiled") {
+		t.Errorf("failure reason %q does not say why", report.Files[0].Reason)
+	}
+
+	// Two sources with the same name cannot share an output directory
+	write("sub/a.tif", "a")
+	report = RunBatch(context.Background(), []string{filepath.Join(dir, "sub", "b.tif"), filepath.Join(dir, "sub", "a.tif"), filepath.Join(dir, "a.tif")},
+		BatchOptions{OutputDir: filepath.Join(dir, "out")})
+	if report.Files[2].Status != BatchFailed || !strings.Contains(report.Files[2].Reason, "also the output") {
+		t.Errorf("colliding output: %+v", report.Files[2])
+	}
+}
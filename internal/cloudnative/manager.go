@@ -4,28 +4,68 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"sync"
+	"strconv"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 )
 
-// Manager manages cloud-native format conversion jobs
+// Details kept on conversion jobs
+const (
+	detailSourcePath   = "source_path"
+	detailOutputPath   = "output_path"
+	detailSourceFormat = "source_format"
+	detailTargetFormat = "target_format"
+	detailInputSize    = "input_size"
+	detailOutputSize   = "output_size"
+)
+
+// Manager runs cloud-native format conversions as background jobs
 type Manager struct {
-	jobs      map[string]*ConversionJob
-	stopChans map[string]chan struct{}
-	mu        sync.RWMutex
+	jobs *jobs.Manager
+}
+
+// NewManager creates a conversion manager running jobs on jm
+func NewManager(jm *jobs.Manager) *Manager {
+	return &Manager{jobs: jm}
 }
 
-// NewManager creates a new conversion job manager
-func NewManager() *Manager {
-	return &Manager{
-		jobs:      make(map[string]*ConversionJob),
-		stopChans: make(map[string]chan struct{}),
+// OnChange registers fn to be called with a job whenever its status,
+// progress or message changes
+func (m *Manager) OnChange(fn func(job *ConversionJob)) {
+	m.jobs.OnChange(func(info jobs.Info) {
+		if info.Kind == jobs.KindConversion {
+			fn(conversionJob(info))
+		}
+	})
+}
+
+// conversionJob presents a background job as a conversion job
+func conversionJob(info jobs.Info) *ConversionJob {
+	job := &ConversionJob{
+		ID:           info.ID,
+		SourcePath:   info.Details[detailSourcePath],
+		OutputPath:   info.Details[detailOutputPath],
+		SourceFormat: info.Details[detailSourceFormat],
+		TargetFormat: ConversionType(info.Details[detailTargetFormat]),
+		Status:       JobStatus(info.Status),
+		Progress:     int(info.Progress),
+		Message:      info.Message,
+		Error:        info.Error,
+		StartedAt:    info.CreatedAt,
 	}
+	if info.CompletedAt != nil {
+		job.CompletedAt = *info.CompletedAt
+	}
+	job.InputSize, _ = strconv.ParseInt(info.Details[detailInputSize], 10, 64)
+	job.OutputSize, _ = strconv.ParseInt(info.Details[detailOutputSize], 10, 64)
+	if info.Status == jobs.StatusFailed {
+		job.Message = "Conversion failed"
+	}
+	return job
 }
 
-// StartJob starts a new conversion job
+// StartJob queues a new conversion job
 func (m *Manager) StartJob(sourcePath string, targetFormat ConversionType, opts ConversionOptions) (*ConversionJob, error) {
 	// Generate output path
 	outputPath := GenerateOutputPath(sourcePath, targetFormat)
@@ -36,242 +76,121 @@ func (m *Manager) StartJob(sourcePath string, targetFormat ConversionType, opts
 		return nil, fmt.Errorf("failed to stat source file: %w", err)
 	}
 
-	job := &ConversionJob{
-		ID:           uuid.New().String(),
-		SourcePath:   sourcePath,
-		OutputPath:   outputPath,
-		SourceFormat: GetSourceFormat(sourcePath),
-		TargetFormat: targetFormat,
-		Status:       JobStatusPending,
-		Progress:     0,
-		Message:      "Queued for conversion",
-		StartedAt:    time.Now(),
-		InputSize:    stat.Size(),
+	spec := jobs.Spec{
+		Kind:  jobs.KindConversion,
+		Title: fmt.Sprintf("Convert %s to %s", stat.Name(), targetFormat),
+		Details: map[string]string{
+			detailSourcePath:   sourcePath,
+			detailOutputPath:   outputPath,
+			detailSourceFormat: GetSourceFormat(sourcePath),
+			detailTargetFormat: string(targetFormat),
+			detailInputSize:    strconv.FormatInt(stat.Size(), 10),
+		},
 	}
+	job := m.jobs.Submit(spec, func(ctx context.Context, job *jobs.Job) error {
+		return runConversion(ctx, job, sourcePath, outputPath, targetFormat, opts)
+	})
 
-	m.mu.Lock()
-	m.jobs[job.ID] = job
-	stopChan := make(chan struct{})
-	m.stopChans[job.ID] = stopChan
-	m.mu.Unlock()
-
-	// Start conversion in background
-	go m.runJob(job, opts, stopChan)
-
-	return job, nil
+	return conversionJob(job.Info()), nil
 }
 
-// runJob executes the conversion job
-func (m *Manager) runJob(job *ConversionJob, opts ConversionOptions, stopChan chan struct{}) {
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Listen for stop signal
-	go func() {
-		<-stopChan
-		cancel()
-	}()
-
-	m.updateJobStatus(job.ID, JobStatusRunning, 0, "Starting conversion...")
+// runConversion executes a conversion job
+func runConversion(ctx context.Context, job *jobs.Job, sourcePath, outputPath string, targetFormat ConversionType, opts ConversionOptions) error {
+	job.SetMessage("Starting conversion...")
 
 	// Progress callback
 	progress := func(p int, msg string) {
 		if p >= 0 {
-			m.updateJobProgress(job.ID, p, msg)
+			job.SetProgress(float64(p), msg)
 		} else {
-			m.updateJobMessage(job.ID, msg)
+			job.SetMessage(msg)
 		}
 	}
 
 	var err error
-	switch job.TargetFormat {
+	switch targetFormat {
 	case ConversionCOG:
-		err = ConvertToCOG(ctx, job.SourcePath, job.OutputPath, opts, progress)
+		err = ConvertToCOG(ctx, sourcePath, outputPath, opts, progress)
 	case ConversionCOPC:
-		err = ConvertToCOPC(ctx, job.SourcePath, job.OutputPath, opts, progress)
+		err = ConvertToCOPC(ctx, sourcePath, outputPath, opts, progress)
 	case ConversionGeoParquet:
-		err = ConvertToGeoParquet(ctx, job.SourcePath, job.OutputPath, opts, progress)
+		err = ConvertToGeoParquet(ctx, sourcePath, outputPath, opts, progress)
 	default:
-		err = fmt.Errorf("unsupported conversion type: %s", job.TargetFormat)
+		err = fmt.Errorf("unsupported conversion type: %s", targetFormat)
 	}
 
 	if ctx.Err() != nil {
-		m.updateJobStatus(job.ID, JobStatusCancelled, job.Progress, "Conversion cancelled")
-		return
+		return jobs.ErrCancelled
 	}
-
 	if err != nil {
-		m.updateJobError(job.ID, err.Error())
-		return
+		return err
 	}
 
 	// Get output file size
-	if stat, err := os.Stat(job.OutputPath); err == nil {
-		m.mu.Lock()
-		if j, ok := m.jobs[job.ID]; ok {
-			j.OutputSize = stat.Size()
-		}
-		m.mu.Unlock()
-	}
-
-	m.updateJobStatus(job.ID, JobStatusCompleted, 100, "Conversion complete")
-}
-
-// updateJobStatus updates the job status
-func (m *Manager) updateJobStatus(id string, status JobStatus, progress int, message string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if job, ok := m.jobs[id]; ok {
-		job.Status = status
-		job.Progress = progress
-		job.Message = message
-		if status == JobStatusCompleted || status == JobStatusFailed || status == JobStatusCancelled {
-			job.CompletedAt = time.Now()
-		}
-	}
-}
-
-// updateJobProgress updates the job progress
-func (m *Manager) updateJobProgress(id string, progress int, message string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if job, ok := m.jobs[id]; ok {
-		job.Progress = progress
-		job.Message = message
-	}
-}
-
-// updateJobMessage updates the job message without changing progress
-func (m *Manager) updateJobMessage(id string, message string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if job, ok := m.jobs[id]; ok {
-		job.Message = message
-	}
-}
-
-// updateJobError marks the job as failed
-func (m *Manager) updateJobError(id string, errMsg string) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	if job, ok := m.jobs[id]; ok {
-		job.Status = JobStatusFailed
-		job.Error = errMsg
-		job.Message = "Conversion failed"
-		job.CompletedAt = time.Now()
+	if stat, err := os.Stat(outputPath); err == nil {
+		job.SetDetail(detailOutputSize, strconv.FormatInt(stat.Size(), 10))
 	}
+	job.SetMessage("Conversion complete")
+	return nil
 }
 
 // GetJob returns a job by ID
 func (m *Manager) GetJob(id string) *ConversionJob {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	if job, ok := m.jobs[id]; ok {
-		// Return a copy to avoid race conditions
-		copy := *job
-		return &copy
+	job := m.jobs.Get(id)
+	if job == nil || job.Kind() != jobs.KindConversion {
+		return nil
 	}
-	return nil
+	return conversionJob(job.Info())
 }
 
 // ListJobs returns all jobs
 func (m *Manager) ListJobs() []*ConversionJob {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	jobs := make([]*ConversionJob, 0, len(m.jobs))
-	for _, job := range m.jobs {
-		copy := *job
-		jobs = append(jobs, &copy)
+	infos := m.jobs.List(jobs.KindConversion)
+	list := make([]*ConversionJob, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, conversionJob(info))
 	}
-	return jobs
+	return list
 }
 
 // ListActiveJobs returns jobs that are pending or running
 func (m *Manager) ListActiveJobs() []*ConversionJob {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	var jobs []*ConversionJob
-	for _, job := range m.jobs {
-		if job.Status == JobStatusPending || job.Status == JobStatusRunning {
-			copy := *job
-			jobs = append(jobs, &copy)
+	var list []*ConversionJob
+	for _, info := range m.jobs.List(jobs.KindConversion) {
+		if !info.Status.Done() {
+			list = append(list, conversionJob(info))
 		}
 	}
-	return jobs
+	return list
 }
 
 // CancelJob cancels a running or pending job
 func (m *Manager) CancelJob(id string) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	job, ok := m.jobs[id]
-	if !ok {
+	if m.GetJob(id) == nil {
 		return false
 	}
-
-	if job.Status != JobStatusPending && job.Status != JobStatusRunning {
-		return false // Can only cancel pending or running jobs
-	}
-
-	// Signal stop
-	if stopChan, ok := m.stopChans[id]; ok {
-		close(stopChan)
-		delete(m.stopChans, id)
-	}
-
-	return true
+	return m.jobs.Cancel(id)
 }
 
 // RemoveJob removes a completed/failed/cancelled job from the list
 func (m *Manager) RemoveJob(id string) bool {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	job, ok := m.jobs[id]
-	if !ok {
-		return false
-	}
-
-	// Only remove completed, failed, or cancelled jobs
-	if job.Status == JobStatusPending || job.Status == JobStatusRunning {
+	if m.GetJob(id) == nil {
 		return false
 	}
-
-	delete(m.jobs, id)
-	delete(m.stopChans, id)
-	return true
+	return m.jobs.Remove(id)
 }
 
-// CleanupOldJobs removes jobs older than the specified duration
+// CleanupOldJobs removes finished jobs older than the specified duration
 func (m *Manager) CleanupOldJobs(maxAge time.Duration) int {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-
 	cutoff := time.Now().Add(-maxAge)
 	removed := 0
-
-	for id, job := range m.jobs {
-		// Skip active jobs
-		if job.Status == JobStatusPending || job.Status == JobStatusRunning {
-			continue
-		}
-
-		// Remove old completed jobs
-		if job.CompletedAt.Before(cutoff) {
-			delete(m.jobs, id)
-			delete(m.stopChans, id)
-			removed++
+	for _, job := range m.ListJobs() {
+		if job.Status != JobStatusPending && job.Status != JobStatusRunning && job.CompletedAt.Before(cutoff) {
+			if m.jobs.Remove(job.ID) {
+				removed++
+			}
 		}
 	}
-
 	return removed
 }
 
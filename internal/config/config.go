@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,8 @@ const (
 	configDir    = "kartoza-cloudbench"
 	oldConfigDir = "kartoza-geoserver-client" // For migration
 	configFile   = "config.json"
+	usersFile    = "users.json"
+	auditFile    = "audit.jsonl"
 )
 
 // Connection represents a GeoServer connection configuration
@@ -26,20 +29,51 @@ type Connection struct {
 
 // SyncConfiguration represents a saved sync setup
 type SyncConfiguration struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	SourceID     string   `json:"source_id"`      // Connection ID of source server
-	DestIDs      []string `json:"destination_ids"` // Connection IDs of destination servers
-	SyncOptions  SyncOptions `json:"options"`
-	CreatedAt    string   `json:"created_at"`
-	LastSyncedAt string   `json:"last_synced_at,omitempty"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	SourceID     string            `json:"source_id"`       // Connection ID of source server
+	DestIDs      []string          `json:"destination_ids"` // Connection IDs of destination servers
+	SyncOptions  SyncOptions       `json:"options"`
+	Mappings     *SyncMappingRules `json:"mappings,omitempty"` // Optional name and parameter rewriting
+	CreatedAt    string            `json:"created_at"`
+	LastSyncedAt string            `json:"last_synced_at,omitempty"`
+}
+
+// SyncMappingRules rewrites object names and store connection parameters
+// while syncing, e.g. to turn staging names into production names
+type SyncMappingRules struct {
+	WorkspaceRenames map[string]string `json:"workspace_renames,omitempty"` // Source workspace -> destination workspace
+	StyleRenames     map[string]string `json:"style_renames,omitempty"`     // Source style ("name" or "workspace:name") -> destination style name
+	NameRewrites     []NameRewriteRule `json:"name_rewrites,omitempty"`     // Applied in order to names without an explicit rename
+	// DataStoreOverrides replaces datastore connection parameters, keyed by destination connection ID
+	DataStoreOverrides map[string]DataStoreParamOverride `json:"datastore_overrides,omitempty"`
+}
+
+// NameRewriteRule rewrites a name's prefix and/or suffix
+type NameRewriteRule struct {
+	// Kinds limits the rule to object kinds: workspace, style, datastore, layer, layergroup (empty = all)
+	Kinds       []string `json:"kinds,omitempty"`
+	StripPrefix string   `json:"strip_prefix,omitempty"`
+	AddPrefix   string   `json:"add_prefix,omitempty"`
+	StripSuffix string   `json:"strip_suffix,omitempty"`
+	AddSuffix   string   `json:"add_suffix,omitempty"`
+}
+
+// DataStoreParamOverride replaces datastore connection parameters on one destination.
+// Empty fields keep the source value.
+type DataStoreParamOverride struct {
+	Host     string            `json:"host,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	Database string            `json:"database,omitempty"`
+	Schema   string            `json:"schema,omitempty"`
+	Params   map[string]string `json:"params,omitempty"` // Any other connection parameter by key
 }
 
 // DataStoreSyncStrategy defines how datastores should be synced
 type DataStoreSyncStrategy string
 
 const (
-	// DataStoreSameConnection copies datastore config as-is (requires same DB access)
+	// DataStoreSameConnection copies datastore config, applying any destination parameter overrides
 	DataStoreSameConnection DataStoreSyncStrategy = "same_connection"
 	// DataStoreGeoPackageCopy exports data to GeoPackage and syncs as file store
 	DataStoreGeoPackageCopy DataStoreSyncStrategy = "geopackage_copy"
@@ -55,13 +89,29 @@ type SyncOptions struct {
 	Layers          bool `json:"layers"`
 	Styles          bool `json:"styles"`
 	LayerGroups     bool `json:"layergroups"`
+	GWC             bool `json:"gwc"`                // Gridsets, tile layer settings and disk quota
+	GWCSeed         bool `json:"gwc_seed,omitempty"` // Seed tiles for layers created by the sync
 	// Filter options
 	WorkspaceFilter []string `json:"workspace_filter,omitempty"` // If set, only sync these workspaces
 	// Datastore sync strategy
 	DataStoreStrategy DataStoreSyncStrategy `json:"datastore_strategy,omitempty"` // How to sync datastores
+	// Concurrency options
+	Workers   int     `json:"workers,omitempty"`    // Number of concurrent sync workers, default 4
+	RateLimit float64 `json:"rate_limit,omitempty"` // Max requests per second per server, 0 = unlimited
 }
 
-// DefaultSyncOptions returns default sync options (sync everything)
+// GetWorkers returns the number of sync workers, with a default of 4
+func (o SyncOptions) GetWorkers() int {
+	if o.Workers <= 0 {
+		return 4
+	}
+	if o.Workers > 32 {
+		return 32 // Avoid overwhelming servers
+	}
+	return o.Workers
+}
+
+// DefaultSyncOptions returns default sync options (sync the catalog, not tile caching)
 func DefaultSyncOptions() SyncOptions {
 	return SyncOptions{
 		Workspaces:        true,
@@ -70,6 +120,7 @@ func DefaultSyncOptions() SyncOptions {
 		Layers:            true,
 		Styles:            true,
 		LayerGroups:       true,
+		GWC:               false, // Overwrites the destination's gridsets and disk quota
 		DataStoreStrategy: DataStoreSkip, // Default to skip for safety
 	}
 }
@@ -126,6 +177,7 @@ type SavedQuery struct {
 
 // Config holds the application configuration
 type Config struct {
+	Version            int                 `json:"version"` // Schema version, see CurrentVersion
 	Connections      []Connection        `json:"connections"`
 	ActiveConnection string              `json:"active_connection"`
 	LastLocalPath    string              `json:"last_local_path"`
@@ -137,6 +189,17 @@ type Config struct {
 	S3Connections      []S3Connection      `json:"s3_connections,omitempty"`      // S3-compatible storage connections
 	QGISProjects       []QGISProject       `json:"qgis_projects,omitempty"`       // QGIS project files
 	GeoNodeConnections []GeoNodeConnection `json:"geonode_connections,omitempty"` // GeoNode instance connections
+	SecretsBackend     string              `json:"secrets_backend,omitempty"`     // "vault" or "keyring"; empty stores secrets in this file
+	JobLimits          map[string]int      `json:"job_limits,omitempty"`          // Concurrent background jobs per kind, e.g. {"sync": 2}
+	SnapshotHours      int                 `json:"snapshot_hours,omitempty"`      // Hours between catalog snapshots of each GeoServer by the web server; 0 disables
+	CacheTTLHours      int                 `json:"cache_ttl_hours,omitempty"`     // Hours cached sync data is reused before downloading it again; 0 keeps it until the source changes
+	CacheMaxSizeMB     int                 `json:"cache_max_size_mb,omitempty"`   // Size the sync cache is trimmed to, least recently used first; 0 for no limit
+	CacheMaxAgeDays    int                 `json:"cache_max_age_days,omitempty"`  // Days after which cached sync data is removed; 0 for no limit
+	RESTCacheTTLSecs   int                 `json:"rest_cache_ttl_secs,omitempty"` // Seconds GeoServer REST responses are cached in memory; 0 disables
+	RESTCacheTTLs      map[string]int      `json:"rest_cache_ttls,omitempty"`     // Seconds by REST resource, e.g. {"workspaces": 300}, overriding rest_cache_ttl_secs
+	RasterChecksumPx   int                 `json:"raster_checksum_px,omitempty"`  // Side in pixels of the raster subset fetched with WCS GetCoverage and checksummed after an upload; 0 skips the checksum
+
+	stamp fileStamp // Contents of the file this config was loaded from, to detect concurrent saves
 }
 
 // GetPingInterval returns the ping interval in seconds, with a default of 60
@@ -162,6 +225,7 @@ func (c *Config) SetPingInterval(seconds int) {
 func DefaultConfig() *Config {
 	home, _ := os.UserHomeDir()
 	return &Config{
+		Version:       CurrentVersion,
 		Connections:   []Connection{},
 		LastLocalPath: home,
 		Theme:         "default",
@@ -182,6 +246,25 @@ func configPath() (string, error) {
 	return filepath.Join(configHome, configDir, configFile), nil
 }
 
+// UsersPath returns the path of the web server's user account store, which
+// lives next to the config file
+func UsersPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), usersFile), nil
+}
+
+// AuditPath returns the path of the audit log, next to the config file
+func AuditPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), auditFile), nil
+}
+
 // Load loads the configuration from disk
 func Load() (*Config, error) {
 	// Try to migrate from old config location if new one doesn't exist
@@ -203,14 +286,66 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
 
+	stamp := stampOf(data)
+	migrated, version, err := migrate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if version != CurrentVersion {
+		backupPath, err := backupConfig(path, data, version)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeFileAtomic(path, migrated); err != nil {
+			return nil, err
+		}
+		stamp = stampOf(migrated)
+		fmt.Fprintf(os.Stderr, "Upgraded config schema from version %d to %d (backup: %s)\n", version, CurrentVersion, backupPath)
+	}
+
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.stamp = stamp
+
+	if err := cfg.resolveSecrets(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
+// Reload replaces the config with the file on disk, discarding unsaved changes
+func (c *Config) Reload() error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	*c = *cfg
+	return nil
+}
+
+// Update applies a change and saves it. When another process saved the file
+// in the meantime, the config is reloaded and the change applied again, so
+// neither process's changes are lost.
+func (c *Config) Update(apply func(*Config)) error {
+	apply(c)
+	err := c.Save()
+	if !errors.Is(err, ErrConfigChanged) {
+		return err
+	}
+
+	if err := c.Reload(); err != nil {
+		return err
+	}
+	apply(c)
+	return c.Save()
+}
+
 // migrateOldConfig migrates config from old kartoza-geoserver-client directory
 func migrateOldConfig() error {
 	homeDir, err := os.UserHomeDir()
@@ -262,21 +397,42 @@ func (c *Config) Save() error {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	// Refuse to overwrite changes saved by another process since this
+	// config was loaded
+	unlock, err := lockConfig(path)
 	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+		return err
 	}
+	defer unlock()
 
-	// Atomic write using temp file
-	tmpPath := path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write config: %w", err)
+	current, err := currentStamp(path)
+	if err != nil {
+		return err
+	}
+	if current != c.stamp {
+		return fmt.Errorf("%s: %w; reload to pick up the changes", path, ErrConfigChanged)
 	}
 
-	if err := os.Rename(tmpPath, path); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("failed to save config: %w", err)
+	// Secrets are written to the secrets backend and referenced from the file
+	c.Version = CurrentVersion
+	stored, err := c.storeSecrets()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(stored, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
 	}
+	c.stamp = stampOf(data)
 
 	return nil
 }
@@ -452,9 +608,8 @@ func (c *Config) DeleteQuery(serviceName, queryName string) {
 	}
 }
 
-// QGISProjectsDir returns the directory for storing uploaded QGIS projects
-// This is stored in XDG_DATA_HOME/kartoza-cloudbench/qgis-projects/
-func QGISProjectsDir() (string, error) {
+// dataDir returns (and creates) a subdirectory of XDG_DATA_HOME/kartoza-cloudbench/
+func dataDir(name string) (string, error) {
 	dataHome := os.Getenv("XDG_DATA_HOME")
 	if dataHome == "" {
 		home, err := os.UserHomeDir()
@@ -464,14 +619,52 @@ func QGISProjectsDir() (string, error) {
 		dataHome = filepath.Join(home, ".local", "share")
 	}
 
-	dir := filepath.Join(dataHome, configDir, "qgis-projects")
+	dir := filepath.Join(dataHome, configDir, name)
 	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create QGIS projects directory: %w", err)
+		return "", fmt.Errorf("failed to create %s directory: %w", name, err)
 	}
 
 	return dir, nil
 }
 
+// QGISProjectsDir returns the directory for storing uploaded QGIS projects
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/qgis-projects/
+func QGISProjectsDir() (string, error) {
+	return dataDir("qgis-projects")
+}
+
+// SyncCheckpointsDir returns the directory for sync task checkpoints
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/sync-checkpoints/
+func SyncCheckpointsDir() (string, error) {
+	return dataDir("sync-checkpoints")
+}
+
+// JobsDir returns the directory background job records are kept in
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/jobs/
+func JobsDir() (string, error) {
+	return dataDir("jobs")
+}
+
+// OfflineDir returns the directory the last-fetched catalog of each
+// connection and its queued offline changes are kept in
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/offline/
+func OfflineDir() (string, error) {
+	return dataDir("offline")
+}
+
+// SnapshotsDir returns the directory catalog snapshots are kept in
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/snapshots/
+func SnapshotsDir() (string, error) {
+	return dataDir("snapshots")
+}
+
+// TLSDir returns the directory the web server's self-signed certificate is
+// kept in
+// This is stored in XDG_DATA_HOME/kartoza-cloudbench/tls/
+func TLSDir() (string, error) {
+	return dataDir("tls")
+}
+
 // GetS3Connection returns an S3 connection by ID
 func (c *Config) GetS3Connection(id string) *S3Connection {
 	for i := range c.S3Connections {
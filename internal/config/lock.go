@@ -0,0 +1,103 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrConfigChanged is returned by Save when another process (or another
+// Config loaded in this one) saved the file since this Config was loaded
+var ErrConfigChanged = errors.New("config file was changed by another process")
+
+const (
+	lockTimeout = 5 * time.Second
+	lockStale   = 30 * time.Second // A lock older than this was left by a crashed process
+)
+
+// fileStamp identifies the config file contents a Config was loaded from
+type fileStamp struct {
+	exists bool
+	sum    [sha256.Size]byte
+}
+
+func stampOf(data []byte) fileStamp {
+	return fileStamp{exists: true, sum: sha256.Sum256(data)}
+}
+
+// currentStamp returns the stamp of the config file on disk
+func currentStamp(path string) (fileStamp, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return fileStamp{}, nil
+	}
+	if err != nil {
+		return fileStamp{}, fmt.Errorf("failed to read config: %w", err)
+	}
+	return stampOf(data), nil
+}
+
+// lockConfig takes the config write lock, shared by all CloudBench
+// processes. It uses an exclusive lock file so it works on every platform.
+func lockConfig(path string) (unlock func(), err error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock config: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("config is locked by another process (remove %s if no other CloudBench is running)", lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CurrentVersion is the config schema version written by this build
+const CurrentVersion = 1
+
+// migration upgrades a raw config document by one schema version
+type migration struct {
+	version     int // Version the document has after the migration
+	description string
+	apply       func(doc map[string]interface{}) error
+}
+
+// migrations upgrade older configs, in version order. To change the schema,
+// append a migration and bump CurrentVersion.
+var migrations = []migration{
+	{1, "add schema version and fill defaults of unversioned configs", migrateToV1},
+}
+
+// migrate upgrades raw config data to CurrentVersion. It returns the data
+// unchanged and the version found when no migration is needed.
+func migrate(data []byte) ([]byte, int, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	version := 0
+	if v, ok := doc["version"].(float64); ok {
+		version = int(v)
+	}
+	if version > CurrentVersion {
+		return nil, version, fmt.Errorf("config schema version %d is newer than this build supports (%d); please upgrade", version, CurrentVersion)
+	}
+	if version == CurrentVersion {
+		return data, version, nil
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+		if err := m.apply(doc); err != nil {
+			return nil, version, fmt.Errorf("config migration to version %d (%s) failed: %w", m.version, m.description, err)
+		}
+		doc["version"] = m.version
+	}
+
+	migrated, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, version, fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+	return migrated, version, nil
+}
+
+// backupConfig keeps a copy of a config before it is migrated
+func backupConfig(path string, data []byte, version int) (string, error) {
+	backupPath := fmt.Sprintf("%s.v%d.bak", path, version)
+	if err := os.WriteFile(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up config: %w", err)
+	}
+	return backupPath, nil
+}
+
+// migrateToV1 fills in values that older builds defaulted at runtime
+func migrateToV1(doc map[string]interface{}) error {
+	if doc["connections"] == nil {
+		doc["connections"] = []interface{}{}
+	}
+	if theme, _ := doc["theme"].(string); theme == "" {
+		doc["theme"] = "default"
+	}
+
+	syncConfigs, _ := doc["sync_configs"].([]interface{})
+	for _, sc := range syncConfigs {
+		scMap, ok := sc.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("sync configuration is not an object")
+		}
+		options, _ := scMap["options"].(map[string]interface{})
+		if options == nil {
+			options = make(map[string]interface{})
+			scMap["options"] = options
+		}
+		// An empty strategy has always meant skip
+		if strategy, _ := options["datastore_strategy"].(string); strategy == "" {
+			options["datastore_strategy"] = string(DataStoreSkip)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path, err := configPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadMigratesUnversionedConfig(t *testing.T) {
+	path := setupConfigDir(t)
+	old := `{"connections": null, "theme": "", "sync_configs": [{"id": "s", "source_id": "a", "options": {"layers": true}}]}`
+	if err := os.WriteFile(path, []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Version != CurrentVersion || cfg.Theme != "default" || cfg.Connections == nil {
+		t.Fatalf("config not migrated: %+v", cfg)
+	}
+	if cfg.SyncConfigs[0].SyncOptions.DataStoreStrategy != DataStoreSkip || !cfg.SyncConfigs[0].SyncOptions.Layers {
+		t.Fatalf("sync options not migrated: %+v", cfg.SyncConfigs[0].SyncOptions)
+	}
+	if backup, err := os.ReadFile(path + ".v0.bak"); err != nil || string(backup) != old {
+		t.Fatalf("backup not written: %v", err)
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	path := setupConfigDir(t)
+	data := `{"version": 1, "connections": [{"id": "a", "url": "http://a"}, {"id": "a", "url": "http://b"}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var validationErr *ValidationError
+	if _, err := Load(); !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+		t.Fatalf("expected a duplicate id problem, got %v", err)
+	}
+}
+
+func TestSaveDetectsConcurrentChanges(t *testing.T) {
+	setupConfigDir(t)
+
+	first, _ := Load()
+	second, _ := Load()
+
+	first.AddConnection(Connection{ID: "a", Name: "A", URL: "http://a"})
+	if err := first.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.Save(); !errors.Is(err, ErrConfigChanged) {
+		t.Fatalf("expected ErrConfigChanged, got %v", err)
+	}
+
+	if err := second.Update(func(c *Config) {
+		c.AddConnection(Connection{ID: "b", Name: "B", URL: "http://b"})
+	}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Connections) != 2 {
+		t.Fatalf("expected both connections to be kept, got %+v", saved.Connections)
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"syscall"
+
+	"github.com/google/uuid"
+)
+
+// ProcessOwner identifies the CloudBench process running work that is
+// recorded on disk, such as a sync checkpoint or a background job, so other
+// processes sharing the config directory leave it alone
+type ProcessOwner struct {
+	PID      int    `json:"pid"`
+	Host     string `json:"host"`
+	Instance string `json:"instance"` // Random per process, since PIDs are reused, e.g. in containers
+}
+
+var currentOwner = func() ProcessOwner {
+	host, _ := os.Hostname()
+	return ProcessOwner{PID: os.Getpid(), Host: host, Instance: uuid.New().String()}
+}()
+
+// CurrentOwner returns the owner identifying this process
+func CurrentOwner() ProcessOwner {
+	return currentOwner
+}
+
+// IsCurrent reports whether the owner is this process
+func (o ProcessOwner) IsCurrent() bool {
+	return o == currentOwner
+}
+
+// Alive reports whether the owning process may still be running. Records
+// without an owner were written by a process that has exited. Processes on
+// other hosts cannot be checked and are assumed to be running.
+func (o ProcessOwner) Alive() bool {
+	switch {
+	case o.PID == 0:
+		return false
+	case o.Host != currentOwner.Host:
+		return true
+	case o.PID == currentOwner.PID:
+		return o.Instance == currentOwner.Instance
+	}
+
+	process, err := os.FindProcess(o.PID)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only succeeds for a running process there
+		return true
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// RunningElsewhere reports whether the owner is another CloudBench process
+// that is still running
+func (o ProcessOwner) RunningElsewhere() bool {
+	return !o.IsCurrent() && o.Alive()
+}
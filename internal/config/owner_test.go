@@ -0,0 +1,31 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestProcessOwnerAlive(t *testing.T) {
+	current := CurrentOwner()
+	if !current.Alive() || current.RunningElsewhere() {
+		t.Error("this process is not alive, or runs elsewhere")
+	}
+
+	// The parent process, e.g. go test, is another live process
+	parent := ProcessOwner{PID: os.Getppid(), Host: current.Host, Instance: "other"}
+	if !parent.RunningElsewhere() {
+		t.Error("a live process on this host is not running elsewhere")
+	}
+
+	// A previous process that had this PID, as in a restarted container
+	previous := ProcessOwner{PID: current.PID, Host: current.Host, Instance: "previous"}
+	if previous.Alive() {
+		t.Error("a previous process with this PID is alive")
+	}
+	if (ProcessOwner{}).Alive() {
+		t.Error("a record without an owner has a live owner")
+	}
+	if !(ProcessOwner{PID: 1, Host: current.Host + ".elsewhere"}).Alive() {
+		t.Error("a process on another host is assumed gone")
+	}
+}
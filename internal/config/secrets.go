@@ -0,0 +1,198 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+)
+
+const secretsVaultFile = "secrets.vault"
+
+// maxUnlockAttempts limits how often LoadWithPrompt asks for the passphrase
+const maxUnlockAttempts = 3
+
+// secretField is a credential in the config and the name it is stored under
+type secretField struct {
+	name  string
+	value *string
+}
+
+// secretFields returns every credential held by the config
+func (c *Config) secretFields() []secretField {
+	var fields []secretField
+	for i := range c.Connections {
+		conn := &c.Connections[i]
+		fields = append(fields, secretField{"geoserver/" + conn.ID + "/password", &conn.Password})
+	}
+	for i := range c.S3Connections {
+		conn := &c.S3Connections[i]
+		fields = append(fields, secretField{"s3/" + conn.ID + "/secret_key", &conn.SecretKey})
+	}
+	for i := range c.GeoNodeConnections {
+		conn := &c.GeoNodeConnections[i]
+		fields = append(fields,
+			secretField{"geonode/" + conn.ID + "/password", &conn.Password},
+			secretField{"geonode/" + conn.ID + "/token", &conn.Token},
+		)
+	}
+	return fields
+}
+
+// SecretsVaultPath returns the path of the encrypted secrets vault
+func SecretsVaultPath() (string, error) {
+	path, err := configPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), secretsVaultFile), nil
+}
+
+// OpenSecrets returns the configured secrets backend
+func (c *Config) OpenSecrets() (secrets.Backend, error) {
+	if c.SecretsBackend == "" {
+		return nil, fmt.Errorf("no secrets backend configured")
+	}
+	vaultPath, err := SecretsVaultPath()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Open(c.SecretsBackend, vaultPath)
+}
+
+// resolveSecrets replaces secret references with the stored values. The
+// backend is opened even without references, so a locked vault is reported
+// on load rather than on the next save.
+func (c *Config) resolveSecrets() error {
+	if c.SecretsBackend == "" {
+		for _, field := range c.secretFields() {
+			if secrets.IsRef(*field.value) {
+				return fmt.Errorf("config references secrets but no secrets backend is configured")
+			}
+		}
+		return nil
+	}
+
+	backend, err := c.OpenSecrets()
+	if err != nil {
+		return fmt.Errorf("failed to open secrets: %w", err)
+	}
+	for _, field := range c.secretFields() {
+		if !secrets.IsRef(*field.value) {
+			continue
+		}
+
+		name := secrets.RefName(*field.value)
+		value, err := backend.Get(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			*field.value = ""
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		*field.value = value
+	}
+	return nil
+}
+
+// storeSecrets writes the credentials to the secrets backend and returns a
+// copy of the config holding references in their place. Plaintext values left
+// in the file by older versions are moved the same way.
+func (c *Config) storeSecrets() (*Config, error) {
+	if c.SecretsBackend == "" {
+		return c, nil
+	}
+	backend, err := c.OpenSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open secrets: %w", err)
+	}
+
+	stored := *c
+	stored.Connections = slices.Clone(c.Connections)
+	stored.S3Connections = slices.Clone(c.S3Connections)
+	stored.GeoNodeConnections = slices.Clone(c.GeoNodeConnections)
+
+	keep := make(map[string]bool)
+	for _, field := range stored.secretFields() {
+		if *field.value == "" {
+			continue
+		}
+		if secrets.IsRef(*field.value) {
+			// Unresolved reference, keep it as is
+			keep[secrets.RefName(*field.value)] = true
+			continue
+		}
+		if err := backend.Set(field.name, *field.value); err != nil {
+			return nil, fmt.Errorf("failed to store secret %s: %w", field.name, err)
+		}
+		*field.value = secrets.Ref(field.name)
+		keep[field.name] = true
+	}
+
+	// Remove secrets of deleted connections
+	if lister, ok := backend.(secrets.Lister); ok {
+		if names, err := lister.List(); err == nil {
+			for _, name := range names {
+				if !keep[name] {
+					backend.Delete(name)
+				}
+			}
+		}
+	}
+
+	return &stored, nil
+}
+
+// EnableSecrets moves all credentials out of the config file into the given
+// backend. For the vault backend a vault is created with passphrase unless
+// one already exists.
+func (c *Config) EnableSecrets(backend, passphrase string) error {
+	if backend == secrets.BackendVault {
+		vaultPath, err := SecretsVaultPath()
+		if err != nil {
+			return err
+		}
+		if !secrets.Exists(vaultPath) {
+			if _, err := secrets.CreateVault(vaultPath, passphrase); err != nil {
+				return err
+			}
+		}
+		secrets.SetPassphrase(passphrase)
+	}
+
+	c.SecretsBackend = backend
+	return c.Save()
+}
+
+// DisableSecrets writes all credentials back into the config file
+func (c *Config) DisableSecrets() error {
+	c.SecretsBackend = ""
+	return c.Save()
+}
+
+// LoadWithPrompt loads the configuration, asking for the secrets passphrase
+// with prompt when the vault is locked or the passphrase is wrong
+func LoadWithPrompt(prompt func(message string) (string, error)) (*Config, error) {
+	message := "Secrets passphrase: "
+	for attempt := 0; ; attempt++ {
+		cfg, err := Load()
+		if err == nil || attempt == maxUnlockAttempts {
+			return cfg, err
+		}
+		if !errors.Is(err, secrets.ErrLocked) && !errors.Is(err, secrets.ErrWrongPassphrase) {
+			return nil, err
+		}
+		if errors.Is(err, secrets.ErrWrongPassphrase) {
+			message = "Wrong passphrase, try again: "
+		}
+
+		passphrase, err := prompt(message)
+		if err != nil {
+			return nil, err
+		}
+		secrets.SetPassphrase(passphrase)
+	}
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+)
+
+// ValidationError lists the problems found in a config
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the config for problems that would break the application,
+// such as missing or duplicate IDs. It returns a *ValidationError.
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	// checkID reports empty and duplicate IDs within one list
+	checkID := func(kind string, index int, id string, seen map[string]bool) {
+		if id == "" {
+			addf("%s[%d]: missing id", kind, index)
+		} else if seen[id] {
+			addf("%s[%d]: duplicate id %q", kind, index, id)
+		}
+		seen[id] = true
+	}
+
+	seen := make(map[string]bool)
+	for i, conn := range c.Connections {
+		checkID("connections", i, conn.ID, seen)
+		if conn.URL == "" {
+			addf("connections[%d] (%s): missing url", i, conn.Name)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for i, conn := range c.S3Connections {
+		checkID("s3_connections", i, conn.ID, seen)
+		if conn.Endpoint == "" {
+			addf("s3_connections[%d] (%s): missing endpoint", i, conn.Name)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for i, conn := range c.GeoNodeConnections {
+		checkID("geonode_connections", i, conn.ID, seen)
+		if conn.URL == "" {
+			addf("geonode_connections[%d] (%s): missing url", i, conn.Name)
+		}
+	}
+
+	seen = make(map[string]bool)
+	for i, sc := range c.SyncConfigs {
+		checkID("sync_configs", i, sc.ID, seen)
+		if sc.SourceID == "" {
+			addf("sync_configs[%d] (%s): missing source_id", i, sc.Name)
+		}
+		switch sc.SyncOptions.DataStoreStrategy {
+		case "", DataStoreSameConnection, DataStoreGeoPackageCopy, DataStoreSkip:
+		default:
+			addf("sync_configs[%d] (%s): unknown datastore_strategy %q", i, sc.Name, sc.SyncOptions.DataStoreStrategy)
+		}
+	}
+
+	kinds := make([]string, 0, len(c.JobLimits))
+	for kind := range c.JobLimits {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		limit := c.JobLimits[kind]
+		switch kind {
+		case "sync", "import", "conversion", "seed":
+		default:
+			addf("job_limits: unknown job kind %q", kind)
+		}
+		if limit < 0 {
+			addf("job_limits: %s limit must not be negative", kind)
+		}
+	}
+
+	if c.SnapshotHours < 0 {
+		addf("snapshot_hours must not be negative")
+	}
+	if c.CacheTTLHours < 0 {
+		addf("cache_ttl_hours must not be negative")
+	}
+	if c.CacheMaxSizeMB < 0 {
+		addf("cache_max_size_mb must not be negative")
+	}
+	if c.CacheMaxAgeDays < 0 {
+		addf("cache_max_age_days must not be negative")
+	}
+	if c.RESTCacheTTLSecs < 0 {
+		addf("rest_cache_ttl_secs must not be negative")
+	}
+	resources := make([]string, 0, len(c.RESTCacheTTLs))
+	for resource := range c.RESTCacheTTLs {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		switch resource {
+		case "about", "coverages", "coveragestores", "datastores", "featuretypes", "layergroups",
+			"layers", "services", "settings", "styles", "workspaces":
+		default:
+			addf("rest_cache_ttls: unknown resource %q", resource)
+		}
+		if c.RESTCacheTTLs[resource] < 0 {
+			addf("rest_cache_ttls: %s TTL must not be negative", resource)
+		}
+	}
+	if c.RasterChecksumPx < 0 {
+		addf("raster_checksum_px must not be negative")
+	}
+
+	switch c.SecretsBackend {
+	case "", secrets.BackendVault, secrets.BackendKeyring:
+	default:
+		addf("unknown secrets_backend %q", c.SecretsBackend)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -16,6 +16,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 )
 
@@ -237,6 +238,28 @@ type DashboardsResponse struct {
 	Dashboards []Dashboard `json:"dashboards"`
 }
 
+// WithAudit returns a copy of the client that records every change it makes
+// as actor
+func (c *Client) WithAudit(actor *audit.Actor) *Client {
+	if actor == nil {
+		return c
+	}
+	base := c.httpClient.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	prefix := ""
+	if u, err := url.Parse(c.baseURL); err == nil {
+		prefix = u.Path
+	}
+
+	audited := *c
+	httpClient := *c.httpClient
+	httpClient.Transport = &audit.Transport{Base: base, Actor: actor, Service: audit.ServiceGeoNode, Prefix: prefix}
+	audited.httpClient = &httpClient
+	return &audited
+}
+
 // doRequest performs an HTTP request with authentication
 func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
 	reqURL := c.baseURL + path
@@ -683,7 +706,7 @@ func (c *Client) UploadDataset(filePath string, opts *UploadOptions) (*UploadRes
 	}
 
 	// Use longer timeout for uploads
-	client := &http.Client{Timeout: 5 * time.Minute}
+	client := &http.Client{Timeout: 5 * time.Minute, Transport: c.httpClient.Transport}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("upload request failed: %w", err)
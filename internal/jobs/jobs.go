@@ -0,0 +1,573 @@
+// Package jobs tracks background jobs of every kind - syncs, imports,
+// conversions and tile seeding - with common status fields, concurrency
+// limits per kind and records that survive a restart.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// Kind is the kind of work a job does
+type Kind string
+
+// Job kinds
+const (
+	KindSync       Kind = "sync"
+	KindImport     Kind = "import"
+	KindConversion Kind = "conversion"
+	KindSeed       Kind = "seed"
+)
+
+// Kinds lists every job kind
+var Kinds = []Kind{KindSync, KindImport, KindConversion, KindSeed}
+
+// Status is the state of a job
+type Status string
+
+// Job statuses
+const (
+	StatusPending   Status = "pending" // Waiting for a free slot
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Done reports whether a job with this status has finished
+func (s Status) Done() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// ErrCancelled is returned by a job's run function when it stopped because
+// it was cancelled, including by means other than Cancel
+var ErrCancelled = errors.New("job cancelled")
+
+// DefaultLimits is how many jobs of each kind may run at once, unless
+// overridden by SetLimits
+var DefaultLimits = map[Kind]int{
+	KindSync:       2,
+	KindImport:     2,
+	KindConversion: 2,
+	KindSeed:       4,
+}
+
+const (
+	// maxLog is how many log lines are kept per job
+	maxLog = 500
+	// saveInterval limits how often progress of a running job is written
+	saveInterval = 2 * time.Second
+	// retention is how long finished jobs are kept
+	retention = 7 * 24 * time.Hour
+)
+
+// Info is a snapshot of a job, as listed by /api/jobs and stored on disk
+type Info struct {
+	ID          string            `json:"id"`
+	Kind        Kind              `json:"kind"`
+	Title       string            `json:"title"`
+	Status      Status            `json:"status"`
+	Progress    float64           `json:"progress"` // 0-100
+	Message     string            `json:"message,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Log         []string          `json:"log,omitempty"`
+	Details     map[string]string `json:"details,omitempty"` // Kind-specific fields, e.g. the file an import reads
+	CreatedAt   time.Time         `json:"createdAt"`
+	StartedAt   *time.Time        `json:"startedAt,omitempty"`
+	CompletedAt *time.Time        `json:"completedAt,omitempty"`
+}
+
+// RunFunc does a job's work, reporting progress through job. It should
+// return promptly once ctx is cancelled.
+type RunFunc func(ctx context.Context, job *Job) error
+
+// Spec describes a job to submit
+type Spec struct {
+	ID      string // Optional; replaces a finished job with the same ID
+	Kind    Kind
+	Title   string
+	Details map[string]string
+}
+
+// Job is a background job. Its run function reports through the setters;
+// everyone else reads it through Info and the accessors.
+type Job struct {
+	info      Info
+	run       RunFunc
+	ctx       context.Context
+	cancel    context.CancelFunc
+	manager   *Manager
+	lastSaved time.Time
+	mu        sync.Mutex
+}
+
+// ID returns the job's ID
+func (j *Job) ID() string {
+	return j.info.ID // Never changes
+}
+
+// Kind returns the job's kind
+func (j *Job) Kind() Kind {
+	return j.info.Kind // Never changes
+}
+
+// Status returns the job's status
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info.Status
+}
+
+// Progress returns the job's progress, 0-100
+func (j *Job) Progress() float64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info.Progress
+}
+
+// Logs returns a copy of the job's log
+func (j *Job) Logs() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.info.Log...)
+}
+
+// Cancel stops the job; see Manager.Cancel
+func (j *Job) Cancel() bool {
+	return j.manager.Cancel(j.info.ID)
+}
+
+// Info returns a snapshot of the job
+func (j *Job) Info() Info {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.snapshot()
+}
+
+// snapshot copies the job's info. The caller must hold j.mu.
+func (j *Job) snapshot() Info {
+	info := j.info
+	info.Log = append([]string(nil), j.info.Log...)
+	if j.info.Details != nil {
+		info.Details = make(map[string]string, len(j.info.Details))
+		for k, v := range j.info.Details {
+			info.Details[k] = v
+		}
+	}
+	return info
+}
+
+// Detail returns a kind-specific field of the job
+func (j *Job) Detail(key string) string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info.Details[key]
+}
+
+// SetProgress sets the job's progress (0-100) and message
+func (j *Job) SetProgress(progress float64, message string) {
+	j.update(false, func(info *Info) {
+		info.Progress = progress
+		info.Message = message
+	})
+}
+
+// SetMessage sets the job's message without changing its progress
+func (j *Job) SetMessage(message string) {
+	j.update(false, func(info *Info) {
+		info.Message = message
+	})
+}
+
+// SetDetail sets a kind-specific field of the job
+func (j *Job) SetDetail(key, value string) {
+	j.update(false, func(info *Info) {
+		if info.Details == nil {
+			info.Details = make(map[string]string)
+		}
+		info.Details[key] = value
+	})
+}
+
+// AddLog appends a timestamped line to the job's log
+func (j *Job) AddLog(message string) {
+	line := fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), message)
+	j.update(false, func(info *Info) {
+		info.Log = append(info.Log, line)
+		if len(info.Log) > maxLog {
+			info.Log = info.Log[len(info.Log)-maxLog:]
+		}
+	})
+}
+
+// update changes the job and tells the manager. Status changes (force) are
+// always saved; other changes at most every saveInterval.
+func (j *Job) update(force bool, change func(info *Info)) {
+	j.mu.Lock()
+	change(&j.info)
+	info := j.snapshot()
+	save := force || time.Since(j.lastSaved) >= saveInterval
+	if save {
+		j.lastSaved = time.Now()
+	}
+	j.mu.Unlock()
+
+	j.manager.changed(info, save)
+}
+
+// Manager runs jobs, queueing them while their kind is at its limit
+type Manager struct {
+	dir       string // Where job records are kept; empty keeps them in memory only
+	limits    map[Kind]int
+	jobs      map[string]*Job
+	queue     []*Job // Pending jobs in submission order
+	running   map[Kind]int
+	listeners []func(Info)
+	draining  bool          // Set by Shutdown; queued jobs are no longer started
+	idle      chan struct{} // Closed when the last running job ends while draining
+	mu        sync.Mutex
+}
+
+// NewManager creates a job manager keeping records in dir, which may be
+// empty to keep them in memory only
+func NewManager(dir string) *Manager {
+	limits := make(map[Kind]int, len(DefaultLimits))
+	for kind, n := range DefaultLimits {
+		limits[kind] = n
+	}
+	return &Manager{
+		dir:     dir,
+		limits:  limits,
+		jobs:    make(map[string]*Job),
+		running: make(map[Kind]int),
+	}
+}
+
+// Default is the job manager shared by the TUI and web server
+var Default *Manager
+
+func init() {
+	dir, err := config.JobsDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: job records will not be kept: %v\n", err)
+	}
+	Default = NewManager(dir)
+	if err := Default.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load job records: %v\n", err)
+	}
+}
+
+// SetLimits overrides how many jobs of each kind may run at once, e.g. from
+// the config's job_limits. Kinds left out, or set to 0, use DefaultLimits.
+func (m *Manager) SetLimits(limits map[string]int) {
+	m.mu.Lock()
+	for _, kind := range Kinds {
+		m.limits[kind] = DefaultLimits[kind]
+		if n := limits[string(kind)]; n > 0 {
+			m.limits[kind] = n
+		}
+	}
+	started := m.schedule()
+	m.mu.Unlock()
+
+	m.announce(started)
+}
+
+// Limit returns how many jobs of a kind may run at once
+func (m *Manager) Limit(kind Kind) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.limits[kind]
+}
+
+// OnChange registers fn to be called with a snapshot of a job whenever it
+// changes. fn must not block.
+func (m *Manager) OnChange(fn func(Info)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Submit queues a job and starts it as soon as its kind is below its limit.
+// If a job with the spec's ID is still pending or running, that job is
+// returned and run is not used.
+func (m *Manager) Submit(spec Spec, run RunFunc) *Job {
+	id := spec.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &Job{
+		info: Info{
+			ID:        id,
+			Kind:      spec.Kind,
+			Title:     spec.Title,
+			Status:    StatusPending,
+			Message:   "Queued",
+			Details:   spec.Details,
+			CreatedAt: time.Now(),
+		},
+		run:     run,
+		ctx:     ctx,
+		cancel:  cancel,
+		manager: m,
+	}
+
+	m.mu.Lock()
+	if existing, ok := m.jobs[id]; ok && !existing.Status().Done() {
+		m.mu.Unlock()
+		cancel()
+		return existing
+	}
+	m.jobs[id] = job
+	m.queue = append(m.queue, job)
+	started := m.schedule()
+	info := job.Info()
+	m.mu.Unlock()
+
+	m.changed(info, true)
+	m.announce(started)
+	return job
+}
+
+// schedule starts queued jobs whose kind has a free slot and returns them.
+// The caller must hold m.mu and announce the started jobs after unlocking.
+func (m *Manager) schedule() []*Job {
+	if m.draining {
+		return nil
+	}
+	var started []*Job
+	queue := m.queue[:0]
+	for _, job := range m.queue {
+		kind := job.info.Kind
+		if m.running[kind] >= m.limits[kind] {
+			queue = append(queue, job)
+			continue
+		}
+		m.running[kind]++
+		started = append(started, job)
+	}
+	m.queue = queue
+	return started
+}
+
+// announce marks started jobs running and runs them
+func (m *Manager) announce(started []*Job) {
+	for _, job := range started {
+		job.update(true, func(info *Info) {
+			now := time.Now()
+			info.Status = StatusRunning
+			info.StartedAt = &now
+			info.Message = ""
+		})
+		go m.execute(job)
+	}
+}
+
+// execute runs a job and records how it ended
+func (m *Manager) execute(job *Job) {
+	err := job.run(job.ctx, job)
+
+	job.update(true, func(info *Info) {
+		now := time.Now()
+		info.CompletedAt = &now
+		switch {
+		case errors.Is(err, ErrCancelled) || job.ctx.Err() != nil:
+			info.Status = StatusCancelled
+			info.Message = "Cancelled"
+		case err != nil:
+			info.Status = StatusFailed
+			info.Error = err.Error()
+		default:
+			info.Status = StatusCompleted
+			info.Progress = 100
+		}
+	})
+	job.cancel()
+
+	m.mu.Lock()
+	m.running[job.info.Kind]--
+	started := m.schedule()
+	if m.idle != nil && m.runningCount() == 0 {
+		close(m.idle)
+		m.idle = nil
+	}
+	m.mu.Unlock()
+
+	m.announce(started)
+}
+
+// runningCount returns how many jobs are running. The caller must hold m.mu.
+func (m *Manager) runningCount() int {
+	n := 0
+	for _, count := range m.running {
+		n += count
+	}
+	return n
+}
+
+// Shutdown stops starting queued jobs and waits for the running ones to
+// finish. If ctx ends first, the running jobs are cancelled and Shutdown
+// waits briefly for them to record it before returning ctx's error. Queued
+// jobs stay pending and are marked failed when the records are next loaded.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.draining = true
+	if m.runningCount() == 0 {
+		m.mu.Unlock()
+		return nil
+	}
+	if m.idle == nil {
+		m.idle = make(chan struct{})
+	}
+	idle := m.idle
+	queued := make(map[*Job]bool, len(m.queue))
+	for _, job := range m.queue {
+		queued[job] = true
+	}
+	var running []*Job
+	for _, job := range m.jobs {
+		if !queued[job] && !job.Status().Done() {
+			running = append(running, job)
+		}
+	}
+	m.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+	for _, job := range running {
+		job.cancel()
+	}
+	select {
+	case <-idle:
+	case <-time.After(shutdownGrace):
+	}
+	return ctx.Err()
+}
+
+// shutdownGrace is how long Shutdown waits for cancelled jobs to end
+const shutdownGrace = 5 * time.Second
+
+// Get returns a job by ID, or nil
+func (m *Manager) Get(id string) *Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id]
+}
+
+// List returns snapshots of the jobs of a kind, or of all kinds if kind is
+// empty, newest first
+func (m *Manager) List(kind Kind) []Info {
+	m.mu.Lock()
+	jobs := make([]*Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		if kind == "" || job.info.Kind == kind {
+			jobs = append(jobs, job)
+		}
+	}
+	m.mu.Unlock()
+
+	infos := make([]Info, 0, len(jobs))
+	for _, job := range jobs {
+		infos = append(infos, job.Info())
+	}
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].CreatedAt.After(infos[k].CreatedAt)
+	})
+	return infos
+}
+
+// Cancel stops a job. A pending job is dropped from the queue; a running
+// job's context is cancelled and it ends once its run function returns.
+// It returns false if the job does not exist or has finished.
+func (m *Manager) Cancel(id string) bool {
+	m.mu.Lock()
+	job, ok := m.jobs[id]
+	if !ok || job.Status().Done() {
+		m.mu.Unlock()
+		return false
+	}
+
+	queued := false
+	for i, j := range m.queue {
+		if j == job {
+			m.queue = append(m.queue[:i], m.queue[i+1:]...)
+			queued = true
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	job.cancel()
+	if queued {
+		job.update(true, func(info *Info) {
+			now := time.Now()
+			info.Status = StatusCancelled
+			info.Message = "Cancelled"
+			info.CompletedAt = &now
+		})
+	}
+	return true
+}
+
+// Remove deletes a finished job and its record. It returns false if the job
+// does not exist or is still pending or running, here or in another
+// CloudBench process.
+func (m *Manager) Remove(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok || !job.Status().Done() || m.recordRunningElsewhere(id) {
+		return false
+	}
+	delete(m.jobs, id)
+	m.deleteRecord(id)
+	return true
+}
+
+// ClearFinished removes finished jobs of a kind, or of all kinds if kind is
+// empty, and returns how many were removed. Jobs another CloudBench process
+// has since run again under the same ID are kept.
+func (m *Manager) ClearFinished(kind Kind) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	removed := 0
+	for id, job := range m.jobs {
+		if (kind == "" || job.info.Kind == kind) && job.Status().Done() && !m.recordRunningElsewhere(id) {
+			delete(m.jobs, id)
+			m.deleteRecord(id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// changed saves a job's record, if save is set, and tells the listeners
+func (m *Manager) changed(info Info, save bool) {
+	if save {
+		if err := m.writeRecord(info); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	m.mu.Lock()
+	listeners := append([]func(Info){}, m.listeners...)
+	m.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(info)
+	}
+}
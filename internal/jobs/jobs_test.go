@@ -0,0 +1,175 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// waitFor polls until the job reaches a status
+func waitFor(t *testing.T, job *Job, status Status) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for job.Status() != status {
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, expected %s", job.ID(), job.Status(), status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestManagerLimitsCancelAndReload(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir)
+	m.SetLimits(map[string]int{"import": 1})
+
+	release := make(chan struct{})
+	blocking := func(ctx context.Context, job *Job) error {
+		job.AddLog("started")
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ErrCancelled
+		}
+	}
+
+	first := m.Submit(Spec{Kind: KindImport, Title: "first"}, blocking)
+	second := m.Submit(Spec{Kind: KindImport, Title: "second"}, blocking)
+	third := m.Submit(Spec{Kind: KindImport, Title: "third"}, blocking)
+	other := m.Submit(Spec{Kind: KindSeed, Title: "other kind"}, func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		return ErrCancelled
+	})
+
+	waitFor(t, first, StatusRunning)
+	waitFor(t, other, StatusRunning)
+	if second.Status() != StatusPending || third.Status() != StatusPending {
+		t.Fatalf("expected queued imports, got %s and %s", second.Status(), third.Status())
+	}
+
+	// A queued job is dropped without running
+	if !third.Cancel() {
+		t.Fatal("expected the pending job to be cancelled")
+	}
+	waitFor(t, third, StatusCancelled)
+
+	// Finishing the first import starts the next one
+	release <- struct{}{}
+	waitFor(t, first, StatusCompleted)
+	waitFor(t, second, StatusRunning)
+
+	failing := m.Submit(Spec{Kind: KindConversion}, func(ctx context.Context, job *Job) error {
+		return errors.New("gdal missing")
+	})
+	waitFor(t, failing, StatusFailed)
+	if info := failing.Info(); info.Error != "gdal missing" || info.CompletedAt == nil {
+		t.Errorf("unexpected failed job: %+v", info)
+	}
+
+	// A new process sees finished jobs as they were, and unfinished ones as failed
+	reloaded := NewManager(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get(first.ID()); got == nil || got.Status() != StatusCompleted || len(got.Logs()) != 1 {
+		t.Errorf("expected the completed job with its log, got %+v", got)
+	}
+	if got := reloaded.Get(second.ID()); got == nil || got.Status() != StatusFailed {
+		t.Errorf("expected the running job to be marked failed, got %+v", got)
+	}
+	if n := len(reloaded.List(KindImport)); n != 3 {
+		t.Errorf("expected 3 import jobs, got %d", n)
+	}
+
+	close(release)
+	waitFor(t, second, StatusCompleted)
+	other.Cancel()
+	waitFor(t, other, StatusCancelled)
+	if removed := m.ClearFinished(""); removed != 5 {
+		t.Errorf("expected 5 finished jobs to be cleared, got %d", removed)
+	}
+}
+
+func TestManagerShutdown(t *testing.T) {
+	m := NewManager("")
+	m.SetLimits(map[string]int{"sync": 1})
+
+	release := make(chan struct{})
+	finishing := m.Submit(Spec{Kind: KindSync, Title: "finishing"}, func(ctx context.Context, job *Job) error {
+		<-release
+		return nil
+	})
+	queued := m.Submit(Spec{Kind: KindSync, Title: "queued"}, func(ctx context.Context, job *Job) error {
+		return nil
+	})
+	waitFor(t, finishing, StatusRunning)
+
+	// Running jobs are waited for, queued ones are not started
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+	}()
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if finishing.Status() != StatusCompleted || queued.Status() != StatusPending {
+		t.Fatalf("unexpected statuses %s and %s", finishing.Status(), queued.Status())
+	}
+
+	// Jobs still running when the deadline passes are cancelled
+	stuck := NewManager("")
+	job := stuck.Submit(Spec{Kind: KindSeed, Title: "stuck"}, func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		return ErrCancelled
+	})
+	waitFor(t, job, StatusRunning)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := stuck.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to pass, got %v", err)
+	}
+	if job.Status() != StatusCancelled {
+		t.Fatalf("expected the stuck job to be cancelled, got %s", job.Status())
+	}
+}
+
+// TestManagerLeavesOtherProcessesJobs checks that jobs another live
+// CloudBench process is running are neither failed nor removed
+func TestManagerLeavesOtherProcessesJobs(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir)
+	done := m.Submit(Spec{ID: "shared", Kind: KindImport}, func(ctx context.Context, job *Job) error { return nil })
+	waitFor(t, done, StatusCompleted)
+
+	// The parent process, e.g. go test, stands in for another CloudBench
+	other := config.CurrentOwner()
+	other.PID, other.Instance = os.Getppid(), "other"
+	write := func(id string) {
+		data, _ := json.Marshal(record{Info: Info{ID: id, Kind: KindImport, Status: StatusRunning}, Owner: other})
+		if err := os.WriteFile(m.recordPath(id), data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("live")
+	write("shared") // Resubmitted by the other process
+
+	reloaded := NewManager(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.Get("live"); got != nil {
+		t.Errorf("loaded a job another process is running: %+v", got.Info())
+	}
+	if removed := m.ClearFinished(""); removed != 0 {
+		t.Errorf("cleared %d jobs another process is running", removed)
+	}
+	if _, err := os.Stat(m.recordPath("shared")); err != nil {
+		t.Errorf("record of a job another process is running was removed: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// record is a job's info as saved on disk, with the process running it
+type record struct {
+	Info
+	Owner config.ProcessOwner `json:"owner"`
+}
+
+// recordPath returns the file a job's record is kept in
+func (m *Manager) recordPath(id string) string {
+	return filepath.Join(m.dir, id+".json")
+}
+
+// writeRecord saves a job's record, replacing the file atomically so a
+// crash never leaves half a record
+func (m *Manager) writeRecord(info Info) error {
+	if m.dir == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(record{Info: info, Owner: config.CurrentOwner()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", info.ID, err)
+	}
+	tmp := m.recordPath(info.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", info.ID, err)
+	}
+	if err := os.Rename(tmp, m.recordPath(info.ID)); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to save job %s: %w", info.ID, err)
+	}
+	return nil
+}
+
+// deleteRecord removes a job's record, if any
+func (m *Manager) deleteRecord(id string) {
+	if m.dir != "" {
+		os.Remove(m.recordPath(id))
+	}
+}
+
+// readRecord reads a job's record
+func readRecord(path string) (record, error) {
+	var rec record
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rec, err
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, err
+	}
+	if rec.ID == "" {
+		return rec, fmt.Errorf("job record %s has no ID", path)
+	}
+	return rec, nil
+}
+
+// runningElsewhere reports whether another live CloudBench process is
+// running a job, according to its record. A finished job is run again under
+// the same ID when it is resubmitted.
+func (rec record) runningElsewhere() bool {
+	return !rec.Status.Done() && rec.Owner.RunningElsewhere()
+}
+
+// recordRunningElsewhere reports whether a job's record on disk belongs to a
+// job another live CloudBench process is running
+func (m *Manager) recordRunningElsewhere(id string) bool {
+	if m.dir == "" {
+		return false
+	}
+	rec, err := readRecord(m.recordPath(id))
+	return err == nil && rec.runningElsewhere()
+}
+
+// Load registers the jobs recorded on disk, e.g. by a previous CloudBench
+// process. Jobs that were pending or running when their process exited
+// cannot be picked up again and are marked failed; jobs another running
+// CloudBench process owns are left to it. Finished jobs older than the
+// retention period are removed. Unreadable records are skipped.
+func (m *Manager) Load() error {
+	if m.dir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list job records: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-retention)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		rec, err := readRecord(filepath.Join(m.dir, entry.Name()))
+		if err != nil || rec.runningElsewhere() {
+			continue
+		}
+		info := rec.Info
+		if _, exists := m.jobs[info.ID]; exists {
+			continue
+		}
+
+		if !info.Status.Done() {
+			now := time.Now()
+			info.Status = StatusFailed
+			info.Error = "CloudBench exited while the job was running"
+			info.CompletedAt = &now
+		} else if info.CompletedAt != nil && info.CompletedAt.Before(cutoff) {
+			m.deleteRecord(info.ID)
+			continue
+		}
+
+		m.jobs[info.ID] = &Job{info: info, manager: m, cancel: func() {}}
+	}
+	return nil
+}
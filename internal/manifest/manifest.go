@@ -0,0 +1,248 @@
+// Package manifest describes GeoServer configuration declaratively in YAML
+// and reconciles a live server with it. A plan lists the changes that would
+// make the server match the manifest; applying the plan makes them.
+//
+// Only what the manifest states is managed: fields left out are not
+// compared, and objects left out are kept unless pruning is requested.
+//
+// A manifest can also be exported from a live server and stored as a
+// catalog tree with one file per object (see Export and WriteTree).
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifest is the desired configuration of a GeoServer
+type Manifest struct {
+	Workspaces []Workspace `yaml:"workspaces" json:"workspaces"`
+	Styles     []Style     `yaml:"styles,omitempty" json:"styles,omitempty"` // Global styles
+
+	dir string // Directory style files are relative to
+}
+
+// Workspace is a workspace and the objects in it
+type Workspace struct {
+	Name           string          `yaml:"name" json:"name"`
+	Isolated       *bool           `yaml:"isolated,omitempty" json:"isolated,omitempty"`
+	DataStores     []DataStore     `yaml:"datastores,omitempty" json:"datastores,omitempty"`
+	CoverageStores []CoverageStore `yaml:"coveragestores,omitempty" json:"coveragestores,omitempty"`
+	Styles         []Style         `yaml:"styles,omitempty" json:"styles,omitempty"`
+	Layers         []Layer         `yaml:"layers,omitempty" json:"layers,omitempty"`
+	LayerGroups    []LayerGroup    `yaml:"layergroups,omitempty" json:"layergroups,omitempty"`
+}
+
+// DataStore is a vector data store. Parameter values may reference secrets
+// as ${secret:name} (from the CloudBench secrets backend) or ${env:NAME}.
+type DataStore struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Type        string            `yaml:"type,omitempty" json:"type,omitempty"`
+	Enabled     *bool             `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Parameters  map[string]string `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// CoverageStore is a raster store
+type CoverageStore struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Type        string `yaml:"type" json:"type"` // e.g. GeoTIFF, ImageMosaic
+	URL         string `yaml:"url" json:"url"`
+	Enabled     *bool  `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+}
+
+// Style is an SLD style read from a file
+type Style struct {
+	Name string `yaml:"name" json:"name"`
+	File string `yaml:"file" json:"file"` // Relative to the manifest
+
+	sld string // Content exported from a server or read from a catalog tree
+}
+
+// Layer is a feature type or coverage published from a store
+type Layer struct {
+	Name         string     `yaml:"name" json:"name"`
+	Store        string     `yaml:"store" json:"store"`
+	Title        string     `yaml:"title,omitempty" json:"title,omitempty"`
+	Abstract     string     `yaml:"abstract,omitempty" json:"abstract,omitempty"`
+	Keywords     []string   `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	SRS          string     `yaml:"srs,omitempty" json:"srs,omitempty"`
+	Enabled      *bool      `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	Advertised   *bool      `yaml:"advertised,omitempty" json:"advertised,omitempty"`
+	DefaultStyle string     `yaml:"default_style,omitempty" json:"default_style,omitempty"`
+	Styles       []string   `yaml:"styles,omitempty" json:"styles,omitempty"`
+	Tiles        *TileLayer `yaml:"gwc,omitempty" json:"gwc,omitempty"`
+}
+
+// TileLayer is the GeoWebCache configuration of a layer
+type TileLayer struct {
+	Enabled       *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	GridSets      []string `yaml:"gridsets,omitempty" json:"gridsets,omitempty"`
+	Formats       []string `yaml:"formats,omitempty" json:"formats,omitempty"`
+	MetaTiles     []int    `yaml:"metatiles,omitempty,flow" json:"metatiles,omitempty"` // Width and height
+	Gutter        *int     `yaml:"gutter,omitempty" json:"gutter,omitempty"`
+	ExpireCache   *int     `yaml:"expire_cache,omitempty" json:"expire_cache,omitempty"`     // Seconds
+	ExpireClients *int     `yaml:"expire_clients,omitempty" json:"expire_clients,omitempty"` // Seconds
+}
+
+// LayerGroup is a layer group in a workspace
+type LayerGroup struct {
+	Name   string   `yaml:"name" json:"name"`
+	Title  string   `yaml:"title,omitempty" json:"title,omitempty"`
+	Mode   string   `yaml:"mode,omitempty" json:"mode,omitempty"`
+	Layers []string `yaml:"layers" json:"layers"` // workspace:layer, or layer in the group's workspace
+}
+
+// Load reads and validates a manifest file
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m.dir = filepath.Dir(path)
+	return m, nil
+}
+
+// Parse decodes and validates a manifest, rejecting unknown fields so that
+// typos do not silently leave settings unmanaged
+func Parse(data []byte) (*Manifest, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// validate checks names are given and unique, and that layers name a store
+func (m *Manifest) validate() error {
+	seen := make(map[string]bool)
+	unique := func(kind, name string) error {
+		if name == "" || strings.HasSuffix(name, ":") {
+			return fmt.Errorf("a %s has no name", kind)
+		}
+		key := kind + " " + name
+		if seen[key] {
+			return fmt.Errorf("%s %s is listed twice", kind, name)
+		}
+		seen[key] = true
+		return nil
+	}
+
+	for _, s := range m.Styles {
+		if err := unique("style", s.Name); err != nil {
+			return err
+		}
+	}
+	for _, ws := range m.Workspaces {
+		if err := unique("workspace", ws.Name); err != nil {
+			return err
+		}
+		for _, s := range ws.DataStores {
+			if err := unique("store", ws.Name+":"+s.Name); err != nil {
+				return err
+			}
+		}
+		for _, s := range ws.CoverageStores {
+			if err := unique("store", ws.Name+":"+s.Name); err != nil {
+				return err
+			}
+		}
+		for _, s := range ws.Styles {
+			if err := unique("style", ws.Name+":"+s.Name); err != nil {
+				return err
+			}
+			if s.File == "" {
+				return fmt.Errorf("style %s:%s has no file", ws.Name, s.Name)
+			}
+		}
+		for _, l := range ws.Layers {
+			if err := unique("layer", ws.Name+":"+l.Name); err != nil {
+				return err
+			}
+			if l.Store == "" {
+				return fmt.Errorf("layer %s:%s has no store", ws.Name, l.Name)
+			}
+			if t := l.Tiles; t != nil && len(t.MetaTiles) != 0 && len(t.MetaTiles) != 2 {
+				return fmt.Errorf("layer %s:%s: metatiles must be [width, height]", ws.Name, l.Name)
+			}
+		}
+		for _, g := range ws.LayerGroups {
+			if err := unique("layer group", ws.Name+":"+g.Name); err != nil {
+				return err
+			}
+			if len(g.Layers) == 0 {
+				return fmt.Errorf("layer group %s:%s has no layers", ws.Name, g.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// readStyle returns the SLD of a style
+func (m *Manifest) readStyle(s Style) (string, error) {
+	if s.sld != "" {
+		return s.sld, nil
+	}
+	path := s.File
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(m.dir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("style %s: %w", s.Name, err)
+	}
+	return string(data), nil
+}
+
+// secretRef matches ${secret:name} and ${env:NAME} references
+var secretRef = regexp.MustCompile(`\$\{(secret|env):([^}]+)\}`)
+
+// hasSecret reports whether a parameter value references a secret
+func hasSecret(value string) bool {
+	return secretRef.MatchString(value)
+}
+
+// resolve replaces the secret references in a parameter value
+func resolve(value string, secret func(name string) (string, error)) (string, error) {
+	var err error
+	resolved := secretRef.ReplaceAllStringFunc(value, func(ref string) string {
+		match := secretRef.FindStringSubmatch(ref)
+		switch match[1] {
+		case "env":
+			v, ok := os.LookupEnv(match[2])
+			if !ok && err == nil {
+				err = fmt.Errorf("environment variable %s is not set", match[2])
+			}
+			return v
+		default:
+			if secret == nil {
+				if err == nil {
+					err = fmt.Errorf("secret %s: no secrets backend is configured", match[2])
+				}
+				return ""
+			}
+			v, e := secret(match[2])
+			if e != nil && err == nil {
+				err = fmt.Errorf("secret %s: %w", match[2], e)
+			}
+			return v
+		}
+	})
+	return resolved, err
+}
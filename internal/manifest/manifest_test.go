@@ -0,0 +1,441 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+)
+
+// fakeCatalog is an in-memory GeoServer catalog
+type fakeCatalog struct {
+	workspaces  map[string]bool
+	dataStores  map[string]*api.DataStoreDetails
+	styles      map[string]string
+	layers      map[string]*models.LayerMetadata
+	layerStyles map[string]*api.LayerStyles
+	tiles       map[string]models.GWCLayer
+	groups      map[string]*models.LayerGroupDetails
+	calls       []string
+}
+
+func newFakeCatalog() *fakeCatalog {
+	return &fakeCatalog{
+		workspaces:  map[string]bool{},
+		dataStores:  map[string]*api.DataStoreDetails{},
+		styles:      map[string]string{},
+		layers:      map[string]*models.LayerMetadata{},
+		layerStyles: map[string]*api.LayerStyles{},
+		tiles:       map[string]models.GWCLayer{},
+		groups:      map[string]*models.LayerGroupDetails{},
+	}
+}
+
+func (f *fakeCatalog) call(format string, args ...any) {
+	f.calls = append(f.calls, fmt.Sprintf(format, args...))
+}
+
+// list returns the names of objects keyed workspace:name in a workspace
+func list[V any](m map[string]V, workspace string) []string {
+	var names []string
+	for _, key := range sortedKeys(m) {
+		if ws, name, _ := strings.Cut(key, ":"); ws == workspace {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (f *fakeCatalog) GetWorkspaces() ([]models.Workspace, error) {
+	var out []models.Workspace
+	for _, name := range sortedKeys(f.workspaces) {
+		out = append(out, models.Workspace{Name: name})
+	}
+	return out, nil
+}
+
+func (f *fakeCatalog) GetWorkspaceConfig(name string) (*models.WorkspaceConfig, error) {
+	return &models.WorkspaceConfig{Name: name, Isolated: f.workspaces[name]}, nil
+}
+
+func (f *fakeCatalog) CreateWorkspaceWithConfig(config models.WorkspaceConfig) error {
+	f.call("create workspace %s", config.Name)
+	f.workspaces[config.Name] = config.Isolated
+	return nil
+}
+
+func (f *fakeCatalog) SetWorkspaceIsolated(name string, isolated bool) error {
+	f.call("isolate workspace %s %v", name, isolated)
+	f.workspaces[name] = isolated
+	return nil
+}
+
+func (f *fakeCatalog) GetDataStores(workspace string) ([]models.DataStore, error) {
+	var out []models.DataStore
+	for _, name := range list(f.dataStores, workspace) {
+		out = append(out, models.DataStore{Name: name})
+	}
+	return out, nil
+}
+
+func (f *fakeCatalog) GetDataStoreDetails(workspace, storeName string) (*api.DataStoreDetails, error) {
+	d := *f.dataStores[workspace+":"+storeName]
+	return &d, nil
+}
+
+func (f *fakeCatalog) CreateDataStoreFromDetails(workspace string, details *api.DataStoreDetails) error {
+	f.call("create datastore %s:%s", workspace, details.Name)
+	f.dataStores[workspace+":"+details.Name] = details
+	return nil
+}
+
+func (f *fakeCatalog) UpdateDataStoreFromDetails(workspace string, details *api.DataStoreDetails) error {
+	f.call("update datastore %s:%s", workspace, details.Name)
+	f.dataStores[workspace+":"+details.Name] = details
+	return nil
+}
+
+func (f *fakeCatalog) DeleteDataStore(workspace, name string, recurse bool) error {
+	f.call("delete datastore %s:%s", workspace, name)
+	delete(f.dataStores, workspace+":"+name)
+	return nil
+}
+
+func (f *fakeCatalog) GetCoverageStores(workspace string) ([]models.CoverageStore, error) {
+	return nil, nil
+}
+
+func (f *fakeCatalog) GetCoverageStoreDetails(workspace, storeName string) (*api.CoverageStoreDetails, error) {
+	return nil, fmt.Errorf("no coverage store %s", storeName)
+}
+
+func (f *fakeCatalog) CreateCoverageStoreFromDetails(workspace string, details *api.CoverageStoreDetails) error {
+	f.call("create coveragestore %s:%s", workspace, details.Name)
+	return nil
+}
+
+func (f *fakeCatalog) UpdateCoverageStoreFromDetails(workspace string, details *api.CoverageStoreDetails) error {
+	f.call("update coveragestore %s:%s", workspace, details.Name)
+	return nil
+}
+
+func (f *fakeCatalog) DeleteCoverageStore(workspace, name string, recurse bool) error {
+	f.call("delete coveragestore %s:%s", workspace, name)
+	return nil
+}
+
+func (f *fakeCatalog) GetStyles(workspace string) ([]models.Style, error) {
+	var out []models.Style
+	for _, name := range list(f.styles, workspace) {
+		out = append(out, models.Style{Name: name})
+	}
+	return out, nil
+}
+
+func (f *fakeCatalog) GetStyleSLD(workspace, styleName string) (string, error) {
+	return f.styles[workspace+":"+styleName], nil
+}
+
+func (f *fakeCatalog) CreateOrUpdateStyle(workspace, styleName, sldContent string) error {
+	f.call("put style %s:%s", workspace, styleName)
+	f.styles[workspace+":"+styleName] = sldContent
+	return nil
+}
+
+func (f *fakeCatalog) DeleteStyle(workspace, name string, purge bool) error {
+	f.call("delete style %s:%s", workspace, name)
+	delete(f.styles, workspace+":"+name)
+	return nil
+}
+
+func (f *fakeCatalog) GetLayers(workspace string) ([]models.Layer, error) {
+	var out []models.Layer
+	for _, name := range list(f.layers, workspace) {
+		out = append(out, models.Layer{Name: name})
+	}
+	return out, nil
+}
+
+func (f *fakeCatalog) GetLayerMetadata(workspace, layerName string) (*models.LayerMetadata, error) {
+	meta := *f.layers[workspace+":"+layerName]
+	return &meta, nil
+}
+
+func (f *fakeCatalog) UpdateLayerMetadata(workspace string, metadata *models.LayerMetadata) error {
+	f.call("update layer %s:%s", workspace, metadata.Name)
+	f.layers[workspace+":"+metadata.Name] = metadata
+	return nil
+}
+
+func (f *fakeCatalog) GetLayerStyles(workspace, layerName string) (*api.LayerStyles, error) {
+	if s, ok := f.layerStyles[workspace+":"+layerName]; ok {
+		return s, nil
+	}
+	return &api.LayerStyles{}, nil
+}
+
+func (f *fakeCatalog) UpdateLayerStyles(workspace, layerName, defaultStyle string, additionalStyles []string) error {
+	f.call("style layer %s:%s %s", workspace, layerName, defaultStyle)
+	f.layerStyles[workspace+":"+layerName] = &api.LayerStyles{DefaultStyle: defaultStyle, AdditionalStyles: additionalStyles}
+	return nil
+}
+
+func (f *fakeCatalog) PublishFeatureType(workspace, dataStore, featureTypeName string) error {
+	f.call("publish %s:%s from %s", workspace, featureTypeName, dataStore)
+	f.layers[workspace+":"+featureTypeName] = &models.LayerMetadata{Name: featureTypeName, Store: dataStore, Enabled: true}
+	return nil
+}
+
+func (f *fakeCatalog) PublishCoverage(workspace, coverageStore, coverageName string) error {
+	f.call("publish %s:%s from %s", workspace, coverageName, coverageStore)
+	return nil
+}
+
+func (f *fakeCatalog) DeleteLayerWithCleanup(workspace, name string) error {
+	f.call("delete layer %s:%s", workspace, name)
+	delete(f.layers, workspace+":"+name)
+	return nil
+}
+
+func (f *fakeCatalog) GetGWCLayer(layerName string) (*models.GWCLayer, error) {
+	if l, ok := f.tiles[layerName]; ok {
+		return &l, nil
+	}
+	return nil, fmt.Errorf("no tile layer %s", layerName)
+}
+
+func (f *fakeCatalog) UpdateGWCLayer(layer models.GWCLayer) error {
+	f.call("tile layer %s", layer.Name)
+	f.tiles[layer.Name] = layer
+	return nil
+}
+
+func (f *fakeCatalog) GetLayerGroups(workspace string) ([]models.LayerGroup, error) {
+	var out []models.LayerGroup
+	for _, name := range list(f.groups, workspace) {
+		out = append(out, models.LayerGroup{Name: name})
+	}
+	return out, nil
+}
+
+func (f *fakeCatalog) GetLayerGroup(workspace, name string) (*models.LayerGroupDetails, error) {
+	return f.groups[workspace+":"+name], nil
+}
+
+func (f *fakeCatalog) CreateLayerGroup(workspace string, config models.LayerGroupCreate) error {
+	f.call("create layergroup %s:%s", workspace, config.Name)
+	details := &models.LayerGroupDetails{Name: config.Name, Title: config.Title, Mode: config.Mode, Enabled: true}
+	for _, l := range config.Layers {
+		details.Layers = append(details.Layers, models.LayerGroupItem{Type: "layer", Name: l})
+	}
+	f.groups[workspace+":"+config.Name] = details
+	return nil
+}
+
+func (f *fakeCatalog) UpdateLayerGroup(workspace, name string, update models.LayerGroupUpdate) error {
+	f.call("update layergroup %s:%s", workspace, name)
+	return nil
+}
+
+func (f *fakeCatalog) DeleteLayerGroup(workspace, name string) error {
+	f.call("delete layergroup %s:%s", workspace, name)
+	delete(f.groups, workspace+":"+name)
+	return nil
+}
+
+const site = `
+workspaces:
+  - name: cities
+    datastores:
+      - name: postgis
+        type: PostGIS
+        parameters:
+          host: db
+          passwd: ${secret:db-password}
+    styles:
+      - name: roads
+        file: roads.sld
+    layers:
+      - name: roads
+        store: postgis
+        title: Roads
+        default_style: roads
+        gwc:
+          gridsets: [EPSG:3857]
+          metatiles: [4, 4]
+    layergroups:
+      - name: base
+        layers: [roads]
+`
+
+func TestPlanAndApply(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "roads.sld"), []byte("<StyledLayerDescriptor>\n  <Rule/>\n</StyledLayerDescriptor>\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "site.yaml"), []byte(site), 0644)
+	m, err := Load(filepath.Join(dir, "site.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	catalog := newFakeCatalog()
+	catalog.workspaces["cities"] = false
+	catalog.dataStores["cities:old"] = &api.DataStoreDetails{Name: "old"}
+	catalog.styles[":point"] = "<global/>"
+	opts := Options{Prune: true, Secret: func(name string) (string, error) { return "s3cret", nil }}
+
+	plan, err := m.Plan(catalog, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if create, update, remove := plan.Counts(); create != 5 || update != 0 || remove != 1 {
+		t.Fatalf("expected 5 creations and 1 deletion, got %d, %d, %d", create, update, remove)
+	}
+	if err := plan.Apply(catalog, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"delete datastore cities:old",
+		"put style cities:roads",
+		"create datastore cities:postgis",
+		"publish cities:roads from postgis",
+		"update layer cities:roads",
+		"style layer cities:roads roads",
+		"tile layer cities:roads",
+		"create layergroup cities:base",
+	}
+	if got := strings.Join(catalog.calls, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("unexpected calls:\n%s", got)
+	}
+	if catalog.dataStores["cities:postgis"].ConnectionParameters["passwd"] != "s3cret" {
+		t.Error("secret parameter was not resolved")
+	}
+	if _, ok := catalog.styles[":point"]; !ok {
+		t.Error("global style was pruned")
+	}
+
+	// A second plan finds nothing to do, even with reindented SLD
+	catalog.styles["cities:roads"] = "<StyledLayerDescriptor>\n<Rule/>\n</StyledLayerDescriptor>"
+	plan, err = m.Plan(catalog, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		var out strings.Builder
+		plan.Write(&out)
+		t.Fatalf("expected no changes after apply, got:\n%s", out.String())
+	}
+
+	// Drift in a managed field is updated, and only that field is reported
+	catalog.layers["cities:roads"].Title = "Streets"
+	plan, err = m.Plan(catalog, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out strings.Builder
+	plan.Write(&out)
+	if !strings.Contains(out.String(), `~ layer cities:roads`) || !strings.Contains(out.String(), `title: "Streets" -> "Roads"`) {
+		t.Errorf("unexpected plan:\n%s", out.String())
+	}
+}
+
+func TestParseRejectsInvalidManifests(t *testing.T) {
+	for _, manifest := range []string{
+		"workspaces:\n  - name: a\n    layer: []\n",
+		"workspaces:\n  - name: a\n  - name: a\n",
+		"workspaces:\n  - name: a\n    layers:\n      - name: l\n",
+	} {
+		if _, err := Parse([]byte(manifest)); err == nil {
+			t.Errorf("expected an error for:\n%s", manifest)
+		}
+	}
+}
+
+func TestExportTreeRoundTrip(t *testing.T) {
+	catalog := newFakeCatalog()
+	catalog.workspaces["cities"] = true
+	catalog.dataStores["cities:postgis"] = &api.DataStoreDetails{Name: "postgis", Type: "PostGIS", Enabled: true, ConnectionParameters: map[string]string{
+		"host": "db", "passwd": "crypt1:abc", "namespace": "http://cities",
+	}}
+	catalog.styles[":point"] = "<point/>"
+	catalog.styles["cities:roads"] = "<roads/>"
+	catalog.layers["cities:roads"] = &models.LayerMetadata{Name: "roads", Store: "postgis", Title: "Roads", Enabled: true, Advertised: true}
+	catalog.layerStyles["cities:roads"] = &api.LayerStyles{DefaultStyle: "cities:roads"}
+	catalog.tiles["cities:roads"] = models.GWCLayer{Name: "cities:roads", Enabled: true, GridSubsets: []string{"EPSG:3857"}, MimeFormats: []string{"image/png"}, MetaWidth: 4, MetaHeight: 4}
+	catalog.CreateLayerGroup("cities", models.LayerGroupCreate{Name: "base", Mode: "SINGLE", Layers: []string{"cities:roads"}})
+
+	m, err := Export(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "workspaces", "cities", "layers", "rivers.yaml")
+	os.MkdirAll(filepath.Dir(stale), 0755)
+	os.WriteFile(stale, []byte("name: rivers\n"), 0644)
+	if err := m.WriteTree(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale layer file was not removed")
+	}
+	store, _ := os.ReadFile(filepath.Join(dir, "workspaces", "cities", "datastores", "postgis.yaml"))
+	if strings.Contains(string(store), "crypt1") || strings.Contains(string(store), "namespace") ||
+		!strings.Contains(string(store), "${secret:"+SecretName("cities", "postgis", "passwd")+"}") {
+		t.Errorf("secrets or volatile parameters were exported:\n%s", store)
+	}
+	for _, file := range []string{"styles/point.sld", "workspaces/cities/styles/roads.sld", "workspaces/cities/workspace.yaml", "workspaces/cities/layergroups/base.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Errorf("missing %s", file)
+		}
+	}
+
+	// Reimporting onto the same server changes nothing, without needing secrets
+	loaded, err := LoadTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog.calls = nil
+	plan, err := loaded.Plan(catalog, Options{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Changes) != 0 {
+		var out strings.Builder
+		plan.Write(&out)
+		t.Errorf("expected no changes, got:\n%s", out.String())
+	}
+}
+
+// TestRestoreKeepsLiveSecrets restores a data store from a snapshot, whose
+// exported password is a reference to a secret that was never stored
+func TestRestoreKeepsLiveSecrets(t *testing.T) {
+	m, err := LoadTreeFiles(map[string][]byte{
+		"workspaces/topp/datastores/db.yaml": []byte("name: db\ntype: PostGIS\nparameters:\n  host: db1\n  passwd: ${secret:geoserver/topp/db/passwd}\n"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	catalog := newFakeCatalog()
+	catalog.workspaces["topp"] = false
+	catalog.dataStores["topp:db"] = &api.DataStoreDetails{Name: "db", Type: "PostGIS", Enabled: true,
+		ConnectionParameters: map[string]string{"host": "db2", "passwd": "crypt1:abc"}}
+	missing := func(name string) (string, error) { return "", fmt.Errorf("%s not found", name) }
+
+	if _, err := m.Plan(catalog, Options{Secret: missing}); err == nil {
+		t.Fatal("plan resolved a missing secret")
+	}
+	plan, err := m.Plan(catalog, Options{Secret: missing, KeepLiveSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Apply(catalog, nil); err != nil {
+		t.Fatal(err)
+	}
+	if params := catalog.dataStores["topp:db"].ConnectionParameters; params["host"] != "db1" || params["passwd"] != "crypt1:abc" {
+		t.Errorf("restored parameters = %v; want the snapshot host and the live password", params)
+	}
+}
@@ -0,0 +1,869 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+)
+
+// Catalog is the part of the GeoServer REST API a plan reads and changes.
+// *api.Client implements it.
+type Catalog interface {
+	GetWorkspaces() ([]models.Workspace, error)
+	GetWorkspaceConfig(name string) (*models.WorkspaceConfig, error)
+	CreateWorkspaceWithConfig(config models.WorkspaceConfig) error
+	SetWorkspaceIsolated(name string, isolated bool) error
+
+	GetDataStores(workspace string) ([]models.DataStore, error)
+	GetDataStoreDetails(workspace, storeName string) (*api.DataStoreDetails, error)
+	CreateDataStoreFromDetails(workspace string, details *api.DataStoreDetails) error
+	UpdateDataStoreFromDetails(workspace string, details *api.DataStoreDetails) error
+	DeleteDataStore(workspace, name string, recurse bool) error
+
+	GetCoverageStores(workspace string) ([]models.CoverageStore, error)
+	GetCoverageStoreDetails(workspace, storeName string) (*api.CoverageStoreDetails, error)
+	CreateCoverageStoreFromDetails(workspace string, details *api.CoverageStoreDetails) error
+	UpdateCoverageStoreFromDetails(workspace string, details *api.CoverageStoreDetails) error
+	DeleteCoverageStore(workspace, name string, recurse bool) error
+
+	GetStyles(workspace string) ([]models.Style, error)
+	GetStyleSLD(workspace, styleName string) (string, error)
+	CreateOrUpdateStyle(workspace, styleName, sldContent string) error
+	DeleteStyle(workspace, name string, purge bool) error
+
+	GetLayers(workspace string) ([]models.Layer, error)
+	GetLayerMetadata(workspace, layerName string) (*models.LayerMetadata, error)
+	UpdateLayerMetadata(workspace string, metadata *models.LayerMetadata) error
+	GetLayerStyles(workspace, layerName string) (*api.LayerStyles, error)
+	UpdateLayerStyles(workspace, layerName, defaultStyle string, additionalStyles []string) error
+	PublishFeatureType(workspace, dataStore, featureTypeName string) error
+	PublishCoverage(workspace, coverageStore, coverageName string) error
+	DeleteLayerWithCleanup(workspace, name string) error
+
+	GetGWCLayer(layerName string) (*models.GWCLayer, error)
+	UpdateGWCLayer(layer models.GWCLayer) error
+
+	GetLayerGroups(workspace string) ([]models.LayerGroup, error)
+	GetLayerGroup(workspace, name string) (*models.LayerGroupDetails, error)
+	CreateLayerGroup(workspace string, config models.LayerGroupCreate) error
+	UpdateLayerGroup(workspace, name string, update models.LayerGroupUpdate) error
+	DeleteLayerGroup(workspace, name string) error
+}
+
+// Action is what a change does to an object
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// Kind is the kind of object a change affects
+type Kind string
+
+const (
+	KindWorkspace     Kind = "workspace"
+	KindDataStore     Kind = "datastore"
+	KindCoverageStore Kind = "coveragestore"
+	KindStyle         Kind = "style"
+	KindLayer         Kind = "layer"
+	KindTileLayer     Kind = "tilelayer"
+	KindLayerGroup    Kind = "layergroup"
+)
+
+// Change is one change to the live server
+type Change struct {
+	Action    Action      `json:"action"`
+	Kind      Kind        `json:"kind"`
+	Workspace string      `json:"workspace,omitempty"` // Empty for global styles
+	Name      string      `json:"name"`
+	Fields    []FieldDiff `json:"fields,omitempty"` // What an update changes
+
+	apply func(Catalog) error
+}
+
+// FieldDiff is a field whose live value differs from the manifest
+type FieldDiff struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Ref returns the qualified name of the changed object
+func (c Change) Ref() string {
+	if c.Workspace == "" || c.Kind == KindWorkspace {
+		return c.Name
+	}
+	return c.Workspace + ":" + c.Name
+}
+
+// order runs deletions first, dependents before what they depend on, then
+// creations and updates, dependencies first
+func (c Change) order() int {
+	if c.Action == ActionDelete {
+		switch c.Kind {
+		case KindLayerGroup:
+			return 0
+		case KindLayer:
+			return 1
+		case KindDataStore, KindCoverageStore:
+			return 2
+		default:
+			return 3
+		}
+	}
+	switch c.Kind {
+	case KindWorkspace:
+		return 10
+	case KindStyle:
+		return 11
+	case KindDataStore, KindCoverageStore:
+		return 12
+	case KindLayer:
+		return 13
+	case KindTileLayer:
+		return 14
+	default:
+		return 15
+	}
+}
+
+// Plan is the ordered list of changes that make a server match a manifest
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Options control planning
+type Options struct {
+	// Prune deletes stores, styles, layers and layer groups that are in a
+	// workspace of the manifest but not listed in it. Workspaces missing from
+	// the manifest and global styles are never deleted.
+	Prune bool
+	// Secret returns the value of a secret named in a ${secret:name} reference
+	Secret func(name string) (string, error)
+	// KeepLiveSecrets keeps the live value of an existing store's parameter
+	// when its secret reference cannot be resolved, as when restoring a
+	// snapshot whose secrets were never stored
+	KeepLiveSecrets bool
+}
+
+// Counts returns how many objects the plan creates, updates and deletes
+func (p *Plan) Counts() (create, update, remove int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case ActionCreate:
+			create++
+		case ActionUpdate:
+			update++
+		case ActionDelete:
+			remove++
+		}
+	}
+	return
+}
+
+// Write prints the plan for a person to review
+func (p *Plan) Write(w io.Writer) {
+	symbols := map[Action]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	for _, c := range p.Changes {
+		fmt.Fprintf(w, "%s %s %s\n", symbols[c.Action], c.Kind, c.Ref())
+		for _, f := range c.Fields {
+			fmt.Fprintf(w, "    %s: %s -> %s\n", f.Field, f.From, f.To)
+		}
+	}
+	create, update, remove := p.Counts()
+	if create+update+remove == 0 {
+		fmt.Fprintln(w, "No changes. The server matches the manifest.")
+		return
+	}
+	fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to delete.\n", create, update, remove)
+}
+
+// Apply makes the changes in order, stopping at the first failure. done is
+// called after each change that succeeded.
+func (p *Plan) Apply(catalog Catalog, done func(Change)) error {
+	for _, c := range p.Changes {
+		if err := c.apply(catalog); err != nil {
+			return fmt.Errorf("failed to %s %s %s: %w", c.Action, c.Kind, c.Ref(), err)
+		}
+		if done != nil {
+			done(c)
+		}
+	}
+	return nil
+}
+
+// Plan compares the manifest with the live server and returns the changes
+// needed to make the server match it
+func (m *Manifest) Plan(catalog Catalog, opts Options) (*Plan, error) {
+	p := &planner{catalog: catalog, m: m, opts: opts, changes: []Change{}}
+
+	liveWorkspaces, err := catalog.GetWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool)
+	for _, ws := range liveWorkspaces {
+		exists[ws.Name] = true
+	}
+
+	if err := p.styles("", m.Styles, true, false); err != nil {
+		return nil, err
+	}
+	for _, ws := range m.Workspaces {
+		if err := p.workspace(ws, exists[ws.Name]); err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", ws.Name, err)
+		}
+	}
+
+	sort.SliceStable(p.changes, func(i, j int) bool { return p.changes[i].order() < p.changes[j].order() })
+	return &Plan{Changes: p.changes}, nil
+}
+
+// planner accumulates the changes of a plan
+type planner struct {
+	catalog Catalog
+	m       *Manifest
+	opts    Options
+	changes []Change
+}
+
+func (p *planner) add(c Change) {
+	p.changes = append(p.changes, c)
+}
+
+func (p *planner) workspace(ws Workspace, exists bool) error {
+	isolated := ws.Isolated != nil && *ws.Isolated
+	if !exists {
+		p.add(Change{Action: ActionCreate, Kind: KindWorkspace, Name: ws.Name, apply: func(c Catalog) error {
+			return c.CreateWorkspaceWithConfig(models.WorkspaceConfig{Name: ws.Name, Isolated: isolated})
+		}})
+	} else if ws.Isolated != nil {
+		live, err := p.catalog.GetWorkspaceConfig(ws.Name)
+		if err != nil {
+			return err
+		}
+		var fields []FieldDiff
+		diffBool(&fields, "isolated", live.Isolated, ws.Isolated)
+		if len(fields) > 0 {
+			p.add(Change{Action: ActionUpdate, Kind: KindWorkspace, Name: ws.Name, Fields: fields, apply: func(c Catalog) error {
+				return c.SetWorkspaceIsolated(ws.Name, isolated)
+			}})
+		}
+	}
+
+	if err := p.styles(ws.Name, ws.Styles, exists, p.opts.Prune); err != nil {
+		return err
+	}
+	storeKinds, err := p.stores(ws, exists)
+	if err != nil {
+		return err
+	}
+	if err := p.layers(ws, exists, storeKinds); err != nil {
+		return err
+	}
+	return p.layerGroups(ws, exists)
+}
+
+// styles plans the styles of a workspace, or the global styles
+func (p *planner) styles(workspace string, styles []Style, exists, prune bool) error {
+	live := make(map[string]bool)
+	if exists {
+		liveStyles, err := p.catalog.GetStyles(workspace)
+		if err != nil {
+			return err
+		}
+		for _, s := range liveStyles {
+			live[s.Name] = true
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, s := range styles {
+		wanted[s.Name] = true
+		sld, err := p.m.readStyle(s)
+		if err != nil {
+			return err
+		}
+		change := Change{Action: ActionCreate, Kind: KindStyle, Workspace: workspace, Name: s.Name}
+		if live[s.Name] {
+			liveSLD, err := p.catalog.GetStyleSLD(workspace, s.Name)
+			if err != nil {
+				return err
+			}
+			if normalizeSLD(liveSLD) == normalizeSLD(sld) {
+				continue
+			}
+			change.Action = ActionUpdate
+			change.Fields = []FieldDiff{{Field: "sld", From: lineCount(liveSLD), To: s.File + " (" + lineCount(sld) + ")"}}
+		}
+		name := s.Name
+		change.apply = func(c Catalog) error { return c.CreateOrUpdateStyle(workspace, name, sld) }
+		p.add(change)
+	}
+
+	if prune {
+		for _, name := range sortedKeys(live) {
+			if !wanted[name] {
+				name := name
+				p.add(Change{Action: ActionDelete, Kind: KindStyle, Workspace: workspace, Name: name, apply: func(c Catalog) error {
+					return c.DeleteStyle(workspace, name, true)
+				}})
+			}
+		}
+	}
+	return nil
+}
+
+// stores plans the data and coverage stores of a workspace and returns the
+// kind of every store layers may be published from
+func (p *planner) stores(ws Workspace, exists bool) (map[string]Kind, error) {
+	kinds := make(map[string]Kind)
+	liveData := make(map[string]bool)
+	liveCoverage := make(map[string]bool)
+	if exists {
+		dataStores, err := p.catalog.GetDataStores(ws.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range dataStores {
+			liveData[s.Name] = true
+			kinds[s.Name] = KindDataStore
+		}
+		coverageStores, err := p.catalog.GetCoverageStores(ws.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range coverageStores {
+			liveCoverage[s.Name] = true
+			kinds[s.Name] = KindCoverageStore
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, s := range ws.DataStores {
+		wanted[s.Name] = true
+		kinds[s.Name] = KindDataStore
+		if err := p.dataStore(ws.Name, s, liveData[s.Name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, s := range ws.CoverageStores {
+		wanted[s.Name] = true
+		kinds[s.Name] = KindCoverageStore
+		if err := p.coverageStore(ws.Name, s, liveCoverage[s.Name]); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.opts.Prune {
+		for _, name := range sortedKeys(liveData) {
+			if !wanted[name] {
+				name := name
+				p.add(Change{Action: ActionDelete, Kind: KindDataStore, Workspace: ws.Name, Name: name, apply: func(c Catalog) error {
+					return c.DeleteDataStore(ws.Name, name, true)
+				}})
+			}
+		}
+		for _, name := range sortedKeys(liveCoverage) {
+			if !wanted[name] {
+				name := name
+				p.add(Change{Action: ActionDelete, Kind: KindCoverageStore, Workspace: ws.Name, Name: name, apply: func(c Catalog) error {
+					return c.DeleteCoverageStore(ws.Name, name, true)
+				}})
+			}
+		}
+	}
+	return kinds, nil
+}
+
+// dataStore plans a data store. Parameters holding secrets are not compared,
+// since GeoServer does not return passwords as they were set; they are
+// resolved and sent whenever the store is created or updated.
+func (p *planner) dataStore(workspace string, s DataStore, exists bool) error {
+	if !exists {
+		params, err := p.resolveParameters(s, nil)
+		if err != nil {
+			return err
+		}
+		details := &api.DataStoreDetails{Name: s.Name, Description: s.Description, Type: s.Type, Enabled: boolOr(s.Enabled, true), ConnectionParameters: params}
+		p.add(Change{Action: ActionCreate, Kind: KindDataStore, Workspace: workspace, Name: s.Name, apply: func(c Catalog) error {
+			return c.CreateDataStoreFromDetails(workspace, details)
+		}})
+		return nil
+	}
+
+	live, err := p.catalog.GetDataStoreDetails(workspace, s.Name)
+	if err != nil {
+		return err
+	}
+	var fields []FieldDiff
+	diffString(&fields, "description", live.Description, s.Description)
+	diffBool(&fields, "enabled", live.Enabled, s.Enabled)
+	for _, key := range sortedKeys(s.Parameters) {
+		value := s.Parameters[key]
+		if hasSecret(value) {
+			continue
+		}
+		if live.ConnectionParameters[key] != value {
+			fields = append(fields, FieldDiff{Field: "parameters." + key, From: strconv.Quote(live.ConnectionParameters[key]), To: strconv.Quote(value)})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	// Secrets are only needed once the store has to be written
+	params, err := p.resolveParameters(s, live.ConnectionParameters)
+	if err != nil {
+		return err
+	}
+	details := *live
+	details.ConnectionParameters = make(map[string]string, len(live.ConnectionParameters))
+	for key, value := range live.ConnectionParameters {
+		details.ConnectionParameters[key] = value
+	}
+	for key, value := range params {
+		details.ConnectionParameters[key] = value
+	}
+	if s.Description != "" {
+		details.Description = s.Description
+	}
+	details.Enabled = boolOr(s.Enabled, live.Enabled)
+	p.add(Change{Action: ActionUpdate, Kind: KindDataStore, Workspace: workspace, Name: s.Name, Fields: fields, apply: func(c Catalog) error {
+		return c.UpdateDataStoreFromDetails(workspace, &details)
+	}})
+	return nil
+}
+
+// resolveParameters returns a data store's parameters with secret references
+// replaced by their values. With KeepLiveSecrets, parameters that cannot be
+// resolved take their value from live.
+func (p *planner) resolveParameters(s DataStore, live map[string]string) (map[string]string, error) {
+	params := make(map[string]string, len(s.Parameters))
+	for key, value := range s.Parameters {
+		resolved, err := resolve(value, p.opts.Secret)
+		if liveValue, ok := live[key]; err != nil && ok && p.opts.KeepLiveSecrets {
+			resolved, err = liveValue, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("data store %s: parameter %s: %w", s.Name, key, err)
+		}
+		params[key] = resolved
+	}
+	return params, nil
+}
+
+func (p *planner) coverageStore(workspace string, s CoverageStore, exists bool) error {
+	if !exists {
+		details := &api.CoverageStoreDetails{Name: s.Name, Description: s.Description, Type: s.Type, Enabled: boolOr(s.Enabled, true), URL: s.URL}
+		p.add(Change{Action: ActionCreate, Kind: KindCoverageStore, Workspace: workspace, Name: s.Name, apply: func(c Catalog) error {
+			return c.CreateCoverageStoreFromDetails(workspace, details)
+		}})
+		return nil
+	}
+
+	live, err := p.catalog.GetCoverageStoreDetails(workspace, s.Name)
+	if err != nil {
+		return err
+	}
+	var fields []FieldDiff
+	diffString(&fields, "description", live.Description, s.Description)
+	diffString(&fields, "url", live.URL, s.URL)
+	diffBool(&fields, "enabled", live.Enabled, s.Enabled)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	details := *live
+	if s.Description != "" {
+		details.Description = s.Description
+	}
+	if s.URL != "" {
+		details.URL = s.URL
+	}
+	details.Enabled = boolOr(s.Enabled, live.Enabled)
+	p.add(Change{Action: ActionUpdate, Kind: KindCoverageStore, Workspace: workspace, Name: s.Name, Fields: fields, apply: func(c Catalog) error {
+		return c.UpdateCoverageStoreFromDetails(workspace, &details)
+	}})
+	return nil
+}
+
+func (p *planner) layers(ws Workspace, exists bool, storeKinds map[string]Kind) error {
+	live := make(map[string]bool)
+	if exists {
+		liveLayers, err := p.catalog.GetLayers(ws.Name)
+		if err != nil {
+			return err
+		}
+		for _, l := range liveLayers {
+			live[l.Name] = true
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, l := range ws.Layers {
+		wanted[l.Name] = true
+		kind, ok := storeKinds[l.Store]
+		if !ok {
+			return fmt.Errorf("layer %s: store %s does not exist and is not in the manifest", l.Name, l.Store)
+		}
+		if err := p.layer(ws.Name, l, kind, live[l.Name]); err != nil {
+			return fmt.Errorf("layer %s: %w", l.Name, err)
+		}
+	}
+
+	if p.opts.Prune {
+		for _, name := range sortedKeys(live) {
+			if !wanted[name] {
+				name := name
+				p.add(Change{Action: ActionDelete, Kind: KindLayer, Workspace: ws.Name, Name: name, apply: func(c Catalog) error {
+					return c.DeleteLayerWithCleanup(ws.Name, name)
+				}})
+			}
+		}
+	}
+	return nil
+}
+
+func (p *planner) layer(workspace string, l Layer, storeKind Kind, exists bool) error {
+	var metaFields, styleFields []FieldDiff
+	if exists {
+		meta, err := p.catalog.GetLayerMetadata(workspace, l.Name)
+		if err != nil {
+			return err
+		}
+		if meta.Store != "" && meta.Store != l.Store {
+			return fmt.Errorf("it is published from store %s, not %s; delete it to move it", meta.Store, l.Store)
+		}
+		diffString(&metaFields, "title", meta.Title, l.Title)
+		diffString(&metaFields, "abstract", meta.Abstract, l.Abstract)
+		diffList(&metaFields, "keywords", meta.Keywords, l.Keywords)
+		diffString(&metaFields, "srs", meta.SRS, l.SRS)
+		diffBool(&metaFields, "enabled", meta.Enabled, l.Enabled)
+		diffBool(&metaFields, "advertised", meta.Advertised, l.Advertised)
+
+		if l.DefaultStyle != "" || l.Styles != nil {
+			styles, err := p.catalog.GetLayerStyles(workspace, l.Name)
+			if err != nil {
+				return err
+			}
+			diffString(&styleFields, "default_style", localName(workspace, styles.DefaultStyle), localName(workspace, l.DefaultStyle))
+			diffList(&styleFields, "styles", localNames(workspace, styles.AdditionalStyles), localNames(workspace, l.Styles))
+		}
+	}
+
+	setMetadata := !exists && (l.Title != "" || l.Abstract != "" || l.Keywords != nil || l.SRS != "" || l.Enabled != nil || l.Advertised != nil)
+	setStyles := !exists && (l.DefaultStyle != "" || l.Styles != nil)
+	if exists {
+		setMetadata = len(metaFields) > 0
+		setStyles = len(styleFields) > 0
+	}
+
+	if !exists || setMetadata || setStyles {
+		change := Change{Action: ActionUpdate, Kind: KindLayer, Workspace: workspace, Name: l.Name, Fields: append(metaFields, styleFields...)}
+		if !exists {
+			change.Action = ActionCreate
+		}
+		change.apply = func(c Catalog) error {
+			if !exists {
+				var err error
+				if storeKind == KindCoverageStore {
+					err = c.PublishCoverage(workspace, l.Store, l.Name)
+				} else {
+					err = c.PublishFeatureType(workspace, l.Store, l.Name)
+				}
+				if err != nil {
+					return err
+				}
+			}
+			if setMetadata {
+				if err := updateLayerMetadata(c, workspace, l); err != nil {
+					return err
+				}
+			}
+			if setStyles {
+				return updateLayerStyles(c, workspace, l)
+			}
+			return nil
+		}
+		p.add(change)
+	}
+
+	if l.Tiles != nil {
+		return p.tileLayer(workspace, l, exists)
+	}
+	return nil
+}
+
+// updateLayerMetadata writes the metadata the manifest sets over the live metadata
+func updateLayerMetadata(c Catalog, workspace string, l Layer) error {
+	meta, err := c.GetLayerMetadata(workspace, l.Name)
+	if err != nil {
+		return err
+	}
+	if l.Title != "" {
+		meta.Title = l.Title
+	}
+	if l.Abstract != "" {
+		meta.Abstract = l.Abstract
+	}
+	if l.Keywords != nil {
+		meta.Keywords = l.Keywords
+	}
+	if l.SRS != "" {
+		meta.SRS = l.SRS
+	}
+	meta.Enabled = boolOr(l.Enabled, meta.Enabled)
+	meta.Advertised = boolOr(l.Advertised, meta.Advertised)
+	return c.UpdateLayerMetadata(workspace, meta)
+}
+
+// updateLayerStyles writes the styles the manifest sets over the live styles
+func updateLayerStyles(c Catalog, workspace string, l Layer) error {
+	styles, err := c.GetLayerStyles(workspace, l.Name)
+	if err != nil {
+		return err
+	}
+	defaultStyle, additional := styles.DefaultStyle, styles.AdditionalStyles
+	if l.DefaultStyle != "" {
+		defaultStyle = l.DefaultStyle
+	}
+	if l.Styles != nil {
+		additional = l.Styles
+	}
+	return c.UpdateLayerStyles(workspace, l.Name, defaultStyle, additional)
+}
+
+func (p *planner) tileLayer(workspace string, l Layer, layerExists bool) error {
+	ref := workspace + ":" + l.Name
+	var live *models.GWCLayer
+	if layerExists {
+		// A layer without a tile layer is reported as an error
+		live, _ = p.catalog.GetGWCLayer(ref)
+	}
+
+	want := models.GWCLayer{Name: ref, Enabled: true}
+	if live != nil {
+		want = *live
+		want.GridSubsetLevels = append([]models.GWCGridSubset(nil), live.GridSubsetLevels...)
+	}
+	t := l.Tiles
+	want.Enabled = boolOr(t.Enabled, want.Enabled)
+	if t.GridSets != nil {
+		want.GridSubsets = t.GridSets
+		want.GridSubsetLevels = gridSubsetLevels(t.GridSets, want.GridSubsetLevels)
+	}
+	if t.Formats != nil {
+		want.MimeFormats = t.Formats
+	}
+	if len(t.MetaTiles) == 2 {
+		want.MetaWidth, want.MetaHeight = t.MetaTiles[0], t.MetaTiles[1]
+	}
+	want.Gutter = intOr(t.Gutter, want.Gutter)
+	want.ExpireCache = intOr(t.ExpireCache, want.ExpireCache)
+	want.ExpireClients = intOr(t.ExpireClients, want.ExpireClients)
+
+	change := Change{Action: ActionCreate, Kind: KindTileLayer, Workspace: workspace, Name: l.Name}
+	if live != nil {
+		var fields []FieldDiff
+		diffBool(&fields, "enabled", live.Enabled, t.Enabled)
+		diffList(&fields, "gridsets", live.GridSubsets, t.GridSets)
+		diffList(&fields, "formats", live.MimeFormats, t.Formats)
+		if len(t.MetaTiles) == 2 {
+			diffString(&fields, "metatiles", fmt.Sprintf("%dx%d", live.MetaWidth, live.MetaHeight), fmt.Sprintf("%dx%d", t.MetaTiles[0], t.MetaTiles[1]))
+		}
+		diffInt(&fields, "gutter", live.Gutter, t.Gutter)
+		diffInt(&fields, "expire_cache", live.ExpireCache, t.ExpireCache)
+		diffInt(&fields, "expire_clients", live.ExpireClients, t.ExpireClients)
+		if len(fields) == 0 {
+			return nil
+		}
+		change.Action = ActionUpdate
+		change.Fields = fields
+	}
+	change.apply = func(c Catalog) error { return c.UpdateGWCLayer(want) }
+	p.add(change)
+	return nil
+}
+
+// gridSubsetLevels keeps the zoom limits of grid sets that stay configured
+func gridSubsetLevels(gridSets []string, live []models.GWCGridSubset) []models.GWCGridSubset {
+	byName := make(map[string]models.GWCGridSubset)
+	for _, gs := range live {
+		byName[gs.GridSetName] = gs
+	}
+	levels := make([]models.GWCGridSubset, len(gridSets))
+	for i, name := range gridSets {
+		if gs, ok := byName[name]; ok {
+			levels[i] = gs
+		} else {
+			levels[i] = models.GWCGridSubset{GridSetName: name}
+		}
+	}
+	return levels
+}
+
+func (p *planner) layerGroups(ws Workspace, exists bool) error {
+	live := make(map[string]bool)
+	if exists {
+		groups, err := p.catalog.GetLayerGroups(ws.Name)
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			live[g.Name] = true
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, g := range ws.LayerGroups {
+		wanted[g.Name] = true
+		layers := qualifiedNames(ws.Name, g.Layers)
+		mode := strings.ToUpper(g.Mode)
+
+		if !live[g.Name] {
+			if mode == "" {
+				mode = "SINGLE"
+			}
+			create := models.LayerGroupCreate{Name: g.Name, Title: g.Title, Mode: mode, Layers: layers}
+			p.add(Change{Action: ActionCreate, Kind: KindLayerGroup, Workspace: ws.Name, Name: g.Name, apply: func(c Catalog) error {
+				return c.CreateLayerGroup(ws.Name, create)
+			}})
+			continue
+		}
+
+		details, err := p.catalog.GetLayerGroup(ws.Name, g.Name)
+		if err != nil {
+			return err
+		}
+		liveLayers := make([]string, len(details.Layers))
+		for i, item := range details.Layers {
+			liveLayers[i] = item.Name
+		}
+		var fields []FieldDiff
+		diffString(&fields, "title", details.Title, g.Title)
+		diffString(&fields, "mode", details.Mode, mode)
+		diffList(&fields, "layers", qualifiedNames(ws.Name, liveLayers), layers)
+		if len(fields) == 0 {
+			continue
+		}
+
+		update := models.LayerGroupUpdate{Title: g.Title, Mode: mode, Layers: layers, Enabled: details.Enabled}
+		name := g.Name
+		p.add(Change{Action: ActionUpdate, Kind: KindLayerGroup, Workspace: ws.Name, Name: name, Fields: fields, apply: func(c Catalog) error {
+			return c.UpdateLayerGroup(ws.Name, name, update)
+		}})
+	}
+
+	if p.opts.Prune {
+		for _, name := range sortedKeys(live) {
+			if !wanted[name] {
+				name := name
+				p.add(Change{Action: ActionDelete, Kind: KindLayerGroup, Workspace: ws.Name, Name: name, apply: func(c Catalog) error {
+					return c.DeleteLayerGroup(ws.Name, name)
+				}})
+			}
+		}
+	}
+	return nil
+}
+
+// Field comparison helpers. Values the manifest leaves unset are not compared.
+
+func diffString(fields *[]FieldDiff, field, live, want string) {
+	if want != "" && live != want {
+		*fields = append(*fields, FieldDiff{Field: field, From: strconv.Quote(live), To: strconv.Quote(want)})
+	}
+}
+
+func diffBool(fields *[]FieldDiff, field string, live bool, want *bool) {
+	if want != nil && live != *want {
+		*fields = append(*fields, FieldDiff{Field: field, From: strconv.FormatBool(live), To: strconv.FormatBool(*want)})
+	}
+}
+
+func diffInt(fields *[]FieldDiff, field string, live int, want *int) {
+	if want != nil && live != *want {
+		*fields = append(*fields, FieldDiff{Field: field, From: strconv.Itoa(live), To: strconv.Itoa(*want)})
+	}
+}
+
+func diffList(fields *[]FieldDiff, field string, live, want []string) {
+	if want != nil && strings.Join(live, "\x00") != strings.Join(want, "\x00") {
+		*fields = append(*fields, FieldDiff{Field: field, From: "[" + strings.Join(live, ", ") + "]", To: "[" + strings.Join(want, ", ") + "]"})
+	}
+}
+
+func boolOr(v *bool, fallback bool) bool {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+func intOr(v *int, fallback int) int {
+	if v == nil {
+		return fallback
+	}
+	return *v
+}
+
+// localName strips the workspace prefix from a name in that workspace
+func localName(workspace, name string) string {
+	return strings.TrimPrefix(name, workspace+":")
+}
+
+func localNames(workspace string, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	local := make([]string, len(names))
+	for i, name := range names {
+		local[i] = localName(workspace, name)
+	}
+	return local
+}
+
+// qualifiedNames prefixes unqualified names with the workspace
+func qualifiedNames(workspace string, names []string) []string {
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		if !strings.Contains(name, ":") {
+			name = workspace + ":" + name
+		}
+		qualified[i] = name
+	}
+	return qualified
+}
+
+// normalizeSLD ignores indentation and blank lines, which GeoServer may not
+// preserve
+func normalizeSLD(sld string) string {
+	var lines []string
+	for _, line := range strings.Split(sld, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func lineCount(s string) string {
+	return fmt.Sprintf("%d lines", strings.Count(strings.TrimRight(s, "\n"), "\n")+1)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,505 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"gopkg.in/yaml.v3"
+)
+
+// A catalog tree is a manifest split into one file per object, so that a
+// catalog committed to git diffs object by object:
+//
+//	styles/<style>.sld
+//	workspaces/<workspace>/workspace.yaml
+//	workspaces/<workspace>/datastores/<store>.yaml
+//	workspaces/<workspace>/coveragestores/<store>.yaml
+//	workspaces/<workspace>/styles/<style>.sld
+//	workspaces/<workspace>/layers/<layer>.yaml
+//	workspaces/<workspace>/layergroups/<group>.yaml
+//
+// File names are the object names, path-escaped.
+const (
+	treeStyles         = "styles"
+	treeWorkspaces     = "workspaces"
+	treeWorkspaceFile  = "workspace.yaml"
+	treeDataStores     = "datastores"
+	treeCoverageStores = "coveragestores"
+	treeLayers         = "layers"
+	treeLayerGroups    = "layergroups"
+)
+
+// Export reads the catalog of a live server into a manifest. Secret data
+// store parameters are replaced by ${secret:...} references (see SecretName),
+// and fields that change without the configuration changing, such as
+// hrefs and dates, are left out. Styles are exported as SLD.
+func Export(catalog Catalog) (*Manifest, error) {
+	m := &Manifest{Workspaces: []Workspace{}}
+
+	styles, err := exportStyles(catalog, "")
+	if err != nil {
+		return nil, err
+	}
+	m.Styles = styles
+
+	workspaces, err := catalog.GetWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+	for _, w := range workspaces {
+		ws, err := exportWorkspace(catalog, w.Name)
+		if err != nil {
+			return nil, fmt.Errorf("workspace %s: %w", w.Name, err)
+		}
+		m.Workspaces = append(m.Workspaces, ws)
+	}
+	return m, nil
+}
+
+func exportWorkspace(catalog Catalog, name string) (Workspace, error) {
+	ws := Workspace{Name: name}
+	config, err := catalog.GetWorkspaceConfig(name)
+	if err != nil {
+		return ws, err
+	}
+	ws.Isolated = &config.Isolated
+
+	dataStores, err := catalog.GetDataStores(name)
+	if err != nil {
+		return ws, err
+	}
+	for _, s := range dataStores {
+		details, err := catalog.GetDataStoreDetails(name, s.Name)
+		if err != nil {
+			return ws, fmt.Errorf("data store %s: %w", s.Name, err)
+		}
+		store := DataStore{Name: details.Name, Description: details.Description, Type: details.Type, Enabled: boolPtr(details.Enabled), Parameters: map[string]string{}}
+		for key, value := range details.ConnectionParameters {
+			switch {
+			case key == "namespace":
+				// Set by GeoServer from the workspace
+			case isSecretParameter(key, value):
+				store.Parameters[key] = "${secret:" + SecretName(name, s.Name, key) + "}"
+			default:
+				store.Parameters[key] = value
+			}
+		}
+		ws.DataStores = append(ws.DataStores, store)
+	}
+	sort.Slice(ws.DataStores, func(i, j int) bool { return ws.DataStores[i].Name < ws.DataStores[j].Name })
+
+	coverageStores, err := catalog.GetCoverageStores(name)
+	if err != nil {
+		return ws, err
+	}
+	for _, s := range coverageStores {
+		details, err := catalog.GetCoverageStoreDetails(name, s.Name)
+		if err != nil {
+			return ws, fmt.Errorf("coverage store %s: %w", s.Name, err)
+		}
+		ws.CoverageStores = append(ws.CoverageStores, CoverageStore{Name: details.Name, Description: details.Description, Type: details.Type, URL: details.URL, Enabled: boolPtr(details.Enabled)})
+	}
+	sort.Slice(ws.CoverageStores, func(i, j int) bool { return ws.CoverageStores[i].Name < ws.CoverageStores[j].Name })
+
+	if ws.Styles, err = exportStyles(catalog, name); err != nil {
+		return ws, err
+	}
+
+	layers, err := catalog.GetLayers(name)
+	if err != nil {
+		return ws, err
+	}
+	for _, l := range layers {
+		layer, err := exportLayer(catalog, name, l.Name)
+		if err != nil {
+			return ws, fmt.Errorf("layer %s: %w", l.Name, err)
+		}
+		ws.Layers = append(ws.Layers, layer)
+	}
+	sort.Slice(ws.Layers, func(i, j int) bool { return ws.Layers[i].Name < ws.Layers[j].Name })
+
+	groups, err := catalog.GetLayerGroups(name)
+	if err != nil {
+		return ws, err
+	}
+	for _, g := range groups {
+		details, err := catalog.GetLayerGroup(name, g.Name)
+		if err != nil {
+			return ws, fmt.Errorf("layer group %s: %w", g.Name, err)
+		}
+		group := LayerGroup{Name: details.Name, Title: details.Title, Mode: details.Mode}
+		for _, item := range details.Layers {
+			// Nested layer groups cannot be recreated through the API client
+			if item.Type == "layer" {
+				group.Layers = append(group.Layers, localName(name, item.Name))
+			}
+		}
+		ws.LayerGroups = append(ws.LayerGroups, group)
+	}
+	sort.Slice(ws.LayerGroups, func(i, j int) bool { return ws.LayerGroups[i].Name < ws.LayerGroups[j].Name })
+	return ws, nil
+}
+
+func exportStyles(catalog Catalog, workspace string) ([]Style, error) {
+	styles, err := catalog.GetStyles(workspace)
+	if err != nil {
+		return nil, err
+	}
+	dir := treeStyles
+	if workspace != "" {
+		dir = filepath.Join(treeWorkspaces, escapeName(workspace), treeStyles)
+	}
+
+	var out []Style
+	for _, s := range styles {
+		sld, err := catalog.GetStyleSLD(workspace, s.Name)
+		if err != nil {
+			return nil, fmt.Errorf("style %s: %w", s.Name, err)
+		}
+		out = append(out, Style{Name: s.Name, File: filepath.ToSlash(filepath.Join(dir, escapeName(s.Name)+".sld")), sld: sld})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func exportLayer(catalog Catalog, workspace, name string) (Layer, error) {
+	meta, err := catalog.GetLayerMetadata(workspace, name)
+	if err != nil {
+		return Layer{}, err
+	}
+	layer := Layer{
+		Name:       name,
+		Store:      meta.Store,
+		Title:      meta.Title,
+		Abstract:   meta.Abstract,
+		Keywords:   meta.Keywords,
+		SRS:        meta.SRS,
+		Enabled:    boolPtr(meta.Enabled),
+		Advertised: boolPtr(meta.Advertised),
+	}
+
+	styles, err := catalog.GetLayerStyles(workspace, name)
+	if err != nil {
+		return layer, err
+	}
+	layer.DefaultStyle = localName(workspace, styles.DefaultStyle)
+	layer.Styles = localNames(workspace, styles.AdditionalStyles)
+
+	// Layers without a tile layer are reported as an error
+	if gwc, err := catalog.GetGWCLayer(workspace + ":" + name); err == nil {
+		tiles := &TileLayer{
+			Enabled:       boolPtr(gwc.Enabled),
+			GridSets:      gwc.GridSubsets,
+			Formats:       gwc.MimeFormats,
+			Gutter:        &gwc.Gutter,
+			ExpireCache:   &gwc.ExpireCache,
+			ExpireClients: &gwc.ExpireClients,
+		}
+		if gwc.MetaWidth > 0 && gwc.MetaHeight > 0 {
+			tiles.MetaTiles = []int{gwc.MetaWidth, gwc.MetaHeight}
+		}
+		layer.Tiles = tiles
+	}
+	return layer, nil
+}
+
+// SecretName is the name an exported data store parameter's secret is
+// referenced by. Importing the tree needs that secret in the secrets backend,
+// or the reference replaced, e.g. by ${env:NAME}.
+func SecretName(workspace, store, parameter string) string {
+	return "geoserver/" + workspace + "/" + store + "/" + parameter
+}
+
+// isSecretParameter reports whether a data store parameter holds a
+// credential. GeoServer returns these encrypted, so they cannot be exported.
+func isSecretParameter(key, value string) bool {
+	return audit.IsSecret(key, value)
+}
+
+// TreeFiles returns the files of the manifest's catalog tree, keyed by their
+// slash-separated path in the tree
+func (m *Manifest) TreeFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	addYAML := func(path string, v interface{}) error {
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		files[path] = buf.Bytes()
+		return nil
+	}
+	addStyles := func(styles []Style) {
+		for _, s := range styles {
+			files[s.File] = []byte(s.sld)
+		}
+	}
+
+	addStyles(m.Styles)
+	for _, ws := range m.Workspaces {
+		wsDir := treeWorkspaces + "/" + escapeName(ws.Name) + "/"
+		if err := addYAML(wsDir+treeWorkspaceFile, Workspace{Name: ws.Name, Isolated: ws.Isolated}); err != nil {
+			return nil, err
+		}
+		for _, s := range ws.DataStores {
+			if err := addYAML(wsDir+treeDataStores+"/"+escapeName(s.Name)+".yaml", s); err != nil {
+				return nil, err
+			}
+		}
+		for _, s := range ws.CoverageStores {
+			if err := addYAML(wsDir+treeCoverageStores+"/"+escapeName(s.Name)+".yaml", s); err != nil {
+				return nil, err
+			}
+		}
+		addStyles(ws.Styles)
+		for _, l := range ws.Layers {
+			if err := addYAML(wsDir+treeLayers+"/"+escapeName(l.Name)+".yaml", l); err != nil {
+				return nil, err
+			}
+		}
+		for _, g := range ws.LayerGroups {
+			if err := addYAML(wsDir+treeLayerGroups+"/"+escapeName(g.Name)+".yaml", g); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// WriteTree writes the manifest to dir as a catalog tree. Files left in the
+// tree by an earlier export for objects that no longer exist are removed.
+func (m *Manifest) WriteTree(dir string) error {
+	files, err := m.TreeFiles()
+	if err != nil {
+		return err
+	}
+	written := make(map[string]bool)
+	for rel, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+		written[path] = true
+	}
+	return removeStale(dir, written)
+}
+
+// removeStale deletes catalog files under dir that were not written, and
+// directories left empty. Files that are not part of a tree are kept.
+func removeStale(dir string, written map[string]bool) error {
+	for _, sub := range []string{treeStyles, treeWorkspaces} {
+		root := filepath.Join(dir, sub)
+		var dirs []string
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if d.IsDir() {
+				dirs = append(dirs, path)
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, path)
+			if _, _, _, ok := TreeObject(filepath.ToSlash(rel)); ok && !written[path] {
+				return os.Remove(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		// Deepest first, so parents are empty once their children are gone
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i]) // Fails unless empty
+		}
+	}
+	return nil
+}
+
+// TreeObject returns the object a catalog tree path holds. ok is false for
+// paths that are not part of a tree.
+func TreeObject(path string) (kind Kind, workspace, name string, ok bool) {
+	parts := strings.Split(path, "/")
+	file := parts[len(parts)-1]
+	switch {
+	case len(parts) == 2 && parts[0] == treeStyles && strings.HasSuffix(file, ".sld"):
+		kind = KindStyle
+	case len(parts) == 3 && parts[0] == treeWorkspaces && file == treeWorkspaceFile:
+		kind = KindWorkspace
+	case len(parts) == 4 && parts[0] == treeWorkspaces:
+		switch {
+		case parts[2] == treeStyles && strings.HasSuffix(file, ".sld"):
+			kind = KindStyle
+		case parts[2] == treeDataStores && strings.HasSuffix(file, ".yaml"):
+			kind = KindDataStore
+		case parts[2] == treeCoverageStores && strings.HasSuffix(file, ".yaml"):
+			kind = KindCoverageStore
+		case parts[2] == treeLayers && strings.HasSuffix(file, ".yaml"):
+			kind = KindLayer
+		case parts[2] == treeLayerGroups && strings.HasSuffix(file, ".yaml"):
+			kind = KindLayerGroup
+		default:
+			return "", "", "", false
+		}
+	default:
+		return "", "", "", false
+	}
+
+	var err error
+	if len(parts) > 2 {
+		if workspace, err = url.PathUnescape(parts[1]); err != nil {
+			return "", "", "", false
+		}
+	}
+	if kind == KindWorkspace {
+		return kind, workspace, workspace, true
+	}
+	if name, err = url.PathUnescape(strings.TrimSuffix(strings.TrimSuffix(file, ".yaml"), ".sld")); err != nil {
+		return "", "", "", false
+	}
+	return kind, workspace, name, true
+}
+
+// LoadTree reads a catalog tree written by WriteTree into a manifest. Files
+// that are not part of a tree are ignored.
+func LoadTree(dir string) (*Manifest, error) {
+	files := make(map[string][]byte)
+	for _, sub := range []string{treeStyles, treeWorkspaces} {
+		err := filepath.WalkDir(filepath.Join(dir, sub), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			rel, _ := filepath.Rel(dir, path)
+			rel = filepath.ToSlash(rel)
+			if _, _, _, ok := TreeObject(rel); !ok || d.IsDir() {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			files[rel] = data
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m, err := LoadTreeFiles(files)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	m.dir = dir
+	return m, nil
+}
+
+// LoadTreeFiles builds a manifest from catalog tree files keyed by path, as
+// returned by TreeFiles. The files may be any part of a tree; workspaces
+// without a workspace.yaml are managed only through their contents.
+func LoadTreeFiles(files map[string][]byte) (*Manifest, error) {
+	m := &Manifest{}
+	workspaces := make(map[string]*Workspace)
+	var order []string
+	workspace := func(name string) *Workspace {
+		if ws, ok := workspaces[name]; ok {
+			return ws
+		}
+		workspaces[name] = &Workspace{Name: name}
+		order = append(order, name)
+		return workspaces[name]
+	}
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		kind, wsName, name, ok := TreeObject(path)
+		if !ok {
+			continue
+		}
+		data := files[path]
+		if kind == KindStyle {
+			style := Style{Name: name, File: path, sld: string(data)}
+			if wsName == "" {
+				m.Styles = append(m.Styles, style)
+			} else {
+				ws := workspace(wsName)
+				ws.Styles = append(ws.Styles, style)
+			}
+			continue
+		}
+
+		ws := workspace(wsName)
+		var err error
+		switch kind {
+		case KindWorkspace:
+			var w Workspace
+			err = decodeTreeFile(path, data, &w)
+			ws.Isolated = w.Isolated
+		case KindDataStore:
+			var s DataStore
+			err = decodeTreeFile(path, data, &s)
+			ws.DataStores = append(ws.DataStores, s)
+		case KindCoverageStore:
+			var s CoverageStore
+			err = decodeTreeFile(path, data, &s)
+			ws.CoverageStores = append(ws.CoverageStores, s)
+		case KindLayer:
+			var l Layer
+			err = decodeTreeFile(path, data, &l)
+			ws.Layers = append(ws.Layers, l)
+		case KindLayerGroup:
+			var g LayerGroup
+			err = decodeTreeFile(path, data, &g)
+			ws.LayerGroups = append(ws.LayerGroups, g)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, name := range order {
+		m.Workspaces = append(m.Workspaces, *workspaces[name])
+	}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeTreeFile strictly decodes one YAML file of a tree
+func decodeTreeFile(path string, data []byte, v interface{}) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// escapeName makes an object name safe to use as a file name
+func escapeName(name string) string {
+	return url.PathEscape(name)
+}
+
+func boolPtr(v bool) *bool {
+	return &v
+}
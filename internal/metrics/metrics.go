@@ -0,0 +1,168 @@
+// Package metrics writes metrics in the Prometheus text exposition format.
+// Histograms accumulate observations; gauges and counters whose values are
+// read from elsewhere at scrape time are written directly.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the media type of the text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are histogram buckets in seconds suited to request latencies
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Label is a label name and value
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Sample is one value of a gauge or counter
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+// WriteGauge writes a gauge with its samples. Nothing is written when there
+// are no samples.
+func WriteGauge(w io.Writer, name, help string, samples ...Sample) {
+	writeSamples(w, name, help, "gauge", samples)
+}
+
+// WriteCounter writes a counter with its samples
+func WriteCounter(w io.Writer, name, help string, samples ...Sample) {
+	writeSamples(w, name, help, "counter", samples)
+}
+
+func writeSamples(w io.Writer, name, help, kind string, samples []Sample) {
+	if len(samples) == 0 {
+		return
+	}
+	writeHeader(w, name, help, kind)
+	for _, s := range samples {
+		fmt.Fprintf(w, "%s%s %s\n", name, formatLabels(s.Labels), formatValue(s.Value))
+	}
+}
+
+func writeHeader(w io.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// formatLabels formats labels as {a="1",b="2"}, or nothing without labels
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, len(labels))
+	for i, l := range labels {
+		parts[i] = l.Name + `="` + escape.Replace(l.Value) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Histogram counts observations in buckets, per combination of label values
+type Histogram struct {
+	name       string
+	help       string
+	buckets    []float64
+	labelNames []string
+	series     map[string]*histogramSeries
+	mu         sync.Mutex
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // Per bucket, not cumulative
+	sum         float64
+	count       uint64
+}
+
+// NewHistogram creates a histogram with upper bucket bounds in ascending
+// order and the names of its labels
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return &Histogram{
+		name:       name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		series:     make(map[string]*histogramSeries),
+	}
+}
+
+// Observe records a value for the given label values, one per label name
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// Write writes the histogram, its series sorted by label values
+func (h *Histogram) Write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := h.series[key]
+		labels := make([]Label, len(h.labelNames), len(h.labelNames)+1)
+		for i, name := range h.labelNames {
+			labels[i] = Label{Name: name, Value: s.labelValues[i]}
+		}
+
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			le := append(labels, Label{Name: "le", Value: formatValue(bound)})
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(le), cumulative)
+		}
+		le := append(labels, Label{Name: "le", Value: "+Inf"})
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(le), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(labels), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(labels), s.count)
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExposition(t *testing.T) {
+	h := NewHistogram("request_seconds", "Request latency", []float64{0.1, 1}, "route")
+	h.Observe(0.05, "/api/jobs")
+	h.Observe(0.5, "/api/jobs")
+	h.Observe(3, "/api/jobs")
+
+	var b strings.Builder
+	h.Write(&b)
+	WriteGauge(&b, "up", "Whether the server answers", Sample{Labels: []Label{{Name: "name", Value: `a "b"`}}, Value: 1})
+	WriteGauge(&b, "empty", "Not written")
+
+	want := `# HELP request_seconds Request latency
+# TYPE request_seconds histogram
+request_seconds_bucket{route="/api/jobs",le="0.1"} 1
+request_seconds_bucket{route="/api/jobs",le="1"} 2
+request_seconds_bucket{route="/api/jobs",le="+Inf"} 3
+request_seconds_sum{route="/api/jobs"} 3.55
+request_seconds_count{route="/api/jobs"} 3
+# HELP up Whether the server answers
+# TYPE up gauge
+up{name="a \"b\""} 1
+`
+	if b.String() != want {
+		t.Errorf("unexpected exposition:\n%s", b.String())
+	}
+}
@@ -475,6 +475,20 @@ type GWCLayer struct {
 	Enabled    bool     `json:"enabled"`
 	GridSubsets []string `json:"gridSubsets,omitempty"`
 	MimeFormats []string `json:"mimeFormats,omitempty"`
+	// Tiling settings, populated when fetching a single layer
+	GridSubsetLevels []GWCGridSubset `json:"gridSubsetLevels,omitempty"`
+	MetaWidth        int             `json:"metaWidth,omitempty"`
+	MetaHeight       int             `json:"metaHeight,omitempty"`
+	Gutter           int             `json:"gutter,omitempty"`
+	ExpireCache      int             `json:"expireCache,omitempty"`   // Seconds, 0 = never
+	ExpireClients    int             `json:"expireClients,omitempty"` // Seconds, 0 = never
+}
+
+// GWCGridSubset represents a layer's use of a grid set, optionally limited to a zoom range
+type GWCGridSubset struct {
+	GridSetName string `json:"gridSetName"`
+	ZoomStart   *int   `json:"zoomStart,omitempty"`
+	ZoomStop    *int   `json:"zoomStop,omitempty"`
 }
 
 // GWCSeedRequest represents a seed/truncate request for GWC
@@ -523,6 +537,14 @@ type GWCGridSet struct {
 	MinY        float64 `json:"minY,omitempty"`
 	MaxX        float64 `json:"maxX,omitempty"`
 	MaxY        float64 `json:"maxY,omitempty"`
+	// Full definition, populated when fetching a single grid set
+	Description       string    `json:"description,omitempty"`
+	AlignTopLeft      bool      `json:"alignTopLeft,omitempty"`
+	Resolutions       []float64 `json:"resolutions,omitempty"`
+	ScaleDenominators []float64 `json:"scaleDenominators,omitempty"`
+	MetersPerUnit     float64   `json:"metersPerUnit,omitempty"`
+	PixelSize         float64   `json:"pixelSize,omitempty"`
+	YCoordinateFirst  bool      `json:"yCoordinateFirst,omitempty"`
 }
 
 // GWCDiskQuota represents disk quota configuration
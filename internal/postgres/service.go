@@ -9,6 +9,8 @@ import (
 	"strings"
 
 	_ "github.com/lib/pq"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 )
 
 // ServiceEntry represents a PostgreSQL service configuration from pg_service.conf
@@ -242,8 +244,37 @@ func PGServiceFileExists() bool {
 	return false
 }
 
-// SaveServiceEntry saves or updates a service entry in pg_service.conf
-func SaveServiceEntry(entry ServiceEntry) error {
+// auditSummary describes a service entry for the audit log, without its password
+func (s *ServiceEntry) auditSummary() string {
+	if s == nil {
+		return ""
+	}
+	summary := fmt.Sprintf("host=%s port=%s dbname=%s user=%s sslmode=%s", s.Host, s.Port, s.DBName, s.User, s.SSLMode)
+	if s.Hidden {
+		summary += " (hidden)"
+	}
+	return summary
+}
+
+// findService returns the entry with the given name, or nil
+func findService(services []ServiceEntry, name string) *ServiceEntry {
+	for i := range services {
+		if services[i].Name == name {
+			entry := services[i]
+			return &entry
+		}
+	}
+	return nil
+}
+
+// recordService adds a pg_service.conf change to the audit log
+func recordService(actor *audit.Actor, action, name string, before, after *ServiceEntry, err error) {
+	actor.ForConnection(name).Record(audit.ServicePostgres, action, "pg_service.conf/"+name, before.auditSummary(), after.auditSummary(), err)
+}
+
+// SaveServiceEntry saves or updates a service entry in pg_service.conf.
+// actor, which may be nil, is recorded in the audit log.
+func SaveServiceEntry(entry ServiceEntry, actor *audit.Actor) error {
 	path := GetPGServiceFilePath()
 	if path == "" {
 		return fmt.Errorf("could not determine pg_service.conf path")
@@ -256,6 +287,7 @@ func SaveServiceEntry(entry ServiceEntry) error {
 	}
 
 	// Update or add the entry
+	before := findService(services, entry.Name)
 	found := false
 	for i, s := range services {
 		if s.Name == entry.Name {
@@ -269,11 +301,18 @@ func SaveServiceEntry(entry ServiceEntry) error {
 	}
 
 	// Write back to file
-	return writePGServiceFile(path, services)
+	err := writePGServiceFile(path, services)
+	action := audit.ActionUpdate
+	if before == nil {
+		action = audit.ActionCreate
+	}
+	recordService(actor, action, entry.Name, before, &entry, err)
+	return err
 }
 
-// DeleteServiceEntry removes a service entry from pg_service.conf
-func DeleteServiceEntry(name string) error {
+// DeleteServiceEntry removes a service entry from pg_service.conf.
+// actor, which may be nil, is recorded in the audit log.
+func DeleteServiceEntry(name string, actor *audit.Actor) error {
 	path := GetPGServiceFilePath()
 	if path == "" {
 		return fmt.Errorf("could not determine pg_service.conf path")
@@ -292,7 +331,9 @@ func DeleteServiceEntry(name string) error {
 		}
 	}
 
-	return writePGServiceFile(path, filtered)
+	err = writePGServiceFile(path, filtered)
+	recordService(actor, audit.ActionDelete, name, findService(services, name), nil, err)
+	return err
 }
 
 // writePGServiceFile writes service entries to a pg_service.conf file
@@ -341,8 +382,9 @@ func writePGServiceFile(path string, services []ServiceEntry) error {
 	return os.WriteFile(path, []byte(content.String()), 0600)
 }
 
-// SetServiceHidden sets the hidden state for a service
-func SetServiceHidden(name string, hidden bool) error {
+// SetServiceHidden sets the hidden state for a service.
+// actor, which may be nil, is recorded in the audit log.
+func SetServiceHidden(name string, hidden bool, actor *audit.Actor) error {
 	path := GetPGServiceFilePath()
 	if path == "" {
 		return fmt.Errorf("could not determine pg_service.conf path")
@@ -353,6 +395,7 @@ func SetServiceHidden(name string, hidden bool) error {
 		return err
 	}
 
+	before := findService(services, name)
 	found := false
 	for i, s := range services {
 		if s.Name == name {
@@ -366,7 +409,9 @@ func SetServiceHidden(name string, hidden bool) error {
 		return fmt.Errorf("service '%s' not found", name)
 	}
 
-	return writePGServiceFile(path, services)
+	err = writePGServiceFile(path, services)
+	recordService(actor, audit.ActionUpdate, name, before, findService(services, name), err)
+	return err
 }
 
 // ServerStats represents statistics about a PostgreSQL server
@@ -18,6 +18,16 @@ import (
 //go:embed static/*
 var staticFiles embed.FS
 
+// GetPreviewHTML returns the embedded preview page. It requests the layer
+// from api/layer and api/metadata relative to the page's URL.
+func GetPreviewHTML() string {
+	data, err := staticFiles.ReadFile("static/index.html")
+	if err != nil {
+		return "<html><body><h1>Preview not found</h1></body></html>"
+	}
+	return string(data)
+}
+
 // LayerInfo contains information about a layer to preview
 type LayerInfo struct {
 	Name         string `json:"name"`
@@ -147,6 +157,14 @@ func (s *Server) GetPort() int {
 	return s.port
 }
 
+// SetLayer sets the layer to preview without starting the server, for a
+// host that serves the preview page itself
+func (s *Server) SetLayer(layer *LayerInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.layer = layer
+}
+
 // GetCurrentLayer returns the current layer info
 func (s *Server) GetCurrentLayer() *LayerInfo {
 	s.mu.RLock()
@@ -11,6 +11,7 @@ import (
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 )
 
@@ -20,6 +21,7 @@ type Client struct {
 	endpoint string
 	connID   string
 	useSSL   bool
+	audit    *audit.Actor // Records changes; nil when not audited
 }
 
 // NewClient creates a new S3 client from a connection configuration
@@ -62,6 +64,39 @@ func NewClientDirect(endpoint, accessKey, secretKey, region string, useSSL, path
 	}, nil
 }
 
+// WithAudit returns a copy of the client that records every change it makes
+// as actor
+func (c *Client) WithAudit(actor *audit.Actor) *Client {
+	audited := *c
+	audited.audit = actor
+	return &audited
+}
+
+// record adds a change to the audit log, if the client is audited
+func (c *Client) record(action, bucket, key, before, after string, err error) {
+	if c.audit == nil {
+		return
+	}
+	resource := "/" + bucket
+	if key != "" {
+		resource += "/" + key
+	}
+	c.audit.Record(audit.ServiceS3, action, resource, before, after, err)
+}
+
+// describeObject summarizes an object for the audit log, or "" if it does
+// not exist or the client is not audited
+func (c *Client) describeObject(ctx context.Context, bucket, key string) string {
+	if c.audit == nil {
+		return ""
+	}
+	info, err := c.GetObjectInfo(ctx, bucket, key)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d bytes, %s, etag %s, modified %s", info.Size, info.ContentType, info.ETag, info.LastModified.Format(time.RFC3339))
+}
+
 // TestConnection tests the S3 connection by listing buckets
 func (c *Client) TestConnection(ctx context.Context) (*ConnectionTestResult, error) {
 	buckets, err := c.mc.ListBuckets(ctx)
@@ -105,18 +140,20 @@ func (c *Client) CreateBucket(ctx context.Context, bucketName string) error {
 		if errExists == nil && exists {
 			return nil // Bucket already exists, not an error
 		}
-		return fmt.Errorf("failed to create bucket: %w", err)
+		err = fmt.Errorf("failed to create bucket: %w", err)
 	}
-	return nil
+	c.record(audit.ActionCreate, bucketName, "", "", "bucket", err)
+	return err
 }
 
 // DeleteBucket deletes an empty bucket
 func (c *Client) DeleteBucket(ctx context.Context, bucketName string) error {
 	err := c.mc.RemoveBucket(ctx, bucketName)
 	if err != nil {
-		return fmt.Errorf("failed to delete bucket: %w", err)
+		err = fmt.Errorf("failed to delete bucket: %w", err)
 	}
-	return nil
+	c.record(audit.ActionDelete, bucketName, "", "bucket", "", err)
+	return err
 }
 
 // BucketExists checks if a bucket exists
@@ -216,11 +253,7 @@ func (c *Client) PutObject(ctx context.Context, bucket, key string, reader io.Re
 		UserMetadata: opts.Metadata,
 	}
 
-	_, err := c.mc.PutObject(ctx, bucket, key, reader, size, putOpts)
-	if err != nil {
-		return fmt.Errorf("failed to put object: %w", err)
-	}
-	return nil
+	return c.putObject(ctx, bucket, key, reader, size, putOpts)
 }
 
 // PutObjectWithProgress uploads an object with progress callback
@@ -231,11 +264,25 @@ func (c *Client) PutObjectWithProgress(ctx context.Context, bucket, key string,
 		Progress:     &progressReader{callback: progress, total: size},
 	}
 
+	return c.putObject(ctx, bucket, key, reader, size, putOpts)
+}
+
+// putObject uploads an object and records the change
+func (c *Client) putObject(ctx context.Context, bucket, key string, reader io.Reader, size int64, putOpts minio.PutObjectOptions) error {
+	before := c.describeObject(ctx, bucket, key)
+	action := audit.ActionUpdate
+	if before == "" {
+		action = audit.ActionCreate
+	}
+
 	_, err := c.mc.PutObject(ctx, bucket, key, reader, size, putOpts)
 	if err != nil {
-		return fmt.Errorf("failed to put object: %w", err)
+		err = fmt.Errorf("failed to put object: %w", err)
+	}
+	if c.audit != nil {
+		c.record(action, bucket, key, before, fmt.Sprintf("%d bytes, %s", size, putOpts.ContentType), err)
 	}
-	return nil
+	return err
 }
 
 // progressReader implements io.Reader to track upload progress
@@ -251,11 +298,13 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 
 // DeleteObject deletes an object from S3
 func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	before := c.describeObject(ctx, bucket, key)
 	err := c.mc.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to delete object: %w", err)
+		err = fmt.Errorf("failed to delete object: %w", err)
 	}
-	return nil
+	c.record(audit.ActionDelete, bucket, key, before, "", err)
+	return err
 }
 
 // DeleteObjects deletes multiple objects from S3
@@ -269,12 +318,22 @@ func (c *Client) DeleteObjects(ctx context.Context, bucket string, keys []string
 		}
 	}()
 
+	failed := make(map[string]error)
+	var firstErr error
 	for err := range c.mc.RemoveObjects(ctx, bucket, objectsCh, minio.RemoveObjectsOptions{}) {
 		if err.Err != nil {
-			return fmt.Errorf("failed to delete object %s: %w", err.ObjectName, err.Err)
+			failed[err.ObjectName] = err.Err
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to delete object %s: %w", err.ObjectName, err.Err)
+			}
 		}
 	}
-	return nil
+	if c.audit != nil {
+		for _, key := range keys {
+			c.record(audit.ActionDelete, bucket, key, "", "", failed[key])
+		}
+	}
+	return firstErr
 }
 
 // GetPresignedURL generates a presigned URL for downloading an object
@@ -306,11 +365,20 @@ func (c *Client) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket, d
 		Object: dstKey,
 	}
 
+	before := c.describeObject(ctx, dstBucket, dstKey)
+	action := audit.ActionUpdate
+	if before == "" {
+		action = audit.ActionCreate
+	}
+
 	_, err := c.mc.CopyObject(ctx, dst, src)
 	if err != nil {
-		return fmt.Errorf("failed to copy object: %w", err)
+		err = fmt.Errorf("failed to copy object: %w", err)
+	}
+	if c.audit != nil {
+		c.record(action, dstBucket, dstKey, before, "copy of /"+srcBucket+"/"+srcKey, err)
 	}
-	return nil
+	return err
 }
 
 // GetEndpoint returns the S3 endpoint URL
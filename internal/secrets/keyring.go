@@ -0,0 +1,67 @@
+package secrets
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringService is the attribute secrets are stored under in the keyring
+const keyringService = "kartoza-cloudbench"
+
+// Keyring stores secrets in the desktop keyring (Secret Service API) using
+// the secret-tool command from libsecret
+type Keyring struct{}
+
+// NewKeyring creates a keyring backend
+func NewKeyring() *Keyring {
+	return &Keyring{}
+}
+
+// KeyringAvailable reports whether the secret-tool command is installed
+func KeyringAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// Name returns the backend name
+func (k *Keyring) Name() string {
+	return BackendKeyring
+}
+
+// Get returns the value of a secret
+func (k *Keyring) Get(name string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keyringService, "name", name).Output()
+	if err != nil {
+		// secret-tool exits non-zero without output when nothing matches
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("keyring lookup failed: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set stores the value of a secret
+func (k *Keyring) Set(name, value string) error {
+	cmd := exec.Command("secret-tool", "store",
+		"--label", "Kartoza CloudBench: "+name,
+		"service", keyringService, "name", name)
+	cmd.Stdin = strings.NewReader(value)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("keyring store failed: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// Delete removes a secret
+func (k *Keyring) Delete(name string) error {
+	if err := exec.Command("secret-tool", "clear", "service", keyringService, "name", name).Run(); err != nil {
+		return fmt.Errorf("keyring clear failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package secrets keeps connection credentials out of the plaintext config
+// file. Secret values are stored in a Backend and the config holds references
+// ("secret:<name>") in their place.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// RefPrefix marks a config value as a reference to a stored secret
+const RefPrefix = "secret:"
+
+// PassphraseEnv is the environment variable holding the vault passphrase for
+// headless use (e.g. the web server running as a service)
+const PassphraseEnv = "CLOUDBENCH_SECRETS_PASSPHRASE"
+
+// Backend names
+const (
+	BackendVault   = "vault"
+	BackendKeyring = "keyring"
+)
+
+var (
+	// ErrLocked is returned when the vault is needed but no passphrase is known
+	ErrLocked = errors.New("secrets vault is locked")
+	// ErrWrongPassphrase is returned when the vault cannot be decrypted
+	ErrWrongPassphrase = errors.New("wrong secrets passphrase")
+	// ErrNotFound is returned when a referenced secret does not exist
+	ErrNotFound = errors.New("secret not found")
+)
+
+// Backend stores secret values by name
+type Backend interface {
+	// Name returns the backend name
+	Name() string
+
+	// Get returns the value of a secret, or ErrNotFound
+	Get(name string) (string, error)
+
+	// Set stores the value of a secret
+	Set(name, value string) error
+
+	// Delete removes a secret. Deleting a missing secret is not an error.
+	Delete(name string) error
+}
+
+// Lister is implemented by backends that can enumerate their secrets, which
+// allows secrets of deleted connections to be pruned
+type Lister interface {
+	List() ([]string, error)
+}
+
+// IsRef reports whether a config value is a secret reference
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// Ref returns the reference stored in the config for a secret name
+func Ref(name string) string {
+	return RefPrefix + name
+}
+
+// RefName returns the secret name of a reference
+func RefName(ref string) string {
+	return strings.TrimPrefix(ref, RefPrefix)
+}
+
+// Process-wide unlock state, so the vault is only decrypted once
+var (
+	mu         sync.Mutex
+	passphrase string
+	vaults     = make(map[string]*Vault)
+)
+
+// SetPassphrase sets the passphrase used to unlock the vault
+func SetPassphrase(p string) {
+	mu.Lock()
+	defer mu.Unlock()
+	passphrase = p
+}
+
+// currentPassphrase returns the passphrase set with SetPassphrase, falling
+// back to the PassphraseEnv environment variable
+func currentPassphrase() string {
+	if passphrase != "" {
+		return passphrase
+	}
+	return os.Getenv(PassphraseEnv)
+}
+
+// HasPassphrase reports whether a passphrase is available without prompting
+func HasPassphrase() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return currentPassphrase() != ""
+}
+
+// Open returns the named backend. The vault at vaultPath is unlocked with the
+// current passphrase and kept open for the lifetime of the process.
+func Open(backend, vaultPath string) (Backend, error) {
+	switch backend {
+	case BackendVault:
+		mu.Lock()
+		defer mu.Unlock()
+
+		if v, ok := vaults[vaultPath]; ok {
+			return v, nil
+		}
+		p := currentPassphrase()
+		if p == "" {
+			return nil, ErrLocked
+		}
+		v, err := OpenVault(vaultPath, p)
+		if err != nil {
+			return nil, err
+		}
+		vaults[vaultPath] = v
+		return v, nil
+
+	case BackendKeyring:
+		if !KeyringAvailable() {
+			return nil, fmt.Errorf("keyring backend requires secret-tool (libsecret) to be installed")
+		}
+		return NewKeyring(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown secrets backend: %s", backend)
+	}
+}
+
+// Forget closes any open vaults, e.g. after the passphrase was rotated
+func Forget() {
+	mu.Lock()
+	defer mu.Unlock()
+	vaults = make(map[string]*Vault)
+}
+
+// PromptPassphrase reads a passphrase from the terminal without echoing it
+func PromptPassphrase(prompt string) (string, error) {
+	fd := os.Stdin.Fd()
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("%w: set %s or run from a terminal", ErrLocked, PassphraseEnv)
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,379 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const vaultVersion = 1
+
+// KDFParams are the argon2id parameters used to derive an encryption key
+type KDFParams struct {
+	Name    string `json:"name"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+var defaultKDF = KDFParams{Name: "argon2id", Time: 1, Memory: 64 * 1024, Threads: 4}
+
+// validate rejects parameters that are unsupported, or that would take too
+// much memory or time to derive a key with. They come from files that may
+// have been crafted, such as imported bundles.
+func (p KDFParams) validate() error {
+	if p.Name != defaultKDF.Name {
+		return fmt.Errorf("unsupported key derivation: %s", p.Name)
+	}
+	if p.Time < 1 || p.Time > 16 || p.Memory < 8*1024 || p.Memory > 1024*1024 || p.Threads < 1 || p.Threads > 64 {
+		return fmt.Errorf("key derivation parameters out of range (time %d, memory %d KiB, threads %d)", p.Time, p.Memory, p.Threads)
+	}
+	return nil
+}
+
+// vaultFile is the on-disk format of the vault. Secrets are stored as a JSON
+// object encrypted with AES-256-GCM.
+type vaultFile struct {
+	Version    int       `json:"version"`
+	KDF        KDFParams `json:"kdf"`
+	Salt       []byte    `json:"salt"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Sealed is data encrypted with a key derived from a passphrase, e.g. the
+// secrets of an exported connection bundle
+type Sealed struct {
+	KDF        KDFParams `json:"kdf"`
+	Salt       []byte    `json:"salt"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// Seal encrypts plaintext with passphrase
+func Seal(passphrase string, plaintext []byte) (*Sealed, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(deriveKey(passphrase, salt, defaultKDF))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return &Sealed{
+		KDF:        defaultKDF,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+// Open decrypts sealed data, returning ErrWrongPassphrase if it cannot
+func (s *Sealed) Open(passphrase string) ([]byte, error) {
+	if err := s.KDF.validate(); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(deriveKey(passphrase, s.Salt, s.KDF))
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length %d", len(s.Nonce))
+	}
+	plaintext, err := gcm.Open(nil, s.Nonce, s.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// Vault is an encrypted local secrets file unlocked with a master passphrase
+type Vault struct {
+	path       string
+	passphrase string
+
+	mu      sync.Mutex
+	kdf     KDFParams
+	salt    []byte
+	key     []byte
+	secrets map[string]string
+	modTime time.Time
+}
+
+// CreateVault creates a new, empty vault. An existing vault is not overwritten.
+func CreateVault(path, passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("vault already exists: %s", path)
+	}
+
+	v := &Vault{path: path, secrets: make(map[string]string)}
+	if err := v.setPassphrase(passphrase); err != nil {
+		return nil, err
+	}
+	if err := v.write(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// OpenVault decrypts an existing vault
+func OpenVault(path, passphrase string) (*Vault, error) {
+	v := &Vault{path: path, passphrase: passphrase}
+	if err := v.load(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Exists reports whether a vault file exists at path
+func Exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Name returns the backend name
+func (v *Vault) Name() string {
+	return BackendVault
+}
+
+// Path returns the vault file path
+func (v *Vault) Path() string {
+	return v.path
+}
+
+// Get returns the value of a secret
+func (v *Vault) Get(name string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return "", err
+	}
+	value, ok := v.secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Set stores the value of a secret
+func (v *Vault) Set(name, value string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return err
+	}
+	if current, ok := v.secrets[name]; ok && current == value {
+		return nil
+	}
+	v.secrets[name] = value
+	return v.write()
+}
+
+// Delete removes a secret
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return err
+	}
+	if _, ok := v.secrets[name]; !ok {
+		return nil
+	}
+	delete(v.secrets, name)
+	return v.write()
+}
+
+// List returns the names of all stored secrets
+func (v *Vault) List() ([]string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(v.secrets))
+	for name := range v.secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Rotate re-encrypts the vault with a new passphrase
+func (v *Vault) Rotate(newPassphrase string) error {
+	if newPassphrase == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err := v.reload(); err != nil {
+		return err
+	}
+	if err := v.setPassphrase(newPassphrase); err != nil {
+		return err
+	}
+	return v.write()
+}
+
+// setPassphrase derives a new key from passphrase with a fresh salt
+func (v *Vault) setPassphrase(passphrase string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	v.passphrase = passphrase
+	v.kdf = defaultKDF
+	v.salt = salt
+	v.key = deriveKey(passphrase, salt, v.kdf)
+	return nil
+}
+
+func deriveKey(passphrase string, salt []byte, p KDFParams) []byte {
+	return argon2.IDKey([]byte(passphrase), salt, p.Time, p.Memory, p.Threads, 32)
+}
+
+// reload re-reads the vault if another process changed it
+func (v *Vault) reload() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+	if info.ModTime().Equal(v.modTime) {
+		return nil
+	}
+	return v.load()
+}
+
+// load reads and decrypts the vault file
+func (v *Vault) load() error {
+	info, err := os.Stat(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return fmt.Errorf("failed to read vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse vault: %w", err)
+	}
+	if file.Version != vaultVersion || file.KDF.Name != defaultKDF.Name {
+		return fmt.Errorf("unsupported vault format (version %d, kdf %s)", file.Version, file.KDF.Name)
+	}
+	if err := file.KDF.validate(); err != nil {
+		return err
+	}
+
+	// Only derive the key again if the vault was rotated since it was opened
+	key := v.key
+	if key == nil || string(file.Salt) != string(v.salt) || file.KDF != v.kdf {
+		key = deriveKey(v.passphrase, file.Salt, file.KDF)
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	if len(file.Nonce) != gcm.NonceSize() {
+		return fmt.Errorf("invalid nonce length %d", len(file.Nonce))
+	}
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return ErrWrongPassphrase
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("failed to parse vault contents: %w", err)
+	}
+
+	v.kdf = file.KDF
+	v.salt = file.Salt
+	v.key = key
+	v.secrets = secrets
+	v.modTime = info.ModTime()
+	return nil
+}
+
+// write encrypts the secrets and saves the vault atomically
+func (v *Vault) write() error {
+	plaintext, err := json.Marshal(v.secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	gcm, err := newGCM(v.key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	data, err := json.MarshalIndent(vaultFile{
+		Version:    vaultVersion,
+		KDF:        v.kdf,
+		Salt:       v.salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal vault: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(v.path), 0700); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	// Atomic write using temp file
+	tmpPath := v.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write vault: %w", err)
+	}
+	if err := os.Rename(tmpPath, v.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save vault: %w", err)
+	}
+
+	if info, err := os.Stat(v.path); err == nil {
+		v.modTime = info.ModTime()
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.vault")
+
+	v, err := CreateVault(path, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("geoserver/abc/password", "geoserver"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := OpenVault(path, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	if err := v.Rotate("second"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenVault(path, "first"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("old passphrase still opens the vault: %v", err)
+	}
+
+	reopened, err := OpenVault(path, "second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := reopened.Get("geoserver/abc/password"); err != nil || value != "geoserver" {
+		t.Fatalf("Get = %q, %v", value, err)
+	}
+	if _, err := reopened.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSealedRejectsCraftedKDF(t *testing.T) {
+	sealed, err := Seal("pass", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plaintext, err := sealed.Open("pass"); err != nil || string(plaintext) != "secret" {
+		t.Fatalf("Open = %q, %v", plaintext, err)
+	}
+
+	for _, kdf := range []KDFParams{
+		{Name: "argon2id", Time: 1, Memory: 4 * 1024 * 1024, Threads: 4}, // 4 GiB
+		{Name: "argon2id", Time: 1, Memory: 64 * 1024, Threads: 0},
+		{Name: "argon2id", Time: 1000, Memory: 64 * 1024, Threads: 4},
+	} {
+		crafted := *sealed
+		crafted.KDF = kdf
+		if _, err := crafted.Open("pass"); err == nil || errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("Open with %+v: %v, want a parameter error", kdf, err)
+		}
+	}
+
+	crafted := *sealed
+	crafted.Nonce = crafted.Nonce[:4]
+	if _, err := crafted.Open("pass"); err == nil {
+		t.Error("Open with a short nonce succeeded")
+	}
+}
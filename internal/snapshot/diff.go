@@ -0,0 +1,239 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// How an object differs between two snapshots
+const (
+	Added    = "added"
+	Removed  = "removed"
+	Modified = "modified"
+)
+
+// maxLineDiff bounds the work of diffing two SLDs line by line; larger
+// styles are reported as changed without the lines
+const maxLineDiff = 4_000_000
+
+// Change is an object that differs between two snapshots
+type Change struct {
+	Action    string               `json:"action"` // added, removed or modified
+	Kind      manifest.Kind        `json:"kind"`
+	Workspace string               `json:"workspace,omitempty"`
+	Name      string               `json:"name"`
+	Path      string               `json:"path"` // Catalog tree path, for Restore
+	Fields    []manifest.FieldDiff `json:"fields,omitempty"`
+}
+
+// Diff lists the objects added, removed and modified from one snapshot to
+// another, in catalog tree order. Settings are compared field by field, with
+// nested fields named by their path, e.g. parameters.host; styles are
+// compared line by line.
+func (s *Store) Diff(from, to *Snapshot) ([]Change, error) {
+	paths := make(map[string]bool)
+	for path := range from.Files {
+		paths[path] = true
+	}
+	for path := range to.Files {
+		paths[path] = true
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	changes := []Change{}
+	for _, path := range sorted {
+		kind, workspace, name, ok := manifest.TreeObject(path)
+		if !ok {
+			continue
+		}
+		before, inFrom := from.Files[path]
+		after, inTo := to.Files[path]
+		if before == after {
+			continue
+		}
+
+		c := Change{Action: Modified, Kind: kind, Workspace: workspace, Name: name, Path: path}
+		var prev, next []byte
+		var err error
+		if inFrom {
+			if prev, err = s.readObject(before); err != nil {
+				return nil, err
+			}
+		} else {
+			c.Action = Added
+		}
+		if inTo {
+			if next, err = s.readObject(after); err != nil {
+				return nil, err
+			}
+		} else {
+			c.Action = Removed
+		}
+
+		if kind == manifest.KindStyle {
+			c.Fields = diffLines(string(prev), string(next))
+		} else if c.Fields, err = diffFields(prev, next); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// diffFields compares two YAML documents field by field
+func diffFields(prev, next []byte) ([]manifest.FieldDiff, error) {
+	before, err := flatten(prev)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flatten(next)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for k := range before {
+		keys[k] = true
+	}
+	for k := range after {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var fields []manifest.FieldDiff
+	for _, k := range sorted {
+		if before[k] != after[k] {
+			fields = append(fields, manifest.FieldDiff{Field: k, From: before[k], To: after[k]})
+		}
+	}
+	return fields, nil
+}
+
+// flatten reads a YAML document into its scalar fields by dotted path.
+// Lists of scalars are kept whole, as [a, b].
+func flatten(data []byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	if len(data) == 0 {
+		return fields, nil
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	flattenValue(fields, "", doc)
+	return fields, nil
+}
+
+func flattenValue(fields map[string]string, key string, v interface{}) {
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "." + k
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			flattenValue(fields, join(k), item)
+		}
+	case []interface{}:
+		scalars := make([]string, 0, len(v))
+		for i, item := range v {
+			switch item.(type) {
+			case map[string]interface{}, []interface{}:
+				flattenValue(fields, fmt.Sprintf("%s[%d]", key, i), item)
+			default:
+				scalars = append(scalars, fmt.Sprint(item))
+			}
+		}
+		if len(scalars) == len(v) {
+			fields[key] = "[" + strings.Join(scalars, ", ") + "]"
+		}
+	case nil:
+	default:
+		fields[key] = fmt.Sprint(v)
+	}
+}
+
+// diffLines compares two texts line by line, reporting each run of changed
+// lines as a field named by the lines' numbers in the new text
+func diffLines(prev, next string) []manifest.FieldDiff {
+	a, b := splitLines(prev), splitLines(next)
+	if len(a)*len(b) > maxLineDiff {
+		return []manifest.FieldDiff{{Field: "sld", From: fmt.Sprintf("%d lines", len(a)), To: fmt.Sprintf("%d lines", len(b))}}
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var fields []manifest.FieldDiff
+	var removed, added []string
+	start := 0
+	flush := func(j int) {
+		if len(removed) == 0 && len(added) == 0 {
+			return
+		}
+		field := fmt.Sprintf("line %d", start+1)
+		if j-start > 1 {
+			field = fmt.Sprintf("lines %d-%d", start+1, j)
+		}
+		fields = append(fields, manifest.FieldDiff{Field: field, From: strings.Join(removed, "\n"), To: strings.Join(added, "\n")})
+		removed, added = nil, nil
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush(j)
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			if len(removed) == 0 && len(added) == 0 {
+				start = j
+			}
+			added = append(added, b[j])
+			j++
+		default:
+			if len(removed) == 0 && len(added) == 0 {
+				start = j
+			}
+			removed = append(removed, a[i])
+			i++
+		}
+	}
+	flush(j)
+	return fields
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
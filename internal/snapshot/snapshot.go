@@ -0,0 +1,397 @@
+// Package snapshot keeps point-in-time copies of GeoServer catalogs and
+// compares them. A snapshot is a catalog tree, as exported by the manifest
+// package, stored as content-addressed files so that the many objects that
+// do not change between snapshots are stored once.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+)
+
+// What took a snapshot
+const (
+	TriggerManual    = "manual"
+	TriggerScheduled = "scheduled"
+)
+
+// idFormat is the layout of snapshot IDs, which sort by time
+const idFormat = "20060102T150405Z"
+
+// objectsDir holds the content of every snapshot's files, shared between
+// connections
+const objectsDir = "objects"
+
+// ErrNotFound is returned for snapshots that do not exist
+var ErrNotFound = errors.New("snapshot not found")
+
+// Snapshot is the catalog of a connection at a point in time
+type Snapshot struct {
+	ID         string            `json:"id"`
+	Connection string            `json:"connection"`
+	Time       time.Time         `json:"time"`
+	Trigger    string            `json:"trigger"` // manual or scheduled
+	Objects    int               `json:"objects"`
+	Files      map[string]string `json:"files,omitempty"` // Content hash by catalog tree path
+}
+
+// Store keeps snapshots in a directory: an index file per snapshot under a
+// directory per connection, and the files' content gzipped under objects/,
+// named by its SHA-256.
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewStore returns a store keeping snapshots in dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Default is the snapshot store shared by the TUI, web server and CLI
+var Default *Store
+
+func init() {
+	dir, err := config.SnapshotsDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: catalog snapshots will not be kept: %v\n", err)
+	}
+	Default = NewStore(dir)
+}
+
+// Take exports the catalog of a connection and captures it. A client's
+// response cache and offline store are bypassed, so the snapshot holds what
+// is on the server now.
+func Take(store *Store, connID string, catalog manifest.Catalog, trigger string) (*Snapshot, bool, error) {
+	if client, ok := catalog.(*api.Client); ok {
+		catalog = client.Uncached()
+	}
+	m, err := manifest.Export(catalog)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to export catalog: %w", err)
+	}
+	files, err := m.TreeFiles()
+	if err != nil {
+		return nil, false, err
+	}
+	return store.Capture(connID, trigger, files)
+}
+
+// Capture stores catalog tree files as a new snapshot of a connection. When
+// they are the same as in the connection's latest snapshot, nothing is
+// stored: the latest snapshot is returned with created false.
+func (s *Store) Capture(connID, trigger string, files map[string][]byte) (snap *Snapshot, created bool, err error) {
+	if s.dir == "" {
+		return nil, false, fmt.Errorf("no snapshot directory")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hashes := make(map[string]string, len(files))
+	for path, data := range files {
+		hash, err := s.writeObject(data)
+		if err != nil {
+			return nil, false, err
+		}
+		hashes[path] = hash
+	}
+
+	list, err := s.list(connID)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(list) > 0 {
+		latest, err := s.get(connID, list[0].ID)
+		if err != nil {
+			return nil, false, err
+		}
+		if sameFiles(latest.Files, hashes) {
+			return latest, false, nil
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	snap = &Snapshot{
+		ID:         now.Format(idFormat),
+		Connection: connID,
+		Time:       now,
+		Trigger:    trigger,
+		Objects:    len(hashes),
+		Files:      hashes,
+	}
+	for i := 2; s.exists(connID, snap.ID); i++ {
+		snap.ID = fmt.Sprintf("%s-%d", now.Format(idFormat), i)
+	}
+	if err := s.writeIndex(snap); err != nil {
+		return nil, false, err
+	}
+	return snap, true, nil
+}
+
+// List returns the snapshots of a connection, newest first, without their
+// file lists
+func (s *Store) List(connID string) ([]Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(connID)
+}
+
+func (s *Store) list(connID string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.connDir(connID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Snapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	list := []Snapshot{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		snap, err := s.get(connID, strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue // Unreadable index, e.g. from a crash
+		}
+		snap.Files = nil
+		list = append(list, *snap)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Time.Equal(list[j].Time) {
+			return list[i].Time.After(list[j].Time)
+		}
+		return list[i].ID > list[j].ID
+	})
+	return list, nil
+}
+
+// Get returns a snapshot with its file list
+func (s *Store) Get(connID, id string) (*Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(connID, id)
+}
+
+func (s *Store) get(connID, id string) (*Snapshot, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.indexPath(connID, id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("snapshot %s: %w", id, err)
+	}
+	if snap.Files == nil {
+		snap.Files = map[string]string{}
+	}
+	return &snap, nil
+}
+
+// Delete removes a snapshot, and the stored content no other snapshot uses
+func (s *Store) Delete(connID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.get(connID, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.indexPath(connID, id)); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %w", id, err)
+	}
+	os.Remove(s.connDir(connID)) // Fails unless empty
+	return s.collect()
+}
+
+// ReadFile returns the content of a snapshot's file
+func (s *Store) ReadFile(snap *Snapshot, path string) ([]byte, error) {
+	hash, ok := snap.Files[path]
+	if !ok {
+		return nil, fmt.Errorf("snapshot %s has no %s", snap.ID, path)
+	}
+	return s.readObject(hash)
+}
+
+// Restore returns a manifest holding one object of a snapshot, given by its
+// catalog tree path. Applying it without pruning recreates the object, or
+// reverts its settings, and leaves everything else alone.
+func (s *Store) Restore(snap *Snapshot, path string) (*manifest.Manifest, error) {
+	if _, _, _, ok := manifest.TreeObject(path); !ok {
+		return nil, fmt.Errorf("%s is not a catalog object", path)
+	}
+	data, err := s.ReadFile(snap, path)
+	if err != nil {
+		return nil, err
+	}
+	return manifest.LoadTreeFiles(map[string][]byte{path: data})
+}
+
+// collect removes stored content that no snapshot uses
+func (s *Store) collect() error {
+	used := make(map[string]bool)
+	conns, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, conn := range conns {
+		if !conn.IsDir() || conn.Name() == objectsDir {
+			continue
+		}
+		connID, err := url.PathUnescape(conn.Name())
+		if err != nil {
+			continue
+		}
+		entries, err := os.ReadDir(filepath.Join(s.dir, conn.Name()))
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if !strings.HasSuffix(entry.Name(), ".json") {
+				continue
+			}
+			snap, err := s.get(connID, strings.TrimSuffix(entry.Name(), ".json"))
+			if err != nil {
+				// Keep everything rather than lose content an index we cannot
+				// read may refer to
+				return nil
+			}
+			for _, hash := range snap.Files {
+				used[hash] = true
+			}
+		}
+	}
+
+	return filepath.WalkDir(filepath.Join(s.dir, objectsDir), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() && !used[d.Name()] {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
+
+func (s *Store) connDir(connID string) string {
+	return filepath.Join(s.dir, url.PathEscape(connID))
+}
+
+func (s *Store) indexPath(connID, id string) string {
+	return filepath.Join(s.connDir(connID), id+".json")
+}
+
+func (s *Store) objectPath(hash string) string {
+	return filepath.Join(s.dir, objectsDir, hash[:2], hash)
+}
+
+func (s *Store) exists(connID, id string) bool {
+	_, err := os.Stat(s.indexPath(connID, id))
+	return err == nil
+}
+
+// writeIndex saves a snapshot's index, replacing the file atomically so a
+// crash never leaves half an index
+func (s *Store) writeIndex(snap *Snapshot) error {
+	if err := os.MkdirAll(s.connDir(snap.Connection), 0755); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot %s: %w", snap.ID, err)
+	}
+	path := s.indexPath(snap.Connection, snap.ID)
+	if err := writeAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to save snapshot %s: %w", snap.ID, err)
+	}
+	return nil
+}
+
+// writeObject stores content under its hash, unless it is already stored
+func (s *Store) writeObject(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	path := s.objectPath(hash)
+	if _, err := os.Stat(path); err == nil {
+		return hash, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", fmt.Errorf("failed to store snapshot content: %w", err)
+	}
+	if err := writeAtomic(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to store snapshot content: %w", err)
+	}
+	return hash, nil
+}
+
+func (s *Store) readObject(hash string) ([]byte, error) {
+	if len(hash) < 2 || strings.ContainsAny(hash, `/\.`) {
+		return nil, fmt.Errorf("invalid content hash %q", hash)
+	}
+	f, err := os.Open(s.objectPath(hash))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot content: %w", err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot content: %w", err)
+	}
+	return io.ReadAll(zr)
+}
+
+func writeAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func sameFiles(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, hash := range a {
+		if b[path] != hash {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+)
+
+func TestCaptureDiffAndRestore(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	first := map[string][]byte{
+		"styles/line.sld":                         []byte("<sld>\n<stroke>red</stroke>\n</sld>\n"),
+		"workspaces/topp/workspace.yaml":          []byte("name: topp\nisolated: false\n"),
+		"workspaces/topp/datastores/db.yaml":      []byte("name: db\ntype: PostGIS\nparameters:\n  host: db1\n  port: \"5432\"\n"),
+		"workspaces/topp/layers/roads.yaml":       []byte("name: roads\nstore: db\nkeywords: [a, b]\n"),
+		"workspaces/topp/styles/obsolete.sld":     []byte("<sld/>\n"),
+		"workspaces/topp/layergroups/base.yaml":   []byte("name: base\nlayers: [roads]\n"),
+		"workspaces/topp/coveragestores/dem.yaml": []byte("name: dem\ntype: GeoTIFF\nurl: file:dem.tif\n"),
+	}
+	snap1, created, err := store.Capture("conn-1", TriggerManual, first)
+	if err != nil || !created {
+		t.Fatalf("Capture() = %v, %v", created, err)
+	}
+	if snap1.Objects != len(first) {
+		t.Errorf("Objects = %d, want %d", snap1.Objects, len(first))
+	}
+
+	// An unchanged catalog is not captured again
+	same, created, err := store.Capture("conn-1", TriggerScheduled, first)
+	if err != nil || created || same.ID != snap1.ID {
+		t.Fatalf("Capture(unchanged) = %v, %v, %v; want the first snapshot", same.ID, created, err)
+	}
+
+	second := make(map[string][]byte)
+	for k, v := range first {
+		second[k] = v
+	}
+	second["styles/line.sld"] = []byte("<sld>\n<stroke>blue</stroke>\n</sld>\n")
+	second["workspaces/topp/datastores/db.yaml"] = []byte("name: db\ntype: PostGIS\nparameters:\n  host: db2\n  port: \"5432\"\n")
+	second["workspaces/topp/layers/rivers.yaml"] = []byte("name: rivers\nstore: db\n")
+	delete(second, "workspaces/topp/styles/obsolete.sld")
+	snap2, created, err := store.Capture("conn-1", TriggerScheduled, second)
+	if err != nil || !created {
+		t.Fatalf("Capture(changed) = %v, %v", created, err)
+	}
+	if snap2.ID == snap1.ID {
+		t.Errorf("snapshots taken in the same second share ID %s", snap1.ID)
+	}
+
+	list, err := store.List("conn-1")
+	if err != nil || len(list) != 2 || list[0].ID != snap2.ID || list[0].Files != nil {
+		t.Fatalf("List() = %+v, %v; want both snapshots, newest first, without files", list, err)
+	}
+
+	changes, err := store.Diff(snap1, snap2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{Action: Modified, Kind: manifest.KindStyle, Name: "line", Path: "styles/line.sld",
+			Fields: []manifest.FieldDiff{{Field: "line 2", From: "<stroke>red</stroke>", To: "<stroke>blue</stroke>"}}},
+		{Action: Modified, Kind: manifest.KindDataStore, Workspace: "topp", Name: "db", Path: "workspaces/topp/datastores/db.yaml",
+			Fields: []manifest.FieldDiff{{Field: "parameters.host", From: "db1", To: "db2"}}},
+		{Action: Added, Kind: manifest.KindLayer, Workspace: "topp", Name: "rivers", Path: "workspaces/topp/layers/rivers.yaml",
+			Fields: []manifest.FieldDiff{{Field: "name", To: "rivers"}, {Field: "store", To: "db"}}},
+		{Action: Removed, Kind: manifest.KindStyle, Workspace: "topp", Name: "obsolete", Path: "workspaces/topp/styles/obsolete.sld",
+			Fields: []manifest.FieldDiff{{Field: "line 1", From: "<sld/>"}}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", changes, want)
+	}
+
+	// Restoring one object gives a manifest with only that object
+	m, err := store.Restore(snap1, "workspaces/topp/layers/roads.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Styles) != 0 || len(m.Workspaces) != 1 || m.Workspaces[0].Isolated != nil ||
+		len(m.Workspaces[0].Layers) != 1 || len(m.Workspaces[0].DataStores) != 0 {
+		t.Fatalf("Restore() = %+v; want the roads layer alone", m)
+	}
+	if l := m.Workspaces[0].Layers[0]; l.Name != "roads" || l.Store != "db" || !reflect.DeepEqual(l.Keywords, []string{"a", "b"}) {
+		t.Errorf("restored layer = %+v", l)
+	}
+
+	// Deleting a snapshot removes the content only it used
+	obsolete := store.objectPath(snap1.Files["workspaces/topp/styles/obsolete.sld"])
+	shared := store.objectPath(snap1.Files["workspaces/topp/layers/roads.yaml"])
+	if err := store.Delete("conn-1", snap1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(obsolete); !os.IsNotExist(err) {
+		t.Errorf("content only the deleted snapshot used was kept: %v", err)
+	}
+	if _, err := os.Stat(shared); err != nil {
+		t.Errorf("content still in use was removed: %v", err)
+	}
+	if _, err := store.Get("conn-1", snap1.ID); err != ErrNotFound {
+		t.Errorf("Get(deleted) error = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Get("conn-1", filepath.Join("..", "x")); err != ErrNotFound {
+		t.Errorf("Get(path) error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,256 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// checkpointInterval limits how often a running task's checkpoint is written
+const checkpointInterval = 2 * time.Second
+
+// Checkpoint is the on-disk progress record of a sync task. It holds
+// everything needed to resume the task after a stop, failure or restart.
+type Checkpoint struct {
+	TaskID     string                   `json:"task_id"`
+	ConfigID   string                   `json:"config_id,omitempty"`
+	SourceID   string                   `json:"source_id"`
+	DestID     string                   `json:"dest_id"`
+	Options    config.SyncOptions       `json:"options"`
+	Mappings   *config.SyncMappingRules `json:"mappings,omitempty"`
+	Status     string                   `json:"status"`
+	ItemsTotal int                      `json:"items_total"`
+	Completed  map[string]string        `json:"completed"`        // Item key -> "done" or "skipped"
+	Failed     map[string]WorkItem      `json:"failed,omitempty"` // Item key -> item, for retrying
+	StartedAt  time.Time                `json:"started_at"`
+	UpdatedAt  time.Time                `json:"updated_at"`
+	Owner      config.ProcessOwner      `json:"owner"` // Process that last ran the task
+
+	mu        sync.Mutex
+	dirty     bool
+	lastSaved time.Time
+}
+
+// newCheckpoint creates an empty checkpoint for a task
+func newCheckpoint(task *Task, options config.SyncOptions, mappings *config.SyncMappingRules) *Checkpoint {
+	return &Checkpoint{
+		TaskID:    task.ID,
+		ConfigID:  task.ConfigID,
+		SourceID:  task.SourceID,
+		DestID:    task.DestID,
+		Options:   options,
+		Mappings:  mappings,
+		Status:    task.Status,
+		Completed: make(map[string]string),
+		Failed:    make(map[string]WorkItem),
+		StartedAt: task.StartedAt,
+		Owner:     config.CurrentOwner(),
+	}
+}
+
+// runningElsewhere reports whether another live CloudBench process is
+// running the task
+func (c *Checkpoint) runningElsewhere() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return (c.Status == "running" || c.Status == "pending") && c.Owner.RunningElsewhere()
+}
+
+// checkpointPath returns the file a task's checkpoint is stored in
+func checkpointPath(taskID string) (string, error) {
+	dir, err := config.SyncCheckpointsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, taskID+".json"), nil
+}
+
+// loadCheckpoint reads a task's checkpoint from disk
+func loadCheckpoint(taskID string) (*Checkpoint, error) {
+	path, err := checkpointPath(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return readCheckpoint(path)
+}
+
+func readCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if cp.Completed == nil {
+		cp.Completed = make(map[string]string)
+	}
+	if cp.Failed == nil {
+		cp.Failed = make(map[string]WorkItem)
+	}
+	return &cp, nil
+}
+
+// listCheckpoints reads all checkpoints on disk, ignoring unreadable files
+func listCheckpoints() ([]*Checkpoint, error) {
+	dir, err := config.SyncCheckpointsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checkpoints: %w", err)
+	}
+
+	var checkpoints []*Checkpoint
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		cp, err := readCheckpoint(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, nil
+}
+
+// record stores the outcome of an item. Done and skipped items are not
+// synced again on resume; failed items are kept for a retry pass.
+func (c *Checkpoint) record(item WorkItem, result itemResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := item.Key()
+	switch result {
+	case resultDone:
+		c.Completed[key] = "done"
+		delete(c.Failed, key)
+	case resultSkipped:
+		c.Completed[key] = "skipped"
+		delete(c.Failed, key)
+	default:
+		c.Failed[key] = item
+	}
+	c.dirty = true
+}
+
+// forget drops the recorded failure of a listing that has now succeeded.
+// What it lists is planned and recorded as items of its own.
+func (c *Checkpoint) forget(item WorkItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := item.Key()
+	if _, ok := c.Failed[key]; ok {
+		delete(c.Failed, key)
+		c.dirty = true
+	}
+}
+
+// completedResult returns the recorded outcome of an already synced item
+func (c *Checkpoint) completedResult(key string) (itemResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.Completed[key] {
+	case "done":
+		return resultDone, true
+	case "skipped":
+		return resultSkipped, true
+	}
+	return resultFailed, false
+}
+
+// failedItems returns the items that failed, in stage order
+func (c *Checkpoint) failedItems() []WorkItem {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	queue := &workQueue{}
+	for _, item := range c.Failed {
+		queue.add(item)
+	}
+	var items []WorkItem
+	for _, stageItems := range queue.stages {
+		items = append(items, stageItems...)
+	}
+	return items
+}
+
+// counts returns the number of done and skipped items
+func (c *Checkpoint) counts() (done, skipped int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, result := range c.Completed {
+		if result == "done" {
+			done++
+		} else {
+			skipped++
+		}
+	}
+	return done, skipped
+}
+
+// setStatus updates the task status and total recorded in the checkpoint
+func (c *Checkpoint) setStatus(status string, itemsTotal int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Status = status
+	c.ItemsTotal = itemsTotal
+	c.dirty = true
+}
+
+// save writes the checkpoint to disk. Unless force is set, writes are skipped
+// when nothing changed or the last write was less than checkpointInterval ago.
+func (c *Checkpoint) save(force bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !force && (!c.dirty || time.Since(c.lastSaved) < checkpointInterval) {
+		return nil
+	}
+
+	path, err := checkpointPath(c.TaskID)
+	if err != nil {
+		return err
+	}
+
+	c.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	// Atomic write using temp file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+
+	c.dirty = false
+	c.lastSaved = time.Now()
+	return nil
+}
+
+// remove deletes the checkpoint file
+func (c *Checkpoint) remove() {
+	if path, err := checkpointPath(c.TaskID); err == nil {
+		os.Remove(path)
+	}
+}
@@ -3,6 +3,7 @@ package sync
 import (
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
 	"github.com/kartoza/kartoza-cloudbench/internal/cache"
@@ -10,7 +11,9 @@ import (
 	"github.com/kartoza/kartoza-cloudbench/internal/models"
 )
 
-// Executor handles the actual sync operations
+// Executor handles the actual sync operations.
+// It first plans all work items from the source server, so the task total is
+// known up front, then runs the items stage by stage with a pool of workers.
 type Executor struct {
 	task         *Task
 	sourceClient *api.Client
@@ -19,40 +22,75 @@ type Executor struct {
 	stopChan     chan struct{}
 	sourceID     string // Source connection ID for cache
 	cacheManager *cache.Manager
+	mapper       *nameMapper // Name and parameter rewriting for this destination
+	checkpoint   *Checkpoint // Progress record, used to skip items synced before a resume
+	retryItems   []WorkItem  // If set, only these items are synced instead of planning
+
+	created   map[string]bool // Qualified names of layers and groups created by this run
+	createdMu sync.Mutex
 }
 
 // Execute runs the sync operation
 func (e *Executor) Execute() {
-	e.task.AddLog("Analyzing source server...")
+	workers := e.options.GetWorkers()
+
+	var queue *workQueue
+	if e.retryItems != nil {
+		// Retry pass: the task total is kept from the original run
+		queue = &workQueue{}
+		for _, item := range e.retryItems {
+			if item.Kind == KindListing {
+				e.replan(queue, item)
+				continue
+			}
+			queue.add(item)
+		}
+		e.task.AddLog(fmt.Sprintf("Retrying %d failed items with %d workers", queue.len(), workers))
+	} else {
+		e.task.AddLog("Analyzing source server...")
 
-	if e.options.Workspaces {
-		workspaces, err := e.sourceClient.GetWorkspaces()
+		var err error
+		queue, err = e.plan()
 		if err != nil {
-			e.task.SetError(fmt.Sprintf("Failed to get workspaces: %v", err))
+			e.task.SetError(err.Error())
+			return
+		}
+		if e.isStopped() {
 			return
 		}
 
-		for _, ws := range workspaces {
+		e.task.AddTotal(queue.len())
+		e.task.AddLog(fmt.Sprintf("Planned %d items, syncing with %d workers", queue.len(), workers))
+		queue = e.skipCompleted(queue)
+	}
+	e.task.UpdateProgress()
+
+	for st := stage(0); st < numStages; st++ {
+		items := queue.stages[st]
+		if len(items) == 0 {
+			continue
+		}
+		if e.isStopped() {
+			return
+		}
+		e.task.AddLog(fmt.Sprintf("Syncing %s (%d items)...", stageNames[st], len(items)))
+
+		// Nested layer groups must exist before the groups containing them
+		levels := [][]WorkItem{items}
+		if st == stageLayerGroups {
+			levels = layerGroupLevels(items, e.nestedLayerGroups)
+		}
+		for _, level := range levels {
 			if e.isStopped() {
 				return
 			}
-
-			// Check workspace filter
-			if len(e.options.WorkspaceFilter) > 0 {
-				if !e.matchesFilter(ws.Name) {
-					continue
-				}
-			}
-
-			e.syncWorkspace(ws.Name)
+			runStage(level, workers, e.stopChan, e.processItem)
 		}
 	}
 
-	// Sync global styles
-	if e.options.Styles {
-		e.syncGlobalStyles()
+	if e.isStopped() {
+		return
 	}
-
 	e.task.AddLog("Sync completed!")
 }
 
@@ -74,443 +112,660 @@ func (e *Executor) matchesFilter(name string) bool {
 	return false
 }
 
-func (e *Executor) syncWorkspace(name string) {
-	e.task.IncrementTotal()
-	e.task.SetCurrentItem(fmt.Sprintf("Workspace: %s", name))
-	e.task.AddLog(fmt.Sprintf("Syncing workspace: %s", name))
+// skipCompleted accounts for items already synced according to the checkpoint
+// and returns a queue of the remaining items
+func (e *Executor) skipCompleted(queue *workQueue) *workQueue {
+	if e.checkpoint == nil {
+		return queue
+	}
 
-	// Try to create workspace on destination
-	err := e.destClient.CreateWorkspace(name)
-	if err != nil {
-		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "409") {
-			e.task.IncrementSkipped()
-			e.task.AddLog(fmt.Sprintf("Workspace %s already exists, skipping", name))
-		} else {
-			e.task.IncrementFailed()
-			e.task.AddLog(fmt.Sprintf("Failed to create workspace %s: %v", name, err))
+	pending := &workQueue{}
+	skipped := 0
+	for _, items := range queue.stages {
+		for _, item := range items {
+			result, ok := e.checkpoint.completedResult(item.Key())
+			if !ok {
+				pending.add(item)
+				continue
+			}
+			if result == resultDone {
+				e.task.IncrementDone()
+			} else {
+				e.task.IncrementSkipped()
+			}
+			skipped++
 		}
-	} else {
-		e.task.IncrementDone()
-		e.task.AddLog(fmt.Sprintf("Created workspace: %s", name))
 	}
-	e.task.UpdateProgress()
 
-	// Sync styles for this workspace
-	if e.options.Styles {
-		e.syncWorkspaceStyles(name)
+	if skipped > 0 {
+		e.task.AddLog(fmt.Sprintf("Resuming from checkpoint: %d items already synced", skipped))
 	}
+	return pending
+}
 
-	// Sync data stores
-	if e.options.DataStores {
-		e.syncDataStores(name)
+// markCreated records that a layer or layer group was created on the destination
+func (e *Executor) markCreated(workspace, name string) {
+	e.createdMu.Lock()
+	defer e.createdMu.Unlock()
+	if e.created == nil {
+		e.created = make(map[string]bool)
 	}
+	e.created[workspace+":"+name] = true
+}
 
-	// Sync coverage stores
-	if e.options.CoverageStores {
-		e.syncCoverageStores(name)
+// wasCreated reports whether this run created the layer or layer group
+func (e *Executor) wasCreated(qualifiedName string) bool {
+	e.createdMu.Lock()
+	defer e.createdMu.Unlock()
+	return e.created[qualifiedName]
+}
+
+// isConflict reports whether an error means the object already exists on the destination
+func isConflict(err error) bool {
+	return strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "409")
+}
+
+// plan lists everything to sync on the source server and queues it by stage
+func (e *Executor) plan() (*workQueue, error) {
+	queue := &workQueue{}
+
+	if e.options.Workspaces {
+		workspaces, err := e.sourceClient.GetWorkspaces()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get workspaces: %v", err)
+		}
+
+		for _, ws := range workspaces {
+			if e.isStopped() {
+				return queue, nil
+			}
+
+			// Check workspace filter
+			if len(e.options.WorkspaceFilter) > 0 {
+				if !e.matchesFilter(ws.Name) {
+					continue
+				}
+			}
+
+			queue.add(WorkItem{Kind: KindWorkspace, Name: ws.Name})
+			e.planWorkspace(queue, ws.Name)
+		}
 	}
 
-	// Sync layer groups
-	if e.options.LayerGroups {
-		e.syncLayerGroups(name)
+	// Sync tile caching configuration
+	if e.options.GWC {
+		e.planGWC(queue)
 	}
-}
 
-func (e *Executor) syncWorkspaceStyles(workspace string) {
-	styles, err := e.sourceClient.GetStyles(workspace)
-	if err != nil {
-		e.task.AddLog(fmt.Sprintf("Failed to get styles for %s: %v", workspace, err))
-		return
+	// Sync global styles
+	if e.options.Styles {
+		e.planListing(queue, listingItem(listStyles, "", ""))
 	}
 
-	for _, style := range styles {
-		if e.isStopped() {
-			return
-		}
+	return queue, nil
+}
 
-		e.task.IncrementTotal()
-		e.task.SetCurrentItem(fmt.Sprintf("Style: %s:%s", workspace, style.Name))
+// planWorkspace queues the styles, stores, layers and layer groups of a workspace
+func (e *Executor) planWorkspace(queue *workQueue, workspace string) {
+	if e.options.Styles {
+		e.planListing(queue, listingItem(listStyles, workspace, ""))
+	}
+	if e.options.DataStores {
+		e.planListing(queue, listingItem(listDataStores, workspace, ""))
+	}
+	if e.options.CoverageStores {
+		e.planListing(queue, listingItem(listCoverageStores, workspace, ""))
+	}
+	if e.options.LayerGroups {
+		e.planListing(queue, listingItem(listLayerGroups, workspace, ""))
+	}
+}
 
-		// Get style content from source
-		sld, err := e.sourceClient.GetStyleSLD(workspace, style.Name)
-		if err != nil {
-			e.task.IncrementFailed()
-			e.task.AddLog(fmt.Sprintf("Failed to get style %s: %v", style.Name, err))
-			continue
+// planListing queues what a listing returns. A listing that fails counts as
+// a failed item and is recorded in the checkpoint, so a retry pass lists it
+// again.
+func (e *Executor) planListing(queue *workQueue, listing WorkItem) {
+	err := e.list(queue, listing)
+	if err == nil {
+		if e.checkpoint != nil {
+			e.checkpoint.forget(listing)
 		}
+		return
+	}
+	e.task.AddLog(fmt.Sprintf("Failed to list %s: %v", listing.listed(), err))
+	e.task.IncrementTotal()
+	e.task.IncrementFailed()
+	if e.checkpoint != nil {
+		e.checkpoint.record(listing, resultFailed)
+	}
+}
 
-		// Create on destination
-		err = e.destClient.CreateOrUpdateStyle(workspace, style.Name, sld)
-		if err != nil {
-			if strings.Contains(err.Error(), "already exists") {
-				e.task.IncrementSkipped()
-			} else {
-				e.task.IncrementFailed()
-				e.task.AddLog(fmt.Sprintf("Failed to create style %s: %v", style.Name, err))
+// replan runs a listing that failed before, for a retry pass, and queues what
+// it returns. The listing itself was counted in the original run's total.
+func (e *Executor) replan(queue *workQueue, listing WorkItem) {
+	planned := &workQueue{}
+	result := resultDone
+	if err := e.list(planned, listing); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to list %s: %v", listing.listed(), err))
+		result = resultFailed
+		e.task.IncrementFailed()
+	} else {
+		e.task.IncrementDone()
+		e.task.AddTotal(planned.len())
+		for _, items := range planned.stages {
+			for _, item := range items {
+				queue.add(item)
 			}
-		} else {
-			e.task.IncrementDone()
 		}
-		e.task.UpdateProgress()
+	}
+	if e.checkpoint != nil {
+		e.checkpoint.record(listing, result)
 	}
 }
 
-func (e *Executor) syncGlobalStyles() {
-	styles, err := e.sourceClient.GetStyles("")
-	if err != nil {
-		e.task.AddLog(fmt.Sprintf("Failed to get global styles: %v", err))
-		return
+// list runs one listing of the source server and queues what it returns
+func (e *Executor) list(queue *workQueue, listing WorkItem) error {
+	switch listing.Name {
+	case listStyles:
+		return e.listStyles(queue, listing.Workspace)
+	case listDataStores:
+		return e.listDataStores(queue, listing.Workspace)
+	case listFeatureTypes:
+		return e.listFeatureTypes(queue, listing.Workspace, listing.Store)
+	case listCoverageStores:
+		return e.listCoverageStores(queue, listing.Workspace)
+	case listCoverages:
+		return e.listCoverages(queue, listing.Workspace, listing.Store)
+	case listLayerGroups:
+		return e.listLayerGroups(queue, listing.Workspace)
+	case listGridSets:
+		return e.listGridSets(queue)
+	case listTileLayers:
+		return e.listTileLayers(queue)
 	}
+	return fmt.Errorf("unknown listing %q", listing.Name)
+}
 
+func (e *Executor) listStyles(queue *workQueue, workspace string) error {
+	styles, err := e.sourceClient.GetStyles(workspace)
+	if err != nil {
+		return err
+	}
 	for _, style := range styles {
+		queue.add(WorkItem{Kind: KindStyle, Workspace: workspace, Name: style.Name})
+	}
+	return nil
+}
+
+func (e *Executor) listDataStores(queue *workQueue, workspace string) error {
+	stores, err := e.sourceClient.GetDataStores(workspace)
+	if err != nil {
+		return err
+	}
+	for _, store := range stores {
 		if e.isStopped() {
-			return
+			return nil
 		}
-
-		e.task.IncrementTotal()
-		e.task.SetCurrentItem(fmt.Sprintf("Global Style: %s", style.Name))
-
-		sld, err := e.sourceClient.GetStyleSLD("", style.Name)
-		if err != nil {
-			e.task.IncrementFailed()
+		if e.options.DataStoreStrategy != config.DataStoreGeoPackageCopy {
+			queue.add(WorkItem{Kind: KindDataStore, Workspace: workspace, Name: store.Name})
 			continue
 		}
 
-		err = e.destClient.CreateOrUpdateStyle("", style.Name, sld)
-		if err != nil {
-			if strings.Contains(err.Error(), "already exists") {
-				e.task.IncrementSkipped()
-			} else {
-				e.task.IncrementFailed()
-			}
-		} else {
-			e.task.IncrementDone()
-		}
-		e.task.UpdateProgress()
+		// Network-based copy: each feature type is downloaded via WFS and uploaded
+		e.planListing(queue, listingItem(listFeatureTypes, workspace, store.Name))
 	}
+	return nil
 }
 
-func (e *Executor) syncDataStores(workspace string) {
-	stores, err := e.sourceClient.GetDataStores(workspace)
+func (e *Executor) listFeatureTypes(queue *workQueue, workspace, store string) error {
+	featureTypes, err := e.sourceClient.GetFeatureTypes(workspace, store)
 	if err != nil {
-		e.task.AddLog(fmt.Sprintf("Failed to get data stores for %s: %v", workspace, err))
-		return
+		return err
 	}
+	if len(featureTypes) == 0 {
+		e.task.AddLog(fmt.Sprintf("No feature types found in store %s", store))
+		e.task.IncrementTotal()
+		e.task.IncrementSkipped()
+		return nil
+	}
+	for _, ft := range featureTypes {
+		queue.add(WorkItem{Kind: KindFeatureType, Workspace: workspace, Store: store, Name: ft.Name})
+	}
+	return nil
+}
 
+func (e *Executor) listCoverageStores(queue *workQueue, workspace string) error {
+	stores, err := e.sourceClient.GetCoverageStores(workspace)
+	if err != nil {
+		return err
+	}
 	for _, store := range stores {
 		if e.isStopped() {
-			return
+			return nil
 		}
+		// Each coverage is downloaded via WCS and uploaded
+		e.planListing(queue, listingItem(listCoverages, workspace, store.Name))
+	}
+	return nil
+}
 
+func (e *Executor) listCoverages(queue *workQueue, workspace, store string) error {
+	coverages, err := e.sourceClient.GetCoverages(workspace, store)
+	if err != nil {
+		return err
+	}
+	if len(coverages) == 0 {
+		e.task.AddLog(fmt.Sprintf("No coverages found in store %s", store))
 		e.task.IncrementTotal()
-		e.task.SetCurrentItem(fmt.Sprintf("DataStore: %s:%s", workspace, store.Name))
-		e.task.AddLog(fmt.Sprintf("Syncing data store: %s", store.Name))
-
-		// Handle based on sync strategy
-		switch e.options.DataStoreStrategy {
-		case config.DataStoreSameConnection:
-			// Skip - this would require same DB access which isn't network-only
-			e.task.AddLog(fmt.Sprintf("Strategy: Same Connection - Skipping %s (requires destination to have same DB access)", store.Name))
-			e.task.IncrementSkipped()
-		case config.DataStoreGeoPackageCopy:
-			// Network-based: download data via WFS and upload to destination
-			e.syncDataStoreViaWFS(workspace, store.Name)
-		default: // Skip
-			e.task.AddLog(fmt.Sprintf("Strategy: Skip - Data store %s noted (requires manual configuration)", store.Name))
-			e.task.IncrementSkipped()
-		}
-		e.task.UpdateProgress()
+		e.task.IncrementSkipped()
+		return nil
+	}
+	for _, cov := range coverages {
+		queue.add(WorkItem{Kind: KindCoverage, Workspace: workspace, Store: store, Name: cov.Name})
 	}
+	return nil
 }
 
-// syncDataStoreViaWFS downloads feature data via WFS to cache and uploads to destination
-// This creates new datastores on destination with the actual data
-func (e *Executor) syncDataStoreViaWFS(workspace, storeName string) {
-	e.task.AddLog(fmt.Sprintf("Strategy: Data Copy via Cache - Syncing data from %s", storeName))
-
-	// Get all feature types from this store
-	featureTypes, err := e.sourceClient.GetFeatureTypes(workspace, storeName)
+func (e *Executor) listLayerGroups(queue *workQueue, workspace string) error {
+	groups, err := e.sourceClient.GetLayerGroups(workspace)
 	if err != nil {
-		e.task.IncrementFailed()
-		e.task.AddLog(fmt.Sprintf("Failed to get feature types for %s: %v", storeName, err))
+		return err
+	}
+	for _, group := range groups {
+		queue.add(WorkItem{Kind: KindLayerGroup, Workspace: workspace, Name: group.Name})
+	}
+	return nil
+}
+
+// processItem syncs a single work item and records its outcome on the task.
+// Items interrupted by a stop request are left unaccounted.
+func (e *Executor) processItem(item WorkItem) {
+	if e.isStopped() {
 		return
 	}
 
-	if len(featureTypes) == 0 {
-		e.task.AddLog(fmt.Sprintf("No feature types found in store %s", storeName))
-		e.task.IncrementSkipped()
+	e.task.SetCurrentItem(item.Label())
+
+	var result itemResult
+	switch item.Kind {
+	case KindWorkspace:
+		result = e.syncWorkspace(item)
+	case KindStyle:
+		result = e.syncStyle(item)
+	case KindDataStore:
+		result = e.syncDataStore(item)
+	case KindFeatureType, KindCoverage:
+		result = e.syncLayerData(item)
+	case KindLayerGroup:
+		result = e.syncLayerGroup(item)
+	case KindGridSet:
+		result = e.syncGridSet(item)
+	case KindDiskQuota:
+		result = e.syncDiskQuota(item)
+	case KindTileLayer:
+		result = e.syncTileLayer(item)
+	}
+
+	if result == resultFailed && e.isStopped() {
 		return
 	}
 
-	// For each feature type, check cache -> download if needed -> upload from cache
-	syncedAny := false
-	for _, ft := range featureTypes {
-		if e.isStopped() {
-			return
-		}
+	switch result {
+	case resultDone:
+		e.task.IncrementDone()
+	case resultSkipped:
+		e.task.IncrementSkipped()
+	default:
+		e.task.IncrementFailed()
+	}
+	e.task.UpdateProgress()
 
-		e.task.SetCurrentItem(fmt.Sprintf("Syncing: %s:%s", workspace, ft.Name))
-
-		var shapeData []byte
-		var cacheEntry *cache.CacheEntry
-
-		// Check if we have a valid cache entry
-		if e.cacheManager != nil {
-			cacheEntry, _ = e.cacheManager.GetCacheEntry(e.sourceID, workspace, cache.ResourceTypeFeatureType, storeName, ft.Name)
-			if cacheEntry != nil && cacheEntry.DataFile != "" {
-				// Check if cache is still valid
-				valid, _ := e.cacheManager.IsCacheValid(e.sourceClient, cacheEntry)
-				if valid {
-					e.task.AddLog(fmt.Sprintf("Using cached data for %s", ft.Name))
-					shapeData, err = e.cacheManager.ReadCachedData(cacheEntry)
-					if err != nil {
-						e.task.AddLog(fmt.Sprintf("Cache read failed, re-downloading: %v", err))
-						cacheEntry = nil
-					}
-				} else {
-					e.task.AddLog(fmt.Sprintf("Cache outdated for %s, re-downloading", ft.Name))
-					cacheEntry = nil
-				}
-			}
+	if e.checkpoint != nil {
+		e.checkpoint.record(item, result)
+		if err := e.checkpoint.save(false); err != nil {
+			e.task.AddLog(fmt.Sprintf("Warning: failed to save checkpoint: %v", err))
 		}
+	}
+}
 
-		// If no valid cache, download to cache
-		if cacheEntry == nil || shapeData == nil {
-			e.task.AddLog(fmt.Sprintf("Downloading %s (metadata + data via WFS)...", ft.Name))
+func (e *Executor) syncWorkspace(item WorkItem) itemResult {
+	destName := e.mapper.Workspace(item.Name)
+	if destName != item.Name {
+		e.task.AddLog(fmt.Sprintf("Syncing workspace: %s (as %s)", item.Name, destName))
+	} else {
+		e.task.AddLog(fmt.Sprintf("Syncing workspace: %s", item.Name))
+	}
 
-			if e.cacheManager != nil {
-				// Download to cache (includes both metadata and data)
-				cacheEntry, err = e.cacheManager.CacheFeatureType(e.sourceClient, e.sourceID, workspace, storeName, ft.Name)
-				if err != nil {
-					e.task.AddLog(fmt.Sprintf("Failed to cache %s: %v", ft.Name, err))
-					continue
-				}
-				if cacheEntry.DataFile != "" {
-					shapeData, err = e.cacheManager.ReadCachedData(cacheEntry)
-					if err != nil {
-						e.task.AddLog(fmt.Sprintf("Failed to read cached data for %s: %v", ft.Name, err))
-						continue
-					}
-				} else {
-					e.task.AddLog(fmt.Sprintf("No data file in cache for %s (WFS download may have failed)", ft.Name))
-					continue
-				}
-			} else {
-				// No cache manager, download directly
-				shapeData, err = e.sourceClient.DownloadLayerAsShapefile(workspace, ft.Name)
-				if err != nil {
-					e.task.AddLog(fmt.Sprintf("Failed to download %s via WFS: %v", ft.Name, err))
-					continue
-				}
-			}
+	// Try to create workspace on destination
+	err := e.destClient.CreateWorkspace(destName)
+	if err != nil {
+		if isConflict(err) {
+			e.task.AddLog(fmt.Sprintf("Workspace %s already exists, skipping", destName))
+			return resultSkipped
 		}
+		e.task.AddLog(fmt.Sprintf("Failed to create workspace %s: %v", destName, err))
+		return resultFailed
+	}
 
-		e.task.AddLog(fmt.Sprintf("Uploading %s (%.2f KB) to destination...", ft.Name, float64(len(shapeData))/1024))
+	e.task.AddLog(fmt.Sprintf("Created workspace: %s", destName))
+	return resultDone
+}
 
-		// Upload to destination - use feature type name as store name
-		destStoreName := ft.Name
-		err = e.destClient.UploadShapefileData(workspace, destStoreName, shapeData)
-		if err != nil {
-			if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "409") {
-				e.task.AddLog(fmt.Sprintf("Store %s already exists on destination, skipping", destStoreName))
-			} else {
-				e.task.AddLog(fmt.Sprintf("Failed to upload %s: %v", ft.Name, err))
-			}
-			continue
-		}
+func (e *Executor) syncStyle(item WorkItem) itemResult {
+	// Get style content from source
+	sld, err := e.sourceClient.GetStyleSLD(item.Workspace, item.Name)
+	if err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to get style %s: %v", item.Name, err))
+		return resultFailed
+	}
 
-		e.task.AddLog(fmt.Sprintf("Successfully synced layer: %s", ft.Name))
-		syncedAny = true
+	// Create on destination
+	destName := e.mapper.Style(item.Workspace, item.Name)
+	err = e.destClient.CreateOrUpdateStyle(e.mapper.Workspace(item.Workspace), destName, sld)
+	if err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return resultSkipped
+		}
+		e.task.AddLog(fmt.Sprintf("Failed to create style %s: %v", destName, err))
+		return resultFailed
 	}
+	return resultDone
+}
 
-	if syncedAny {
-		e.task.IncrementDone()
-	} else {
-		e.task.IncrementSkipped()
+// syncDataStore handles data stores that are not copied via WFS
+func (e *Executor) syncDataStore(item WorkItem) itemResult {
+	e.task.AddLog(fmt.Sprintf("Syncing data store: %s", item.Name))
+
+	switch e.options.DataStoreStrategy {
+	case config.DataStoreSameConnection:
+		return e.copyDataStoreConfig(item)
+	default: // Skip
+		e.task.AddLog(fmt.Sprintf("Strategy: Skip - Data store %s noted (requires manual configuration)", item.Name))
 	}
+	return resultSkipped
 }
 
-func (e *Executor) syncCoverageStores(workspace string) {
-	stores, err := e.sourceClient.GetCoverageStores(workspace)
+// copyDataStoreConfig recreates a datastore's connection on the destination,
+// applying the destination's connection parameter overrides
+func (e *Executor) copyDataStoreConfig(item WorkItem) itemResult {
+	details, err := e.sourceClient.GetDataStoreDetails(item.Workspace, item.Name)
 	if err != nil {
-		e.task.AddLog(fmt.Sprintf("Failed to get coverage stores for %s: %v", workspace, err))
-		return
+		e.task.AddLog(fmt.Sprintf("Failed to get data store details for %s: %v", item.Name, err))
+		return resultFailed
 	}
 
-	for _, store := range stores {
-		if e.isStopped() {
-			return
-		}
-
-		e.task.IncrementTotal()
-		e.task.SetCurrentItem(fmt.Sprintf("CoverageStore: %s:%s", workspace, store.Name))
-		e.task.AddLog(fmt.Sprintf("Syncing coverage store: %s", store.Name))
+	details.Name = e.mapper.Name(mapKindDataStore, item.Name)
+	details.ConnectionParameters = e.mapper.DataStoreParams(details.ConnectionParameters)
+	// The namespace parameter refers to the source workspace
+	delete(details.ConnectionParameters, "namespace")
 
-		// Use WCS to download raster data and upload to destination
-		e.syncCoverageStoreViaWCS(workspace, store.Name)
-		e.task.UpdateProgress()
+	err = e.destClient.CreateDataStoreFromDetails(e.mapper.Workspace(item.Workspace), details)
+	if err != nil {
+		if isConflict(err) {
+			e.task.AddLog(fmt.Sprintf("Data store %s already exists on destination, skipping", details.Name))
+			return resultSkipped
+		}
+		e.task.AddLog(fmt.Sprintf("Failed to create data store %s: %v", details.Name, err))
+		return resultFailed
 	}
+
+	e.task.AddLog(fmt.Sprintf("Strategy: Same Connection - Created data store %s", details.Name))
+	return resultDone
 }
 
-// syncCoverageStoreViaWCS downloads coverage data via WCS to cache and uploads to destination
-func (e *Executor) syncCoverageStoreViaWCS(workspace, storeName string) {
-	e.task.AddLog(fmt.Sprintf("Strategy: Data Copy via Cache - Syncing raster from %s", storeName))
+// syncLayerData copies a feature type (via WFS) or coverage (via WCS) to the
+// destination, going through the local cache when it is available
+func (e *Executor) syncLayerData(item WorkItem) itemResult {
+	data, err := e.loadLayerData(item)
+	if err != nil {
+		e.task.AddLog(err.Error())
+		return resultFailed
+	}
 
-	// Get all coverages from this store
-	coverages, err := e.sourceClient.GetCoverages(workspace, storeName)
+	// Upload to destination - use the layer name as store name
+	destWorkspace := e.mapper.Workspace(item.Workspace)
+	destStoreName := e.mapper.Name(mapKindLayer, item.Name)
+	if item.Kind == KindCoverage {
+		e.task.AddLog(fmt.Sprintf("Uploading %s (%.2f MB) to destination...", item.Name, float64(len(data))/(1024*1024)))
+		err = e.destClient.UploadGeoTIFFData(destWorkspace, destStoreName, data)
+	} else {
+		e.task.AddLog(fmt.Sprintf("Uploading %s (%.2f KB) to destination...", item.Name, float64(len(data))/1024))
+		err = e.destClient.UploadShapefileData(destWorkspace, destStoreName, data)
+	}
 	if err != nil {
-		e.task.IncrementFailed()
-		e.task.AddLog(fmt.Sprintf("Failed to get coverages for %s: %v", storeName, err))
-		return
+		if isConflict(err) {
+			e.task.AddLog(fmt.Sprintf("Store %s already exists on destination, skipping", destStoreName))
+			return resultSkipped
+		}
+		e.task.AddLog(fmt.Sprintf("Failed to upload %s: %v", item.Name, err))
+		return resultFailed
 	}
 
-	if len(coverages) == 0 {
-		e.task.AddLog(fmt.Sprintf("No coverages found in store %s", storeName))
-		e.task.IncrementSkipped()
-		return
+	e.markCreated(item.Workspace, item.Name)
+
+	// GeoServer names the uploaded layer after the file in the upload, while
+	// layer groups and tile layers refer to it by its mapped name
+	if err := e.renameUploadedLayer(item, destWorkspace, destStoreName); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to rename %s to %s: %v", item.Name, destStoreName, err))
+		return resultFailed
 	}
 
-	// For each coverage, check cache -> download if needed -> upload from cache
-	syncedAny := false
-	for _, cov := range coverages {
-		if e.isStopped() {
-			return
+	e.copyLayerStyles(item.Workspace, item.Name, destWorkspace, destStoreName)
+	if item.Kind == KindCoverage {
+		e.task.AddLog(fmt.Sprintf("Successfully synced coverage: %s", item.Name))
+	} else {
+		e.task.AddLog(fmt.Sprintf("Successfully synced layer: %s", item.Name))
+	}
+	return resultDone
+}
+
+// renameUploadedLayer renames the feature type or coverage created by an
+// upload into store to destName, when the upload did not already give it
+// that name
+func (e *Executor) renameUploadedLayer(item WorkItem, workspace, store string) error {
+	destName := store
+	var names []string
+	if item.Kind == KindCoverage {
+		coverages, err := e.destClient.GetCoverages(workspace, store)
+		if err != nil {
+			return err
 		}
+		for _, c := range coverages {
+			names = append(names, c.Name)
+		}
+	} else {
+		featureTypes, err := e.destClient.GetFeatureTypes(workspace, store)
+		if err != nil {
+			return err
+		}
+		for _, ft := range featureTypes {
+			names = append(names, ft.Name)
+		}
+	}
 
-		e.task.SetCurrentItem(fmt.Sprintf("Syncing: %s:%s", workspace, cov.Name))
-
-		var tiffData []byte
-		var cacheEntry *cache.CacheEntry
-
-		// Check if we have a valid cache entry
-		if e.cacheManager != nil {
-			cacheEntry, _ = e.cacheManager.GetCacheEntry(e.sourceID, workspace, cache.ResourceTypeCoverage, storeName, cov.Name)
-			if cacheEntry != nil && cacheEntry.DataFile != "" {
-				// Check if cache is still valid
-				valid, _ := e.cacheManager.IsCacheValid(e.sourceClient, cacheEntry)
-				if valid {
-					e.task.AddLog(fmt.Sprintf("Using cached data for %s", cov.Name))
-					tiffData, err = e.cacheManager.ReadCachedData(cacheEntry)
-					if err != nil {
-						e.task.AddLog(fmt.Sprintf("Cache read failed, re-downloading: %v", err))
-						cacheEntry = nil
-					}
-				} else {
-					e.task.AddLog(fmt.Sprintf("Cache outdated for %s, re-downloading", cov.Name))
-					cacheEntry = nil
-				}
-			}
+	oldName := ""
+	for _, name := range names {
+		switch name {
+		case destName:
+			return nil
+		case item.Name:
+			oldName = name
+		}
+	}
+	if oldName == "" {
+		if len(names) != 1 {
+			return fmt.Errorf("store %s has %d layers, cannot tell which one was uploaded", store, len(names))
 		}
+		oldName = names[0]
+	}
 
-		// If no valid cache, download to cache
-		if cacheEntry == nil || tiffData == nil {
-			e.task.AddLog(fmt.Sprintf("Downloading %s (metadata + data via WCS)...", cov.Name))
+	e.task.AddLog(fmt.Sprintf("Renaming %s to %s on destination", oldName, destName))
+	if item.Kind == KindCoverage {
+		return e.destClient.RenameCoverage(workspace, store, oldName, destName)
+	}
+	return e.destClient.RenameFeatureType(workspace, store, oldName, destName)
+}
 
-			if e.cacheManager != nil {
-				// Download to cache (includes both metadata and data)
-				cacheEntry, err = e.cacheManager.CacheCoverage(e.sourceClient, e.sourceID, workspace, storeName, cov.Name)
-				if err != nil {
-					e.task.AddLog(fmt.Sprintf("Failed to cache %s: %v", cov.Name, err))
-					continue
-				}
-				if cacheEntry.DataFile != "" {
-					tiffData, err = e.cacheManager.ReadCachedData(cacheEntry)
-					if err != nil {
-						e.task.AddLog(fmt.Sprintf("Failed to read cached data for %s: %v", cov.Name, err))
-						continue
-					}
-				} else {
-					e.task.AddLog(fmt.Sprintf("No data file in cache for %s (WCS download may have failed)", cov.Name))
-					continue
-				}
-			} else {
-				// No cache manager, download directly
-				tiffData, err = e.sourceClient.DownloadCoverageAsGeoTIFF(workspace, cov.Name)
-				if err != nil {
-					e.task.AddLog(fmt.Sprintf("Failed to download %s via WCS: %v", cov.Name, err))
-					continue
+// loadLayerData returns the layer's data from a valid cache entry, downloading
+// it to the cache (or directly, without a cache) when needed
+func (e *Executor) loadLayerData(item WorkItem) ([]byte, error) {
+	resType := cache.ResourceTypeFeatureType
+	service := "WFS"
+	if item.Kind == KindCoverage {
+		resType = cache.ResourceTypeCoverage
+		service = "WCS"
+	}
+
+	// Check if we have a valid cache entry
+	if e.cacheManager != nil {
+		cacheEntry, _ := e.cacheManager.GetCacheEntry(e.sourceID, item.Workspace, resType, item.Store, item.Name)
+		if cacheEntry != nil && cacheEntry.DataFile != "" {
+			stale, err := e.cacheManager.StaleReason(e.sourceClient, cacheEntry)
+			switch {
+			case err != nil:
+				e.task.AddLog(fmt.Sprintf("Could not check cache for %s, re-downloading: %v", item.Name, err))
+			case stale != "":
+				e.task.AddLog(fmt.Sprintf("Cache outdated for %s (%s), re-downloading", item.Name, stale))
+			default:
+				e.task.AddLog(fmt.Sprintf("Using cached data for %s", item.Name))
+				data, err := e.cacheManager.ReadCachedData(cacheEntry)
+				if err == nil {
+					return data, nil
 				}
+				e.task.AddLog(fmt.Sprintf("Cache read failed, re-downloading: %v", err))
 			}
 		}
+	}
 
-		e.task.AddLog(fmt.Sprintf("Uploading %s (%.2f MB) to destination...", cov.Name, float64(len(tiffData))/(1024*1024)))
+	e.task.AddLog(fmt.Sprintf("Downloading %s (metadata + data via %s)...", item.Name, service))
 
-		// Upload to destination - use coverage name as store name
-		destStoreName := cov.Name
-		err = e.destClient.UploadGeoTIFFData(workspace, destStoreName, tiffData)
+	if e.cacheManager == nil {
+		// No cache manager, download directly
+		var data []byte
+		var err error
+		if item.Kind == KindCoverage {
+			data, err = e.sourceClient.DownloadCoverageAsGeoTIFF(item.Workspace, item.Name)
+		} else {
+			data, err = e.sourceClient.DownloadLayerAsShapefile(item.Workspace, item.Name)
+		}
 		if err != nil {
-			if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "409") {
-				e.task.AddLog(fmt.Sprintf("Store %s already exists on destination, skipping", destStoreName))
-			} else {
-				e.task.AddLog(fmt.Sprintf("Failed to upload %s: %v", cov.Name, err))
-			}
-			continue
+			return nil, fmt.Errorf("Failed to download %s via %s: %v", item.Name, service, err)
 		}
-
-		e.task.AddLog(fmt.Sprintf("Successfully synced coverage: %s", cov.Name))
-		syncedAny = true
+		return data, nil
 	}
 
-	if syncedAny {
-		e.task.IncrementDone()
+	// Download to cache (includes both metadata and data)
+	var cacheEntry *cache.CacheEntry
+	var err error
+	if item.Kind == KindCoverage {
+		cacheEntry, err = e.cacheManager.CacheCoverage(e.sourceClient, e.sourceID, item.Workspace, item.Store, item.Name)
 	} else {
-		e.task.IncrementSkipped()
+		cacheEntry, err = e.cacheManager.CacheFeatureType(e.sourceClient, e.sourceID, item.Workspace, item.Store, item.Name)
 	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to cache %s: %v", item.Name, err)
+	}
+	if cacheEntry.DataFile == "" {
+		return nil, fmt.Errorf("No data file in cache for %s (%s download may have failed)", item.Name, service)
+	}
+	data, err := e.cacheManager.ReadCachedData(cacheEntry)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read cached data for %s: %v", item.Name, err)
+	}
+	return data, nil
 }
 
-func (e *Executor) syncLayerGroups(workspace string) {
-	groups, err := e.sourceClient.GetLayerGroups(workspace)
+func (e *Executor) syncLayerGroup(item WorkItem) itemResult {
+	e.task.AddLog(fmt.Sprintf("Syncing layer group: %s", item.Name))
+
+	// Get layer group details from source
+	details, err := e.sourceClient.GetLayerGroup(item.Workspace, item.Name)
 	if err != nil {
-		e.task.AddLog(fmt.Sprintf("Failed to get layer groups for %s: %v", workspace, err))
-		return
+		e.task.AddLog(fmt.Sprintf("Failed to get layer group details for %s: %v", item.Name, err))
+		return resultFailed
 	}
 
-	for _, group := range groups {
-		if e.isStopped() {
-			return
+	// Map layer and style references, formatted as workspace:name
+	layerNames := make([]string, 0, len(details.Layers))
+	var layerStyles []models.LayerStyleAssignment
+	for _, layer := range details.Layers {
+		kind := mapKindLayer
+		if layer.Type == "layerGroup" {
+			kind = mapKindLayerGroup
+		}
+		layerName := e.mapper.Ref(kind, layer.Name, item.Workspace)
+		layerNames = append(layerNames, layerName)
+		if layer.StyleName != "" {
+			layerStyles = append(layerStyles, models.LayerStyleAssignment{
+				LayerName: layerName,
+				StyleName: e.mapper.Ref(mapKindStyle, layer.StyleName, ""),
+			})
 		}
+	}
 
-		e.task.IncrementTotal()
-		e.task.SetCurrentItem(fmt.Sprintf("LayerGroup: %s:%s", workspace, group.Name))
-		e.task.AddLog(fmt.Sprintf("Syncing layer group: %s", group.Name))
+	// Create the layer group on destination
+	destName := e.mapper.Name(mapKindLayerGroup, item.Name)
+	createConfig := models.LayerGroupCreate{
+		Name:        destName,
+		Title:       details.Title,
+		Mode:        details.Mode,
+		Layers:      layerNames,
+		LayerStyles: layerStyles,
+	}
 
-		// Get layer group details from source
-		details, err := e.sourceClient.GetLayerGroup(workspace, group.Name)
-		if err != nil {
-			e.task.IncrementFailed()
-			e.task.AddLog(fmt.Sprintf("Failed to get layer group details for %s: %v", group.Name, err))
-			e.task.UpdateProgress()
-			continue
+	err = e.destClient.CreateLayerGroup(e.mapper.Workspace(item.Workspace), createConfig)
+	if err != nil {
+		if isConflict(err) {
+			e.task.AddLog(fmt.Sprintf("LayerGroup %s already exists on destination", destName))
+			return resultSkipped
 		}
+		e.task.AddLog(fmt.Sprintf("Failed to create layer group %s: %v", destName, err))
+		return resultFailed
+	}
 
-		// Extract layer names from the details
-		layerNames := make([]string, 0, len(details.Layers))
-		for _, item := range details.Layers {
-			// Format as workspace:layername
-			layerNames = append(layerNames, fmt.Sprintf("%s:%s", workspace, item.Name))
-		}
+	e.markCreated(item.Workspace, item.Name)
+	e.task.AddLog(fmt.Sprintf("Created layer group: %s", destName))
+	return resultDone
+}
 
-		// Create the layer group on destination
-		createConfig := models.LayerGroupCreate{
-			Name:   group.Name,
-			Title:  details.Title,
-			Mode:   details.Mode,
-			Layers: layerNames,
-		}
+// nestedLayerGroups returns the qualified names of the layer groups nested in a
+// source layer group. Errors are ignored, as syncing the group reports them.
+func (e *Executor) nestedLayerGroups(item WorkItem) []string {
+	details, err := e.sourceClient.GetLayerGroup(item.Workspace, item.Name)
+	if err != nil {
+		return nil
+	}
 
-		err = e.destClient.CreateLayerGroup(workspace, createConfig)
-		if err != nil {
-			if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "409") {
-				e.task.IncrementSkipped()
-				e.task.AddLog(fmt.Sprintf("LayerGroup %s already exists on destination", group.Name))
-			} else {
-				e.task.IncrementFailed()
-				e.task.AddLog(fmt.Sprintf("Failed to create layer group %s: %v", group.Name, err))
-			}
+	var nested []string
+	for _, layer := range details.Layers {
+		if layer.Type != "layerGroup" {
+			continue
+		}
+		if strings.Contains(layer.Name, ":") {
+			nested = append(nested, layer.Name)
 		} else {
-			e.task.IncrementDone()
-			e.task.AddLog(fmt.Sprintf("Created layer group: %s", group.Name))
+			nested = append(nested, item.Workspace+":"+layer.Name)
 		}
-		e.task.UpdateProgress()
+	}
+	return nested
+}
+
+// copyLayerStyles assigns the source layer's default and additional styles to
+// the destination layer, using the mapped style names. Failures are only logged,
+// as the layer itself was synced.
+func (e *Executor) copyLayerStyles(workspace, name, destWorkspace, destName string) {
+	styles, err := e.sourceClient.GetLayerStyles(workspace, name)
+	if err != nil || styles.DefaultStyle == "" {
+		return
+	}
+
+	defaultStyle := e.mapper.Ref(mapKindStyle, styles.DefaultStyle, "")
+	additional := make([]string, 0, len(styles.AdditionalStyles))
+	for _, style := range styles.AdditionalStyles {
+		additional = append(additional, e.mapper.Ref(mapKindStyle, style, ""))
+	}
+
+	if err := e.destClient.UpdateLayerStyles(destWorkspace, destName, defaultStyle, additional); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to assign styles to %s: %v", destName, err))
 	}
 }
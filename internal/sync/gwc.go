@@ -0,0 +1,191 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+)
+
+// defaultSeedZoomStop limits seeding of grid subsets without an explicit zoom range
+const defaultSeedZoomStop = 10
+
+// builtinGridSets are defined by GeoWebCache itself and cannot be modified
+var builtinGridSets = map[string]bool{
+	"EPSG:4326":        true,
+	"EPSG:4326x2":      true,
+	"EPSG:900913":      true,
+	"EPSG:3857":        true,
+	"GlobalCRS84Pixel": true,
+	"GlobalCRS84Scale": true,
+	"GoogleCRS84Quad":  true,
+}
+
+// planGWC queues custom gridsets, the disk quota and the tile layers of the
+// synced workspaces whose layers are synced or already on the destination
+func (e *Executor) planGWC(queue *workQueue) {
+	e.planListing(queue, listingItem(listGridSets, "", ""))
+	queue.add(WorkItem{Kind: KindDiskQuota, Name: "diskquota"})
+	e.planListing(queue, listingItem(listTileLayers, "", ""))
+}
+
+func (e *Executor) listGridSets(queue *workQueue) error {
+	gridSets, err := e.sourceClient.GetGWCGridSets()
+	if err != nil {
+		return err
+	}
+	for _, gs := range gridSets {
+		if builtinGridSets[gs.Name] {
+			continue
+		}
+		queue.add(WorkItem{Kind: KindGridSet, Name: gs.Name})
+	}
+	return nil
+}
+
+func (e *Executor) listTileLayers(queue *workQueue) error {
+	layers, err := e.sourceClient.GetGWCLayers()
+	if err != nil {
+		return err
+	}
+
+	// Layers this sync creates; other tile layers are only carried over when
+	// their layer already exists on the destination
+	synced := make(map[string]bool)
+	for _, items := range queue.stages {
+		for _, item := range items {
+			if item.Kind == KindFeatureType || item.Kind == KindCoverage {
+				synced[item.Workspace+":"+item.Name] = true
+			}
+		}
+	}
+	destLayers := make(map[string]map[string]bool)
+
+	skipped := 0
+	for _, layer := range layers {
+		workspace := ""
+		if idx := strings.Index(layer.Name, ":"); idx > 0 {
+			workspace = layer.Name[:idx]
+		}
+		// Only carry over tile layers whose workspace is part of the sync
+		if len(e.options.WorkspaceFilter) > 0 && !e.matchesFilter(workspace) {
+			continue
+		}
+		if !synced[layer.Name] && !e.destHasLayer(destLayers, layer.Name) {
+			skipped++
+			continue
+		}
+		queue.add(WorkItem{Kind: KindTileLayer, Workspace: workspace, Name: layer.Name})
+	}
+	if skipped > 0 {
+		e.task.AddLog(fmt.Sprintf("Skipped %d tile layers whose layers are neither synced nor on the destination", skipped))
+	}
+	return nil
+}
+
+// destHasLayer reports whether the destination has the layer a source
+// "workspace:name" reference maps to. Layer lists are fetched once per
+// workspace and kept in known.
+func (e *Executor) destHasLayer(known map[string]map[string]bool, ref string) bool {
+	workspace, name, ok := strings.Cut(e.mapper.Ref(mapKindLayer, ref, ""), ":")
+	if !ok {
+		return false
+	}
+	names, fetched := known[workspace]
+	if !fetched {
+		names = make(map[string]bool)
+		layers, err := e.destClient.GetLayers(workspace)
+		if err != nil {
+			e.task.AddLog(fmt.Sprintf("Failed to get destination layers for %s: %v", workspace, err))
+		}
+		for _, layer := range layers {
+			names[layer.Name] = true
+		}
+		known[workspace] = names
+	}
+	return names[name]
+}
+
+func (e *Executor) syncGridSet(item WorkItem) itemResult {
+	gridSet, err := e.sourceClient.GetGWCGridSet(item.Name)
+	if err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to get gridset %s: %v", item.Name, err))
+		return resultFailed
+	}
+
+	if err := e.destClient.CreateOrUpdateGWCGridSet(*gridSet); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to sync gridset %s: %v", item.Name, err))
+		return resultFailed
+	}
+
+	e.task.AddLog(fmt.Sprintf("Synced gridset: %s", item.Name))
+	return resultDone
+}
+
+func (e *Executor) syncDiskQuota(item WorkItem) itemResult {
+	quota, err := e.sourceClient.GetGWCDiskQuota()
+	if err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to get disk quota: %v", err))
+		return resultFailed
+	}
+
+	if err := e.destClient.UpdateGWCDiskQuota(*quota); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to sync disk quota: %v", err))
+		return resultFailed
+	}
+
+	e.task.AddLog("Synced disk quota configuration")
+	return resultDone
+}
+
+// syncTileLayer copies a layer's tile caching settings, and seeds it when
+// requested and the layer was created by this sync
+func (e *Executor) syncTileLayer(item WorkItem) itemResult {
+	layer, err := e.sourceClient.GetGWCLayer(item.Name)
+	if err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to get tile layer %s: %v", item.Name, err))
+		return resultFailed
+	}
+
+	layer.Name = e.mapper.Ref(mapKindLayer, item.Name, "")
+	if err := e.destClient.UpdateGWCLayer(*layer); err != nil {
+		e.task.AddLog(fmt.Sprintf("Failed to sync tile layer %s: %v", layer.Name, err))
+		return resultFailed
+	}
+	e.task.AddLog(fmt.Sprintf("Synced tile layer: %s", layer.Name))
+
+	if e.options.GWCSeed && e.wasCreated(item.Name) {
+		e.seedTileLayer(layer.Name, layer.GridSubsetLevels, layer.MimeFormats)
+	}
+	return resultDone
+}
+
+// seedTileLayer starts a seed task on the destination for every grid subset and format
+func (e *Executor) seedTileLayer(name string, gridSubsets []models.GWCGridSubset, formats []string) {
+	for _, gs := range gridSubsets {
+		zoomStart, zoomStop := 0, defaultSeedZoomStop
+		if gs.ZoomStart != nil {
+			zoomStart = *gs.ZoomStart
+		}
+		if gs.ZoomStop != nil && *gs.ZoomStop < zoomStop {
+			zoomStop = *gs.ZoomStop
+		}
+
+		for _, format := range formats {
+			err := e.destClient.SeedLayer(name, models.GWCSeedRequest{
+				Name:        name,
+				GridSetID:   gs.GridSetName,
+				ZoomStart:   zoomStart,
+				ZoomStop:    zoomStop,
+				Format:      format,
+				Type:        "seed",
+				ThreadCount: 2,
+			})
+			if err != nil {
+				e.task.AddLog(fmt.Sprintf("Failed to seed %s (%s, %s): %v", name, gs.GridSetName, format, err))
+				continue
+			}
+			e.task.AddLog(fmt.Sprintf("Seeding %s (%s, %s, zoom %d-%d)", name, gs.GridSetName, format, zoomStart, zoomStop))
+		}
+	}
+}
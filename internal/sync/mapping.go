@@ -0,0 +1,142 @@
+package sync
+
+import (
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// Object kinds that name rewrite rules can be limited to
+const (
+	mapKindWorkspace  = "workspace"
+	mapKindStyle      = "style"
+	mapKindDataStore  = "datastore"
+	mapKindLayer      = "layer"
+	mapKindLayerGroup = "layergroup"
+)
+
+// nameMapper applies a sync configuration's mapping rules for one destination.
+// A nil rule set maps every name to itself.
+type nameMapper struct {
+	rules    config.SyncMappingRules
+	override *config.DataStoreParamOverride
+}
+
+// newNameMapper creates a mapper for the given destination connection
+func newNameMapper(rules *config.SyncMappingRules, destID string) *nameMapper {
+	m := &nameMapper{}
+	if rules == nil {
+		return m
+	}
+	m.rules = *rules
+	if o, ok := rules.DataStoreOverrides[destID]; ok {
+		m.override = &o
+	}
+	return m
+}
+
+// Workspace returns the destination name of a workspace
+func (m *nameMapper) Workspace(name string) string {
+	if name == "" {
+		return ""
+	}
+	if renamed, ok := m.rules.WorkspaceRenames[name]; ok {
+		return renamed
+	}
+	return m.rewrite(mapKindWorkspace, name)
+}
+
+// Style returns the destination name of a style in a workspace ("" for global styles)
+func (m *nameMapper) Style(workspace, name string) string {
+	if workspace != "" {
+		if renamed, ok := m.rules.StyleRenames[workspace+":"+name]; ok {
+			return renamed
+		}
+	}
+	if renamed, ok := m.rules.StyleRenames[name]; ok {
+		return renamed
+	}
+	return m.rewrite(mapKindStyle, name)
+}
+
+// Name returns the destination name of a datastore, layer or layer group
+func (m *nameMapper) Name(kind, name string) string {
+	return m.rewrite(kind, name)
+}
+
+// Ref maps a "workspace:name" reference, as used by layer groups and tile
+// layers. Unqualified names are resolved against defaultWorkspace.
+func (m *nameMapper) Ref(kind, ref, defaultWorkspace string) string {
+	workspace, name := defaultWorkspace, ref
+	if idx := strings.Index(ref, ":"); idx >= 0 {
+		workspace, name = ref[:idx], ref[idx+1:]
+	}
+
+	if kind == mapKindStyle {
+		name = m.Style(workspace, name)
+	} else {
+		name = m.rewrite(kind, name)
+	}
+	if workspace == "" {
+		return name
+	}
+	return m.Workspace(workspace) + ":" + name
+}
+
+// DataStoreParams returns a copy of a datastore's connection parameters with the
+// destination's overrides applied. Named overrides only replace parameters the
+// store already has, so file-based stores are left alone.
+func (m *nameMapper) DataStoreParams(params map[string]string) map[string]string {
+	result := make(map[string]string, len(params))
+	for k, v := range params {
+		result[k] = v
+	}
+	if m.override == nil {
+		return result
+	}
+
+	named := map[string]string{
+		"host":     m.override.Host,
+		"port":     m.override.Port,
+		"database": m.override.Database,
+		"schema":   m.override.Schema,
+	}
+	for key, value := range named {
+		if _, exists := result[key]; exists && value != "" {
+			result[key] = value
+		}
+	}
+	for key, value := range m.override.Params {
+		result[key] = value
+	}
+	return result
+}
+
+// rewrite applies the name rewrite rules matching kind, in order
+func (m *nameMapper) rewrite(kind, name string) string {
+	for _, rule := range m.rules.NameRewrites {
+		if !ruleApplies(rule, kind) {
+			continue
+		}
+		if rule.StripPrefix != "" {
+			name = strings.TrimPrefix(name, rule.StripPrefix)
+		}
+		if rule.StripSuffix != "" {
+			name = strings.TrimSuffix(name, rule.StripSuffix)
+		}
+		name = rule.AddPrefix + name + rule.AddSuffix
+	}
+	return name
+}
+
+func ruleApplies(rule config.NameRewriteRule, kind string) bool {
+	if len(rule.Kinds) == 0 {
+		return true
+	}
+	for _, k := range rule.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
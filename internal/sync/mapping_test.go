@@ -0,0 +1,200 @@
+package sync
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+func testMapper() *nameMapper {
+	return newNameMapper(&config.SyncMappingRules{
+		WorkspaceRenames: map[string]string{"legacy": "archive"},
+		StyleRenames:     map[string]string{"stg_roads:line": "road_line", "point": "marker"},
+		NameRewrites: []config.NameRewriteRule{
+			{Kinds: []string{"workspace"}, StripPrefix: "stg_"},
+			{Kinds: []string{"layergroup"}, AddSuffix: "_group"},
+		},
+		DataStoreOverrides: map[string]config.DataStoreParamOverride{
+			"prod": {Host: "db.prod", Database: "gis", Params: map[string]string{"user": "reader"}},
+		},
+	}, "prod")
+}
+
+func TestNameMapperWorkspaces(t *testing.T) {
+	m := testMapper()
+	cases := map[string]string{
+		"stg_roads": "roads",
+		"legacy":    "archive",
+		"other":     "other",
+		"":          "",
+	}
+	for in, want := range cases {
+		if got := m.Workspace(in); got != want {
+			t.Errorf("Workspace(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNameMapperRefs(t *testing.T) {
+	m := testMapper()
+	cases := []struct {
+		kind, ref, defaultWorkspace, want string
+	}{
+		{mapKindLayer, "stg_roads:highways", "", "roads:highways"},
+		{mapKindLayer, "highways", "stg_roads", "roads:highways"},
+		{mapKindLayerGroup, "stg_roads:network", "", "roads:network_group"},
+		{mapKindStyle, "stg_roads:line", "", "roads:road_line"},
+		{mapKindStyle, "point", "", "marker"},
+	}
+	for _, c := range cases {
+		if got := m.Ref(c.kind, c.ref, c.defaultWorkspace); got != c.want {
+			t.Errorf("Ref(%q, %q, %q) = %q, want %q", c.kind, c.ref, c.defaultWorkspace, got, c.want)
+		}
+	}
+}
+
+func TestNameMapperDataStoreParams(t *testing.T) {
+	m := testMapper()
+	source := map[string]string{"host": "db.staging", "database": "gis_stg", "port": "5432"}
+
+	params := m.DataStoreParams(source)
+	if params["host"] != "db.prod" || params["database"] != "gis" || params["port"] != "5432" || params["user"] != "reader" {
+		t.Fatalf("unexpected params: %v", params)
+	}
+	if _, ok := params["schema"]; ok {
+		t.Fatalf("schema should not be added to a store without one: %v", params)
+	}
+	if source["host"] != "db.staging" {
+		t.Fatal("source params were modified")
+	}
+
+	// Other destinations are left unchanged
+	other := newNameMapper(&config.SyncMappingRules{}, "staging")
+	if got := other.DataStoreParams(source); got["host"] != "db.staging" {
+		t.Fatalf("unexpected params for other destination: %v", got)
+	}
+}
+
+// TestRenamedLayerEndToEnd checks that an uploaded layer takes its mapped
+// name on the destination, so layer groups and tile layers referring to it
+// by that name find it
+func TestRenamedLayerEndToEnd(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/wfs":
+			w.Header().Set("Content-Type", "application/zip")
+			io.WriteString(w, "PK\x03\x04 shapefile")
+		case "/rest/workspaces/topp/layergroups/network":
+			io.WriteString(w, `{"layerGroup": {"name": "network", "mode": "SINGLE",
+				"publishables": {"published": {"@type": "layer", "name": "topp:roads"}}}}`)
+		case "/gwc/rest/layers/topp:roads.json":
+			io.WriteString(w, `{"GeoServerLayer": {"name": "topp:roads", "enabled": true, "mimeFormats": ["image/png"]}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer source.Close()
+
+	// The destination names an uploaded feature type after the uploaded
+	// file, and only knows layers by their current names
+	var mu sync.Mutex
+	featureTypes := map[string]string{} // Store name to feature type name
+	var groupLayers []string
+	var tileLayer string
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		layerExists := func(ref string) bool {
+			for _, name := range featureTypes {
+				if ref == "topp:"+name {
+					return true
+				}
+			}
+			return false
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/workspaces/topp/datastores/"), "/")
+		switch {
+		case r.Method == http.MethodPut && len(parts) == 2 && parts[1] == "file.shp":
+			featureTypes[parts[0]] = "roads"
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && len(parts) == 2 && parts[1] == "featuretypes":
+			io.WriteString(w, fmt.Sprintf(`{"featureTypes": {"featureType": [{"name": %q}]}}`, featureTypes[parts[0]]))
+		case r.Method == http.MethodPut && len(parts) == 3 && parts[1] == "featuretypes" && featureTypes[parts[0]] == parts[2]:
+			var body struct {
+				FeatureType struct {
+					Name string `json:"name"`
+				} `json:"featureType"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			featureTypes[parts[0]] = body.FeatureType.Name
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/workspaces/topp/layergroups":
+			var body struct {
+				LayerGroup struct {
+					Publishables struct {
+						Published []struct {
+							Name string `json:"name"`
+						} `json:"published"`
+					} `json:"publishables"`
+				} `json:"layerGroup"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			for _, p := range body.LayerGroup.Publishables.Published {
+				if !layerExists(p.Name) {
+					http.Error(w, "no such layer: "+p.Name, http.StatusBadRequest)
+					return
+				}
+				groupLayers = append(groupLayers, p.Name)
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/gwc/rest/layers/"):
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/gwc/rest/layers/"), ".json")
+			if !layerExists(name) {
+				http.Error(w, "no such layer: "+name, http.StatusBadRequest)
+				return
+			}
+			tileLayer = name
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer dest.Close()
+
+	e := &Executor{
+		task:         &Task{ID: "task"},
+		sourceClient: api.NewClientDirect(source.URL, "admin", "geoserver"),
+		destClient:   api.NewClientDirect(dest.URL, "admin", "geoserver"),
+		stopChan:     make(chan struct{}),
+		mapper: newNameMapper(&config.SyncMappingRules{
+			NameRewrites: []config.NameRewriteRule{{Kinds: []string{"layer"}, AddPrefix: "prod_"}},
+		}, "prod"),
+	}
+
+	if got := e.syncLayerData(WorkItem{Kind: KindFeatureType, Workspace: "topp", Store: "states", Name: "roads"}); got != resultDone {
+		t.Fatalf("syncLayerData = %v, logs: %v", got, e.task.GetLogs())
+	}
+	if featureTypes["prod_roads"] != "prod_roads" {
+		t.Fatalf("uploaded feature types %v, want prod_roads renamed to its mapped name", featureTypes)
+	}
+	if got := e.syncLayerGroup(WorkItem{Kind: KindLayerGroup, Workspace: "topp", Name: "network"}); got != resultDone {
+		t.Fatalf("syncLayerGroup = %v, logs: %v", got, e.task.GetLogs())
+	}
+	if len(groupLayers) != 1 || groupLayers[0] != "topp:prod_roads" {
+		t.Errorf("layer group refers to %v, want topp:prod_roads", groupLayers)
+	}
+	if got := e.syncTileLayer(WorkItem{Kind: KindTileLayer, Name: "topp:roads"}); got != resultDone {
+		t.Fatalf("syncTileLayer = %v, logs: %v", got, e.task.GetLogs())
+	}
+	if tileLayer != "topp:prod_roads" {
+		t.Errorf("tile layer synced as %q, want topp:prod_roads", tileLayer)
+	}
+}
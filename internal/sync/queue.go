@@ -0,0 +1,246 @@
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errSyncStopped is returned by rate limited requests when the task is stopped
+var errSyncStopped = errors.New("sync stopped")
+
+// ItemKind identifies the kind of catalog object a work item syncs
+type ItemKind string
+
+const (
+	KindWorkspace   ItemKind = "workspace"
+	KindStyle       ItemKind = "style"
+	KindDataStore   ItemKind = "datastore"
+	KindFeatureType ItemKind = "featuretype"
+	KindCoverage    ItemKind = "coverage"
+	KindLayerGroup  ItemKind = "layergroup"
+	KindGridSet     ItemKind = "gridset"
+	KindDiskQuota   ItemKind = "diskquota"
+	KindTileLayer   ItemKind = "tilelayer"
+	KindListing     ItemKind = "listing" // A listing of the source that failed while planning
+)
+
+// Listings of the source server, named after their REST collection. A
+// listing item's Name is one of these.
+const (
+	listStyles         = "styles"
+	listDataStores     = "datastores"
+	listFeatureTypes   = "featuretypes"
+	listCoverageStores = "coveragestores"
+	listCoverages      = "coverages"
+	listLayerGroups    = "layergroups"
+	listGridSets       = "gridsets"
+	listTileLayers     = "tilelayers"
+)
+
+// stage groups work items that may run concurrently. Stages run in order, so
+// every item can rely on the objects of earlier stages existing on the destination.
+type stage int
+
+const (
+	stageWorkspaces stage = iota
+	stageStyles
+	stageStores
+	stageLayers
+	stageLayerGroups
+	stageGridSets
+	stageTileLayers
+	numStages
+)
+
+// stageNames are used in task log messages
+var stageNames = [numStages]string{"workspaces", "styles", "stores", "layers", "layer groups", "gridsets", "tile layers"}
+
+// stageOf returns the stage an item kind belongs to
+func stageOf(kind ItemKind) stage {
+	switch kind {
+	case KindWorkspace, KindListing:
+		return stageWorkspaces
+	case KindStyle:
+		return stageStyles
+	case KindDataStore:
+		return stageStores
+	case KindFeatureType, KindCoverage:
+		return stageLayers
+	case KindGridSet, KindDiskQuota:
+		return stageGridSets
+	case KindTileLayer:
+		return stageTileLayers
+	default:
+		return stageLayerGroups
+	}
+}
+
+// WorkItem is a single unit of sync work
+type WorkItem struct {
+	Kind      ItemKind `json:"kind"`
+	Workspace string   `json:"workspace,omitempty"` // Empty for global styles
+	Store     string   `json:"store,omitempty"`
+	Name      string   `json:"name"`
+}
+
+// listingItem returns the work item recording a listing of a workspace, of a
+// store, or of the whole server
+func listingItem(name, workspace, store string) WorkItem {
+	return WorkItem{Kind: KindListing, Workspace: workspace, Store: store, Name: name}
+}
+
+// listed describes what a listing item lists, e.g. datastores of topp
+func (w WorkItem) listed() string {
+	switch {
+	case w.Store != "":
+		return fmt.Sprintf("%s of %s:%s", w.Name, w.Workspace, w.Store)
+	case w.Workspace != "":
+		return fmt.Sprintf("%s of %s", w.Name, w.Workspace)
+	default:
+		return w.Name
+	}
+}
+
+// Key returns a stable identifier for the item, unique within a sync task
+func (w WorkItem) Key() string {
+	return fmt.Sprintf("%s:%s/%s/%s", w.Kind, w.Workspace, w.Store, w.Name)
+}
+
+// Label returns a human readable description of the item
+func (w WorkItem) Label() string {
+	switch {
+	case w.Kind == KindWorkspace:
+		return fmt.Sprintf("Workspace: %s", w.Name)
+	case w.Kind == KindStyle && w.Workspace == "":
+		return fmt.Sprintf("Global Style: %s", w.Name)
+	case w.Kind == KindStyle:
+		return fmt.Sprintf("Style: %s:%s", w.Workspace, w.Name)
+	case w.Kind == KindDataStore:
+		return fmt.Sprintf("DataStore: %s:%s", w.Workspace, w.Name)
+	case w.Kind == KindFeatureType:
+		return fmt.Sprintf("FeatureType: %s:%s", w.Workspace, w.Name)
+	case w.Kind == KindCoverage:
+		return fmt.Sprintf("Coverage: %s:%s", w.Workspace, w.Name)
+	case w.Kind == KindGridSet:
+		return fmt.Sprintf("GridSet: %s", w.Name)
+	case w.Kind == KindDiskQuota:
+		return "Disk Quota"
+	case w.Kind == KindTileLayer:
+		return fmt.Sprintf("Tile Layer: %s", w.Name)
+	case w.Kind == KindListing:
+		return fmt.Sprintf("Listing: %s", w.listed())
+	default:
+		return fmt.Sprintf("LayerGroup: %s:%s", w.Workspace, w.Name)
+	}
+}
+
+// itemResult is the outcome of syncing a single work item
+type itemResult int
+
+const (
+	resultDone itemResult = iota
+	resultSkipped
+	resultFailed
+)
+
+// workQueue holds the planned work items of a sync task, grouped by stage
+type workQueue struct {
+	stages [numStages][]WorkItem
+}
+
+// add queues an item in the stage matching its kind
+func (q *workQueue) add(item WorkItem) {
+	st := stageOf(item.Kind)
+	q.stages[st] = append(q.stages[st], item)
+}
+
+// len returns the total number of queued items
+func (q *workQueue) len() int {
+	n := 0
+	for _, items := range q.stages {
+		n += len(items)
+	}
+	return n
+}
+
+// layerGroupLevels orders layer groups so that every group follows the groups
+// nested in it. Each level may run concurrently once the levels before it are
+// done. nested returns the qualified names (workspace:name) of the groups a
+// group contains; groups that are not queued are assumed to exist already,
+// and groups nested in a cycle go in a last level.
+func layerGroupLevels(items []WorkItem, nested func(WorkItem) []string) [][]WorkItem {
+	queued := make(map[string]bool, len(items))
+	for _, item := range items {
+		queued[item.Workspace+":"+item.Name] = true
+	}
+	deps := make(map[string][]string, len(items))
+	for _, item := range items {
+		key := item.Workspace + ":" + item.Name
+		for _, dep := range nested(item) {
+			if queued[dep] && dep != key {
+				deps[key] = append(deps[key], dep)
+			}
+		}
+	}
+
+	var levels [][]WorkItem
+	done := make(map[string]bool, len(items))
+	for remaining := items; len(remaining) > 0; {
+		var level, blocked []WorkItem
+		for _, item := range remaining {
+			ready := true
+			for _, dep := range deps[item.Workspace+":"+item.Name] {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				level = append(level, item)
+			} else {
+				blocked = append(blocked, item)
+			}
+		}
+		if len(level) == 0 {
+			return append(levels, blocked)
+		}
+		for _, item := range level {
+			done[item.Workspace+":"+item.Name] = true
+		}
+		levels = append(levels, level)
+		remaining = blocked
+	}
+	return levels
+}
+
+// runStage processes the items of one stage with a pool of workers and waits
+// for all of them to finish. Workers stop picking up new items once stopChan closes.
+func runStage(items []WorkItem, workers int, stopChan <-chan struct{}, process func(WorkItem)) {
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	itemChan := make(chan WorkItem)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range itemChan {
+				process(item)
+			}
+		}()
+	}
+
+feed:
+	for _, item := range items {
+		select {
+		case <-stopChan:
+			break feed
+		case itemChan <- item:
+		}
+	}
+	close(itemChan)
+	wg.Wait()
+}
@@ -0,0 +1,140 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+func TestWorkQueueStages(t *testing.T) {
+	q := &workQueue{}
+	q.add(WorkItem{Kind: KindLayerGroup, Workspace: "ws", Name: "group"})
+	q.add(WorkItem{Kind: KindFeatureType, Workspace: "ws", Store: "store", Name: "roads"})
+	q.add(WorkItem{Kind: KindStyle, Name: "line"})
+	q.add(WorkItem{Kind: KindWorkspace, Name: "ws"})
+
+	if q.len() != 4 {
+		t.Fatalf("expected 4 items, got %d", q.len())
+	}
+	if len(q.stages[stageWorkspaces]) != 1 || len(q.stages[stageStyles]) != 1 ||
+		len(q.stages[stageLayers]) != 1 || len(q.stages[stageLayerGroups]) != 1 {
+		t.Fatalf("items queued in wrong stages: %+v", q.stages)
+	}
+}
+
+func TestLayerGroupLevels(t *testing.T) {
+	group := func(name string) WorkItem {
+		return WorkItem{Kind: KindLayerGroup, Workspace: "ws", Name: name}
+	}
+	nested := map[string][]string{
+		"city":    {"ws:roads", "ws:parcels"},
+		"roads":   {"ws:streets", "other:base"}, // other:base is not synced
+		"streets": nil,
+		"parcels": nil,
+		"loop_a":  {"ws:loop_b"},
+		"loop_b":  {"ws:loop_a"},
+	}
+	items := []WorkItem{group("city"), group("loop_a"), group("roads"), group("streets"), group("parcels"), group("loop_b")}
+
+	levels := layerGroupLevels(items, func(item WorkItem) []string { return nested[item.Name] })
+	var got []string
+	for _, level := range levels {
+		names := ""
+		for _, item := range level {
+			names += item.Name + " "
+		}
+		got = append(got, names)
+	}
+	want := []string{"streets parcels ", "roads ", "city ", "loop_a loop_b "}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("levels %q, want %q", got, want)
+	}
+}
+
+func TestRunStageProcessesAllItems(t *testing.T) {
+	items := make([]WorkItem, 50)
+	for i := range items {
+		items[i] = WorkItem{Kind: KindStyle, Name: string(rune('a' + i%26))}
+	}
+
+	var processed int32
+	runStage(items, 8, make(chan struct{}), func(WorkItem) {
+		atomic.AddInt32(&processed, 1)
+	})
+
+	if processed != 50 {
+		t.Fatalf("expected 50 processed items, got %d", processed)
+	}
+}
+
+func TestRunStageStops(t *testing.T) {
+	items := make([]WorkItem, 50)
+	stopChan := make(chan struct{})
+
+	var processed int32
+	runStage(items, 2, stopChan, func(WorkItem) {
+		if atomic.AddInt32(&processed, 1) == 5 {
+			close(stopChan)
+		}
+	})
+
+	if processed >= 50 {
+		t.Fatalf("expected stop to prevent remaining items, processed %d", processed)
+	}
+}
+
+// TestFailedListingIsRetried checks that a listing that fails while planning
+// is recorded as a failed item, and planned again by a retry pass
+func TestFailedListingIsRetried(t *testing.T) {
+	var down atomic.Bool
+	down.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/workspaces/topp/styles" {
+			http.NotFound(w, r)
+			return
+		}
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"styles": {"style": [{"name": "roads"}]}}`)
+	}))
+	defer server.Close()
+
+	task := &Task{ID: "task"}
+	options := config.SyncOptions{Styles: true}
+	e := &Executor{
+		task:         task,
+		sourceClient: api.NewClientDirect(server.URL, "admin", "geoserver"),
+		options:      options,
+		stopChan:     make(chan struct{}),
+		checkpoint:   newCheckpoint(task, options, nil),
+	}
+
+	queue := &workQueue{}
+	e.planWorkspace(queue, "topp")
+	failed := e.checkpoint.failedItems()
+	if queue.len() != 0 || len(failed) != 1 || failed[0] != listingItem(listStyles, "topp", "") {
+		t.Fatalf("failed listing: queued %d items, recorded %+v", queue.len(), failed)
+	}
+	if task.ItemsTotal != 1 || task.ItemsFailed != 1 {
+		t.Errorf("task counts %d failed of %d, want 1 of 1", task.ItemsFailed, task.ItemsTotal)
+	}
+
+	down.Store(false)
+	queue = &workQueue{}
+	e.replan(queue, failed[0])
+	if styles := queue.stages[stageStyles]; len(styles) != 1 || styles[0].Name != "roads" {
+		t.Errorf("retried listing queued %+v", queue.stages)
+	}
+	if len(e.checkpoint.failedItems()) != 0 {
+		t.Error("listing still recorded as failed after it succeeded")
+	}
+}
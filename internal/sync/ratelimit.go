@@ -0,0 +1,122 @@
+package sync
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+)
+
+// rateLimiter spaces requests evenly so that at most a fixed number are
+// started per second. Tasks sharing a limiter may ask for different rates;
+// the strictest rate of the tasks still running applies to all of them.
+type rateLimiter struct {
+	mu        sync.Mutex
+	intervals map[time.Duration]int // Number of running tasks asking for each interval
+	next      time.Time
+}
+
+// serverLimiters holds one limiter per server URL, so that concurrent tasks
+// talking to the same server share a single request budget. Tasks without a
+// rate limit do not use it.
+var (
+	serverLimiters   = make(map[string]*rateLimiter)
+	serverLimitersMu sync.Mutex
+)
+
+// limiterFor returns the shared limiter for a server with the task's rate
+// added, or nil when unlimited. The task calls release when it ends.
+func limiterFor(baseURL string, requestsPerSecond float64) (l *rateLimiter, release func()) {
+	if requestsPerSecond <= 0 {
+		return nil, func() {}
+	}
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+
+	serverLimitersMu.Lock()
+	defer serverLimitersMu.Unlock()
+
+	l, ok := serverLimiters[baseURL]
+	if !ok {
+		l = &rateLimiter{intervals: make(map[time.Duration]int)}
+		serverLimiters[baseURL] = l
+	}
+	l.mu.Lock()
+	l.intervals[interval]++
+	l.mu.Unlock()
+
+	var once sync.Once
+	return l, func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.intervals[interval]--; l.intervals[interval] == 0 {
+				delete(l.intervals, interval)
+			}
+		})
+	}
+}
+
+// interval returns the longest interval any running task asks for. The
+// caller holds l.mu.
+func (l *rateLimiter) interval() time.Duration {
+	var longest time.Duration
+	for interval := range l.intervals {
+		longest = max(longest, interval)
+	}
+	return longest
+}
+
+// wait blocks until the next request slot is available or the stop channel closes.
+// It returns false if the wait was interrupted.
+func (l *rateLimiter) wait(stopChan <-chan struct{}) bool {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(l.interval())
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stopChan:
+		return false
+	}
+}
+
+// rateLimitedTransport delays each request until its server's limiter allows it
+type rateLimitedTransport struct {
+	base     http.RoundTripper
+	limiter  *rateLimiter
+	stopChan <-chan struct{}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.limiter.wait(t.stopChan) {
+		return nil, errSyncStopped
+	}
+	return t.base.RoundTrip(req)
+}
+
+// applyRateLimit routes a client's requests through its server's shared
+// limiter. The returned function releases the limiter once the task ends.
+func applyRateLimit(client *api.Client, requestsPerSecond float64, stopChan <-chan struct{}) (release func()) {
+	limiter, release := limiterFor(client.BaseURL(), requestsPerSecond)
+	if limiter == nil {
+		return release
+	}
+	client.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		return &rateLimitedTransport{base: base, limiter: limiter, stopChan: stopChan}
+	})
+	return release
+}
@@ -0,0 +1,34 @@
+package sync
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiterKeepsStrictestRate(t *testing.T) {
+	fast, releaseFast := limiterFor("http://shared/geoserver", 10)
+	slow, releaseSlow := limiterFor("http://shared/geoserver", 2)
+	if fast != slow {
+		t.Fatal("tasks on the same server got different limiters")
+	}
+
+	interval := func() time.Duration {
+		fast.mu.Lock()
+		defer fast.mu.Unlock()
+		return fast.interval()
+	}
+	if got := interval(); got != 500*time.Millisecond {
+		t.Errorf("interval with both tasks = %v, want the slower task's 500ms", got)
+	}
+
+	// Releasing twice must not drop the faster task's rate
+	releaseSlow()
+	releaseSlow()
+	if got := interval(); got != 100*time.Millisecond {
+		t.Errorf("interval after the slower task ended = %v, want 100ms", got)
+	}
+	releaseFast()
+	if got := interval(); got != 0 {
+		t.Errorf("interval without tasks = %v, want 0", got)
+	}
+}
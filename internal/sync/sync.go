@@ -2,14 +2,19 @@
 package sync
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/cache"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 )
 
 // Task represents a running sync task
@@ -18,7 +23,7 @@ type Task struct {
 	ConfigID     string     `json:"configId"`
 	SourceID     string     `json:"sourceId"`
 	DestID       string     `json:"destId"`
-	Status       string     `json:"status"` // running, completed, failed, stopped
+	Status       string     `json:"status"` // pending, running, completed, failed, stopped
 	Progress     float64    `json:"progress"`
 	CurrentItem  string     `json:"currentItem"`
 	ItemsTotal   int        `json:"itemsTotal"`
@@ -29,38 +34,89 @@ type Task struct {
 	CompletedAt  *time.Time `json:"completedAt,omitempty"`
 	Error        string     `json:"error,omitempty"`
 	Log          []string   `json:"log"`
+	Resumable    bool       `json:"resumable"` // A checkpoint exists to resume or retry from
 
-	mu sync.Mutex
+	notify func(TaskEvent)
+	job    *jobs.Job // The background job running the task
+	mu     sync.Mutex
 }
 
-// Manager manages running sync tasks
+// Task event kinds passed to listeners registered with OnEvent
+const (
+	EventLog      = "log"
+	EventProgress = "progress"
+	EventComplete = "complete"
+)
+
+// TaskEvent describes a change to a task
+type TaskEvent struct {
+	Task    *Task
+	Kind    string // EventLog, EventProgress or EventComplete
+	Message string // The log line for EventLog, the final status for EventComplete
+}
+
+// Manager manages running sync tasks. Tasks run as background jobs, so
+// they count towards the sync concurrency limit.
 type Manager struct {
 	tasks     map[string]*Task
 	stopChans map[string]chan struct{}
+	jobs      *jobs.Manager
 	mu        sync.RWMutex
+
+	listener   func(TaskEvent)
+	listenerMu sync.RWMutex // Separate from mu so tasks can emit while mu is held
 }
 
-// NewManager creates a new sync manager
-func NewManager() *Manager {
+// NewManager creates a new sync manager running tasks on jm
+func NewManager(jm *jobs.Manager) *Manager {
 	return &Manager{
 		tasks:     make(map[string]*Task),
 		stopChans: make(map[string]chan struct{}),
+		jobs:      jm,
 	}
 }
 
 // Global manager instance
-var DefaultManager = NewManager()
+var DefaultManager = NewManager(jobs.Default)
+
+func init() {
+	// Make interrupted tasks from previous runs available for resuming
+	if err := DefaultManager.LoadCheckpoints(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to load sync checkpoints: %v\n", err)
+	}
+}
+
+// OnEvent registers fn to be called when a task logs a line, makes progress
+// or completes. fn must not block.
+func (m *Manager) OnEvent(fn func(TaskEvent)) {
+	m.listenerMu.Lock()
+	defer m.listenerMu.Unlock()
+	m.listener = fn
+}
 
-// StartSync starts a sync operation for a single destination
-func (m *Manager) StartSync(sourceConn, destConn *config.Connection, options config.SyncOptions, configID string) *Task {
+// emit passes a task event to the listener, if any
+func (m *Manager) emit(ev TaskEvent) {
+	m.listenerMu.RLock()
+	fn := m.listener
+	m.listenerMu.RUnlock()
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// StartSync starts a sync operation for a single destination.
+// mappings may be nil when names are synced unchanged. Changes to the
+// destination are recorded in the audit log as actor, which may be nil.
+func (m *Manager) StartSync(sourceConn, destConn *config.Connection, options config.SyncOptions, mappings *config.SyncMappingRules, configID string, actor *audit.Actor) *Task {
 	task := &Task{
 		ID:        uuid.New().String(),
 		ConfigID:  configID,
 		SourceID:  sourceConn.ID,
 		DestID:    destConn.ID,
-		Status:    "running",
+		Status:    "pending",
 		StartedAt: time.Now(),
 		Log:       []string{fmt.Sprintf("Starting sync from %s to %s", sourceConn.Name, destConn.Name)},
+		notify:    m.emit,
 	}
 
 	stopChan := make(chan struct{})
@@ -69,12 +125,199 @@ func (m *Manager) StartSync(sourceConn, destConn *config.Connection, options con
 	m.stopChans[task.ID] = stopChan
 	m.mu.Unlock()
 
-	// Start sync in goroutine
-	go m.runSync(task, sourceConn, destConn, options, stopChan)
+	// Start sync as a background job, which waits while too many syncs run
+	checkpoint := newCheckpoint(task, options, mappings)
+	m.submit(task, sourceConn, destConn, checkpoint, nil, stopChan, actor)
 
 	return task
 }
 
+// ResumeTask restarts a stopped or failed task from its checkpoint, skipping
+// items that were already synced
+func (m *Manager) ResumeTask(cfg *config.Config, id string, actor *audit.Actor) (*Task, error) {
+	return m.restartTask(cfg, id, false, actor)
+}
+
+// RetryFailedItems re-runs only the items of a task that failed
+func (m *Manager) RetryFailedItems(cfg *config.Config, id string, actor *audit.Actor) (*Task, error) {
+	return m.restartTask(cfg, id, true, actor)
+}
+
+func (m *Manager) restartTask(cfg *config.Config, id string, retryOnly bool, actor *audit.Actor) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok {
+		return nil, fmt.Errorf("task not found")
+	}
+	if task.isActive() {
+		return nil, fmt.Errorf("task is still running")
+	}
+
+	checkpoint, err := loadCheckpoint(id)
+	if err != nil {
+		return nil, fmt.Errorf("no checkpoint for task: %w", err)
+	}
+	if checkpoint.runningElsewhere() {
+		return nil, fmt.Errorf("task is running in another CloudBench process")
+	}
+	checkpoint.Owner = config.CurrentOwner()
+
+	sourceConn := cfg.GetConnection(checkpoint.SourceID)
+	destConn := cfg.GetConnection(checkpoint.DestID)
+	if sourceConn == nil || destConn == nil {
+		return nil, fmt.Errorf("source or destination connection no longer exists")
+	}
+
+	var retryItems []WorkItem
+	if retryOnly {
+		retryItems = checkpoint.failedItems()
+		if len(retryItems) == 0 {
+			return nil, fmt.Errorf("task has no failed items to retry")
+		}
+	}
+
+	// Reset the task; counters are rebuilt from the checkpoint as it runs
+	task.mu.Lock()
+	task.Status = "pending"
+	task.Error = ""
+	task.CompletedAt = nil
+	task.CurrentItem = ""
+	task.Progress = 0
+	task.ItemsTotal = 0
+	task.ItemsDone = 0
+	task.ItemsSkipped = 0
+	task.ItemsFailed = 0
+	task.Resumable = false
+	if retryOnly {
+		done, skipped := checkpoint.counts()
+		task.ItemsTotal = checkpoint.ItemsTotal
+		task.ItemsDone = done
+		task.ItemsSkipped = skipped
+		task.Log = append(task.Log, fmt.Sprintf("Retrying failed items from %s to %s", sourceConn.Name, destConn.Name))
+	} else {
+		task.Log = append(task.Log, fmt.Sprintf("Resuming sync from %s to %s", sourceConn.Name, destConn.Name))
+	}
+	task.mu.Unlock()
+
+	stopChan := make(chan struct{})
+	m.stopChans[id] = stopChan
+	checkpoint.setStatus("running", checkpoint.ItemsTotal)
+	// Claim the task at once, so other processes see it is running
+	if err := checkpoint.save(true); err != nil {
+		task.AddLog(fmt.Sprintf("Warning: failed to save checkpoint: %v", err))
+	}
+
+	m.submit(task, sourceConn, destConn, checkpoint, retryItems, stopChan, actor)
+
+	return task, nil
+}
+
+// submit runs a task as a background job. Cancelling the job stops the task.
+func (m *Manager) submit(task *Task, source, dest *config.Connection, checkpoint *Checkpoint, retryItems []WorkItem, stopChan chan struct{}, actor *audit.Actor) {
+	spec := jobs.Spec{
+		ID:    task.ID,
+		Kind:  jobs.KindSync,
+		Title: fmt.Sprintf("Sync %s to %s", source.Name, dest.Name),
+		Details: map[string]string{
+			"source_id": source.ID,
+			"dest_id":   dest.ID,
+			"config_id": task.ConfigID,
+		},
+	}
+
+	m.jobs.Submit(spec, func(ctx context.Context, job *jobs.Job) error {
+		task.mu.Lock()
+		if task.Status != "pending" {
+			task.mu.Unlock()
+			return jobs.ErrCancelled // Stopped while queued
+		}
+		task.Status = "running"
+		task.job = job
+		task.mu.Unlock()
+
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				m.StopTask(task.ID)
+			case <-done:
+			}
+		}()
+
+		m.runSync(task, source, dest, checkpoint, retryItems, stopChan, actor)
+		close(done)
+
+		m.mu.Lock()
+		if m.stopChans[task.ID] == stopChan {
+			delete(m.stopChans, task.ID)
+		}
+		m.mu.Unlock()
+
+		task.mu.Lock()
+		status, errMsg := task.Status, task.Error
+		task.mu.Unlock()
+		switch status {
+		case "failed":
+			return errors.New(errMsg)
+		case "stopped":
+			return jobs.ErrCancelled
+		}
+		return nil
+	})
+}
+
+// LoadCheckpoints registers tasks from checkpoints left on disk, e.g. by a
+// previous CloudBench process, so they can be resumed. Tasks that were still
+// running when their process exited are marked as failed; tasks another
+// running CloudBench process owns are skipped.
+func (m *Manager) LoadCheckpoints() error {
+	checkpoints, err := listCheckpoints()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, cp := range checkpoints {
+		if _, exists := m.tasks[cp.TaskID]; exists {
+			continue
+		}
+		// Tasks another CloudBench process is running are left to it
+		if cp.runningElsewhere() {
+			continue
+		}
+
+		done, skipped := cp.counts()
+		task := &Task{
+			ID:           cp.TaskID,
+			ConfigID:     cp.ConfigID,
+			SourceID:     cp.SourceID,
+			DestID:       cp.DestID,
+			Status:       cp.Status,
+			ItemsTotal:   cp.ItemsTotal,
+			ItemsDone:    done,
+			ItemsSkipped: skipped,
+			ItemsFailed:  len(cp.Failed),
+			StartedAt:    cp.StartedAt,
+			CompletedAt:  &cp.UpdatedAt,
+			Resumable:    true,
+			Log:          []string{"Restored from checkpoint"},
+			notify:       m.emit,
+		}
+		if task.Status == "running" || task.Status == "pending" {
+			task.Status = "failed"
+			task.Error = "CloudBench exited while the sync was running"
+		}
+		task.UpdateProgress()
+		m.tasks[task.ID] = task
+	}
+
+	return nil
+}
+
 // GetTask returns a specific task
 func (m *Manager) GetTask(id string) *Task {
 	m.mu.RLock()
@@ -105,12 +348,14 @@ func (m *Manager) StopTask(id string) bool {
 
 	close(stopChan)
 	delete(m.stopChans, id)
+	m.jobs.Cancel(id) // Drops the task if it is still queued
 
 	if task, ok := m.tasks[id]; ok {
 		task.mu.Lock()
 		task.Status = "stopped"
 		task.Log = append(task.Log, "Sync stopped by user")
 		task.mu.Unlock()
+		m.emit(TaskEvent{Task: task, Kind: EventLog, Message: "Sync stopped by user"})
 	}
 
 	return true
@@ -124,11 +369,13 @@ func (m *Manager) StopAllTasks() {
 	for id, stopChan := range m.stopChans {
 		close(stopChan)
 		delete(m.stopChans, id)
+		m.jobs.Cancel(id)
 		if task, ok := m.tasks[id]; ok {
 			task.mu.Lock()
 			task.Status = "stopped"
 			task.Log = append(task.Log, "Sync stopped by user")
 			task.mu.Unlock()
+			m.emit(TaskEvent{Task: task, Kind: EventLog, Message: "Sync stopped by user"})
 		}
 	}
 }
@@ -139,8 +386,11 @@ func (m *Manager) ClearCompletedTasks() {
 	defer m.mu.Unlock()
 
 	for id, task := range m.tasks {
-		if task.Status != "running" {
+		if !task.isActive() {
 			delete(m.tasks, id)
+			if path, err := checkpointPath(id); err == nil {
+				os.Remove(path)
+			}
 		}
 	}
 }
@@ -149,13 +399,42 @@ func (m *Manager) ClearCompletedTasks() {
 
 // AddLog adds a log entry to the task
 func (t *Task) AddLog(msg string) {
+	defer t.emit(EventLog, msg)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.Log = append(t.Log, fmt.Sprintf("[%s] %s", time.Now().Format("15:04:05"), msg))
 }
 
+// emit notifies the manager's listener, and the task's job, of a change to
+// the task. It must be called without holding t.mu.
+func (t *Task) emit(kind, msg string) {
+	if t.notify != nil {
+		t.notify(TaskEvent{Task: t, Kind: kind, Message: msg})
+	}
+
+	t.mu.Lock()
+	job, progress, item := t.job, t.Progress, t.CurrentItem
+	t.mu.Unlock()
+	if job == nil {
+		return
+	}
+	switch kind {
+	case EventLog:
+		job.AddLog(msg)
+	case EventProgress:
+		job.SetProgress(progress, item)
+	}
+}
+
+// isActive reports whether the task is queued or running
+func (t *Task) isActive() bool {
+	status := t.GetStatus()
+	return status == "pending" || status == "running"
+}
+
 // SetError sets the task to failed status with an error message
 func (t *Task) SetError(msg string) {
+	defer t.emit(EventLog, "ERROR: "+msg)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.Status = "failed"
@@ -165,6 +444,7 @@ func (t *Task) SetError(msg string) {
 
 // UpdateProgress updates the task progress
 func (t *Task) UpdateProgress() {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	if t.ItemsTotal > 0 {
@@ -174,6 +454,7 @@ func (t *Task) UpdateProgress() {
 
 // SetCurrentItem sets the current item being processed
 func (t *Task) SetCurrentItem(item string) {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.CurrentItem = item
@@ -181,13 +462,23 @@ func (t *Task) SetCurrentItem(item string) {
 
 // IncrementTotal increments the total items count
 func (t *Task) IncrementTotal() {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.ItemsTotal++
 }
 
+// AddTotal adds planned items to the total items count
+func (t *Task) AddTotal(n int) {
+	defer t.emit(EventProgress, "")
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ItemsTotal += n
+}
+
 // IncrementDone increments the done items count
 func (t *Task) IncrementDone() {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.ItemsDone++
@@ -195,6 +486,7 @@ func (t *Task) IncrementDone() {
 
 // IncrementSkipped increments the skipped items count
 func (t *Task) IncrementSkipped() {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.ItemsSkipped++
@@ -202,11 +494,54 @@ func (t *Task) IncrementSkipped() {
 
 // IncrementFailed increments the failed items count
 func (t *Task) IncrementFailed() {
+	defer t.emit(EventProgress, "")
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	t.ItemsFailed++
 }
 
+// TaskProgress is a task's state without its log
+type TaskProgress struct {
+	ID           string     `json:"id"`
+	ConfigID     string     `json:"configId"`
+	SourceID     string     `json:"sourceId"`
+	DestID       string     `json:"destId"`
+	Status       string     `json:"status"`
+	Progress     float64    `json:"progress"`
+	CurrentItem  string     `json:"currentItem"`
+	ItemsTotal   int        `json:"itemsTotal"`
+	ItemsDone    int        `json:"itemsDone"`
+	ItemsSkipped int        `json:"itemsSkipped"`
+	ItemsFailed  int        `json:"itemsFailed"`
+	StartedAt    time.Time  `json:"startedAt"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Resumable    bool       `json:"resumable"`
+}
+
+// Snapshot returns the task's current state without its log
+func (t *Task) Snapshot() TaskProgress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return TaskProgress{
+		ID:           t.ID,
+		ConfigID:     t.ConfigID,
+		SourceID:     t.SourceID,
+		DestID:       t.DestID,
+		Status:       t.Status,
+		Progress:     t.Progress,
+		CurrentItem:  t.CurrentItem,
+		ItemsTotal:   t.ItemsTotal,
+		ItemsDone:    t.ItemsDone,
+		ItemsSkipped: t.ItemsSkipped,
+		ItemsFailed:  t.ItemsFailed,
+		StartedAt:    t.StartedAt,
+		CompletedAt:  t.CompletedAt,
+		Error:        t.Error,
+		Resumable:    t.Resumable,
+	}
+}
+
 // GetStatus returns the current status (thread-safe)
 func (t *Task) GetStatus() string {
 	t.mu.Lock()
@@ -214,6 +549,13 @@ func (t *Task) GetStatus() string {
 	return t.Status
 }
 
+// IsResumable reports whether the task has a checkpoint to resume from (thread-safe)
+func (t *Task) IsResumable() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.Resumable
+}
+
 // GetProgress returns the current progress (thread-safe)
 func (t *Task) GetProgress() float64 {
 	t.mu.Lock()
@@ -237,20 +579,46 @@ func (t *Task) GetLogs() []string {
 	return logs
 }
 
-// runSync performs the actual sync operation
-func (m *Manager) runSync(task *Task, source, dest *config.Connection, options config.SyncOptions, stopChan chan struct{}) {
+// runSync performs the actual sync operation. retryItems is nil for a full
+// (or resumed) run.
+func (m *Manager) runSync(task *Task, source, dest *config.Connection, checkpoint *Checkpoint, retryItems []WorkItem, stopChan chan struct{}, actor *audit.Actor) {
 	defer func() {
+		defer func() { task.emit(EventComplete, task.GetStatus()) }()
+
 		task.mu.Lock()
 		now := time.Now()
 		task.CompletedAt = &now
 		if task.Status == "running" {
 			task.Status = "completed"
 		}
+		status, total, failed := task.Status, task.ItemsTotal, task.ItemsFailed
+		task.mu.Unlock()
+
+		// Keep the checkpoint only while there is something left to resume or retry
+		checkpoint.setStatus(status, total)
+		if status == "completed" && failed == 0 {
+			checkpoint.remove()
+			return
+		}
+		if err := checkpoint.save(true); err != nil {
+			task.AddLog(fmt.Sprintf("Warning: failed to save checkpoint: %v", err))
+			return
+		}
+		task.mu.Lock()
+		task.Resumable = true
 		task.mu.Unlock()
 	}()
 
+	options := checkpoint.Options
+
 	sourceClient := api.NewClient(source)
 	destClient := api.NewClient(dest)
+	defer applyRateLimit(sourceClient, options.RateLimit, stopChan)()
+	defer applyRateLimit(destClient, options.RateLimit, stopChan)()
+	if actor = actor.ForConnection(dest.ID); actor != nil {
+		actor.Via = "sync"
+		destClient = destClient.WithAudit(actor)
+	}
 
 	// Get or create cache manager
 	cacheManager := cache.DefaultManager
@@ -270,6 +638,9 @@ func (m *Manager) runSync(task *Task, source, dest *config.Connection, options c
 		stopChan:     stopChan,
 		sourceID:     source.ID,
 		cacheManager: cacheManager,
+		mapper:       newNameMapper(checkpoint.Mappings, dest.ID),
+		checkpoint:   checkpoint,
+		retryItems:   retryItems,
 	}
 
 	executor.Execute()
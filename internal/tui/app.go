@@ -6,13 +6,16 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/cache"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 	"github.com/kartoza/kartoza-cloudbench/internal/models"
 	"github.com/kartoza/kartoza-cloudbench/internal/preview"
 	"github.com/kartoza/kartoza-cloudbench/internal/tui/components"
@@ -30,6 +33,9 @@ const (
 	ScreenUpload
 	ScreenHelp
 	ScreenSync
+	ScreenAudit
+	ScreenJobs
+	ScreenSnapshots
 )
 
 // CRUDOperation represents the type of CRUD operation
@@ -60,6 +66,9 @@ type AppKeyMap struct {
 	Refresh     key.Binding
 	Escape      key.Binding
 	Sync        key.Binding
+	Audit       key.Binding
+	Jobs        key.Binding
+	Timeline    key.Binding
 	Search      key.Binding
 }
 
@@ -98,6 +107,18 @@ func DefaultAppKeyMap() AppKeyMap {
 			key.WithKeys("S"),
 			key.WithHelp("S", "sync servers"),
 		),
+		Audit: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "audit log"),
+		),
+		Jobs: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "background jobs"),
+		),
+		Timeline: key.NewBinding(
+			key.WithKeys("H"),
+			key.WithHelp("H", "catalog timeline"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("ctrl+k", "/"),
 			key.WithHelp("Ctrl+K", "search"),
@@ -147,6 +168,9 @@ type App struct {
 	treeView          *components.TreeView
 	connectionsScreen *screens.ConnectionsScreen
 	syncScreen        *screens.SyncScreen
+	auditScreen       *screens.AuditScreen
+	jobsScreen        *screens.JobsScreen
+	snapshotsScreen   *screens.SnapshotsScreen
 	dashboardScreen   *screens.DashboardScreen
 	screen            Screen
 	activePanel       Panel
@@ -190,6 +214,9 @@ type App struct {
 	// Pending CRUD command (set by dialog callbacks, executed when dialog closes)
 	pendingCRUDCmd tea.Cmd
 
+	// Connections asked about their queued offline changes since reconnecting
+	replayPrompted map[string]bool
+
 	// Info dialog state
 	infoDialog *components.InfoDialog
 
@@ -238,6 +265,9 @@ func NewApp(cfg *config.Config, version string) *App {
 		treeView:          components.NewTreeView(),
 		connectionsScreen: screens.NewConnectionsScreen(cfg),
 		syncScreen:        screens.NewSyncScreen(cfg),
+		auditScreen:       screens.NewAuditScreen(),
+		jobsScreen:        screens.NewJobsScreen(),
+		snapshotsScreen:   screens.NewSnapshotsScreen(),
 		dashboardScreen:   screens.NewDashboardScreen(cfg),
 		screen:            ScreenDashboard, // Start with dashboard
 		activePanel:       PanelLeft,
@@ -249,12 +279,24 @@ func NewApp(cfg *config.Config, version string) *App {
 	app.fileBrowser.SetActive(true)
 	app.treeView.SetActive(false)
 
+	// Apply the configured per-kind job concurrency limits
+	jobs.Default.SetLimits(cfg.JobLimits)
+	if cache.DefaultManager != nil {
+		cache.DefaultManager.SetTTL(time.Duration(cfg.CacheTTLHours) * time.Hour)
+		go cache.DefaultManager.RunJanitor(cache.JanitorInterval, config.Load, nil, nil)
+	}
+	api.SharedCache.Configure(cfg)
+
 	// Create clients for all connections
 	for i := range cfg.Connections {
 		conn := &cfg.Connections[i]
-		app.clients[conn.ID] = api.NewClient(conn)
+		app.clients[conn.ID] = newClient(conn)
 	}
 
+	// Mark connections whose server is unreachable
+	app.replayPrompted = make(map[string]bool)
+	app.treeView.SetOfflineLabel(app.offlineLabel)
+
 	// Mark as connected if we have any connections
 	if len(cfg.Connections) > 0 {
 		app.treeView.SetConnected(true, "GeoServer Connections")
@@ -268,6 +310,7 @@ func (a *App) Init() tea.Cmd {
 	cmds := []tea.Cmd{
 		a.spinner.Tick,
 		a.dashboardScreen.Init(), // Initialize dashboard
+		a.checkOffline(),
 	}
 
 	// Build initial tree with all connections
@@ -513,13 +556,18 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return a, tea.Batch(cmds...)
 		}
+		if a.screen == ScreenAudit && a.auditScreen.IsEditingField() {
+			var cmd tea.Cmd
+			a.auditScreen, cmd = a.auditScreen.Update(msg)
+			return a, cmd
+		}
 
 		// Handle global keys
 		switch {
 		case key.Matches(msg, a.keyMap.Quit):
-			// Save config before quitting
-			a.config.LastLocalPath = a.fileBrowser.CurrentPath()
-			_ = a.config.Save()
+			// Save config before quitting, keeping changes other processes saved
+			lastPath := a.fileBrowser.CurrentPath()
+			_ = a.config.Update(func(c *config.Config) { c.LastLocalPath = lastPath })
 			return a, tea.Quit
 
 		case key.Matches(msg, a.keyMap.Tab):
@@ -546,6 +594,9 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.screen = ScreenDashboard
 				return a, a.dashboardScreen.TriggerRefresh()
 			} else if a.screen != ScreenDashboard {
+				if a.screen == ScreenJobs {
+					a.jobsScreen.Leave()
+				}
 				a.screen = ScreenMain
 				return a, nil
 			}
@@ -568,7 +619,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if a.activePanel == PanelLeft {
 					a.fileBrowser.Refresh()
 				} else if len(a.clients) > 0 {
-					// Rebuild the tree with all connections
+					// Rebuild the tree with all connections, from fresh responses
+					api.SharedCache.Clear()
 					a.buildConnectionsTree()
 					a.treeView.Refresh()
 				}
@@ -581,6 +633,23 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return a, a.syncScreen.Init()
 			}
 
+		case key.Matches(msg, a.keyMap.Audit):
+			if a.screen == ScreenMain || a.screen == ScreenDashboard {
+				a.screen = ScreenAudit
+				return a, a.auditScreen.Init()
+			}
+
+		case key.Matches(msg, a.keyMap.Jobs):
+			if a.screen == ScreenMain || a.screen == ScreenDashboard {
+				a.screen = ScreenJobs
+				return a, a.jobsScreen.Init()
+			}
+
+		case key.Matches(msg, a.keyMap.Timeline):
+			if a.screen == ScreenMain || a.screen == ScreenDashboard {
+				return a, a.openTimeline()
+			}
+
 		case key.Matches(msg, a.keyMap.Search):
 			// Open search modal
 			return a, a.openSearchModal()
@@ -606,7 +675,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				a.clients = make(map[string]*api.Client)
 				for i := range a.config.Connections {
 					conn := &a.config.Connections[i]
-					a.clients[conn.ID] = api.NewClient(conn)
+					a.clients[conn.ID] = newClient(conn)
 				}
 				// Rebuild tree
 				a.buildConnectionsTree()
@@ -618,6 +687,24 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if cmd != nil {
 				cmds = append(cmds, cmd)
 			}
+		} else if a.screen == ScreenAudit && !a.showHelp {
+			var cmd tea.Cmd
+			a.auditScreen, cmd = a.auditScreen.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		} else if a.screen == ScreenJobs && !a.showHelp {
+			var cmd tea.Cmd
+			a.jobsScreen, cmd = a.jobsScreen.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		} else if a.screen == ScreenSnapshots && !a.showHelp {
+			var cmd tea.Cmd
+			a.snapshotsScreen, cmd = a.snapshotsScreen.Update(msg)
+			if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 		} else if a.screen == ScreenMain && !a.showHelp {
 			if a.activePanel == PanelLeft {
 				var cmd tea.Cmd
@@ -649,6 +736,13 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.spinner, cmd = a.spinner.Update(msg)
 		cmds = append(cmds, cmd)
 
+	case offlineTickMsg:
+		cmds = append(cmds, a.checkOffline())
+
+	case offlineCheckedMsg:
+		a.treeView.Refresh()
+		cmds = append(cmds, a.promptReplay(), scheduleOfflineCheck())
+
 	case connectionWorkspacesLoadedMsg:
 		msg.node.IsLoading = false
 		if msg.err != nil {
@@ -755,7 +849,7 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.clients = make(map[string]*api.Client)
 			for i := range a.config.Connections {
 				conn := &a.config.Connections[i]
-				a.clients[conn.ID] = api.NewClient(conn)
+				a.clients[conn.ID] = newClient(conn)
 			}
 			a.treeView.SetConnected(len(a.clients) > 0, "GeoServer Connections")
 			a.buildConnectionsTree()
@@ -1305,6 +1399,22 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+	case screens.JobsRefreshMsg:
+		// Forward to jobs screen
+		var cmd tea.Cmd
+		a.jobsScreen, cmd = a.jobsScreen.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
+	case screens.SnapshotsMsg:
+		// Forward to snapshots screen
+		var cmd tea.Cmd
+		a.snapshotsScreen, cmd = a.snapshotsScreen.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+
 	case screens.DashboardStatusMsg, screens.DashboardRefreshMsg:
 		// Forward to dashboard screen
 		if a.dashboardScreen != nil {
@@ -1433,6 +1543,12 @@ func (a *App) View() string {
 		content = a.renderConnectionsScreen()
 	case ScreenSync:
 		content = a.renderSyncScreen()
+	case ScreenAudit:
+		content = a.renderAuditScreen()
+	case ScreenJobs:
+		content = a.renderJobsScreen()
+	case ScreenSnapshots:
+		content = a.renderSnapshotsScreen()
 	case ScreenHelp:
 		content = a.renderHelpScreen()
 	default:
@@ -1635,6 +1751,52 @@ func (a *App) renderSyncScreen() string {
 	return a.syncScreen.View()
 }
 
+// renderAuditScreen renders the audit log screen
+func (a *App) renderAuditScreen() string {
+	a.auditScreen.SetSize(a.width, a.height)
+	return a.auditScreen.View()
+}
+
+// renderJobsScreen renders the background jobs screen
+func (a *App) renderJobsScreen() string {
+	a.jobsScreen.SetSize(a.width, a.height)
+	return a.jobsScreen.View()
+}
+
+// renderSnapshotsScreen renders the catalog timeline screen
+func (a *App) renderSnapshotsScreen() string {
+	a.snapshotsScreen.SetSize(a.width, a.height)
+	return a.snapshotsScreen.View()
+}
+
+// openTimeline shows the catalog timeline of the connection selected in the
+// tree, or else the active or first connection
+func (a *App) openTimeline() tea.Cmd {
+	connID := a.config.ActiveConnection
+	if node := a.treeView.SelectedNode(); a.screen == ScreenMain && node != nil && node.ConnectionID != "" {
+		connID = node.ConnectionID
+	}
+	conn := a.config.GetConnection(connID)
+	if conn == nil && len(a.config.Connections) > 0 {
+		conn = &a.config.Connections[0]
+	}
+	if conn == nil || a.clients[conn.ID] == nil {
+		a.errorMsg = "Add a GeoServer connection to see its catalog timeline"
+		return nil
+	}
+
+	cfg := a.config
+	secret := func(name string) (string, error) {
+		backend, err := cfg.OpenSecrets()
+		if err != nil {
+			return "", err
+		}
+		return backend.Get(name)
+	}
+	a.screen = ScreenSnapshots
+	return a.snapshotsScreen.Open(conn.ID, conn.Name, a.clients[conn.ID], secret)
+}
+
 // renderDashboardScreen renders the dashboard screen with proper header/footer
 func (a *App) renderDashboardScreen() string {
 	// Title bar (same as main screen)
@@ -1717,6 +1879,9 @@ func (a *App) renderHelpOverlay(background string) string {
 				{"u", "Upload selected"},
 				{"r", "Refresh"},
 				{"c", "Manage connections"},
+				{"A", "Audit log"},
+				{"J", "Background jobs"},
+				{"H", "Catalog timeline"},
 			},
 		},
 		{
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kartoza/kartoza-cloudbench/internal/cache"
 	"github.com/kartoza/kartoza-cloudbench/internal/models"
 	"github.com/kartoza/kartoza-cloudbench/internal/tui/components"
 )
@@ -47,6 +48,7 @@ func (a *App) showCacheWizard(node *models.TreeNode) tea.Cmd {
 	// Create the cache wizard
 	a.cacheWizard = components.NewCacheWizard(node, gridSets, formats)
 	a.cacheWizard.SetSize(a.width, a.height)
+	a.cacheWizard.SetLocalUsage(a.localCacheUsage())
 
 	// Set callbacks
 	a.cacheWizard.SetCallbacks(
@@ -62,6 +64,28 @@ func (a *App) showCacheWizard(node *models.TreeNode) tea.Cmd {
 	return a.cacheWizard.Init()
 }
 
+// localCacheUsage returns the local sync cache usage per server, by
+// connection name, and the cache's size limit
+func (a *App) localCacheUsage() ([]components.CacheUsage, int64) {
+	if cache.DefaultManager == nil {
+		return nil, 0
+	}
+	policy := cache.ConfigPolicy(a.config)
+	usage, err := cache.DefaultManager.Usage(policy)
+	if err != nil {
+		return nil, policy.MaxSize
+	}
+	result := make([]components.CacheUsage, 0, len(usage))
+	for _, u := range usage {
+		name := u.ServerID
+		if conn := a.config.GetConnection(u.ServerID); conn != nil {
+			name = conn.Name
+		}
+		result = append(result, components.CacheUsage{Server: name, Size: u.Size, Entries: u.Entries, Pinned: u.Pinned})
+	}
+	return result, policy.MaxSize
+}
+
 // executeCacheOperation executes the cache operation based on wizard result
 func (a *App) executeCacheOperation(result components.CacheWizardResult) tea.Cmd {
 	return func() tea.Msg {
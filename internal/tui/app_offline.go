@@ -0,0 +1,161 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/tui/components"
+)
+
+// offlineCheckInterval is how often servers that were unreachable, or have
+// changes queued, are checked to see whether they are back
+const offlineCheckInterval = 30 * time.Second
+
+// offlineTickMsg starts a check of unreachable servers
+type offlineTickMsg struct{}
+
+// offlineCheckedMsg reports that unreachable servers were checked
+type offlineCheckedMsg struct{}
+
+// newClient creates the client for a connection. It shares the response
+// cache, and keeps the catalog it reads so the tree can be browsed while the
+// server is unreachable.
+func newClient(conn *config.Connection) *api.Client {
+	client := api.NewClient(conn)
+	client.SetResponseCache(api.SharedCache)
+	if store, err := api.OpenOfflineStore(conn.ID); err == nil {
+		client.SetOffline(store)
+	}
+	return client.WithAudit(audit.Local(conn.ID))
+}
+
+// scheduleOfflineCheck waits for the next check of unreachable servers
+func scheduleOfflineCheck() tea.Cmd {
+	return tea.Tick(offlineCheckInterval, func(time.Time) tea.Msg { return offlineTickMsg{} })
+}
+
+// checkOffline probes the servers that were unreachable or have changes
+// queued, so a reconnect is noticed even while the tree is idle
+func (a *App) checkOffline() tea.Cmd {
+	var probe []*api.Client
+	for _, client := range a.clients {
+		if store := client.Offline(); store != nil {
+			if status := store.Status(); status.Offline || status.Queued > 0 {
+				probe = append(probe, client)
+			}
+		}
+	}
+	return func() tea.Msg {
+		for _, client := range probe {
+			client.TestConnection()
+		}
+		return offlineCheckedMsg{}
+	}
+}
+
+// offlineLabel marks a connection in the tree: stale while its server is
+// unreachable, and with a count of queued changes
+func (a *App) offlineLabel(connID string) (string, bool) {
+	client := a.clients[connID]
+	if client == nil || client.Offline() == nil {
+		return "", false
+	}
+	status := client.Offline().Status()
+	var parts []string
+	if status.Offline {
+		cached := "offline, read-only"
+		if !status.CachedAt.IsZero() {
+			cached = "offline, cached " + status.CachedAt.Format("Jan 2 15:04")
+		}
+		parts = append(parts, cached)
+	}
+	if status.Queued > 0 {
+		parts = append(parts, fmt.Sprintf("%d queued", status.Queued))
+	}
+	return strings.Join(parts, ", "), status.Offline
+}
+
+// promptReplay asks what to do with the changes queued for a server that is
+// reachable again. Each connection is asked once per reconnect.
+func (a *App) promptReplay() tea.Cmd {
+	if a.crudDialog != nil && a.crudDialog.IsVisible() {
+		return nil
+	}
+	for i := range a.config.Connections {
+		conn := &a.config.Connections[i]
+		client := a.clients[conn.ID]
+		if client == nil || client.Offline() == nil {
+			continue
+		}
+		store := client.Offline()
+		status := store.Status()
+		if status.Offline {
+			delete(a.replayPrompted, conn.ID)
+			continue
+		}
+		if status.Queued == 0 || a.replayPrompted[conn.ID] {
+			continue
+		}
+		queued, err := store.Queued()
+		if err != nil || len(queued) == 0 {
+			continue
+		}
+		a.replayPrompted[conn.ID] = true
+
+		var list strings.Builder
+		for j, change := range queued {
+			if j == 8 {
+				fmt.Fprintf(&list, "\n  ... and %d more", len(queued)-j)
+				break
+			}
+			fmt.Fprintf(&list, "\n  %s %s", change.Method, change.Path)
+		}
+		message := fmt.Sprintf("%s is reachable again. %d changes were made while it was offline:\n%s",
+			conn.Name, len(queued), list.String())
+
+		a.crudDialog = components.NewSelectDialog("Replay Offline Changes", message, []components.SelectOption{
+			{Value: "replay", Label: "Replay them now, oldest first"},
+			{Value: "keep", Label: "Keep them queued"},
+			{Value: "discard", Label: "Discard them"},
+		})
+		a.crudDialog.SetSize(a.width, a.height)
+		name := conn.Name
+		a.crudDialog.SetCallbacks(
+			func(result components.DialogResult) {
+				if !result.Confirmed {
+					return
+				}
+				switch result.SelectedValue {
+				case "replay":
+					a.pendingCRUDCmd = replayQueued(client, name, len(queued))
+				case "discard":
+					if err := store.DiscardQueued(); err != nil {
+						a.errorMsg = fmt.Sprintf("Failed to discard queued changes: %v", err)
+					} else {
+						a.statusMsg = fmt.Sprintf("Discarded %d queued changes to %s", len(queued), name)
+					}
+				}
+			},
+			func() {},
+		)
+		return a.crudDialog.Init()
+	}
+	return nil
+}
+
+// replayQueued replays a server's queued changes
+func replayQueued(client *api.Client, name string, count int) tea.Cmd {
+	return func() tea.Msg {
+		replayed, err := client.ReplayQueued()
+		operation := fmt.Sprintf("Replay of %d queued changes to %s", count, name)
+		if err != nil {
+			err = fmt.Errorf("%d of %d replayed, the rest are still queued: %w", replayed, count, err)
+		}
+		return crudCompleteMsg{success: err == nil, err: err, operation: operation}
+	}
+}
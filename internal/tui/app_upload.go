@@ -184,8 +184,7 @@ func (a *App) uploadFile(files []models.LocalFile, workspace string, connectionI
 			isVerifiable = true
 		case models.FileTypeGeoTIFF:
 			err = client.UploadGeoTIFF(workspace, storeName, file.Path)
-			// GeoTIFF verification is not yet supported (uses different WCS protocol)
-			isVerifiable = false
+			isVerifiable = true
 		case models.FileTypeGeoPackage:
 			err = client.UploadGeoPackage(workspace, storeName, file.Path)
 			isVerifiable = true
@@ -253,12 +252,6 @@ func (a *App) verifyUpload(file models.LocalFile, workspace string, connectionID
 	// Wait a moment for GeoServer to fully process the upload
 	time.Sleep(time.Millisecond * 500)
 
-	// Get local layer info
-	localInfo, err := verify.GetLocalLayerInfo(file.Path)
-	if err != nil {
-		return fmt.Sprintf("Could not read local file: %v", err), false
-	}
-
 	// Get connection credentials
 	var conn *config.Connection
 	for i := range a.config.Connections {
@@ -276,6 +269,16 @@ func (a *App) verifyUpload(file models.LocalFile, workspace string, connectionID
 		return "No client for verification", false
 	}
 
+	if file.Type == models.FileTypeGeoTIFF {
+		return a.verifyRasterUpload(file, workspace, storeName, client.BaseURL(), conn)
+	}
+
+	// Get local layer info
+	localInfo, err := verify.GetLocalLayerInfo(file.Path)
+	if err != nil {
+		return fmt.Sprintf("Could not read local file: %v", err), false
+	}
+
 	// Get remote layer info via WFS
 	remoteInfo, err := verify.GetRemoteLayerInfo(
 		client.BaseURL(),
@@ -294,6 +297,35 @@ func (a *App) verifyUpload(file models.LocalFile, workspace string, connectionID
 	return result.FormatResult(), result.Success
 }
 
+// verifyRasterUpload verifies that the uploaded coverage matches the local
+// raster via WCS, optionally comparing checksums of a subset of pixels
+func (a *App) verifyRasterUpload(file models.LocalFile, workspace, coverage, baseURL string, conn *config.Connection) (string, bool) {
+	localInfo, err := verify.GetLocalRasterInfo(file.Path)
+	if err != nil {
+		return fmt.Sprintf("Could not read local raster: %v", err), false
+	}
+
+	remoteInfo, err := verify.GetRemoteRasterInfo(baseURL, workspace, coverage, conn.Username, conn.Password)
+	if err != nil {
+		return fmt.Sprintf("Could not read remote coverage: %v", err), false
+	}
+
+	var checksumErr error
+	if size := a.config.RasterChecksumPx; size > 0 {
+		checksumErr = verify.ChecksumLocalRaster(file.Path, localInfo, size)
+		if checksumErr == nil {
+			checksumErr = verify.ChecksumRemoteRaster(baseURL, workspace, coverage, conn.Username, conn.Password, remoteInfo, size)
+		}
+	}
+
+	result := verify.VerifyRasterUpload(localInfo, remoteInfo)
+	if checksumErr != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Checksum not compared: %v", checksumErr))
+	}
+
+	return result.FormatResult(), result.Success
+}
+
 // publishLayerFromStore publishes a layer from a data store or coverage store
 // If the layer already exists, it will enable and advertise it instead
 func (a *App) publishLayerFromStore(node *models.TreeNode) tea.Cmd {
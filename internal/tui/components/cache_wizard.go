@@ -35,6 +35,14 @@ type CacheWizardResult struct {
 	Workspace    string
 }
 
+// CacheUsage is one server's share of the local sync cache
+type CacheUsage struct {
+	Server  string
+	Size    int64
+	Entries int
+	Pinned  int // Entries kept regardless of the limits
+}
+
 // CacheWizardKeyMap defines the key bindings
 type CacheWizardKeyMap struct {
 	Up      key.Binding
@@ -111,6 +119,10 @@ type CacheWizard struct {
 	width  int
 	height int
 
+	// Local sync cache usage per server, and its size limit (0 = none)
+	localUsage   []CacheUsage
+	localMaxSize int64
+
 	// Loading state
 	loading bool
 	spinner spinner.Model
@@ -176,6 +188,12 @@ func (w *CacheWizard) SetSize(width, height int) {
 	w.height = height
 }
 
+// SetLocalUsage sets the local sync cache usage shown below the options
+func (w *CacheWizard) SetLocalUsage(usage []CacheUsage, maxSize int64) {
+	w.localUsage = usage
+	w.localMaxSize = maxSize
+}
+
 // IsVisible returns whether the wizard is visible
 func (w *CacheWizard) IsVisible() bool {
 	return w.visible
@@ -377,6 +395,29 @@ func (w *CacheWizard) View() string {
 
 	b.WriteString("\n")
 
+	// Local sync cache
+	if len(w.localUsage) > 0 {
+		var total int64
+		for _, u := range w.localUsage {
+			total += u.Size
+		}
+		header := "Local sync cache: " + formatFileSize(total)
+		if w.localMaxSize > 0 {
+			header += " of " + formatFileSize(w.localMaxSize)
+		}
+		b.WriteString(styles.HelpTextStyle.Render(header))
+		b.WriteString("\n")
+		for _, u := range w.localUsage {
+			line := fmt.Sprintf("  %-18.18s %10s  %d cached", u.Server, formatFileSize(u.Size), u.Entries)
+			if u.Pinned > 0 {
+				line += fmt.Sprintf(", %d pinned", u.Pinned)
+			}
+			b.WriteString(styles.ItemStyle.Render(line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
 	// Help
 	if w.operation == CacheOperationTruncate {
 		b.WriteString(styles.ErrorStyle.Render("⚠ Truncate will delete all cached tiles!"))
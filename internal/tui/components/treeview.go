@@ -199,6 +199,9 @@ type TreeView struct {
 	keyMap     TreeViewKeyMap
 	connected  bool
 	serverName string
+	// offlineLabel describes a connection whose server is unreachable or
+	// has changes queued for it, or returns "" when neither
+	offlineLabel func(connectionID string) (label string, stale bool)
 }
 
 // NewTreeView creates a new tree view component
@@ -209,6 +212,12 @@ func NewTreeView() *TreeView {
 	}
 }
 
+// SetOfflineLabel sets how connections with an unreachable server or queued
+// changes are marked. Nodes of a stale connection are shown muted.
+func (tv *TreeView) SetOfflineLabel(fn func(connectionID string) (label string, stale bool)) {
+	tv.offlineLabel = fn
+}
+
 // SetRoot sets the root node and flattens the tree
 func (tv *TreeView) SetRoot(root *models.TreeNode) {
 	tv.root = root
@@ -681,7 +690,18 @@ func (tv *TreeView) renderNode(fn FlatNode, selected bool) string {
 		}
 	}
 
-	line := fmt.Sprintf("%s%s %s %s%s%s", indent.String(), indicator, icon, name, countBadge, enabledIndicator)
+	// Mark connections whose server is unreachable or has changes queued
+	var offlineBadge string
+	var stale bool
+	if tv.offlineLabel != nil && node.ConnectionID != "" {
+		var label string
+		label, stale = tv.offlineLabel(node.ConnectionID)
+		if label != "" && node.Type == models.NodeTypeConnection {
+			offlineBadge = styles.AccentStyle.Render(" [" + label + "]")
+		}
+	}
+
+	line := fmt.Sprintf("%s%s %s %s%s%s%s", indent.String(), indicator, icon, name, countBadge, enabledIndicator, offlineBadge)
 
 	// Apply style
 	var style lipgloss.Style
@@ -691,6 +711,8 @@ func (tv *TreeView) renderNode(fn FlatNode, selected bool) string {
 		style = styles.SelectedItemStyle
 	} else if node.HasError {
 		style = styles.ErrorStyle
+	} else if stale {
+		style = styles.SubtitleStyle
 	} else {
 		style = styles.ItemStyle
 	}
@@ -0,0 +1,331 @@
+package screens
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/tui/styles"
+)
+
+// AuditKeyMap defines the key bindings for the audit screen
+type AuditKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Filter key.Binding
+	Reload key.Binding
+	Export key.Binding
+	Escape key.Binding
+}
+
+// DefaultAuditKeyMap returns the default key bindings
+func DefaultAuditKeyMap() AuditKeyMap {
+	return AuditKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "filter"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reload"),
+		),
+		Export: key.NewBinding(
+			key.WithKeys("e"),
+			key.WithHelp("e", "export CSV"),
+		),
+		Escape: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// auditLimit is the most records the screen loads at once
+const auditLimit = 500
+
+// AuditScreen browses the audit log of changes
+type AuditScreen struct {
+	log           *audit.Log
+	keys          AuditKeyMap
+	width         int
+	height        int
+	records       []audit.Record
+	cursor        int
+	filterInput   textinput.Model
+	filtering     bool
+	statusMessage string
+}
+
+// NewAuditScreen creates a new audit screen reading the default log
+func NewAuditScreen() *AuditScreen {
+	ti := textinput.New()
+	ti.Placeholder = "user:alice action:delete conn:prod since:2026-01-31 layers/roads"
+	ti.CharLimit = 200
+
+	return &AuditScreen{
+		log:         audit.Default,
+		keys:        DefaultAuditKeyMap(),
+		filterInput: ti,
+	}
+}
+
+// Init loads the records
+func (s *AuditScreen) Init() tea.Cmd {
+	s.reload()
+	return nil
+}
+
+// IsEditingField reports whether the filter is being typed, so global keys
+// must not be handled
+func (s *AuditScreen) IsEditingField() bool {
+	return s.filtering
+}
+
+// parseAuditFilter turns "key:value" terms into a filter. Terms without a
+// known key match the resource path.
+func parseAuditFilter(text string) (audit.Filter, error) {
+	f := audit.Filter{Limit: auditLimit}
+	var resource []string
+	for _, term := range strings.Fields(text) {
+		k, v, ok := strings.Cut(term, ":")
+		if !ok {
+			resource = append(resource, term)
+			continue
+		}
+		switch k {
+		case "user":
+			f.User = v
+		case "via":
+			f.Via = v
+		case "service":
+			f.Service = v
+		case "action":
+			f.Action = v
+		case "conn", "connection":
+			f.Connection = v
+		case "since", "until":
+			t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+			if err != nil {
+				return f, fmt.Errorf("%s must be a date like 2026-01-31", k)
+			}
+			if k == "since" {
+				f.Since = t
+			} else {
+				f.Until = t.AddDate(0, 0, 1)
+			}
+		default:
+			resource = append(resource, term)
+		}
+	}
+	f.Resource = strings.Join(resource, " ")
+	return f, nil
+}
+
+// reload reads the records matching the current filter
+func (s *AuditScreen) reload() {
+	if s.log == nil {
+		s.statusMessage = "Audit log is unavailable"
+		return
+	}
+	filter, err := parseAuditFilter(s.filterInput.Value())
+	if err != nil {
+		s.statusMessage = err.Error()
+		return
+	}
+	records, err := s.log.Query(filter)
+	if err != nil {
+		s.statusMessage = err.Error()
+		return
+	}
+	s.records = records
+	if s.cursor >= len(records) {
+		s.cursor = max(len(records)-1, 0)
+	}
+	s.statusMessage = fmt.Sprintf("%d records", len(records))
+}
+
+// export writes the records shown to a CSV file in the working directory
+func (s *AuditScreen) export() {
+	name := fmt.Sprintf("cloudbench-audit-%s.csv", time.Now().Format("20060102-150405"))
+	f, err := os.Create(name)
+	if err != nil {
+		s.statusMessage = fmt.Sprintf("Export failed: %v", err)
+		return
+	}
+	defer f.Close()
+	if err := audit.WriteCSV(f, s.records); err != nil {
+		s.statusMessage = fmt.Sprintf("Export failed: %v", err)
+		return
+	}
+	s.statusMessage = fmt.Sprintf("Exported %d records to %s", len(s.records), name)
+}
+
+// Update handles messages
+func (s *AuditScreen) Update(msg tea.Msg) (*AuditScreen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+
+	case tea.KeyMsg:
+		if s.filtering {
+			switch msg.String() {
+			case "enter":
+				s.filtering = false
+				s.filterInput.Blur()
+				s.cursor = 0
+				s.reload()
+			case "esc":
+				s.filtering = false
+				s.filterInput.Blur()
+			default:
+				var cmd tea.Cmd
+				s.filterInput, cmd = s.filterInput.Update(msg)
+				return s, cmd
+			}
+			return s, nil
+		}
+
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case key.Matches(msg, s.keys.Down):
+			if s.cursor < len(s.records)-1 {
+				s.cursor++
+			}
+		case key.Matches(msg, s.keys.Filter):
+			s.filtering = true
+			return s, s.filterInput.Focus()
+		case key.Matches(msg, s.keys.Reload):
+			s.reload()
+		case key.Matches(msg, s.keys.Export):
+			s.export()
+		}
+	}
+	return s, nil
+}
+
+// View renders the audit screen
+func (s *AuditScreen) View() string {
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(styles.KartozaBlue).
+		MarginBottom(1)
+	header := headerStyle.Render("📜 Audit Log")
+
+	filterLabel := lipgloss.NewStyle().Foreground(styles.Muted).Render("Filter: ")
+	filterLine := filterLabel + s.filterInput.View()
+
+	// Rows, leaving room for the header, filter, details and help
+	rows := s.height - 16
+	if rows < 5 {
+		rows = 5
+	}
+	start := 0
+	if s.cursor >= rows {
+		start = s.cursor - rows + 1
+	}
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(
+		fmt.Sprintf("%-19s  %-14s  %-7s  %-9s  %-14s  %s", "Time", "User", "Action", "Service", "Connection", "Resource")))
+	for i := start; i < len(s.records) && i < start+rows; i++ {
+		r := s.records[i]
+		line := fmt.Sprintf("%-19s  %-14s  %-7s  %-9s  %-14s  %s",
+			r.Time.Local().Format("2006-01-02 15:04:05"),
+			truncate(r.User, 14),
+			r.Action,
+			r.Service,
+			truncate(r.Connection, 14),
+			r.Resource)
+		style := lipgloss.NewStyle()
+		if r.Error != "" {
+			style = style.Foreground(styles.Danger)
+		}
+		if i == s.cursor {
+			style = style.Background(styles.KartozaBlue).Foreground(styles.TextBright)
+		}
+		lines = append(lines, style.Render(truncate(line, max(s.width-4, 40))))
+	}
+	if len(s.records) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.Muted).Render("No records"))
+	}
+
+	listStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+
+	details := ""
+	if s.cursor < len(s.records) {
+		r := s.records[s.cursor]
+		labelStyle := lipgloss.NewStyle().Foreground(styles.Muted)
+		width := max(s.width-16, 40)
+		detail := []string{
+			labelStyle.Render("Via:    ") + r.Via,
+			labelStyle.Render("Before: ") + truncate(r.Before, width),
+			labelStyle.Render("After:  ") + truncate(r.After, width),
+		}
+		if r.Error != "" {
+			detail = append(detail, labelStyle.Render("Error:  ")+lipgloss.NewStyle().Foreground(styles.Danger).Render(r.Error))
+		}
+		details = strings.Join(detail, "\n")
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(styles.Muted).
+		MarginTop(1)
+	helpText := helpStyle.Render("↑/↓: select • f: filter • r: reload • e: export CSV • esc: back")
+	if s.filtering {
+		helpText = helpStyle.Render("enter: apply filter • esc: cancel")
+	}
+
+	status := ""
+	if s.statusMessage != "" {
+		status = lipgloss.NewStyle().Foreground(styles.KartozaOrange).Render(s.statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		filterLine,
+		listStyle.Render(strings.Join(lines, "\n")),
+		details,
+		status,
+		helpText,
+	)
+}
+
+// SetSize sets the screen size
+func (s *AuditScreen) SetSize(width, height int) {
+	s.width = width
+	s.height = height
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n <= 1 {
+		return string(runes[:n])
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -2,6 +2,8 @@ package screens
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -10,6 +12,8 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/google/uuid"
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/bundle"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 	"github.com/kartoza/kartoza-cloudbench/internal/tui/styles"
 )
@@ -23,6 +27,8 @@ type ConnectionsKeyMap struct {
 	Delete   key.Binding
 	Edit     key.Binding
 	Test     key.Binding
+	Export   key.Binding
+	Import   key.Binding
 	Escape   key.Binding
 	Tab      key.Binding
 	ShiftTab key.Binding
@@ -60,6 +66,14 @@ func DefaultConnectionsKeyMap() ConnectionsKeyMap {
 			key.WithKeys("t"),
 			key.WithHelp("t", "test"),
 		),
+		Export: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "export bundle"),
+		),
+		Import: key.NewBinding(
+			key.WithKeys("i"),
+			key.WithHelp("i", "import bundle"),
+		),
 		Escape: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back"),
@@ -86,6 +100,8 @@ const (
 	ModeList ConnectionsMode = iota
 	ModeAdd
 	ModeEdit
+	ModeExport
+	ModeImport
 )
 
 // ConnectionTestMsg is sent when a connection test completes
@@ -109,6 +125,11 @@ type ConnectionsScreen struct {
 	statusMsg    string
 	errorMsg     string
 	editingID    string
+
+	// Bundle export/import form
+	bundleInputs   []textinput.Model
+	bundleFocus    int
+	bundleConflict string
 }
 
 // Field indices
@@ -120,6 +141,16 @@ const (
 	fieldCount
 )
 
+// Bundle form field indices
+const (
+	bundleFieldPath = iota
+	bundleFieldPassphrase
+	bundleFieldCount
+)
+
+// bundleConflicts are the import conflict strategies, in toggle order
+var bundleConflicts = []string{bundle.ConflictSkip, bundle.ConflictReplace, bundle.ConflictDuplicate}
+
 // NewConnectionsScreen creates a new connections screen
 func NewConnectionsScreen(cfg *config.Config) *ConnectionsScreen {
 	cs := &ConnectionsScreen{
@@ -149,6 +180,17 @@ func NewConnectionsScreen(cfg *config.Config) *ConnectionsScreen {
 		cs.inputs[i] = t
 	}
 
+	cs.bundleInputs = make([]textinput.Model, bundleFieldCount)
+	for i := range cs.bundleInputs {
+		t := textinput.New()
+		t.CharLimit = 512
+		if i == bundleFieldPassphrase {
+			t.Placeholder = "empty: secrets are redacted"
+			t.EchoMode = textinput.EchoPassword
+		}
+		cs.bundleInputs[i] = t
+	}
+
 	return cs
 }
 
@@ -165,6 +207,9 @@ func (cs *ConnectionsScreen) SetSize(width, height int) {
 	for i := range cs.inputs {
 		cs.inputs[i].Width = inputWidth
 	}
+	for i := range cs.bundleInputs {
+		cs.bundleInputs[i].Width = inputWidth
+	}
 }
 
 // Update handles messages
@@ -177,6 +222,8 @@ func (cs *ConnectionsScreen) Update(msg tea.Msg) (*ConnectionsScreen, tea.Cmd) {
 			return cs.updateList(msg)
 		case ModeAdd, ModeEdit:
 			return cs.updateForm(msg)
+		case ModeExport, ModeImport:
+			return cs.updateBundleForm(msg)
 		}
 
 	case ConnectionTestMsg:
@@ -227,8 +274,7 @@ func (cs *ConnectionsScreen) updateList(msg tea.KeyMsg) (*ConnectionsScreen, tea
 	case key.Matches(msg, cs.keyMap.Delete):
 		if len(cs.config.Connections) > 0 {
 			conn := cs.config.Connections[cs.cursor]
-			cs.config.RemoveConnection(conn.ID)
-			if err := cs.config.Save(); err != nil {
+			if err := cs.config.Update(func(c *config.Config) { c.RemoveConnection(conn.ID) }); err != nil {
 				cs.errorMsg = fmt.Sprintf("Failed to save: %v", err)
 			} else {
 				cs.statusMsg = "Connection deleted"
@@ -241,8 +287,7 @@ func (cs *ConnectionsScreen) updateList(msg tea.KeyMsg) (*ConnectionsScreen, tea
 	case key.Matches(msg, cs.keyMap.Enter):
 		if len(cs.config.Connections) > 0 {
 			conn := cs.config.Connections[cs.cursor]
-			cs.config.SetActiveConnection(conn.ID)
-			if err := cs.config.Save(); err != nil {
+			if err := cs.config.Update(func(c *config.Config) { c.SetActiveConnection(conn.ID) }); err != nil {
 				cs.errorMsg = fmt.Sprintf("Failed to save: %v", err)
 			} else {
 				cs.statusMsg = fmt.Sprintf("Connected to %s", conn.Name)
@@ -254,11 +299,145 @@ func (cs *ConnectionsScreen) updateList(msg tea.KeyMsg) (*ConnectionsScreen, tea
 			conn := cs.config.Connections[cs.cursor]
 			return cs, cs.testConnection(&conn)
 		}
+
+	case key.Matches(msg, cs.keyMap.Export):
+		cs.openBundleForm(ModeExport)
+
+	case key.Matches(msg, cs.keyMap.Import):
+		cs.openBundleForm(ModeImport)
 	}
 
 	return cs, nil
 }
 
+// openBundleForm shows the bundle export or import form
+func (cs *ConnectionsScreen) openBundleForm(mode ConnectionsMode) {
+	dir := cs.config.LastLocalPath
+	if dir == "" {
+		dir, _ = os.UserHomeDir()
+	}
+
+	cs.mode = mode
+	cs.statusMsg = ""
+	cs.errorMsg = ""
+	cs.bundleConflict = bundle.ConflictSkip
+	cs.bundleInputs[bundleFieldPath].SetValue(filepath.Join(dir, "cloudbench-bundle.json"))
+	cs.bundleInputs[bundleFieldPassphrase].SetValue("")
+	cs.bundleFocus = bundleFieldPath
+	cs.bundleInputs[bundleFieldPath].Focus()
+	cs.bundleInputs[bundleFieldPassphrase].Blur()
+}
+
+// updateBundleForm handles key presses in the bundle form. Both fields are
+// always editable, so only control keys are interpreted here.
+func (cs *ConnectionsScreen) updateBundleForm(msg tea.KeyMsg) (*ConnectionsScreen, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		cs.mode = ModeList
+		return cs, nil
+
+	case "tab", "shift+tab", "up", "down":
+		cs.bundleInputs[cs.bundleFocus].Blur()
+		cs.bundleFocus = (cs.bundleFocus + 1) % bundleFieldCount
+		cs.bundleInputs[cs.bundleFocus].Focus()
+		return cs, nil
+
+	case "ctrl+t":
+		if cs.mode == ModeImport {
+			for i, c := range bundleConflicts {
+				if c == cs.bundleConflict {
+					cs.bundleConflict = bundleConflicts[(i+1)%len(bundleConflicts)]
+					break
+				}
+			}
+		}
+		return cs, nil
+
+	case "enter":
+		path := strings.TrimSpace(cs.bundleInputs[bundleFieldPath].Value())
+		passphrase := cs.bundleInputs[bundleFieldPassphrase].Value()
+		if path == "" {
+			cs.errorMsg = "A file path is required"
+			return cs, nil
+		}
+
+		var err error
+		if cs.mode == ModeExport {
+			err = cs.exportBundle(path, passphrase)
+		} else {
+			err = cs.importBundle(path, passphrase)
+		}
+		if err != nil {
+			cs.errorMsg = err.Error()
+			cs.statusMsg = ""
+			return cs, nil
+		}
+		cs.errorMsg = ""
+		cs.mode = ModeList
+		return cs, nil
+	}
+
+	var cmd tea.Cmd
+	cs.bundleInputs[cs.bundleFocus], cmd = cs.bundleInputs[cs.bundleFocus].Update(msg)
+	return cs, cmd
+}
+
+// exportBundle writes all connections and settings to a bundle file
+func (cs *ConnectionsScreen) exportBundle(path, passphrase string) error {
+	b, err := bundle.Export(cs.config, bundle.ExportOptions{Passphrase: passphrase})
+	if err != nil {
+		return fmt.Errorf("Export failed: %v", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("Export failed: %v", err)
+	}
+	defer f.Close()
+	if err := b.Write(f, bundle.FormatOf(path)); err != nil {
+		return fmt.Errorf("Export failed: %v", err)
+	}
+
+	cs.statusMsg = fmt.Sprintf("Exported %d objects to %s (secrets %s)", b.Count(), path, b.SecretsMode)
+	return nil
+}
+
+// importBundle merges a bundle file into the configuration
+func (cs *ConnectionsScreen) importBundle(path, passphrase string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Import failed: %v", err)
+	}
+	defer f.Close()
+
+	b, err := bundle.Read(f, bundle.FormatOf(path))
+	if err != nil {
+		return fmt.Errorf("Import failed: %v", err)
+	}
+	var result *bundle.ImportResult
+	var importErr error
+	err = cs.config.Update(func(c *config.Config) {
+		result, importErr = bundle.Import(c, b, bundle.ImportOptions{
+			Passphrase: passphrase,
+			OnConflict: cs.bundleConflict,
+			Actor:      audit.Local(""),
+		})
+	})
+	if importErr != nil {
+		return fmt.Errorf("Import failed: %v", importErr)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to save: %v", err)
+	}
+
+	cs.statusMsg = fmt.Sprintf("Imported bundle: %d added, %d replaced, %d skipped",
+		len(result.Added), len(result.Replaced), len(result.Skipped))
+	if len(result.Warnings) > 0 {
+		cs.statusMsg += " - " + result.Warnings[0]
+	}
+	return nil
+}
+
 // updateForm handles key presses in form mode
 func (cs *ConnectionsScreen) updateForm(msg tea.KeyMsg) (*ConnectionsScreen, tea.Cmd) {
 	// If we're actively editing a field, forward most keys to the input
@@ -337,30 +516,32 @@ func (cs *ConnectionsScreen) saveConnection() tea.Cmd {
 		return nil
 	}
 
-	if cs.mode == ModeEdit {
-		// Update existing connection
-		for i := range cs.config.Connections {
-			if cs.config.Connections[i].ID == cs.editingID {
-				cs.config.Connections[i].Name = name
-				cs.config.Connections[i].URL = url
-				cs.config.Connections[i].Username = username
-				cs.config.Connections[i].Password = password
-				break
+	editing, editingID := cs.mode == ModeEdit, cs.editingID
+	newID := uuid.New().String()
+	err := cs.config.Update(func(c *config.Config) {
+		if editing {
+			// Update existing connection
+			for i := range c.Connections {
+				if c.Connections[i].ID == editingID {
+					c.Connections[i].Name = name
+					c.Connections[i].URL = url
+					c.Connections[i].Username = username
+					c.Connections[i].Password = password
+					break
+				}
 			}
+		} else {
+			// Add new connection
+			c.AddConnection(config.Connection{
+				ID:       newID,
+				Name:     name,
+				URL:      url,
+				Username: username,
+				Password: password,
+			})
 		}
-	} else {
-		// Add new connection
-		conn := config.Connection{
-			ID:       uuid.New().String(),
-			Name:     name,
-			URL:      url,
-			Username: username,
-			Password: password,
-		}
-		cs.config.AddConnection(conn)
-	}
-
-	if err := cs.config.Save(); err != nil {
+	})
+	if err != nil {
 		cs.errorMsg = fmt.Sprintf("Failed to save: %v", err)
 		return nil
 	}
@@ -401,6 +582,8 @@ func (cs *ConnectionsScreen) View() string {
 		b.WriteString(cs.renderForm("Add Connection"))
 	case ModeEdit:
 		b.WriteString(cs.renderForm("Edit Connection"))
+	case ModeExport, ModeImport:
+		b.WriteString(cs.renderBundleForm())
 	}
 
 	// Status messages
@@ -416,7 +599,11 @@ func (cs *ConnectionsScreen) View() string {
 	// Help
 	b.WriteString("\n\n")
 	if cs.mode == ModeList {
-		b.WriteString(styles.HelpTextStyle.Render("a:add  e:edit  d:delete  t:test  Enter:connect  Esc:back"))
+		b.WriteString(styles.HelpTextStyle.Render("a:add  e:edit  d:delete  t:test  x:export  i:import  Enter:connect  Esc:back"))
+	} else if cs.mode == ModeImport {
+		b.WriteString(styles.HelpTextStyle.Render("Tab:next field  Ctrl+T:conflict strategy  Enter:import  Esc:cancel"))
+	} else if cs.mode == ModeExport {
+		b.WriteString(styles.HelpTextStyle.Render("Tab:next field  Enter:export  Esc:cancel"))
 	} else if cs.editingField {
 		b.WriteString(styles.HelpTextStyle.Render("Enter:accept  Esc:cancel edit"))
 	} else {
@@ -507,13 +694,53 @@ func (cs *ConnectionsScreen) renderForm(title string) string {
 	return b.String()
 }
 
+// renderBundleForm renders the bundle export/import form
+func (cs *ConnectionsScreen) renderBundleForm() string {
+	var b strings.Builder
+
+	if cs.mode == ModeExport {
+		b.WriteString(styles.MutedStyle.Render("Export all connections, pg services, sync configurations and saved queries."))
+	} else {
+		b.WriteString(styles.MutedStyle.Render("Import connections and settings from a bundle file."))
+	}
+	b.WriteString("\n\n")
+
+	labels := []string{"File:", "Passphrase:"}
+	for i, input := range cs.bundleInputs {
+		indicator := "  "
+		inputStyle := styles.InputStyle
+		if i == cs.bundleFocus {
+			indicator = styles.ConnectedStyle.Render("\uf0da ") // fa-caret-right
+			inputStyle = styles.FocusedInputStyle
+		}
+
+		b.WriteString(indicator)
+		b.WriteString(styles.ItemStyle.Width(12).Render(labels[i]))
+		b.WriteString(inputStyle.Render(input.View()))
+		b.WriteString("\n\n")
+	}
+
+	if cs.mode == ModeImport {
+		b.WriteString("  ")
+		b.WriteString(styles.ItemStyle.Width(12).Render("Existing:"))
+		b.WriteString(cs.bundleConflict)
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
 // Mode returns the current mode
 func (cs *ConnectionsScreen) Mode() ConnectionsMode {
 	return cs.mode
 }
 
-// IsEditingField returns true if currently editing a text field
+// IsEditingField returns true if currently editing a text field. The bundle
+// form fields are always being edited.
 func (cs *ConnectionsScreen) IsEditingField() bool {
+	if cs.mode == ModeExport || cs.mode == ModeImport {
+		return true
+	}
 	return (cs.mode == ModeAdd || cs.mode == ModeEdit) && cs.editingField
 }
 
@@ -260,6 +260,7 @@ func (d *DashboardScreen) refreshStatusStaggered() tea.Cmd {
 
 func (d *DashboardScreen) fetchServerStatus(conn *config.Connection) ServerStatus {
 	client := api.NewClient(conn)
+	client.SetResponseCache(api.SharedCache)
 	status := ServerStatus{
 		ConnectionID:   conn.ID,
 		ConnectionName: conn.Name,
@@ -360,6 +361,9 @@ func (d *DashboardScreen) View() string {
 			onlineCount, offlineCount, totalLayers, totalStores, d.lastRefresh.Format("15:04:05"),
 		)
 	}
+	if api.SharedCache.Enabled() {
+		summaryText += fmt.Sprintf("  \uf0e7 %.0f%% Cache hits", 100*api.SharedCache.Stats().HitRate)
+	}
 	summary := summaryStyle.Render(summaryText)
 
 	if len(d.statuses) == 0 {
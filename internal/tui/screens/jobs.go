@@ -0,0 +1,266 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+	"github.com/kartoza/kartoza-cloudbench/internal/tui/styles"
+)
+
+// JobsKeyMap defines the key bindings for the jobs screen
+type JobsKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Cancel key.Binding
+	Remove key.Binding
+	Clear  key.Binding
+	Escape key.Binding
+}
+
+// DefaultJobsKeyMap returns the default key bindings
+func DefaultJobsKeyMap() JobsKeyMap {
+	return JobsKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "cancel job"),
+		),
+		Remove: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "remove finished job"),
+		),
+		Clear: key.NewBinding(
+			key.WithKeys("D"),
+			key.WithHelp("D", "clear finished jobs"),
+		),
+		Escape: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// JobsRefreshMsg is sent periodically while the jobs screen is shown
+type JobsRefreshMsg struct{}
+
+// jobsRefreshInterval is how often the jobs screen reloads the job list
+const jobsRefreshInterval = time.Second
+
+// JobsScreen lists background jobs of every kind
+type JobsScreen struct {
+	manager       *jobs.Manager
+	keys          JobsKeyMap
+	width         int
+	height        int
+	jobs          []jobs.Info
+	cursor        int
+	active        bool
+	statusMessage string
+}
+
+// NewJobsScreen creates a new jobs screen showing the default job manager
+func NewJobsScreen() *JobsScreen {
+	return &JobsScreen{
+		manager: jobs.Default,
+		keys:    DefaultJobsKeyMap(),
+	}
+}
+
+// Init loads the jobs and starts refreshing them
+func (s *JobsScreen) Init() tea.Cmd {
+	s.reload()
+	if s.active {
+		return nil // Already refreshing
+	}
+	s.active = true
+	return s.tick()
+}
+
+// Leave stops refreshing once the screen is no longer shown
+func (s *JobsScreen) Leave() {
+	s.active = false
+}
+
+func (s *JobsScreen) tick() tea.Cmd {
+	return tea.Tick(jobsRefreshInterval, func(time.Time) tea.Msg {
+		return JobsRefreshMsg{}
+	})
+}
+
+// reload reads the current jobs
+func (s *JobsScreen) reload() {
+	s.jobs = s.manager.List("")
+	if s.cursor >= len(s.jobs) {
+		s.cursor = max(len(s.jobs)-1, 0)
+	}
+}
+
+// selected returns the job under the cursor
+func (s *JobsScreen) selected() *jobs.Info {
+	if s.cursor < len(s.jobs) {
+		return &s.jobs[s.cursor]
+	}
+	return nil
+}
+
+// Update handles messages
+func (s *JobsScreen) Update(msg tea.Msg) (*JobsScreen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+
+	case JobsRefreshMsg:
+		if !s.active {
+			return s, nil
+		}
+		s.reload()
+		return s, s.tick()
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case key.Matches(msg, s.keys.Down):
+			if s.cursor < len(s.jobs)-1 {
+				s.cursor++
+			}
+		case key.Matches(msg, s.keys.Cancel):
+			if job := s.selected(); job != nil {
+				if s.manager.Cancel(job.ID) {
+					s.statusMessage = fmt.Sprintf("Cancelling %s", job.Title)
+				} else {
+					s.statusMessage = "Job has already finished"
+				}
+				s.reload()
+			}
+		case key.Matches(msg, s.keys.Remove):
+			if job := s.selected(); job != nil {
+				if s.manager.Remove(job.ID) {
+					s.statusMessage = fmt.Sprintf("Removed %s", job.Title)
+				} else {
+					s.statusMessage = "Job is still pending or running"
+				}
+				s.reload()
+			}
+		case key.Matches(msg, s.keys.Clear):
+			s.statusMessage = fmt.Sprintf("Cleared %d finished jobs", s.manager.ClearFinished(""))
+			s.reload()
+		}
+	}
+	return s, nil
+}
+
+// jobStatusStyle colours a job status
+func jobStatusStyle(status jobs.Status) lipgloss.Style {
+	switch status {
+	case jobs.StatusCompleted:
+		return lipgloss.NewStyle().Foreground(styles.Success)
+	case jobs.StatusFailed:
+		return lipgloss.NewStyle().Foreground(styles.Danger)
+	case jobs.StatusRunning:
+		return lipgloss.NewStyle().Foreground(styles.KartozaOrange)
+	default:
+		return lipgloss.NewStyle().Foreground(styles.Muted)
+	}
+}
+
+// View renders the jobs screen
+func (s *JobsScreen) View() string {
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(styles.KartozaBlue).
+		MarginBottom(1)
+	header := headerStyle.Render("⚙ Background Jobs")
+
+	// Rows, leaving room for the header, details and help
+	rows := s.height - 18
+	if rows < 5 {
+		rows = 5
+	}
+	start := 0
+	if s.cursor >= rows {
+		start = s.cursor - rows + 1
+	}
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(
+		fmt.Sprintf("%-19s  %-10s  %-9s  %5s  %s", "Created", "Kind", "Status", "%", "Job")))
+	for i := start; i < len(s.jobs) && i < start+rows; i++ {
+		job := s.jobs[i]
+		line := fmt.Sprintf("%-19s  %-10s  %-9s  %5.1f  %s",
+			job.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			job.Kind,
+			job.Status,
+			job.Progress,
+			job.Title)
+		style := jobStatusStyle(job.Status)
+		if i == s.cursor {
+			style = lipgloss.NewStyle().Background(styles.KartozaBlue).Foreground(styles.TextBright)
+		}
+		lines = append(lines, style.Render(truncate(line, max(s.width-4, 40))))
+	}
+	if len(s.jobs) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.Muted).Render("No jobs"))
+	}
+
+	listStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+
+	details := ""
+	if job := s.selected(); job != nil {
+		labelStyle := lipgloss.NewStyle().Foreground(styles.Muted)
+		width := max(s.width-16, 40)
+		detail := []string{labelStyle.Render("Message: ") + truncate(job.Message, width)}
+		if job.Error != "" {
+			detail = append(detail, labelStyle.Render("Error:   ")+lipgloss.NewStyle().Foreground(styles.Danger).Render(truncate(job.Error, width)))
+		}
+		// The last few log lines
+		logStart := max(len(job.Log)-4, 0)
+		for _, line := range job.Log[logStart:] {
+			detail = append(detail, labelStyle.Render(truncate(line, width+9)))
+		}
+		details = strings.Join(detail, "\n")
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(styles.Muted).
+		MarginTop(1)
+	helpText := helpStyle.Render("↑/↓: select • x: cancel • d: remove finished • D: clear finished • esc: back")
+
+	status := ""
+	if s.statusMessage != "" {
+		status = lipgloss.NewStyle().Foreground(styles.KartozaOrange).Render(s.statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		listStyle.Render(strings.Join(lines, "\n")),
+		details,
+		status,
+		helpText,
+	)
+}
+
+// SetSize sets the screen size
+func (s *JobsScreen) SetSize(width, height int) {
+	s.width = width
+	s.height = height
+}
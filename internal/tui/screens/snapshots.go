@@ -0,0 +1,428 @@
+package screens
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+	"github.com/kartoza/kartoza-cloudbench/internal/snapshot"
+	"github.com/kartoza/kartoza-cloudbench/internal/tui/styles"
+)
+
+// SnapshotsKeyMap defines the key bindings for the snapshots screen
+type SnapshotsKeyMap struct {
+	Up      key.Binding
+	Down    key.Binding
+	Switch  key.Binding
+	Mark    key.Binding
+	Take    key.Binding
+	Restore key.Binding
+	Delete  key.Binding
+	Reload  key.Binding
+	Escape  key.Binding
+}
+
+// DefaultSnapshotsKeyMap returns the default key bindings
+func DefaultSnapshotsKeyMap() SnapshotsKeyMap {
+	return SnapshotsKeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "down"),
+		),
+		Switch: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "snapshots/changes"),
+		),
+		Mark: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "compare from"),
+		),
+		Take: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "take snapshot"),
+		),
+		Restore: key.NewBinding(
+			key.WithKeys("R"),
+			key.WithHelp("R", "restore object"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete snapshot"),
+		),
+		Reload: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "reload"),
+		),
+		Escape: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "back"),
+		),
+	}
+}
+
+// SnapshotsMsg reports the end of a snapshot or restore run in the
+// background
+type SnapshotsMsg struct {
+	Status string
+	Err    error
+}
+
+// SnapshotsScreen is the change timeline of a connection: its catalog
+// snapshots, and the objects that changed between two of them
+type SnapshotsScreen struct {
+	store         *snapshot.Store
+	keys          SnapshotsKeyMap
+	width         int
+	height        int
+	connID        string
+	connName      string
+	catalog       manifest.Catalog
+	secret        func(name string) (string, error)
+	snapshots     []snapshot.Snapshot
+	cursor        int
+	base          string // ID of the snapshot marked to compare from; empty compares with the previous one
+	changes       []snapshot.Change
+	changeCursor  int
+	onChanges     bool // Whether the change list has the focus
+	busy          bool
+	statusMessage string
+}
+
+// NewSnapshotsScreen creates a new snapshots screen using the default store
+func NewSnapshotsScreen() *SnapshotsScreen {
+	return &SnapshotsScreen{
+		store: snapshot.Default,
+		keys:  DefaultSnapshotsKeyMap(),
+	}
+}
+
+// Open shows the timeline of a connection. secret resolves the secrets of
+// restored data stores.
+func (s *SnapshotsScreen) Open(connID, connName string, catalog manifest.Catalog, secret func(string) (string, error)) tea.Cmd {
+	if connID != s.connID {
+		s.cursor, s.base = 0, ""
+	}
+	s.connID, s.connName, s.catalog, s.secret = connID, connName, catalog, secret
+	s.onChanges = false
+	s.reload()
+	return nil
+}
+
+// reload reads the connection's snapshots and the changes of the selected
+// one
+func (s *SnapshotsScreen) reload() {
+	list, err := s.store.List(s.connID)
+	if err != nil {
+		s.statusMessage = err.Error()
+		return
+	}
+	s.snapshots = list
+	if s.cursor >= len(list) {
+		s.cursor = max(len(list)-1, 0)
+	}
+	s.diff()
+}
+
+// compared returns the snapshots the change list compares
+func (s *SnapshotsScreen) compared() (from, to *snapshot.Snapshot) {
+	if s.cursor >= len(s.snapshots) {
+		return nil, nil
+	}
+	to = &s.snapshots[s.cursor]
+	for i := range s.snapshots {
+		if s.snapshots[i].ID == s.base && s.base != to.ID {
+			return &s.snapshots[i], to
+		}
+	}
+	if s.cursor+1 < len(s.snapshots) {
+		return &s.snapshots[s.cursor+1], to
+	}
+	return nil, to
+}
+
+// diff lists the changes between the compared snapshots
+func (s *SnapshotsScreen) diff() {
+	s.changes, s.changeCursor = nil, 0
+	from, to := s.compared()
+	if from == nil {
+		return
+	}
+	fromSnap, err := s.store.Get(s.connID, from.ID)
+	if err != nil {
+		s.statusMessage = err.Error()
+		return
+	}
+	toSnap, err := s.store.Get(s.connID, to.ID)
+	if err != nil {
+		s.statusMessage = err.Error()
+		return
+	}
+	if s.changes, err = s.store.Diff(fromSnap, toSnap); err != nil {
+		s.statusMessage = err.Error()
+	}
+}
+
+// take snapshots the catalog in the background
+func (s *SnapshotsScreen) take() tea.Cmd {
+	store, connID, catalog := s.store, s.connID, s.catalog
+	return func() tea.Msg {
+		snap, created, err := snapshot.Take(store, connID, catalog, snapshot.TriggerManual)
+		if err != nil {
+			return SnapshotsMsg{Err: err}
+		}
+		if !created {
+			return SnapshotsMsg{Status: fmt.Sprintf("No changes since snapshot %s", snap.ID)}
+		}
+		return SnapshotsMsg{Status: fmt.Sprintf("Took snapshot %s of %d objects", snap.ID, snap.Objects)}
+	}
+}
+
+// restore makes the selected object match the snapshot compared from, in
+// the background
+func (s *SnapshotsScreen) restore() tea.Cmd {
+	from, _ := s.compared()
+	if from == nil || s.changeCursor >= len(s.changes) {
+		return nil
+	}
+	c := s.changes[s.changeCursor]
+	if c.Action == snapshot.Added {
+		s.statusMessage = fmt.Sprintf("%s %s is not in snapshot %s; delete it instead", c.Kind, c.Name, from.ID)
+		return nil
+	}
+	store, connID, catalog, secret, id := s.store, s.connID, s.catalog, s.secret, from.ID
+	return func() tea.Msg {
+		snap, err := store.Get(connID, id)
+		if err != nil {
+			return SnapshotsMsg{Err: err}
+		}
+		m, err := store.Restore(snap, c.Path)
+		if err != nil {
+			return SnapshotsMsg{Err: err}
+		}
+		plan, err := m.Plan(catalog, manifest.Options{Secret: secret})
+		if err != nil {
+			return SnapshotsMsg{Err: err}
+		}
+		if err := plan.Apply(catalog, nil); err != nil {
+			return SnapshotsMsg{Err: err}
+		}
+		if len(plan.Changes) == 0 {
+			return SnapshotsMsg{Status: fmt.Sprintf("%s %s already matches snapshot %s", c.Kind, c.Name, id)}
+		}
+		return SnapshotsMsg{Status: fmt.Sprintf("Restored %s %s from snapshot %s (%d changes)", c.Kind, c.Name, id, len(plan.Changes))}
+	}
+}
+
+// Update handles messages
+func (s *SnapshotsScreen) Update(msg tea.Msg) (*SnapshotsScreen, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		s.width = msg.Width
+		s.height = msg.Height
+
+	case SnapshotsMsg:
+		s.busy = false
+		if msg.Err != nil {
+			s.statusMessage = msg.Err.Error()
+		} else {
+			s.statusMessage = msg.Status
+		}
+		s.reload()
+
+	case tea.KeyMsg:
+		if s.busy {
+			return s, nil
+		}
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			if s.onChanges {
+				if s.changeCursor > 0 {
+					s.changeCursor--
+				}
+			} else if s.cursor > 0 {
+				s.cursor--
+				s.diff()
+			}
+		case key.Matches(msg, s.keys.Down):
+			if s.onChanges {
+				if s.changeCursor < len(s.changes)-1 {
+					s.changeCursor++
+				}
+			} else if s.cursor < len(s.snapshots)-1 {
+				s.cursor++
+				s.diff()
+			}
+		case key.Matches(msg, s.keys.Switch):
+			s.onChanges = !s.onChanges && len(s.changes) > 0
+		case key.Matches(msg, s.keys.Mark):
+			if s.cursor < len(s.snapshots) {
+				if id := s.snapshots[s.cursor].ID; s.base == id {
+					s.base = ""
+				} else {
+					s.base = id
+				}
+				s.diff()
+			}
+		case key.Matches(msg, s.keys.Take):
+			s.busy = true
+			s.statusMessage = "Taking snapshot..."
+			return s, s.take()
+		case key.Matches(msg, s.keys.Restore):
+			if cmd := s.restore(); cmd != nil {
+				s.busy = true
+				s.statusMessage = "Restoring..."
+				return s, cmd
+			}
+		case key.Matches(msg, s.keys.Delete):
+			if !s.onChanges && s.cursor < len(s.snapshots) {
+				id := s.snapshots[s.cursor].ID
+				if err := s.store.Delete(s.connID, id); err != nil {
+					s.statusMessage = err.Error()
+				} else {
+					s.statusMessage = fmt.Sprintf("Deleted snapshot %s", id)
+				}
+				s.reload()
+			}
+		case key.Matches(msg, s.keys.Reload):
+			s.reload()
+		}
+	}
+	return s, nil
+}
+
+// View renders the snapshots screen
+func (s *SnapshotsScreen) View() string {
+	headerStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(styles.KartozaBlue).
+		MarginBottom(1)
+	header := headerStyle.Render("🕘 Catalog Timeline: " + s.connName)
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Border).
+		Padding(0, 1)
+	focusedBox := boxStyle.BorderForeground(styles.KartozaBlue)
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	selected := lipgloss.NewStyle().Background(styles.KartozaBlue).Foreground(styles.TextBright)
+
+	rows := max((s.height-16)/2, 5)
+	width := max(s.width-4, 40)
+
+	// Snapshots, newest first
+	var list []string
+	list = append(list, lipgloss.NewStyle().Bold(true).Render(
+		fmt.Sprintf("  %-19s  %-9s  %s", "Time", "Trigger", "Objects")))
+	start := max(s.cursor-rows+1, 0)
+	for i := start; i < len(s.snapshots) && i < start+rows; i++ {
+		snap := s.snapshots[i]
+		mark := "  "
+		if snap.ID == s.base {
+			mark = "◆ "
+		}
+		line := fmt.Sprintf("%s%-19s  %-9s  %d", mark, snap.Time.Local().Format("2006-01-02 15:04:05"), snap.Trigger, snap.Objects)
+		if i == s.cursor {
+			line = selected.Render(line)
+		}
+		list = append(list, line)
+	}
+	if len(s.snapshots) == 0 {
+		list = append(list, muted.Render("No snapshots yet: press t to take one"))
+	}
+
+	// Changes between the compared snapshots
+	var changes []string
+	from, to := s.compared()
+	switch {
+	case to == nil:
+	case from == nil:
+		changes = append(changes, muted.Render("The oldest snapshot has nothing to compare with"))
+	default:
+		changes = append(changes, lipgloss.NewStyle().Bold(true).Render(
+			fmt.Sprintf("Changes from %s to %s", from.Time.Local().Format("2006-01-02 15:04"), to.Time.Local().Format("2006-01-02 15:04"))))
+		if len(s.changes) == 0 {
+			changes = append(changes, muted.Render("No changes"))
+		}
+	}
+	start = max(s.changeCursor-rows+1, 0)
+	for i := start; i < len(s.changes) && i < start+rows; i++ {
+		c := s.changes[i]
+		symbol, color := "~", styles.KartozaOrange
+		switch c.Action {
+		case snapshot.Added:
+			symbol, color = "+", styles.Success
+		case snapshot.Removed:
+			symbol, color = "-", styles.Danger
+		}
+		ref := c.Name
+		if c.Workspace != "" && c.Kind != manifest.KindWorkspace {
+			ref = c.Workspace + ":" + c.Name
+		}
+		line := fmt.Sprintf("%s %-14s %s", symbol, c.Kind, ref)
+		if i == s.changeCursor && s.onChanges {
+			line = selected.Render(line)
+		} else {
+			line = lipgloss.NewStyle().Foreground(color).Render(line)
+		}
+		changes = append(changes, truncate(line, width))
+	}
+
+	// Field diffs of the selected change
+	var fields []string
+	if s.onChanges && s.changeCursor < len(s.changes) {
+		for _, f := range s.changes[s.changeCursor].Fields {
+			fields = append(fields, muted.Render(f.Field+": ")+
+				truncate(oneLine(f.From), width/3)+" → "+truncate(oneLine(f.To), width/3))
+			if len(fields) >= rows {
+				fields = append(fields, muted.Render("…"))
+				break
+			}
+		}
+	}
+
+	listBox, changesBox := focusedBox, boxStyle
+	if s.onChanges {
+		listBox, changesBox = boxStyle, focusedBox
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(styles.Muted).
+		MarginTop(1)
+	helpText := helpStyle.Render("↑/↓: select • space: compare from • tab: changes • t: take snapshot • R: restore object • d: delete • esc: back")
+
+	status := ""
+	if s.statusMessage != "" {
+		status = lipgloss.NewStyle().Foreground(styles.KartozaOrange).Render(s.statusMessage)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		listBox.Render(strings.Join(list, "\n")),
+		changesBox.Render(strings.Join(changes, "\n")),
+		strings.Join(fields, "\n"),
+		status,
+		helpText,
+	)
+}
+
+// SetSize sets the screen size
+func (s *SnapshotsScreen) SetSize(width, height int) {
+	s.width = width
+	s.height = height
+}
+
+// oneLine joins the lines of a multi-line value, e.g. SLD lines
+func oneLine(s string) string {
+	if s == "" {
+		return "∅"
+	}
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 	"github.com/kartoza/kartoza-cloudbench/internal/sync"
 	"github.com/kartoza/kartoza-cloudbench/internal/tui/styles"
@@ -24,6 +25,8 @@ type SyncKeyMap struct {
 	Space    key.Binding
 	Start    key.Binding
 	Stop     key.Binding
+	Resume   key.Binding
+	Retry    key.Binding
 	Escape   key.Binding
 	Tab      key.Binding
 }
@@ -63,6 +66,14 @@ func DefaultSyncKeyMap() SyncKeyMap {
 			key.WithKeys("x"),
 			key.WithHelp("x", "stop"),
 		),
+		Resume: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "resume"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "retry failed"),
+		),
 		Escape: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "back"),
@@ -178,6 +189,16 @@ func (s *SyncScreen) Update(msg tea.Msg) (*SyncScreen, tea.Cmd) {
 			if s.isRunning {
 				s.stopSync()
 			}
+
+		case key.Matches(msg, s.keys.Resume):
+			if !s.isRunning {
+				cmds = append(cmds, s.restartTasks(false))
+			}
+
+		case key.Matches(msg, s.keys.Retry):
+			if !s.isRunning {
+				cmds = append(cmds, s.restartTasks(true))
+			}
 		}
 	}
 
@@ -212,7 +233,7 @@ func (s *SyncScreen) navigateDown() {
 			s.destIdx++
 		}
 	case PanelOptions:
-		if s.optionIdx < 5 {
+		if s.optionIdx < 7 {
 			s.optionIdx++
 		}
 	}
@@ -244,6 +265,10 @@ func (s *SyncScreen) toggleSelection() {
 			s.syncOptions.Styles = !s.syncOptions.Styles
 		case 5:
 			s.syncOptions.LayerGroups = !s.syncOptions.LayerGroups
+		case 6:
+			s.syncOptions.GWC = !s.syncOptions.GWC
+		case 7:
+			s.syncOptions.GWCSeed = !s.syncOptions.GWCSeed
 		}
 	}
 }
@@ -258,7 +283,7 @@ func (s *SyncScreen) updateProgress() {
 	tasks := sync.DefaultManager.GetAllTasks()
 	running := false
 	for _, task := range tasks {
-		if task.GetStatus() == "running" {
+		if status := task.GetStatus(); status == "running" || status == "pending" {
 			running = true
 			break
 		}
@@ -293,7 +318,7 @@ func (s *SyncScreen) startSync() tea.Cmd {
 	for _, destID := range destIDs {
 		destConn := s.config.GetConnection(destID)
 		if destConn != nil {
-			sync.DefaultManager.StartSync(sourceConn, destConn, s.syncOptions, "")
+			sync.DefaultManager.StartSync(sourceConn, destConn, s.syncOptions, nil, "", audit.Local(""))
 		}
 	}
 
@@ -306,6 +331,45 @@ func (s *SyncScreen) stopSync() {
 	s.statusMessage = "Sync stopped"
 }
 
+// restartTasks resumes every resumable task, or re-runs only their failed items
+func (s *SyncScreen) restartTasks(retryOnly bool) tea.Cmd {
+	restarted := 0
+	var lastErr error
+	for _, task := range sync.DefaultManager.GetAllTasks() {
+		if !task.IsResumable() {
+			continue
+		}
+		var err error
+		if retryOnly {
+			_, err = sync.DefaultManager.RetryFailedItems(s.config, task.ID, audit.Local(""))
+		} else {
+			_, err = sync.DefaultManager.ResumeTask(s.config, task.ID, audit.Local(""))
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		restarted++
+	}
+
+	if restarted == 0 {
+		if lastErr != nil {
+			s.statusMessage = fmt.Sprintf("Nothing to restart: %v", lastErr)
+		} else {
+			s.statusMessage = "No stopped or failed tasks to resume"
+		}
+		return nil
+	}
+
+	s.isRunning = true
+	if retryOnly {
+		s.statusMessage = fmt.Sprintf("Retrying failed items of %d task(s)...", restarted)
+	} else {
+		s.statusMessage = fmt.Sprintf("Resuming %d task(s)...", restarted)
+	}
+	return s.tickProgress()
+}
+
 // View renders the sync screen
 func (s *SyncScreen) View() string {
 	// Header
@@ -349,7 +413,7 @@ func (s *SyncScreen) View() string {
 	if s.isRunning {
 		helpText = helpStyle.Render("x: stop • esc: back")
 	} else {
-		helpText = helpStyle.Render("tab: switch panel • space: toggle • s: start sync • esc: back")
+		helpText = helpStyle.Render("tab: switch panel • space: toggle • s: start sync • r: resume • f: retry failed • esc: back")
 	}
 
 	// Status message
@@ -497,6 +561,8 @@ func (s *SyncScreen) renderOptionsPanel(width int) string {
 		{"Layers", s.syncOptions.Layers},
 		{"Styles", s.syncOptions.Styles},
 		{"Layer Groups", s.syncOptions.LayerGroups},
+		{"Tile Caching (GWC)", s.syncOptions.GWC},
+		{"Seed New Layers", s.syncOptions.GWCSeed},
 	}
 
 	var items []string
@@ -548,6 +614,8 @@ func (s *SyncScreen) renderProgress() string {
 			statusIcon = "\uf00d" // fa-times
 		case "stopped":
 			statusIcon = "\uf04d" // fa-stop
+		case "pending":
+			statusIcon = "\uf017" // fa-clock
 		}
 
 		progressBar := s.renderProgressBar(int(progress), 20)
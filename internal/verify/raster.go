@@ -0,0 +1,670 @@
+package verify
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// RasterInfo contains metadata about a raster
+type RasterInfo struct {
+	Width        int
+	Height       int
+	Bands        []BandInfo
+	CRS          string     // EPSG code, e.g. EPSG:4326; empty if unknown
+	GeoTransform [6]float64 // GDAL order: origin x, pixel width, row rotation, origin y, column rotation, pixel height
+	BBox         BoundingBox
+	Checksums    []int // By band, of the top-left ChecksumSize pixels square
+	ChecksumSize int
+
+	axisLabels []string // WCS subset axes, easting first
+}
+
+// BandInfo describes one raster band
+type BandInfo struct {
+	Name     string
+	DataType string   // GDAL name, e.g. Byte, Int16, Float32
+	NoData   *float64 // nil if unset
+	Min      *float64 // Local: computed; remote: the range GeoServer reports, nil if unbounded
+	Max      *float64
+}
+
+// GetLocalRasterInfo reads metadata and band statistics from a local raster
+// using gdalinfo
+func GetLocalRasterInfo(filePath string) (*RasterInfo, error) {
+	// -mm computes the actual minimum and maximum of each band
+	output, err := exec.Command("gdalinfo", "-json", "-mm", filePath).Output()
+	if err != nil {
+		return nil, fmt.Errorf("gdalinfo failed: %w", err)
+	}
+
+	var result struct {
+		Size             []int     `json:"size"`
+		GeoTransform     []float64 `json:"geoTransform"`
+		CoordinateSystem struct {
+			Wkt string `json:"wkt"`
+		} `json:"coordinateSystem"`
+		Stac struct {
+			EPSG int `json:"proj:epsg"`
+		} `json:"stac"`
+		Bands []struct {
+			Description string      `json:"description"`
+			Type        string      `json:"type"`
+			NoData      interface{} `json:"noDataValue"`
+			Min         interface{} `json:"computedMin"`
+			Max         interface{} `json:"computedMax"`
+		} `json:"bands"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse gdalinfo output: %w", err)
+	}
+	if len(result.Size) != 2 || len(result.GeoTransform) != 6 {
+		return nil, fmt.Errorf("raster has no size or georeferencing")
+	}
+
+	info := &RasterInfo{Width: result.Size[0], Height: result.Size[1]}
+	copy(info.GeoTransform[:], result.GeoTransform)
+	info.BBox = bboxFromGeoTransform(info.GeoTransform, info.Width, info.Height)
+
+	if result.Stac.EPSG != 0 {
+		info.CRS = fmt.Sprintf("EPSG:%d", result.Stac.EPSG)
+	} else {
+		info.CRS = epsgFromWKT(result.CoordinateSystem.Wkt)
+	}
+
+	for _, b := range result.Bands {
+		info.Bands = append(info.Bands, BandInfo{
+			Name:     b.Description,
+			DataType: b.Type,
+			NoData:   parseNumber(b.NoData),
+			Min:      parseNumber(b.Min),
+			Max:      parseNumber(b.Max),
+		})
+	}
+
+	return info, nil
+}
+
+// describeCoverage is the part of a WCS 2.0 DescribeCoverage response that
+// is compared
+type describeCoverage struct {
+	Description struct {
+		Envelope struct {
+			SRSName    string `xml:"srsName,attr"`
+			AxisLabels string `xml:"axisLabels,attr"`
+			Lower      string `xml:"lowerCorner"`
+			Upper      string `xml:"upperCorner"`
+		} `xml:"boundedBy>Envelope"`
+		Grid struct {
+			Low  string `xml:"low"`
+			High string `xml:"high"`
+		} `xml:"domainSet>RectifiedGrid>limits>GridEnvelope"`
+		Fields []struct {
+			Name      string   `xml:"name,attr"`
+			NilValues []string `xml:"Quantity>nilValues>NilValues>nilValue"`
+			Interval  string   `xml:"Quantity>constraint>AllowedValues>interval"`
+		} `xml:"rangeType>DataRecord>field"`
+	} `xml:"CoverageDescription"`
+}
+
+// GetRemoteRasterInfo gets metadata about a GeoServer coverage from WCS 2.0
+// DescribeCoverage, with band data types and ranges from the REST API
+func GetRemoteRasterInfo(geoserverURL, workspace, coverage, username, password string) (*RasterInfo, error) {
+	describeURL := fmt.Sprintf("%s/%s/wcs?SERVICE=WCS&VERSION=2.0.1&REQUEST=DescribeCoverage&COVERAGEID=%s",
+		geoserverURL, workspace, url.QueryEscape(workspace+"__"+coverage))
+
+	body, err := fetch(describeURL, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("WCS request failed: %w", err)
+	}
+	if msg := owsException(body); msg != "" {
+		return nil, fmt.Errorf("WCS DescribeCoverage failed: %s", msg)
+	}
+
+	var described describeCoverage
+	if err := xml.Unmarshal(body, &described); err != nil {
+		return nil, fmt.Errorf("failed to parse DescribeCoverage response: %w", err)
+	}
+	desc := described.Description
+
+	low, high := parseNumbers(desc.Grid.Low), parseNumbers(desc.Grid.High)
+	lower, upper := parseNumbers(desc.Envelope.Lower), parseNumbers(desc.Envelope.Upper)
+	if len(low) != 2 || len(high) != 2 || len(lower) != 2 || len(upper) != 2 {
+		return nil, fmt.Errorf("coverage %s:%s has no 2D grid", workspace, coverage)
+	}
+
+	info := &RasterInfo{
+		Width:  int(high[0]-low[0]) + 1,
+		Height: int(high[1]-low[1]) + 1,
+		CRS:    epsgFromURI(desc.Envelope.SRSName),
+	}
+
+	// Geographic CRSs list latitude first
+	labels := strings.Fields(desc.Envelope.AxisLabels)
+	if len(labels) == 2 && northFirst(labels[0]) {
+		labels[0], labels[1] = labels[1], labels[0]
+		lower[0], lower[1] = lower[1], lower[0]
+		upper[0], upper[1] = upper[1], upper[0]
+	}
+	info.axisLabels = labels
+	info.BBox = BoundingBox{MinX: lower[0], MinY: lower[1], MaxX: upper[0], MaxY: upper[1]}
+	info.GeoTransform = [6]float64{
+		lower[0], (upper[0] - lower[0]) / float64(info.Width), 0,
+		upper[1], 0, -(upper[1] - lower[1]) / float64(info.Height),
+	}
+
+	for _, f := range desc.Fields {
+		band := BandInfo{Name: f.Name}
+		if len(f.NilValues) > 0 {
+			band.NoData = parseNumber(strings.TrimSpace(f.NilValues[0]))
+		}
+		if r := parseNumbers(f.Interval); len(r) == 2 {
+			band.Min, band.Max = finite(r[0]), finite(r[1])
+		}
+		info.Bands = append(info.Bands, band)
+	}
+
+	addCoverageDimensions(geoserverURL, workspace, coverage, username, password, info)
+
+	return info, nil
+}
+
+// addCoverageDimensions fills in what WCS does not describe from the
+// coverage's REST configuration: the data type of each band, and its
+// native CRS code
+func addCoverageDimensions(geoserverURL, workspace, coverage, username, password string, info *RasterInfo) {
+	restURL := fmt.Sprintf("%s/rest/workspaces/%s/coverages/%s.json",
+		geoserverURL, url.PathEscape(workspace), url.PathEscape(coverage))
+
+	body, err := fetch(restURL, username, password)
+	if err != nil {
+		return
+	}
+
+	var result struct {
+		Coverage struct {
+			SRS        string `json:"srs"`
+			Dimensions struct {
+				CoverageDimension []struct {
+					Name  string `json:"name"`
+					Range struct {
+						Min interface{} `json:"min"`
+						Max interface{} `json:"max"`
+					} `json:"range"`
+					NullValues struct {
+						Double interface{} `json:"double"`
+					} `json:"nullValues"`
+					DimensionType struct {
+						Name string `json:"name"`
+					} `json:"dimensionType"`
+				} `json:"coverageDimension"`
+			} `json:"dimensions"`
+		} `json:"coverage"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return
+	}
+
+	if result.Coverage.SRS != "" {
+		info.CRS = strings.ToUpper(result.Coverage.SRS)
+	}
+	for i, dim := range result.Coverage.Dimensions.CoverageDimension {
+		if i >= len(info.Bands) {
+			info.Bands = append(info.Bands, BandInfo{Name: dim.Name})
+		}
+		band := &info.Bands[i]
+		band.DataType = gdalDataType(dim.DimensionType.Name)
+		if band.Min == nil && band.Max == nil {
+			band.Min, band.Max = finitePtr(parseNumber(dim.Range.Min)), finitePtr(parseNumber(dim.Range.Max))
+		}
+		if band.NoData == nil {
+			// A single null value is not wrapped in an array
+			switch v := dim.NullValues.Double.(type) {
+			case []interface{}:
+				if len(v) > 0 {
+					band.NoData = parseNumber(v[0])
+				}
+			default:
+				band.NoData = parseNumber(v)
+			}
+		}
+	}
+}
+
+// ChecksumLocalRaster sets the per-band checksums of the top-left pixels of
+// a local raster, size pixels square, using gdal_translate and gdalinfo
+func ChecksumLocalRaster(filePath string, info *RasterInfo, size int) error {
+	size = min(size, info.Width, info.Height)
+
+	tmp, err := tempTIFF()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+
+	n := strconv.Itoa(size)
+	cmd := exec.Command("gdal_translate", "-q", "-of", "GTiff", "-srcwin", "0", "0", n, n, filePath, tmp)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("gdal_translate failed: %s", strings.TrimSpace(string(output)))
+	}
+
+	sums, _, _, err := bandChecksums(tmp)
+	if err != nil {
+		return err
+	}
+	info.Checksums, info.ChecksumSize = sums, size
+	return nil
+}
+
+// ChecksumRemoteRaster fetches the top-left pixels of a coverage, size
+// pixels square, with WCS 2.0 GetCoverage and sets their per-band checksums.
+// info must come from GetRemoteRasterInfo.
+func ChecksumRemoteRaster(geoserverURL, workspace, coverage, username, password string, info *RasterInfo, size int) error {
+	size = min(size, info.Width, info.Height)
+	if len(info.axisLabels) != 2 {
+		return fmt.Errorf("coverage axes unknown")
+	}
+
+	// Inset the subset by a quarter pixel so it selects exactly size pixels
+	// whether GeoServer matches pixel edges or centres
+	gt := info.GeoTransform
+	x0, x1 := gt[0]+0.25*gt[1], gt[0]+(float64(size)-0.25)*gt[1]
+	y0, y1 := gt[3]+(float64(size)-0.25)*gt[5], gt[3]+0.25*gt[5]
+
+	query := url.Values{}
+	query.Set("SERVICE", "WCS")
+	query.Set("VERSION", "2.0.1")
+	query.Set("REQUEST", "GetCoverage")
+	query.Set("COVERAGEID", workspace+"__"+coverage)
+	query.Set("FORMAT", "image/tiff")
+	query["SUBSET"] = []string{
+		fmt.Sprintf("%s(%s,%s)", info.axisLabels[0], formatCoord(x0), formatCoord(x1)),
+		fmt.Sprintf("%s(%s,%s)", info.axisLabels[1], formatCoord(y0), formatCoord(y1)),
+	}
+
+	body, err := fetch(fmt.Sprintf("%s/%s/wcs?%s", geoserverURL, workspace, query.Encode()), username, password)
+	if err != nil {
+		return fmt.Errorf("WCS request failed: %w", err)
+	}
+	if msg := owsException(body); msg != "" {
+		return fmt.Errorf("WCS GetCoverage failed: %s", msg)
+	}
+
+	tmp, err := tempTIFF()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.WriteFile(tmp, body, 0600); err != nil {
+		return err
+	}
+
+	sums, width, height, err := bandChecksums(tmp)
+	if err != nil {
+		return err
+	}
+	if width != size || height != size {
+		return fmt.Errorf("GetCoverage returned %dx%d pixels, expected %dx%d", width, height, size, size)
+	}
+	info.Checksums, info.ChecksumSize = sums, size
+	return nil
+}
+
+// VerifyRasterUpload compares local and remote raster info
+func VerifyRasterUpload(local, remote *RasterInfo) *VerificationResult {
+	result := &VerificationResult{
+		Success:      true,
+		LocalRaster:  local,
+		RemoteRaster: remote,
+		Errors:       []string{},
+		Warnings:     []string{},
+	}
+	fail := func(format string, args ...interface{}) {
+		result.Success = false
+		result.Errors = append(result.Errors, fmt.Sprintf(format, args...))
+	}
+	warn := func(format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+
+	// Compare size
+	result.SizeOK = local.Width == remote.Width && local.Height == remote.Height
+	if !result.SizeOK {
+		fail("Size mismatch: local=%dx%d, remote=%dx%d", local.Width, local.Height, remote.Width, remote.Height)
+	}
+
+	// Compare bands and their data types
+	result.BandsOK = len(local.Bands) == len(remote.Bands)
+	if !result.BandsOK {
+		fail("Band count mismatch: local=%d, remote=%d", len(local.Bands), len(remote.Bands))
+	} else {
+		for i := range local.Bands {
+			l, r := local.Bands[i].DataType, remote.Bands[i].DataType
+			if r != "" && !strings.EqualFold(l, r) {
+				result.BandsOK = false
+				fail("Band %d data type mismatch: local=%s, remote=%s", i+1, l, r)
+			}
+		}
+	}
+
+	// Compare CRS
+	switch {
+	case local.CRS == "" || remote.CRS == "":
+		result.CRSOK = true
+		warn("CRS could not be compared: local=%s, remote=%s", orUnknown(local.CRS), orUnknown(remote.CRS))
+	case strings.EqualFold(local.CRS, remote.CRS):
+		result.CRSOK = true
+	default:
+		fail("CRS mismatch: local=%s, remote=%s", local.CRS, remote.CRS)
+	}
+
+	// Compare georeferencing, to within a hundredth of a pixel
+	lgt, rgt := local.GeoTransform, remote.GeoTransform
+	if lgt[2] != 0 || lgt[4] != 0 {
+		result.GeoTransformOK = true
+		warn("Local raster is rotated; georeferencing not compared")
+	} else {
+		tolX, tolY := math.Abs(lgt[1])*0.01, math.Abs(lgt[5])*0.01
+		result.GeoTransformOK = math.Abs(lgt[0]-rgt[0]) <= tolX && math.Abs(lgt[3]-rgt[3]) <= tolY &&
+			math.Abs(lgt[1]-rgt[1])*float64(local.Width) <= tolX &&
+			math.Abs(lgt[5]-rgt[5])*float64(local.Height) <= tolY
+		if !result.GeoTransformOK {
+			fail("Bounding box mismatch: local=(%.6f,%.6f,%.6f,%.6f), remote=(%.6f,%.6f,%.6f,%.6f)",
+				local.BBox.MinX, local.BBox.MinY, local.BBox.MaxX, local.BBox.MaxY,
+				remote.BBox.MinX, remote.BBox.MinY, remote.BBox.MaxX, remote.BBox.MaxY)
+		}
+	}
+
+	// Compare nodata and value ranges band by band
+	result.NoDataOK, result.RangeOK = true, true
+	for i := 0; i < len(local.Bands) && i < len(remote.Bands); i++ {
+		l, r := local.Bands[i], remote.Bands[i]
+		switch {
+		case l.NoData != nil && r.NoData != nil:
+			if !sameValue(*l.NoData, *r.NoData) {
+				result.NoDataOK = false
+				fail("Band %d nodata mismatch: local=%g, remote=%g", i+1, *l.NoData, *r.NoData)
+			}
+		case l.NoData != nil:
+			warn("Band %d nodata %g is not set on the coverage", i+1, *l.NoData)
+		case r.NoData != nil:
+			warn("Band %d nodata %g is set on the coverage only", i+1, *r.NoData)
+		}
+
+		if l.Min != nil && r.Min != nil && *l.Min < *r.Min || l.Max != nil && r.Max != nil && *l.Max > *r.Max {
+			result.RangeOK = false
+			fail("Band %d values outside the coverage range: local=%s, remote=%s", i+1, formatRange(l), formatRange(r))
+		}
+	}
+
+	// Compare checksums of the subset, if both were fetched
+	if local.Checksums != nil && remote.Checksums != nil {
+		result.ChecksumCompared = true
+		result.ChecksumOK = local.ChecksumSize == remote.ChecksumSize && len(local.Checksums) == len(remote.Checksums)
+		for i := 0; result.ChecksumOK && i < len(local.Checksums); i++ {
+			result.ChecksumOK = local.Checksums[i] == remote.Checksums[i]
+		}
+		if !result.ChecksumOK {
+			fail("Pixel checksum mismatch over %dx%d pixels: local=%v, remote=%v",
+				local.ChecksumSize, local.ChecksumSize, local.Checksums, remote.Checksums)
+		}
+	}
+
+	return result
+}
+
+// writeRasterChecks writes the raster checks of FormatResult
+func (r *VerificationResult) writeRasterChecks(sb *strings.Builder) {
+	local, remote := r.LocalRaster, r.RemoteRaster
+	sb.WriteString(fmt.Sprintf("  Size:          %s (local: %dx%d, remote: %dx%d)\n",
+		statusIcon(r.SizeOK), local.Width, local.Height, remote.Width, remote.Height))
+	sb.WriteString(fmt.Sprintf("  Bands:         %s (%d, %s)\n",
+		statusIcon(r.BandsOK), len(local.Bands), bandTypes(local.Bands)))
+	sb.WriteString(fmt.Sprintf("  CRS:           %s (local: %s, remote: %s)\n",
+		statusIcon(r.CRSOK), orUnknown(local.CRS), orUnknown(remote.CRS)))
+	sb.WriteString(fmt.Sprintf("  Georeference:  %s\n", statusIcon(r.GeoTransformOK)))
+	sb.WriteString(fmt.Sprintf("  NoData:        %s\n", statusIcon(r.NoDataOK)))
+	sb.WriteString(fmt.Sprintf("  Value Range:   %s\n", statusIcon(r.RangeOK)))
+	if r.ChecksumCompared {
+		sb.WriteString(fmt.Sprintf("  Checksum:      %s (%dx%d pixels)\n",
+			statusIcon(r.ChecksumOK), local.ChecksumSize, local.ChecksumSize))
+	}
+}
+
+// bandChecksums returns the checksum of each band of a raster, and its size
+func bandChecksums(filePath string) ([]int, int, int, error) {
+	output, err := exec.Command("gdalinfo", "-json", "-checksum", filePath).Output()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("gdalinfo failed: %w", err)
+	}
+	var result struct {
+		Size  []int `json:"size"`
+		Bands []struct {
+			Checksum int `json:"checksum"`
+		} `json:"bands"`
+	}
+	if err := json.Unmarshal(output, &result); err != nil || len(result.Size) != 2 {
+		return nil, 0, 0, fmt.Errorf("failed to parse gdalinfo output")
+	}
+	sums := make([]int, len(result.Bands))
+	for i, b := range result.Bands {
+		sums[i] = b.Checksum
+	}
+	return sums, result.Size[0], result.Size[1], nil
+}
+
+// fetch GETs a URL and returns its body
+func fetch(rawURL, username, password string) ([]byte, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if msg := owsException(body); msg != "" {
+			return nil, fmt.Errorf("status %d: %s", resp.StatusCode, msg)
+		}
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// owsException returns the text of an OWS exception report, or ""
+func owsException(body []byte) string {
+	if !strings.Contains(string(body[:min(len(body), 512)]), "ExceptionReport") {
+		return ""
+	}
+	var report struct {
+		Exceptions []struct {
+			Code string `xml:"exceptionCode,attr"`
+			Text string `xml:"ExceptionText"`
+		} `xml:"Exception"`
+	}
+	if err := xml.Unmarshal(body, &report); err != nil || len(report.Exceptions) == 0 {
+		return "exception report"
+	}
+	e := report.Exceptions[0]
+	return strings.TrimSpace(e.Code + ": " + strings.TrimSpace(e.Text))
+}
+
+func tempTIFF() (string, error) {
+	f, err := os.CreateTemp("", "cloudbench-verify-*.tif")
+	if err != nil {
+		return "", err
+	}
+	f.Close()
+	return f.Name(), nil
+}
+
+// bboxFromGeoTransform returns the extent of a north-up raster
+func bboxFromGeoTransform(gt [6]float64, width, height int) BoundingBox {
+	x0, x1 := gt[0], gt[0]+float64(width)*gt[1]
+	y0, y1 := gt[3], gt[3]+float64(height)*gt[5]
+	return BoundingBox{
+		MinX: math.Min(x0, x1), MinY: math.Min(y0, y1),
+		MaxX: math.Max(x0, x1), MaxY: math.Max(y0, y1),
+	}
+}
+
+// epsgFromWKT returns the EPSG code of the outermost CRS in WKT 1 or 2
+func epsgFromWKT(wkt string) string {
+	for _, prefix := range []string{`ID["EPSG",`, `AUTHORITY["EPSG","`} {
+		i := strings.LastIndex(wkt, prefix)
+		if i < 0 {
+			continue
+		}
+		code := wkt[i+len(prefix):]
+		if end := strings.IndexAny(code, `"]`); end > 0 {
+			return "EPSG:" + code[:end]
+		}
+	}
+	return ""
+}
+
+// epsgFromURI returns the EPSG code of a CRS URI such as
+// http://www.opengis.net/def/crs/EPSG/0/4326
+func epsgFromURI(uri string) string {
+	if i := strings.Index(uri, "/EPSG/"); i >= 0 {
+		parts := strings.Split(uri[i+1:], "/")
+		if len(parts) == 3 {
+			return "EPSG:" + parts[2]
+		}
+	}
+	if code, ok := strings.CutPrefix(uri, "EPSG:"); ok {
+		return "EPSG:" + code
+	}
+	return ""
+}
+
+// northFirst tells whether an axis label is a northing or latitude
+func northFirst(label string) bool {
+	switch strings.ToLower(label) {
+	case "lat", "latitude", "n", "northing", "y":
+		return true
+	}
+	return false
+}
+
+// gdalDataType maps a GeoServer sample dimension type to its GDAL name
+func gdalDataType(dimensionType string) string {
+	switch dimensionType {
+	case "UNSIGNED_1BIT", "UNSIGNED_2BITS", "UNSIGNED_4BITS", "UNSIGNED_8BITS":
+		return "Byte"
+	case "SIGNED_8BITS":
+		return "Int8"
+	case "UNSIGNED_16BITS":
+		return "UInt16"
+	case "SIGNED_16BITS":
+		return "Int16"
+	case "UNSIGNED_32BITS":
+		return "UInt32"
+	case "SIGNED_32BITS":
+		return "Int32"
+	case "REAL_32BITS":
+		return "Float32"
+	case "REAL_64BITS":
+		return "Float64"
+	default:
+		return ""
+	}
+}
+
+// parseNumber reads a JSON or XML number, which GDAL and GeoServer write as
+// a string for NaN and infinities
+func parseNumber(v interface{}) *float64 {
+	var f float64
+	switch n := v.(type) {
+	case float64:
+		f = n
+	case string:
+		var err error
+		if f, err = strconv.ParseFloat(strings.TrimSpace(n), 64); err != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return &f
+}
+
+// parseNumbers reads space-separated numbers, e.g. a GML position
+func parseNumbers(s string) []float64 {
+	var numbers []float64
+	for _, field := range strings.Fields(s) {
+		f, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil
+		}
+		numbers = append(numbers, f)
+	}
+	return numbers
+}
+
+func finite(f float64) *float64 {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+func finitePtr(f *float64) *float64 {
+	if f == nil {
+		return nil
+	}
+	return finite(*f)
+}
+
+// sameValue compares nodata values, treating NaN as equal to itself
+func sameValue(a, b float64) bool {
+	return a == b || math.IsNaN(a) && math.IsNaN(b)
+}
+
+func formatCoord(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func formatRange(b BandInfo) string {
+	bound := func(f *float64) string {
+		if f == nil {
+			return "?"
+		}
+		return strconv.FormatFloat(*f, 'g', -1, 64)
+	}
+	return "[" + bound(b.Min) + ", " + bound(b.Max) + "]"
+}
+
+func bandTypes(bands []BandInfo) string {
+	types := make([]string, len(bands))
+	for i, b := range bands {
+		types[i] = b.DataType
+	}
+	return strings.Join(types, ", ")
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
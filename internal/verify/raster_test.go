@@ -0,0 +1,118 @@
+package verify
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const describeCoverageXML = `<?xml version="1.0" encoding="UTF-8"?>
+<wcs:CoverageDescriptions xmlns:wcs="http://www.opengis.net/wcs/2.0" xmlns:gml="http://www.opengis.net/gml/3.2"
+    xmlns:gmlcov="http://www.opengis.net/gmlcov/1.0" xmlns:swe="http://www.opengis.net/swe/2.0">
+  <wcs:CoverageDescription gml:id="topp__dem">
+    <gml:boundedBy>
+      <gml:Envelope srsName="http://www.opengis.net/def/crs/EPSG/0/4326" axisLabels="Lat Long" srsDimension="2">
+        <gml:lowerCorner>-34.0 18.0</gml:lowerCorner>
+        <gml:upperCorner>-33.0 20.0</gml:upperCorner>
+      </gml:Envelope>
+    </gml:boundedBy>
+    <wcs:CoverageId>topp__dem</wcs:CoverageId>
+    <gml:domainSet>
+      <gml:RectifiedGrid gml:id="grid00__topp__dem" dimension="2">
+        <gml:limits>
+          <gml:GridEnvelope>
+            <gml:low>0 0</gml:low>
+            <gml:high>199 99</gml:high>
+          </gml:GridEnvelope>
+        </gml:limits>
+      </gml:RectifiedGrid>
+    </gml:domainSet>
+    <gmlcov:rangeType>
+      <swe:DataRecord>
+        <swe:field name="GRAY_INDEX">
+          <swe:Quantity>
+            <swe:nilValues><swe:NilValues><swe:nilValue reason="nodata">-9999.0</swe:nilValue></swe:NilValues></swe:nilValues>
+            <swe:constraint><swe:AllowedValues><swe:interval>-Infinity Infinity</swe:interval></swe:AllowedValues></swe:constraint>
+          </swe:Quantity>
+        </swe:field>
+      </swe:DataRecord>
+    </gmlcov:rangeType>
+  </wcs:CoverageDescription>
+</wcs:CoverageDescriptions>`
+
+const coverageJSON = `{"coverage": {"name": "dem", "srs": "EPSG:4326", "dimensions": {"coverageDimension": [
+  {"name": "GRAY_INDEX", "range": {"min": "-inf", "max": "inf"}, "nullValues": {"double": [-9999]},
+   "dimensionType": {"name": "REAL_32BITS"}}]}}}`
+
+func TestRasterVerification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/geoserver/topp/wcs":
+			if r.URL.Query().Get("COVERAGEID") != "topp__dem" {
+				io.WriteString(w, `<ows:ExceptionReport xmlns:ows="http://www.opengis.net/ows/2.0"><ows:Exception exceptionCode="NoSuchCoverage"><ows:ExceptionText>Could not find coverage</ows:ExceptionText></ows:Exception></ows:ExceptionReport>`)
+				return
+			}
+			io.WriteString(w, describeCoverageXML)
+		case "/geoserver/rest/workspaces/topp/coverages/dem.json":
+			io.WriteString(w, coverageJSON)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	remote, err := GetRemoteRasterInfo(server.URL+"/geoserver", "topp", "dem", "admin", "geoserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.Width != 200 || remote.Height != 100 || remote.CRS != "EPSG:4326" {
+		t.Errorf("remote = %dx%d %s, want 200x100 EPSG:4326", remote.Width, remote.Height, remote.CRS)
+	}
+	if remote.BBox != (BoundingBox{MinX: 18, MinY: -34, MaxX: 20, MaxY: -33}) {
+		t.Errorf("remote BBox = %+v, axes not swapped to easting first", remote.BBox)
+	}
+	if len(remote.Bands) != 1 || remote.Bands[0].DataType != "Float32" ||
+		remote.Bands[0].NoData == nil || *remote.Bands[0].NoData != -9999 || remote.Bands[0].Max != nil {
+		t.Errorf("remote bands = %+v", remote.Bands)
+	}
+
+	if _, err := GetRemoteRasterInfo(server.URL+"/geoserver", "topp", "missing", "", ""); err == nil ||
+		!strings.Contains(err.Error(), "Could not find coverage") {
+		t.Errorf("missing coverage error = %v", err)
+	}
+
+	nodata, lo, hi := -9999.0, 12.5, 1480.0
+	local := &RasterInfo{
+		Width: 200, Height: 100, CRS: "EPSG:4326",
+		GeoTransform: [6]float64{18, 0.01, 0, -33, 0, -0.01},
+		Bands:        []BandInfo{{DataType: "Float32", NoData: &nodata, Min: &lo, Max: &hi}},
+	}
+	if result := VerifyRasterUpload(local, remote); !result.Success {
+		t.Errorf("matching raster failed verification:\n%s", result.FormatResult())
+	}
+
+	local.GeoTransform[0] = 18.5
+	local.Bands[0].DataType = "Int16"
+	result := VerifyRasterUpload(local, remote)
+	if result.Success || result.GeoTransformOK || result.BandsOK || !result.SizeOK {
+		t.Errorf("shifted Int16 raster: %+v", result)
+	}
+	if out := result.FormatResult(); !strings.Contains(out, "FAILED") || !strings.Contains(out, "Georeference") {
+		t.Errorf("FormatResult() =\n%s", out)
+	}
+}
+
+func TestEPSGFromWKT(t *testing.T) {
+	tests := map[string]string{
+		`GEOGCRS["WGS 84",CS[ellipsoidal,2],ID["EPSG",4326]]`:                                                 "EPSG:4326",
+		`PROJCS["WGS 84 / UTM zone 34S",GEOGCS["WGS 84",AUTHORITY["EPSG","4326"]],AUTHORITY["EPSG","32734"]]`: "EPSG:32734",
+		`LOCAL_CS["unnamed"]`: "",
+	}
+	for wkt, want := range tests {
+		if got := epsgFromWKT(wkt); got != want {
+			t.Errorf("epsgFromWKT(%q) = %q, want %q", wkt, got, want)
+		}
+	}
+}
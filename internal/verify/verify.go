@@ -44,6 +44,16 @@ type VerificationResult struct {
 	AttributesOK     bool
 	LocalInfo        *LayerInfo
 	RemoteInfo       *LayerInfo
+	SizeOK           bool // Raster checks, see VerifyRasterUpload
+	BandsOK          bool
+	CRSOK            bool
+	GeoTransformOK   bool
+	NoDataOK         bool
+	RangeOK          bool
+	ChecksumOK       bool
+	ChecksumCompared bool
+	LocalRaster      *RasterInfo
+	RemoteRaster     *RasterInfo
 	Errors           []string
 	Warnings         []string
 }
@@ -552,13 +562,17 @@ func (r *VerificationResult) FormatResult() string {
 	}
 
 	sb.WriteString("Checks:\n")
-	sb.WriteString(fmt.Sprintf("  Feature Count: %s (local: %d, remote: %d)\n",
-		statusIcon(r.FeatureCountOK), r.LocalInfo.FeatureCount, r.RemoteInfo.FeatureCount))
-	sb.WriteString(fmt.Sprintf("  Bounding Box:  %s\n", statusIcon(r.BBoxOK)))
-	sb.WriteString(fmt.Sprintf("  Geometry Type: %s (local: %s, remote: %s)\n",
-		statusIcon(r.GeometryTypeOK), r.LocalInfo.GeometryType, r.RemoteInfo.GeometryType))
-	sb.WriteString(fmt.Sprintf("  Attributes:    %s (%d fields)\n",
-		statusIcon(r.AttributesOK), len(r.LocalInfo.Attributes)))
+	if r.LocalRaster != nil {
+		r.writeRasterChecks(&sb)
+	} else {
+		sb.WriteString(fmt.Sprintf("  Feature Count: %s (local: %d, remote: %d)\n",
+			statusIcon(r.FeatureCountOK), r.LocalInfo.FeatureCount, r.RemoteInfo.FeatureCount))
+		sb.WriteString(fmt.Sprintf("  Bounding Box:  %s\n", statusIcon(r.BBoxOK)))
+		sb.WriteString(fmt.Sprintf("  Geometry Type: %s (local: %s, remote: %s)\n",
+			statusIcon(r.GeometryTypeOK), r.LocalInfo.GeometryType, r.RemoteInfo.GeometryType))
+		sb.WriteString(fmt.Sprintf("  Attributes:    %s (%d fields)\n",
+			statusIcon(r.AttributesOK), len(r.LocalInfo.Attributes)))
+	}
 
 	if len(r.Errors) > 0 {
 		sb.WriteString("\nErrors:\n")
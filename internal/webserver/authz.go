@@ -0,0 +1,109 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+)
+
+// scopeKind says where a route carries the connection and workspace it acts on
+type scopeKind int
+
+const (
+	scopeNone       scopeKind = iota // Global action
+	scopeConnection                  // /api/{type}/{connId}/...
+	scopeWorkspace                   // /api/{type}/{connId}/{workspace}/...
+)
+
+// access is a route's permission policy: the role needed to read (GET, HEAD),
+// change (POST, PUT, PATCH) and delete. Grants on the connection or workspace
+// the request targets count towards the role.
+type access struct {
+	read, write, remove auth.Role
+	scope               scopeKind
+	// query takes the connection and workspace from the connId and workspace
+	// query parameters when the path has none. Only for handlers that act on
+	// those same parameters.
+	query bool
+	// sub overrides the policy by the path segment after the connection ID,
+	// e.g. "buckets" in /api/s3/connections/{id}/buckets
+	sub map[string]access
+}
+
+// Route policies shared by setupRoutes
+var (
+	// readAccess is for routes that only read, including read-only POSTs such as previews
+	readAccess = access{read: auth.RoleViewer, write: auth.RoleViewer, remove: auth.RoleViewer}
+	// catalogAccess lets editors change GeoServer catalog content; deleting needs an admin
+	catalogAccess = access{read: auth.RoleViewer, write: auth.RoleEditor, remove: auth.RoleAdmin, scope: scopeWorkspace}
+	// manageAccess lets everyone see, and admins change, server-wide settings such as sync
+	manageAccess = access{read: auth.RoleViewer, write: auth.RoleAdmin, remove: auth.RoleAdmin}
+	// connectionAccess lets everyone see, and admins of the connection in the path change, a connection
+	connectionAccess = access{read: auth.RoleViewer, write: auth.RoleAdmin, remove: auth.RoleAdmin, scope: scopeConnection}
+	// editAccess lets editors create and change content outside the catalog; deleting needs an admin
+	editAccess = access{read: auth.RoleViewer, write: auth.RoleEditor, remove: auth.RoleAdmin}
+	// adminAccess is for routes that expose or change everything, e.g. bundles with secrets
+	adminAccess = access{read: auth.RoleAdmin, write: auth.RoleAdmin, remove: auth.RoleAdmin}
+)
+
+// required returns the role a request method needs
+func (a access) required(method string) auth.Role {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return a.read
+	case http.MethodDelete:
+		return a.remove
+	default:
+		return a.write
+	}
+}
+
+// target finds the policy, connection and workspace for a request to a route
+// registered under pattern. Routes marked query may pass them as query
+// parameters instead of in the path.
+func (a access) target(r *http.Request, pattern string) (access, string, string) {
+	connID, workspace, _ := parsePathParams(r.URL.Path, strings.TrimSuffix(pattern, "/"))
+	if p, ok := a.sub[workspace]; ok && connID != "" {
+		a = p
+		workspace = ""
+	}
+
+	if connID == "" && a.query {
+		connID = r.URL.Query().Get("connId")
+		workspace = r.URL.Query().Get("workspace")
+	}
+	switch a.scope {
+	case scopeNone:
+		return a, "", ""
+	case scopeConnection:
+		return a, connID, ""
+	}
+	return a, connID, workspace
+}
+
+// authorize enforces a route's policy before calling its handler. Without
+// authentication every request is allowed.
+func (s *Server) authorize(pattern string, a access, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.auth == nil {
+			h(w, r)
+			return
+		}
+
+		user := auth.UserFromContext(r.Context())
+		if user == nil {
+			// Public routes carry no user and need no permission
+			h(w, r)
+			return
+		}
+
+		policy, connID, workspace := a.target(r, pattern)
+		need := policy.required(r.Method)
+		if !user.Can(need, connID, workspace) {
+			s.jsonError(w, "Forbidden: this action needs the "+string(need)+" role", http.StatusForbidden)
+			return
+		}
+		h(w, r)
+	}
+}
@@ -0,0 +1,64 @@
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+)
+
+// TestAuthorizeGrantScope checks that a grant on one connection only counts
+// where the route acts on that connection
+func TestAuthorizeGrantScope(t *testing.T) {
+	s := &Server{auth: &auth.Store{}}
+	mux := http.NewServeMux()
+	s.setupRoutes(mux)
+
+	user := &auth.User{Username: "grantee", Role: auth.RoleViewer,
+		Grants: []auth.Grant{{Connection: "conn1", Role: auth.RoleAdmin}}}
+	request := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader("{}"))
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Global routes ignore connId in the query and need the global role
+	for _, target := range []struct{ method, path string }{
+		{http.MethodPost, "/api/sync/start?connId=conn1"},
+		{http.MethodPost, "/api/sync/configs?connId=conn1"},
+		{http.MethodPost, "/api/connections?connId=conn1"},
+		{http.MethodPost, "/api/pg/services?connId=conn1"},
+		{http.MethodPost, "/api/s3/connections?connId=conn1"},
+		{http.MethodPost, "/api/sync/cache/evict?connId=conn1"},
+		{http.MethodDelete, "/api/rest-cache?connId=conn1"},
+	} {
+		if rec := request(target.method, target.path); rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s by a grantee: status %d, want 403", target.method, target.path, rec.Code)
+		}
+	}
+
+	// The grant applies where the connection is in the path, or in the query
+	// of a route whose handler reads it
+	allowed := func(pattern string, a access, target string) bool {
+		called := false
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req = req.WithContext(auth.WithUser(req.Context(), user))
+		s.authorize(pattern, a, func(http.ResponseWriter, *http.Request) { called = true })(httptest.NewRecorder(), req)
+		return called
+	}
+	uploadAccess := catalogAccess
+	uploadAccess.query = true
+	if !allowed("/api/upload", uploadAccess, "/api/upload?connId=conn1&workspace=topp") {
+		t.Error("grantee denied an upload to its connection")
+	}
+	if allowed("/api/upload", uploadAccess, "/api/upload?connId=conn2&workspace=topp") {
+		t.Error("grantee allowed an upload to another connection")
+	}
+	if !allowed("/api/settings/", connectionAccess, "/api/settings/conn1") {
+		t.Error("grantee denied a settings change on its connection")
+	}
+}
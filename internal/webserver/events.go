@@ -0,0 +1,294 @@
+package webserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/cloudnative"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+	cbsync "github.com/kartoza/kartoza-cloudbench/internal/sync"
+)
+
+// Job types streamed by /api/events
+const (
+	JobTypeSync       = "sync"
+	JobTypeImport     = "import"
+	JobTypeConversion = "conversion"
+	JobTypeSeed       = "seed"
+)
+
+// Event types streamed by /api/events
+const (
+	EventProgress = "progress"
+	EventLog      = "log"
+	EventComplete = "complete"
+)
+
+const (
+	// eventHistory is how many recent events are kept for replay on reconnect
+	eventHistory = 1000
+	// progressInterval is the shortest time between progress events for a job;
+	// log and completion events are never dropped
+	progressInterval = 250 * time.Millisecond
+	// eventBuffer is how many events a slow client may fall behind before it
+	// is disconnected, to reconnect and catch up from the history
+	eventBuffer = 256
+	// heartbeatInterval keeps idle streams open through proxies
+	heartbeatInterval = 20 * time.Second
+)
+
+// Event is a change to a background job
+type Event struct {
+	ID      int64           `json:"id"`
+	Type    string          `json:"type"`    // progress, log or complete
+	JobType string          `json:"jobType"` // sync, import, conversion or seed
+	JobID   string          `json:"jobId"`
+	Message string          `json:"message,omitempty"`
+	Job     json.RawMessage `json:"job,omitempty"` // The job's state, as returned by its status endpoint
+	Time    time.Time       `json:"time"`
+}
+
+// subscriber is a client of the event stream
+type subscriber struct {
+	jobTypes map[string]bool // Empty for all job types
+	jobID    string          // Empty for all jobs
+	ch       chan Event
+}
+
+func (sub *subscriber) matches(e Event) bool {
+	if len(sub.jobTypes) > 0 && !sub.jobTypes[e.JobType] {
+		return false
+	}
+	return sub.jobID == "" || sub.jobID == e.JobID
+}
+
+// eventHub fans job events out to subscribers and keeps recent events so
+// clients can replay what they missed
+type eventHub struct {
+	mu           sync.Mutex
+	nextID       int64
+	history      []Event
+	subscribers  map[*subscriber]struct{}
+	lastProgress map[string]time.Time // jobType/jobID -> time of the last progress event
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{
+		subscribers:  make(map[*subscriber]struct{}),
+		lastProgress: make(map[string]time.Time),
+	}
+}
+
+// publish sends an event for a job. job is encoded as the event's job state
+// and may be nil. Progress events closer together than progressInterval are
+// dropped.
+func (h *eventHub) publish(eventType, jobType, jobID, message string, job interface{}) {
+	now := time.Now()
+	key := jobType + "/" + jobID
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch eventType {
+	case EventProgress:
+		if now.Sub(h.lastProgress[key]) < progressInterval {
+			return
+		}
+		h.lastProgress[key] = now
+	case EventComplete:
+		delete(h.lastProgress, key)
+	}
+
+	var data json.RawMessage
+	if job != nil {
+		data, _ = json.Marshal(job)
+	}
+
+	h.nextID++
+	e := Event{
+		ID:      h.nextID,
+		Type:    eventType,
+		JobType: jobType,
+		JobID:   jobID,
+		Message: message,
+		Job:     data,
+		Time:    now,
+	}
+
+	h.history = append(h.history, e)
+	if len(h.history) > eventHistory {
+		h.history = h.history[len(h.history)-eventHistory:]
+	}
+
+	for sub := range h.subscribers {
+		if !sub.matches(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// Too far behind; the client reconnects and replays
+			delete(h.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// subscribe registers a subscriber and returns the recent events after
+// lastID that it matches
+func (h *eventHub) subscribe(sub *subscriber, lastID int64) []Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var replay []Event
+	for _, e := range h.history {
+		if e.ID > lastID && sub.matches(e) {
+			replay = append(replay, e)
+		}
+	}
+	h.subscribers[sub] = struct{}{}
+	return replay
+}
+
+// unsubscribe removes a subscriber, if it has not already been dropped
+func (h *eventHub) unsubscribe(sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sub]; ok {
+		delete(h.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// closeAll ends every stream, so a shutting-down server does not wait for
+// them
+func (h *eventHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		delete(h.subscribers, sub)
+		close(sub.ch)
+	}
+}
+
+// publishJobEvents streams changes from the sync, conversion and import
+// jobs. Seeding publishes its own events, see runSeed.
+func (s *Server) publishJobEvents() {
+	cbsync.DefaultManager.OnEvent(func(ev cbsync.TaskEvent) {
+		switch ev.Kind {
+		case cbsync.EventLog:
+			s.events.publish(EventLog, JobTypeSync, ev.Task.ID, ev.Message, nil)
+		case cbsync.EventProgress:
+			s.events.publish(EventProgress, JobTypeSync, ev.Task.ID, "", ev.Task.Snapshot())
+		case cbsync.EventComplete:
+			s.events.publish(EventComplete, JobTypeSync, ev.Task.ID, ev.Message, ev.Task.Snapshot())
+		}
+	})
+
+	s.conversionMgr.OnChange(func(job *cloudnative.ConversionJob) {
+		eventType := EventProgress
+		switch job.Status {
+		case cloudnative.JobStatusCompleted, cloudnative.JobStatusFailed, cloudnative.JobStatusCancelled:
+			eventType = EventComplete
+		}
+		s.events.publish(eventType, JobTypeConversion, job.ID, job.Message, job)
+	})
+
+	s.jobs.OnChange(func(info jobs.Info) {
+		if info.Kind != jobs.KindImport {
+			return
+		}
+		eventType := EventProgress
+		if info.Status.Done() {
+			eventType = EventComplete
+		}
+		job := importJob(info)
+		s.events.publish(eventType, JobTypeImport, job.ID, job.Message, job)
+	})
+}
+
+// handleEvents handles GET /api/events - a Server-Sent Events stream of job
+// progress. ?type=sync,import limits the job types and ?job= a single job.
+// Recent events after the Last-Event-ID header (or ?lastEventId=) are
+// replayed first; a new connection without either gets all recent events.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.jsonError(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	sub := &subscriber{
+		jobTypes: make(map[string]bool),
+		jobID:    query.Get("job"),
+		ch:       make(chan Event, eventBuffer),
+	}
+	for _, t := range strings.Split(query.Get("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			sub.jobTypes[t] = true
+		}
+	}
+
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = query.Get("lastEventId")
+	}
+	var after int64
+	if lastID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastID, 10, 64); err != nil {
+			s.jsonError(w, "Invalid last event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx buffering the stream
+
+	replay := s.events.subscribe(sub, after)
+	defer s.events.unsubscribe(sub)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	for _, e := range replay {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, ok := <-sub.ch:
+			if !ok {
+				return
+			}
+			writeEvent(w, e)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes an event in the text/event-stream format
+func writeEvent(w http.ResponseWriter, e Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\ndata: %s\n\n", e.ID, data)
+}
@@ -0,0 +1,38 @@
+package webserver
+
+import "testing"
+
+func TestEventHubReplayAndThrottle(t *testing.T) {
+	h := newEventHub()
+
+	h.publish(EventProgress, JobTypeSync, "a", "", nil)
+	h.publish(EventProgress, JobTypeSync, "a", "", nil) // Too soon, dropped
+	h.publish(EventLog, JobTypeSync, "a", "copying", nil)
+	h.publish(EventProgress, JobTypeImport, "b", "", nil)
+	h.publish(EventComplete, JobTypeSync, "a", "completed", nil)
+
+	all := &subscriber{ch: make(chan Event, eventBuffer)}
+	if replay := h.subscribe(all, 0); len(replay) != 4 {
+		t.Fatalf("expected 4 replayed events, got %d", len(replay))
+	}
+
+	// Reconnecting after event 2 replays only later events for the job
+	job := &subscriber{jobTypes: map[string]bool{JobTypeSync: true}, jobID: "a", ch: make(chan Event, eventBuffer)}
+	replay := h.subscribe(job, 2)
+	if len(replay) != 1 || replay[0].Type != EventComplete || replay[0].Message != "completed" {
+		t.Fatalf("unexpected replay: %+v", replay)
+	}
+
+	h.publish(EventLog, JobTypeImport, "b", "loading", nil)
+	if e := <-all.ch; e.JobID != "b" {
+		t.Errorf("unexpected event for all jobs: %+v", e)
+	}
+	select {
+	case e := <-job.ch:
+		t.Errorf("job subscriber got another job's event: %+v", e)
+	default:
+	}
+
+	h.unsubscribe(job)
+	h.unsubscribe(job) // Safe to repeat
+}
@@ -0,0 +1,81 @@
+package webserver
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+)
+
+// anonymousUser is recorded for changes made while authentication is disabled
+const anonymousUser = "anonymous"
+
+// auditActor returns who a request acts as on a connection, for the audit log
+func (s *Server) auditActor(r *http.Request, connID string) *audit.Actor {
+	if s.audit == nil {
+		return nil
+	}
+	user := anonymousUser
+	if u := auth.UserFromContext(r.Context()); u != nil {
+		user = u.Username
+	}
+	return &audit.Actor{Log: s.audit, User: user, Via: "web", Connection: connID}
+}
+
+// handleAudit lists audit records, newest first, as JSON or CSV
+// GET /api/audit?user=&via=&service=&action=&connection=&resource=&since=&until=&limit=&format=csv
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.audit == nil {
+		s.jsonError(w, "Audit log is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	filter := audit.Filter{
+		User:       q.Get("user"),
+		Via:        q.Get("via"),
+		Service:    q.Get("service"),
+		Action:     q.Get("action"),
+		Connection: q.Get("connection"),
+		Resource:   q.Get("resource"),
+	}
+	var err error
+	if v := q.Get("since"); v != "" {
+		if filter.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			s.jsonError(w, "Invalid since time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("until"); v != "" {
+		if filter.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			s.jsonError(w, "Invalid until time, expected RFC 3339", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			s.jsonError(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := s.audit.Query(filter)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if q.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="cloudbench-audit.csv"`)
+		audit.WriteCSV(w, records)
+		return
+	}
+	s.jsonResponse(w, records)
+}
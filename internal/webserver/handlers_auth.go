@@ -0,0 +1,424 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+)
+
+const (
+	sessionCookieName = "cloudbench_session"
+	csrfHeaderName    = "X-CSRF-Token"
+)
+
+// publicAPIPaths can be reached without logging in
+var publicAPIPaths = map[string]bool{
+	"/api/health":       true,
+	"/api/auth/login":   true,
+	"/api/auth/session": true,
+}
+
+// AuthUserResponse describes a user account
+type AuthUserResponse struct {
+	ID        string       `json:"id"`
+	Username  string       `json:"username"`
+	Role      auth.Role    `json:"role"`
+	Grants    []auth.Grant `json:"grants"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// AuthCapabilitiesResponse tells the web UI which actions to offer
+type AuthCapabilitiesResponse struct {
+	AuthEnabled bool         `json:"auth_enabled"`
+	Role        auth.Role    `json:"role"`
+	Grants      []auth.Grant `json:"grants"`
+}
+
+// AuthUserRequest creates or updates a user account
+type AuthUserRequest struct {
+	Username string       `json:"username,omitempty"`
+	Password string       `json:"password,omitempty"` // Required on create, optional reset on update
+	Role     auth.Role    `json:"role"`
+	Grants   []auth.Grant `json:"grants"`
+}
+
+// AuthSessionResponse is the response for session and login requests
+type AuthSessionResponse struct {
+	AuthEnabled bool              `json:"auth_enabled"`
+	User        *AuthUserResponse `json:"user"`
+	CSRFToken   string            `json:"csrf_token,omitempty"`
+}
+
+// APITokenResponse describes a personal API token without its secret
+type APITokenResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+	Token     string    `json:"token,omitempty"` // Only set when the token is created
+}
+
+// EnableAuth requires a login or API token for all API routes except the
+// health and login endpoints, and for /metrics
+func (s *Server) EnableAuth(store *auth.Store) {
+	s.auth = store
+	s.sessions = auth.NewSessions()
+}
+
+// authMiddleware rejects unauthenticated API requests and puts the user in
+// the request context
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protected := strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/metrics"
+		if !protected || publicAPIPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		user, status, err := s.authenticate(r)
+		if err != nil {
+			s.jsonError(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithUser(r.Context(), user)))
+	})
+}
+
+// authenticate identifies the user from a bearer token or session cookie.
+// Session requests that change state must carry the session's CSRF token.
+func (s *Server) authenticate(r *http.Request) (*auth.User, int, error) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		user, err := s.auth.AuthenticateToken(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			return nil, http.StatusUnauthorized, errors.New("invalid API token")
+		}
+		return user, 0, nil
+	}
+
+	session := s.requestSession(r)
+	if session == nil {
+		return nil, http.StatusUnauthorized, errors.New("authentication required")
+	}
+	user, err := s.auth.User(session.UserID)
+	if err != nil {
+		s.sessions.Delete(session.ID)
+		return nil, http.StatusUnauthorized, errors.New("authentication required")
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		if !session.ValidCSRF(r.Header.Get(csrfHeaderName)) {
+			return nil, http.StatusForbidden, errors.New("missing or invalid CSRF token")
+		}
+	}
+	return user, 0, nil
+}
+
+// requestSession returns the session of the request's cookie, if any
+func (s *Server) requestSession(r *http.Request) *auth.Session {
+	if s.sessions == nil {
+		return nil
+	}
+	cookie, err := r.Cookie(sessionCookieName)
+	if err != nil {
+		return nil
+	}
+	return s.sessions.Get(cookie.Value)
+}
+
+// setSessionCookie sends the session cookie, or clears it for a nil session
+func (s *Server) setSessionCookie(w http.ResponseWriter, r *http.Request, session *auth.Session) {
+	cookie := &http.Cookie{
+		Name:     sessionCookieName,
+		Path:     s.publicBase(r) + "/",
+		HttpOnly: true,
+		Secure:   s.requestScheme(r) == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	if session != nil {
+		cookie.Value = session.ID
+	} else {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(w, cookie)
+}
+
+func authUserResponse(user *auth.User) *AuthUserResponse {
+	grants := user.Grants
+	if grants == nil {
+		grants = []auth.Grant{}
+	}
+	return &AuthUserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Grants:    grants,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// handleHealth reports that the server is up. It never requires a login.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, map[string]string{"status": "ok"})
+}
+
+// handleAuthSession returns the current user and CSRF token
+func (s *Server) handleAuthSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := AuthSessionResponse{AuthEnabled: s.auth != nil}
+	if s.auth != nil {
+		if session := s.requestSession(r); session != nil {
+			if user, err := s.auth.User(session.UserID); err == nil {
+				resp.User = authUserResponse(user)
+				resp.CSRFToken = session.CSRFToken
+			}
+		}
+	}
+	s.jsonResponse(w, resp)
+}
+
+// handleAuthLogin checks a username and password and starts a session
+func (s *Server) handleAuthLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.auth == nil {
+		s.jsonError(w, "Authentication is disabled", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.auth.Authenticate(req.Username, req.Password)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	session, err := s.sessions.Create(user.ID)
+	if err != nil {
+		s.jsonError(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+
+	s.setSessionCookie(w, r, session)
+	s.jsonResponse(w, AuthSessionResponse{
+		AuthEnabled: true,
+		User:        authUserResponse(user),
+		CSRFToken:   session.CSRFToken,
+	})
+}
+
+// handleAuthLogout ends the current session
+func (s *Server) handleAuthLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if session := s.requestSession(r); session != nil {
+		s.sessions.Delete(session.ID)
+	}
+	s.setSessionCookie(w, r, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleAuthPassword changes the current user's password. All of the user's
+// sessions end and a new one is started for this browser.
+func (s *Server) handleAuthPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.auth == nil {
+		s.jsonError(w, "Authentication is disabled", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user := auth.UserFromContext(r.Context())
+	if _, err := s.auth.Authenticate(user.Username, req.CurrentPassword); err != nil {
+		s.jsonError(w, "Current password is incorrect", http.StatusBadRequest)
+		return
+	}
+	if err := s.auth.SetPassword(user.ID, req.NewPassword); err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.sessions.DeleteUser(user.ID)
+	session, err := s.sessions.Create(user.ID)
+	if err != nil {
+		s.jsonError(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	s.setSessionCookie(w, r, session)
+	s.jsonResponse(w, AuthSessionResponse{
+		AuthEnabled: true,
+		User:        authUserResponse(user),
+		CSRFToken:   session.CSRFToken,
+	})
+}
+
+// handleAuthTokens lists and creates the current user's API tokens
+// GET /api/auth/tokens, POST /api/auth/tokens, DELETE /api/auth/tokens/{id}
+func (s *Server) handleAuthTokens(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		s.jsonError(w, "Authentication is disabled", http.StatusBadRequest)
+		return
+	}
+	user := auth.UserFromContext(r.Context())
+	tokenID := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/auth/tokens"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && tokenID == "":
+		tokens := make([]APITokenResponse, 0, len(user.Tokens))
+		for _, t := range user.Tokens {
+			tokens = append(tokens, APITokenResponse{ID: t.ID, Name: t.Name, Prefix: t.Prefix, CreatedAt: t.CreatedAt})
+		}
+		s.jsonResponse(w, tokens)
+
+	case r.Method == http.MethodPost && tokenID == "":
+		var req struct {
+			Name string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+			s.jsonError(w, "Token name is required", http.StatusBadRequest)
+			return
+		}
+		token, t, err := s.auth.CreateToken(user.ID, req.Name)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(APITokenResponse{ID: t.ID, Name: t.Name, Prefix: t.Prefix, CreatedAt: t.CreatedAt, Token: token})
+
+	case r.Method == http.MethodDelete && tokenID != "":
+		if err := s.auth.RevokeToken(user.ID, tokenID); err != nil {
+			s.jsonError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAuthCapabilities returns the current user's role and grants, so the
+// web UI can hide actions the user may not perform
+func (s *Server) handleAuthCapabilities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := AuthCapabilitiesResponse{AuthEnabled: s.auth != nil, Role: auth.RoleAdmin, Grants: []auth.Grant{}}
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		resp.Role = user.Role
+		if user.Grants != nil {
+			resp.Grants = user.Grants
+		}
+	}
+	s.jsonResponse(w, resp)
+}
+
+// handleAuthUsers manages user accounts (admins only)
+// GET/POST /api/auth/users, PUT/DELETE /api/auth/users/{id}
+func (s *Server) handleAuthUsers(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		s.jsonError(w, "Authentication is disabled", http.StatusBadRequest)
+		return
+	}
+	userID := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/api/auth/users"), "/")
+
+	switch {
+	case r.Method == http.MethodGet && userID == "":
+		users := s.auth.Users()
+		resp := make([]*AuthUserResponse, len(users))
+		for i := range users {
+			resp[i] = authUserResponse(&users[i])
+		}
+		s.jsonResponse(w, resp)
+
+	case r.Method == http.MethodPost && userID == "":
+		var req AuthUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		user, err := s.auth.CreateUser(req.Username, req.Password, req.Role, req.Grants)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(authUserResponse(user))
+
+	case r.Method == http.MethodPut && userID != "":
+		var req AuthUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		user, err := s.auth.SetAccess(userID, req.Role, req.Grants)
+		if err != nil {
+			s.jsonError(w, err.Error(), authErrorStatus(err))
+			return
+		}
+		if req.Password != "" {
+			if err := s.auth.SetPassword(userID, req.Password); err != nil {
+				s.jsonError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.sessions.DeleteUser(userID)
+		}
+		s.jsonResponse(w, authUserResponse(user))
+
+	case r.Method == http.MethodDelete && userID != "":
+		if err := s.auth.DeleteUser(userID); err != nil {
+			s.jsonError(w, err.Error(), authErrorStatus(err))
+			return
+		}
+		s.sessions.DeleteUser(userID)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// authErrorStatus maps user store errors to HTTP status codes
+func authErrorStatus(err error) int {
+	if errors.Is(err, auth.ErrUserNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
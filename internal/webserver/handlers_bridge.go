@@ -103,7 +103,7 @@ func (s *Server) handleCreateBridge(w http.ResponseWriter, r *http.Request) {
 	s.clientsMu.RUnlock()
 
 	// Verify GeoServer connection exists
-	client := s.getClient(req.GeoServerConnectionID)
+	client := s.getClient(r, req.GeoServerConnectionID)
 	if client == nil {
 		s.jsonError(w, "GeoServer connection not found", http.StatusNotFound)
 		return
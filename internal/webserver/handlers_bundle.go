@@ -0,0 +1,96 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/bundle"
+	"github.com/kartoza/kartoza-cloudbench/internal/geonode"
+	"github.com/kartoza/kartoza-cloudbench/internal/secrets"
+)
+
+// BundleImportRequest is the request body for importing a bundle
+type BundleImportRequest struct {
+	Bundle     *bundle.Bundle `json:"bundle"`
+	Passphrase string         `json:"passphrase,omitempty"`
+	OnConflict string         `json:"on_conflict,omitempty"`
+}
+
+// handleBundleExport exports connections and settings as a downloadable bundle
+func (s *Server) handleBundleExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var opts bundle.ExportOptions
+	if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	b, err := bundle.Export(s.config, opts)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filename := fmt.Sprintf("cloudbench-bundle-%s.json", time.Now().Format("20060102"))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	b.Write(w, bundle.FormatJSON)
+}
+
+// handleBundleImport merges a bundle into the configuration
+func (s *Server) handleBundleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BundleImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Bundle == nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := bundle.Import(s.config, req.Bundle, bundle.ImportOptions{
+		Passphrase: req.Passphrase,
+		OnConflict: req.OnConflict,
+		Actor:      s.auditActor(r, ""),
+	})
+	if errors.Is(err, secrets.ErrWrongPassphrase) {
+		s.jsonError(w, "Wrong bundle passphrase", http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.saveConfig(); err != nil {
+		s.jsonError(w, "Failed to save configuration", http.StatusInternalServerError)
+		return
+	}
+	s.refreshClients()
+
+	s.jsonResponse(w, result)
+}
+
+// refreshClients recreates the API clients after connections were imported
+func (s *Server) refreshClients() {
+	for i := range s.config.Connections {
+		s.addClient(&s.config.Connections[i])
+	}
+	for i := range s.config.S3Connections {
+		s.addS3Client(&s.config.S3Connections[i])
+	}
+
+	// GeoNode clients are created on first use
+	s.geonodeClientsMu.Lock()
+	s.geonodeClients = make(map[string]*geonode.Client)
+	s.geonodeClientsMu.Unlock()
+}
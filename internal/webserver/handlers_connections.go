@@ -10,13 +10,13 @@ import (
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 )
 
-// ConnectionResponse represents a connection in API responses
+// ConnectionResponse represents a connection in API responses. The password
+// is never returned; updates without one keep the stored password.
 type ConnectionResponse struct {
 	ID       string `json:"id"`
 	Name     string `json:"name"`
 	URL      string `json:"url"`
 	Username string `json:"username"`
-	Password string `json:"password"`
 	IsActive bool   `json:"isActive"`
 }
 
@@ -142,7 +142,6 @@ func (s *Server) listConnections(w http.ResponseWriter, r *http.Request) {
 			Name:     conn.Name,
 			URL:      conn.URL,
 			Username: conn.Username,
-			Password: conn.Password,
 			IsActive: conn.ID == s.config.ActiveConnection,
 		}
 	}
@@ -162,7 +161,6 @@ func (s *Server) getConnection(w http.ResponseWriter, r *http.Request, connID st
 		Name:     conn.Name,
 		URL:      conn.URL,
 		Username: conn.Username,
-		Password: conn.Password,
 		IsActive: conn.ID == s.config.ActiveConnection,
 	})
 }
@@ -269,6 +267,9 @@ func (s *Server) deleteConnection(w http.ResponseWriter, r *http.Request, connID
 		if s.config.Connections[i].ID == connID {
 			s.config.Connections = append(s.config.Connections[:i], s.config.Connections[i+1:]...)
 			s.removeClient(connID)
+			if store, err := api.OpenOfflineStore(connID); err == nil {
+				store.Remove()
+			}
 			if s.config.ActiveConnection == connID {
 				s.config.ActiveConnection = ""
 			}
@@ -292,7 +293,7 @@ func (s *Server) deleteConnection(w http.ResponseWriter, r *http.Request, connID
 
 // testConnection tests if a connection is valid
 func (s *Server) testConnection(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -317,7 +318,7 @@ func (s *Server) testConnection(w http.ResponseWriter, r *http.Request, connID s
 
 // getServerInfo returns server information for a connection
 func (s *Server) getServerInfo(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -131,6 +131,7 @@ func (s *Server) handleServerStatus(w http.ResponseWriter, r *http.Request) {
 // fetchServerStatus fetches the status for a single connection
 func fetchServerStatus(conn *config.Connection) ServerStatusResponse {
 	client := api.NewClient(conn)
+	client.SetResponseCache(api.SharedCache)
 
 	status := ServerStatusResponse{
 		ConnectionID:   conn.ID,
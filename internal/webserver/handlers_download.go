@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
 	"github.com/kartoza/kartoza-cloudbench/internal/cache"
 	"github.com/kartoza/kartoza-cloudbench/internal/sync"
 )
@@ -43,7 +44,7 @@ func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
 		name = parts[3]
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -121,7 +122,7 @@ func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
 		// Check cache first
 		if cache.DefaultManager != nil {
 			entry, _ := cache.DefaultManager.GetCacheEntry(connID, workspace, cache.ResourceTypeFeatureType, "", name)
-			if entry != nil && entry.DataFile != "" {
+			if cachedDataUsable(client, entry) && entry.DataFile != "" {
 				cachedData, cacheErr := cache.DefaultManager.ReadCachedData(entry)
 				if cacheErr == nil {
 					data = cachedData
@@ -151,7 +152,7 @@ func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
 		// Check cache first
 		if cache.DefaultManager != nil {
 			entry, _ := cache.DefaultManager.GetCacheEntry(connID, workspace, cache.ResourceTypeCoverage, "", name)
-			if entry != nil && entry.DataFile != "" {
+			if cachedDataUsable(client, entry) && entry.DataFile != "" {
 				cachedData, cacheErr := cache.DefaultManager.ReadCachedData(entry)
 				if cacheErr == nil {
 					data = cachedData
@@ -224,3 +225,14 @@ func (s *Server) handleDownloadLogs(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
 	w.Write([]byte(content))
 }
+
+// cachedDataUsable tells whether cached data can be served instead of
+// downloading it again. While the server is unreachable only the cached
+// files themselves are checked.
+func cachedDataUsable(client *api.Client, entry *cache.CacheEntry) bool {
+	valid, err := cache.DefaultManager.IsCacheValid(client, entry)
+	if store := client.Offline(); err != nil && store != nil && store.Status().Offline {
+		valid, _ = cache.DefaultManager.IsCacheValid(nil, entry)
+	}
+	return valid
+}
@@ -148,7 +148,7 @@ func (s *Server) createGeoNodeConnection(w http.ResponseWriter, r *http.Request)
 	}
 
 	s.config.AddGeoNodeConnection(conn)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -229,7 +229,7 @@ func (s *Server) updateGeoNodeConnection(w http.ResponseWriter, r *http.Request,
 	}
 
 	s.config.UpdateGeoNodeConnection(*conn)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -257,7 +257,7 @@ func (s *Server) deleteGeoNodeConnection(w http.ResponseWriter, r *http.Request,
 	}
 
 	s.config.RemoveGeoNodeConnection(connID)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -294,14 +294,15 @@ func (s *Server) testGeoNodeConnection(w http.ResponseWriter, r *http.Request, c
 	})
 }
 
-// getGeoNodeClient returns the client for a connection
-func (s *Server) getGeoNodeClient(connID string) *geonode.Client {
+// getGeoNodeClient returns the client for a connection, recording changes
+// in the audit log as the request's user
+func (s *Server) getGeoNodeClient(r *http.Request, connID string) *geonode.Client {
 	s.geonodeClientsMu.RLock()
 	client, ok := s.geonodeClients[connID]
 	s.geonodeClientsMu.RUnlock()
 
 	if ok {
-		return client
+		return client.WithAudit(s.auditActor(r, connID))
 	}
 
 	// Create client if not exists
@@ -315,12 +316,12 @@ func (s *Server) getGeoNodeClient(connID string) *geonode.Client {
 	s.geonodeClients[connID] = client
 	s.geonodeClientsMu.Unlock()
 
-	return client
+	return client.WithAudit(s.auditActor(r, connID))
 }
 
 // getGeoNodeResources returns all resources for a connection
 func (s *Server) getGeoNodeResources(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -345,7 +346,7 @@ func (s *Server) getGeoNodeResources(w http.ResponseWriter, r *http.Request, con
 
 // getGeoNodeDatasets returns datasets (layers) for a connection
 func (s *Server) getGeoNodeDatasets(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -369,7 +370,7 @@ func (s *Server) getGeoNodeDatasets(w http.ResponseWriter, r *http.Request, conn
 
 // getGeoNodeMaps returns maps for a connection
 func (s *Server) getGeoNodeMaps(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -393,7 +394,7 @@ func (s *Server) getGeoNodeMaps(w http.ResponseWriter, r *http.Request, connID s
 
 // getGeoNodeDocuments returns documents for a connection
 func (s *Server) getGeoNodeDocuments(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -417,7 +418,7 @@ func (s *Server) getGeoNodeDocuments(w http.ResponseWriter, r *http.Request, con
 
 // getGeoNodeGeoStories returns geostories for a connection
 func (s *Server) getGeoNodeGeoStories(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -441,7 +442,7 @@ func (s *Server) getGeoNodeGeoStories(w http.ResponseWriter, r *http.Request, co
 
 // getGeoNodeDashboards returns dashboards for a connection
 func (s *Server) getGeoNodeDashboards(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -513,7 +514,7 @@ func (s *Server) handleGeoNodeUpload(w http.ResponseWriter, r *http.Request, con
 		return
 	}
 
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -589,7 +590,7 @@ func (s *Server) handleGeoNodeDownload(w http.ResponseWriter, r *http.Request, c
 		return
 	}
 
-	client := s.getGeoNodeClient(connID)
+	client := s.getGeoNodeClient(r, connID)
 	if client == nil {
 		http.Error(w, "Connection not found", http.StatusNotFound)
 		return
@@ -1,13 +1,20 @@
 package webserver
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 	"github.com/kartoza/kartoza-cloudbench/internal/models"
 )
 
+// seedPollInterval is how often GeoWebCache is asked for seed progress
+const seedPollInterval = 2 * time.Second
+
 // GWCLayerResponse represents a cached layer in the API response
 type GWCLayerResponse struct {
 	Name        string   `json:"name"`
@@ -48,7 +55,7 @@ func (s *Server) handleGWCLayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -114,7 +121,7 @@ func (s *Server) handleGWCSeed(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -146,12 +153,22 @@ func (s *Server) handleGWCSeed(w http.ResponseWriter, r *http.Request) {
 
 // getSeedStatus returns the status of seed tasks for a layer
 func (s *Server) getSeedStatus(w http.ResponseWriter, r *http.Request, client *api.Client, layerName string) {
-	status, err := client.GetSeedStatus(layerName)
+	response, err := seedTasks(client, layerName)
 	if err != nil {
 		s.jsonError(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	s.jsonResponse(w, response)
+}
+
+// seedTasks fetches the seed tasks for a layer with their progress
+func seedTasks(client *api.Client, layerName string) ([]GWCSeedTaskResponse, error) {
+	status, err := client.GetSeedStatus(layerName)
+	if err != nil {
+		return nil, err
+	}
+
 	response := make([]GWCSeedTaskResponse, len(status.Tasks))
 	for i, task := range status.Tasks {
 		progress := 0.0
@@ -169,7 +186,7 @@ func (s *Server) getSeedStatus(w http.ResponseWriter, r *http.Request, client *a
 		}
 	}
 
-	s.jsonResponse(w, response)
+	return response, nil
 }
 
 // SeedRequest represents a seed request from the client
@@ -236,19 +253,81 @@ func (s *Server) startSeedOperation(w http.ResponseWriter, r *http.Request, clie
 		}
 	}
 
-	if err := client.SeedLayer(layerName, seedReq); err != nil {
-		s.jsonError(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
+	// Seeding runs as a background job, which waits while too many run
+	connID, _, _ := parsePathParams(r.URL.Path, "/api/gwc/seed")
+	job := s.jobs.Submit(jobs.Spec{
+		Kind:  jobs.KindSeed,
+		Title: fmt.Sprintf("%s %s", req.Type, layerName),
+		Details: map[string]string{
+			"connection_id": connID,
+			"layer":         layerName,
+			"type":          req.Type,
+		},
+	}, func(ctx context.Context, job *jobs.Job) error {
+		return s.runSeed(ctx, job, client, connID, layerName, seedReq)
+	})
 
 	s.jsonResponse(w, map[string]interface{}{
 		"success": true,
-		"message": "Seed operation started",
+		"message": "Seed operation queued",
 		"layer":   layerName,
 		"type":    req.Type,
+		"jobId":   job.ID(),
 	})
 }
 
+// runSeed starts a seed operation and follows its progress until no seed
+// task for the layer is pending or running. GeoWebCache has no push API, so
+// it is polled. Progress is published as seed events for {connId}/{layer}.
+func (s *Server) runSeed(ctx context.Context, job *jobs.Job, client *api.Client, connID, layerName string, req models.GWCSeedRequest) error {
+	eventID := connID + "/" + layerName
+
+	if err := client.SeedLayer(layerName, req); err != nil {
+		s.events.publish(EventComplete, JobTypeSeed, eventID, err.Error(), nil)
+		return err
+	}
+	job.SetMessage("Seed operation started")
+
+	ticker := time.NewTicker(seedPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := client.TerminateLayerSeedTasks(layerName); err != nil {
+				job.AddLog("Failed to terminate seed tasks: " + err.Error())
+			}
+			s.events.publish(EventComplete, JobTypeSeed, eventID, "Seeding cancelled", nil)
+			return jobs.ErrCancelled
+		case <-ticker.C:
+		}
+
+		tasks, err := seedTasks(client, layerName)
+		if err != nil {
+			s.events.publish(EventComplete, JobTypeSeed, eventID, err.Error(), nil)
+			return err
+		}
+
+		active := false
+		var done, total int64
+		for _, t := range tasks {
+			if t.Status == "Pending" || t.Status == "Running" {
+				active = true
+			}
+			done += t.TilesDone
+			total += t.TilesTotal
+		}
+		if total > 0 {
+			job.SetProgress(float64(done)/float64(total)*100, fmt.Sprintf("%d of %d tiles", done, total))
+		}
+		if !active {
+			s.events.publish(EventComplete, JobTypeSeed, eventID, "Seeding finished", tasks)
+			return nil
+		}
+		s.events.publish(EventProgress, JobTypeSeed, eventID, "", tasks)
+	}
+}
+
 // terminateLayerSeed terminates seed tasks for a specific layer
 func (s *Server) terminateLayerSeed(w http.ResponseWriter, r *http.Request, client *api.Client, layerName string) {
 	if err := client.TerminateLayerSeedTasks(layerName); err != nil {
@@ -291,7 +370,7 @@ func (s *Server) handleGWCTruncate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -369,7 +448,7 @@ func (s *Server) handleGWCGridSets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -443,7 +522,7 @@ func (s *Server) handleGWCDiskQuota(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -3,14 +3,15 @@ package webserver
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 	"github.com/kartoza/kartoza-cloudbench/internal/ogr2ogr"
 )
 
@@ -20,7 +21,7 @@ type ImportJob struct {
 	SourceFile  string    `json:"source_file"`
 	TargetTable string    `json:"target_table"`
 	Service     string    `json:"service"`
-	Status      string    `json:"status"` // "pending", "running", "completed", "failed"
+	Status      string    `json:"status"` // "pending", "running", "completed", "failed", "cancelled"
 	Progress    int       `json:"progress"`
 	Message     string    `json:"message"`
 	StartedAt   time.Time `json:"started_at"`
@@ -28,12 +29,35 @@ type ImportJob struct {
 	Error       string    `json:"error,omitempty"`
 }
 
-var (
-	importJobs   = make(map[string]*ImportJob)
-	importJobsMu sync.RWMutex
-	jobCounter   int
+// Details kept on import jobs
+const (
+	detailSourceFile  = "source_file"
+	detailTargetTable = "target_table"
+	detailService     = "service"
 )
 
+// importJob presents a background job as an import job
+func importJob(info jobs.Info) ImportJob {
+	job := ImportJob{
+		ID:          info.ID,
+		SourceFile:  info.Details[detailSourceFile],
+		TargetTable: info.Details[detailTargetTable],
+		Service:     info.Details[detailService],
+		Status:      string(info.Status),
+		Progress:    int(info.Progress),
+		Message:     info.Message,
+		StartedAt:   info.CreatedAt,
+		Error:       info.Error,
+	}
+	if info.CompletedAt != nil {
+		job.CompletedAt = *info.CompletedAt
+	}
+	if info.Status == jobs.StatusFailed {
+		job.Message = "Import failed"
+	}
+	return job
+}
+
 // ImportRequest represents a request to import data
 type ImportRequest struct {
 	SourceFile    string `json:"source_file"`     // Path to uploaded file or local file
@@ -84,24 +108,19 @@ func (s *Server) handlePGImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create import job
-	importJobsMu.Lock()
-	jobCounter++
+	// Start import as a background job
 	jobID := generateImportJobID()
-	job := &ImportJob{
-		ID:         jobID,
-		SourceFile: req.SourceFile,
-		Service:    req.TargetService,
-		Status:     "pending",
-		Progress:   0,
-		Message:    "Queued for import",
-		StartedAt:  time.Now(),
-	}
-	importJobs[jobID] = job
-	importJobsMu.Unlock()
-
-	// Start import in background
-	go s.runImportJob(job, req)
+	s.jobs.Submit(jobs.Spec{
+		ID:    jobID,
+		Kind:  jobs.KindImport,
+		Title: "Import " + filepath.Base(req.SourceFile),
+		Details: map[string]string{
+			detailSourceFile: req.SourceFile,
+			detailService:    req.TargetService,
+		},
+	}, func(ctx context.Context, job *jobs.Job) error {
+		return s.runImportJob(ctx, job, req)
+	})
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"job_id":  jobID,
@@ -111,20 +130,8 @@ func (s *Server) handlePGImport(w http.ResponseWriter, r *http.Request) {
 }
 
 // runImportJob executes the import job
-func (s *Server) runImportJob(job *ImportJob, req ImportRequest) {
-	updateJob := func(status string, progress int, message string, err string) {
-		importJobsMu.Lock()
-		job.Status = status
-		job.Progress = progress
-		job.Message = message
-		job.Error = err
-		if status == "completed" || status == "failed" {
-			job.CompletedAt = time.Now()
-		}
-		importJobsMu.Unlock()
-	}
-
-	updateJob("running", 0, "Starting import...", "")
+func (s *Server) runImportJob(ctx context.Context, job *jobs.Job, req ImportRequest) error {
+	job.SetMessage("Starting import...")
 
 	opts := ogr2ogr.ImportOptions{
 		SourceFile:    req.SourceFile,
@@ -144,14 +151,12 @@ func (s *Server) runImportJob(job *ImportJob, req ImportRequest) {
 
 	// Progress callback
 	progress := func(pct int, msg string) {
-		updateJob("running", pct, msg, "")
+		job.SetProgress(float64(pct), msg)
 	}
 
-	ctx := context.Background()
 	result, err := ogr2ogr.Import(ctx, opts, progress)
 	if err != nil {
-		updateJob("failed", 0, "Import failed", err.Error())
-		return
+		return err
 	}
 
 	if !result.Success {
@@ -159,15 +164,12 @@ func (s *Server) runImportJob(job *ImportJob, req ImportRequest) {
 		if len(result.Errors) > 0 {
 			errMsg = strings.Join(result.Errors, "; ")
 		}
-		updateJob("failed", 0, errMsg, errMsg)
-		return
+		return errors.New(errMsg)
 	}
 
-	importJobsMu.Lock()
-	job.TargetTable = result.TableName
-	importJobsMu.Unlock()
-
-	updateJob("completed", 100, "Import completed: "+result.TableName, "")
+	job.SetDetail(detailTargetTable, result.TableName)
+	job.SetMessage("Import completed: " + result.TableName)
+	return nil
 }
 
 // handlePGImportStatus handles GET /api/pg/import/{jobId} - get import job status
@@ -188,16 +190,13 @@ func (s *Server) handlePGImportStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	importJobsMu.RLock()
-	job, ok := importJobs[jobID]
-	importJobsMu.RUnlock()
-
-	if !ok {
+	job := s.jobs.Get(jobID)
+	if job == nil || job.Kind() != jobs.KindImport {
 		http.Error(w, "Job not found", http.StatusNotFound)
 		return
 	}
 
-	json.NewEncoder(w).Encode(job)
+	json.NewEncoder(w).Encode(importJob(job.Info()))
 }
 
 // handlePGDetectLayers handles POST /api/pg/detect-layers - detect layers in a file
@@ -399,24 +398,19 @@ func (s *Server) handlePGRasterImport(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Create import job
-	importJobsMu.Lock()
-	jobCounter++
+	// Start import as a background job
 	jobID := generateImportJobID()
-	job := &ImportJob{
-		ID:         jobID,
-		SourceFile: req.SourceFile,
-		Service:    req.TargetService,
-		Status:     "pending",
-		Progress:   0,
-		Message:    "Queued for raster import",
-		StartedAt:  time.Now(),
-	}
-	importJobs[jobID] = job
-	importJobsMu.Unlock()
-
-	// Start import in background
-	go s.runRasterImportJob(job, req)
+	s.jobs.Submit(jobs.Spec{
+		ID:    jobID,
+		Kind:  jobs.KindImport,
+		Title: "Raster import " + filepath.Base(req.SourceFile),
+		Details: map[string]string{
+			detailSourceFile: req.SourceFile,
+			detailService:    req.TargetService,
+		},
+	}, func(ctx context.Context, job *jobs.Job) error {
+		return s.runRasterImportJob(ctx, job, req)
+	})
 
 	json.NewEncoder(w).Encode(map[string]string{
 		"job_id":  jobID,
@@ -426,20 +420,8 @@ func (s *Server) handlePGRasterImport(w http.ResponseWriter, r *http.Request) {
 }
 
 // runRasterImportJob executes the raster import job
-func (s *Server) runRasterImportJob(job *ImportJob, req RasterImportRequest) {
-	updateJob := func(status string, progress int, message string, err string) {
-		importJobsMu.Lock()
-		job.Status = status
-		job.Progress = progress
-		job.Message = message
-		job.Error = err
-		if status == "completed" || status == "failed" {
-			job.CompletedAt = time.Now()
-		}
-		importJobsMu.Unlock()
-	}
-
-	updateJob("running", 0, "Starting raster import...", "")
+func (s *Server) runRasterImportJob(ctx context.Context, job *jobs.Job, req RasterImportRequest) error {
+	job.SetMessage("Starting raster import...")
 
 	opts := ogr2ogr.RasterImportOptions{
 		SourceFile:    req.SourceFile,
@@ -460,14 +442,12 @@ func (s *Server) runRasterImportJob(job *ImportJob, req RasterImportRequest) {
 
 	// Progress callback
 	progress := func(pct int, msg string) {
-		updateJob("running", pct, msg, "")
+		job.SetProgress(float64(pct), msg)
 	}
 
-	ctx := context.Background()
 	result, err := ogr2ogr.ImportRaster(ctx, opts, progress)
 	if err != nil {
-		updateJob("failed", 0, "Raster import failed", err.Error())
-		return
+		return err
 	}
 
 	if !result.Success {
@@ -475,15 +455,12 @@ func (s *Server) runRasterImportJob(job *ImportJob, req RasterImportRequest) {
 		if len(result.Errors) > 0 {
 			errMsg = strings.Join(result.Errors, "; ")
 		}
-		updateJob("failed", 0, errMsg, errMsg)
-		return
+		return errors.New(errMsg)
 	}
 
-	importJobsMu.Lock()
-	job.TargetTable = result.TableName
-	importJobsMu.Unlock()
-
-	updateJob("completed", 100, "Raster import completed: "+result.TableName, "")
+	job.SetDetail(detailTargetTable, result.TableName)
+	job.SetMessage("Raster import completed: " + result.TableName)
+	return nil
 }
 
 // generateImportJobID generates a unique import job ID
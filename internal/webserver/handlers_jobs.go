@@ -0,0 +1,103 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+)
+
+// canCancel reports whether a request may cancel a job. Jobs are cancelled
+// with the role that starts them: admin for syncs, editor for the rest, and
+// for seeding on the connection seeded.
+func (s *Server) canCancel(r *http.Request, info jobs.Info) bool {
+	user := auth.UserFromContext(r.Context())
+	if user == nil {
+		return true // Authentication is disabled
+	}
+	switch info.Kind {
+	case jobs.KindSync:
+		return user.Can(auth.RoleAdmin, "", "")
+	case jobs.KindSeed:
+		return user.Can(auth.RoleEditor, info.Details["connection_id"], "")
+	default:
+		return user.Can(auth.RoleEditor, "", "")
+	}
+}
+
+// handleJobs handles the background jobs of every kind
+// GET /api/jobs?kind=&status= - list jobs, newest first
+// DELETE /api/jobs?kind= - remove finished jobs
+// GET /api/jobs/{id} - get a job
+// POST /api/jobs/{id}/cancel - cancel a pending or running job
+// DELETE /api/jobs/{id} - remove a finished job
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/jobs"), "/")
+	if path == "" {
+		s.handleJobList(w, r)
+		return
+	}
+
+	id, action, _ := strings.Cut(path, "/")
+	job := s.jobs.Get(id)
+	if job == nil {
+		s.jsonError(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && action == "":
+		s.jsonResponse(w, job.Info())
+	case r.Method == http.MethodPost && action == "cancel":
+		if !s.canCancel(r, job.Info()) {
+			s.jsonError(w, "Forbidden: you may not cancel this job", http.StatusForbidden)
+			return
+		}
+		if !job.Cancel() {
+			s.jsonError(w, "Job has already finished", http.StatusConflict)
+			return
+		}
+		s.jsonResponse(w, job.Info())
+	case r.Method == http.MethodDelete && action == "":
+		if !s.jobs.Remove(id) {
+			s.jsonError(w, "Job is still pending or running", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleJobList lists jobs or removes finished ones
+func (s *Server) handleJobList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	kind := jobs.Kind(q.Get("kind"))
+	if kind != "" {
+		known := false
+		for _, k := range jobs.Kinds {
+			known = known || k == kind
+		}
+		if !known {
+			s.jsonError(w, "Unknown job kind", http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status := jobs.Status(q.Get("status"))
+		list := make([]jobs.Info, 0)
+		for _, info := range s.jobs.List(kind) {
+			if status == "" || info.Status == status {
+				list = append(list, info)
+			}
+		}
+		s.jsonResponse(w, list)
+	case http.MethodDelete:
+		s.jsonResponse(w, map[string]int{"removed": s.jobs.ClearFinished(kind)})
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
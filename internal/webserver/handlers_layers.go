@@ -39,7 +39,7 @@ func (s *Server) handleLayers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -187,7 +187,7 @@ func (s *Server) handleFeatureTypes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -262,7 +262,7 @@ func (s *Server) handleCoverages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -393,7 +393,7 @@ func (s *Server) handleLayerMetadata(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -557,7 +557,7 @@ func (s *Server) handleLayerStyles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -0,0 +1,349 @@
+package webserver
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/cache"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+	"github.com/kartoza/kartoza-cloudbench/internal/metrics"
+	"github.com/kartoza/kartoza-cloudbench/internal/postgres"
+)
+
+// jobDurationBuckets are histogram buckets in seconds for background jobs,
+// which run for seconds to hours
+var jobDurationBuckets = []float64{1, 5, 15, 60, 300, 900, 1800, 3600, 3 * 3600, 12 * 3600}
+
+// serverMetrics holds the metrics the web server accumulates between scrapes
+type serverMetrics struct {
+	requests *metrics.Histogram
+	jobs     *metrics.Histogram
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{
+		requests: metrics.NewHistogram("cloudbench_http_request_duration_seconds",
+			"Time taken to answer HTTP requests, by route pattern, method and status code",
+			metrics.DefaultBuckets, "route", "method", "code"),
+		jobs: metrics.NewHistogram("cloudbench_job_duration_seconds",
+			"Run time of finished background jobs, by kind and final status",
+			jobDurationBuckets, "kind", "status"),
+	}
+}
+
+// observeJobs records the run time of jobs as they finish
+func (m *serverMetrics) observeJobs(manager *jobs.Manager) {
+	manager.OnChange(func(info jobs.Info) {
+		if !info.Status.Done() || info.StartedAt == nil || info.CompletedAt == nil {
+			return
+		}
+		m.jobs.Observe(info.CompletedAt.Sub(*info.StartedAt).Seconds(), string(info.Kind), string(info.Status))
+	})
+}
+
+// metricsMiddleware times requests by the mux pattern that serves them, so
+// IDs in paths do not create a series each. Event streams are left out,
+// since they stay open for as long as the page does.
+func (s *Server) metricsMiddleware(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, pattern := mux.Handler(r)
+		if pattern == "/api/events" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		s.metrics.requests.Observe(time.Since(start).Seconds(), pattern, r.Method, strconv.Itoa(rec.status))
+	})
+}
+
+// statusRecorder remembers the status code written to a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack lets handlers take over the connection through the recorder
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := r.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("response does not support hijacking")
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// handleMetrics serves metrics about CloudBench and the GeoServer and
+// PostgreSQL servers it watches, in the Prometheus text format. The watched
+// servers are queried on every scrape, concurrently.
+// GET /metrics
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var (
+		wg        sync.WaitGroup
+		geoserver []ServerStatusResponse
+		pg        []pgMetrics
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		geoserver = s.collectGeoServerStatus()
+	}()
+	go func() {
+		defer wg.Done()
+		pg = collectPostgresStats()
+	}()
+	wg.Wait()
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+
+	// CloudBench itself
+	if s.metrics != nil {
+		s.metrics.requests.Write(w)
+		s.metrics.jobs.Write(w)
+	}
+	writeJobMetrics(w, s.jobs)
+	if cache.DefaultManager != nil {
+		if size, files, err := cache.DefaultManager.GetCacheStats(); err == nil {
+			metrics.WriteGauge(w, "cloudbench_cache_size_bytes", "Size of the local resource cache", metrics.Sample{Value: float64(size)})
+			metrics.WriteGauge(w, "cloudbench_cache_files", "Number of files in the local resource cache", metrics.Sample{Value: float64(files)})
+		}
+	}
+
+	stats := api.SharedCache.Stats()
+	metrics.WriteCounter(w, "cloudbench_rest_cache_hits_total", "GeoServer REST reads answered from the response cache", metrics.Sample{Value: float64(stats.Hits)})
+	metrics.WriteCounter(w, "cloudbench_rest_cache_misses_total", "Cacheable GeoServer REST reads sent to the server", metrics.Sample{Value: float64(stats.Misses)})
+	metrics.WriteCounter(w, "cloudbench_rest_cache_invalidations_total", "Cached REST responses dropped because of a change", metrics.Sample{Value: float64(stats.Invalidations)})
+	metrics.WriteGauge(w, "cloudbench_rest_cache_entries", "REST responses in the response cache", metrics.Sample{Value: float64(stats.Entries)})
+
+	writeGeoServerMetrics(w, geoserver)
+	writePostgresMetrics(w, pg)
+}
+
+// writeJobMetrics writes how many jobs there are of each kind and status
+func writeJobMetrics(w io.Writer, manager *jobs.Manager) {
+	counts := make(map[jobs.Kind]map[jobs.Status]int)
+	for _, kind := range jobs.Kinds {
+		counts[kind] = map[jobs.Status]int{
+			jobs.StatusPending: 0, jobs.StatusRunning: 0, jobs.StatusCompleted: 0,
+			jobs.StatusFailed: 0, jobs.StatusCancelled: 0,
+		}
+	}
+	for _, info := range manager.List("") {
+		if counts[info.Kind] == nil {
+			counts[info.Kind] = make(map[jobs.Status]int)
+		}
+		counts[info.Kind][info.Status]++
+	}
+
+	var samples []metrics.Sample
+	for kind, byStatus := range counts {
+		for status, n := range byStatus {
+			samples = append(samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "kind", Value: string(kind)}, {Name: "status", Value: string(status)}},
+				Value:  float64(n),
+			})
+		}
+	}
+	sortSamples(samples)
+	metrics.WriteGauge(w, "cloudbench_jobs", "Background jobs kept by CloudBench, by kind and status", samples...)
+}
+
+// collectGeoServerStatus fetches the status of every GeoServer connection
+func (s *Server) collectGeoServerStatus() []ServerStatusResponse {
+	s.clientsMu.RLock()
+	conns := append([]config.Connection(nil), s.config.Connections...)
+	s.clientsMu.RUnlock()
+
+	statuses := make([]ServerStatusResponse, len(conns))
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i] = fetchServerStatus(&conns[i])
+		}(i)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// writeGeoServerMetrics writes the status of the watched GeoServers
+func writeGeoServerMetrics(w io.Writer, statuses []ServerStatusResponse) {
+	gauge := func(name, help string, value func(ServerStatusResponse) float64, onlineOnly bool) {
+		var samples []metrics.Sample
+		for _, st := range statuses {
+			if onlineOnly && !st.Online {
+				continue
+			}
+			samples = append(samples, metrics.Sample{
+				Labels: []metrics.Label{{Name: "connection", Value: st.ConnectionID}, {Name: "name", Value: st.ConnectionName}},
+				Value:  value(st),
+			})
+		}
+		metrics.WriteGauge(w, name, help, samples...)
+	}
+
+	gauge("cloudbench_geoserver_up", "Whether the GeoServer answers its REST API",
+		func(st ServerStatusResponse) float64 { return boolValue(st.Online) }, false)
+	gauge("cloudbench_geoserver_response_time_seconds", "Time the GeoServer took to answer the status request",
+		func(st ServerStatusResponse) float64 { return float64(st.ResponseTimeMs) / 1000 }, true)
+	gauge("cloudbench_geoserver_jvm_memory_used_bytes", "JVM memory in use",
+		func(st ServerStatusResponse) float64 { return float64(st.MemoryUsed) }, true)
+	gauge("cloudbench_geoserver_jvm_memory_free_bytes", "JVM memory free",
+		func(st ServerStatusResponse) float64 { return float64(st.MemoryFree) }, true)
+	gauge("cloudbench_geoserver_jvm_memory_total_bytes", "JVM memory allocated",
+		func(st ServerStatusResponse) float64 { return float64(st.MemoryTotal) }, true)
+	gauge("cloudbench_geoserver_cpu_load", "System CPU load reported by the GeoServer",
+		func(st ServerStatusResponse) float64 { return st.CPULoad }, true)
+	gauge("cloudbench_geoserver_workspaces", "Number of workspaces",
+		func(st ServerStatusResponse) float64 { return float64(st.WorkspaceCount) }, true)
+	gauge("cloudbench_geoserver_layers", "Number of layers",
+		func(st ServerStatusResponse) float64 { return float64(st.LayerCount) }, true)
+	gauge("cloudbench_geoserver_datastores", "Number of data stores",
+		func(st ServerStatusResponse) float64 { return float64(st.DataStoreCount) }, true)
+	gauge("cloudbench_geoserver_coverage_stores", "Number of coverage stores",
+		func(st ServerStatusResponse) float64 { return float64(st.CoverageCount) }, true)
+	gauge("cloudbench_geoserver_styles", "Number of styles",
+		func(st ServerStatusResponse) float64 { return float64(st.StyleCount) }, true)
+}
+
+// pgMetrics is the outcome of querying one PostgreSQL service
+type pgMetrics struct {
+	service string
+	stats   *postgres.ServerStats // nil when the server could not be queried
+}
+
+// collectPostgresStats queries every visible pg_service.conf entry
+func collectPostgresStats() []pgMetrics {
+	services, err := postgres.ParsePGServiceFile()
+	if err != nil {
+		return nil
+	}
+
+	var results []pgMetrics
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, svc := range services {
+		if svc.Hidden {
+			continue
+		}
+		wg.Add(1)
+		go func(svc postgres.ServiceEntry) {
+			defer wg.Done()
+			stats, err := svc.GetServerStats()
+			if err != nil {
+				stats = nil
+			}
+			mu.Lock()
+			results = append(results, pgMetrics{service: svc.Name, stats: stats})
+			mu.Unlock()
+		}(svc)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].service < results[j].service })
+	return results
+}
+
+// writePostgresMetrics writes the status of the watched PostgreSQL servers
+func writePostgresMetrics(w io.Writer, results []pgMetrics) {
+	service := func(name string) []metrics.Label {
+		return []metrics.Label{{Name: "service", Value: name}}
+	}
+
+	var up, maxConns, conns, commits, rollbacks []metrics.Sample
+	for _, res := range results {
+		up = append(up, metrics.Sample{Labels: service(res.service), Value: boolValue(res.stats != nil)})
+		if res.stats == nil {
+			continue
+		}
+		st := res.stats
+		maxConns = append(maxConns, metrics.Sample{Labels: service(res.service), Value: float64(st.MaxConnections)})
+		for _, c := range []struct {
+			state string
+			n     int
+		}{
+			{"active", st.ActiveConns},
+			{"idle", st.IdleConns},
+			{"idle_in_transaction", st.IdleInTxConns},
+			{"waiting", st.WaitingConns},
+			{"total", st.CurrentConns},
+		} {
+			conns = append(conns, metrics.Sample{
+				Labels: append(service(res.service), metrics.Label{Name: "state", Value: c.state}),
+				Value:  float64(c.n),
+			})
+		}
+		commits = append(commits, metrics.Sample{Labels: service(res.service), Value: float64(st.XactCommit)})
+		rollbacks = append(rollbacks, metrics.Sample{Labels: service(res.service), Value: float64(st.XactRollback)})
+	}
+
+	metrics.WriteGauge(w, "cloudbench_postgres_up", "Whether the PostgreSQL service accepts connections", up...)
+	metrics.WriteGauge(w, "cloudbench_postgres_max_connections", "The server's max_connections setting", maxConns...)
+	metrics.WriteGauge(w, "cloudbench_postgres_connections", "Client connections by state; total counts every client backend", conns...)
+	metrics.WriteCounter(w, "cloudbench_postgres_transactions_committed_total", "Transactions committed in the service's database", commits...)
+	metrics.WriteCounter(w, "cloudbench_postgres_transactions_rolled_back_total", "Transactions rolled back in the service's database", rollbacks...)
+}
+
+// sortSamples orders samples by their label values, for a stable output
+func sortSamples(samples []metrics.Sample) {
+	key := func(s metrics.Sample) string {
+		values := make([]string, len(s.Labels))
+		for i, l := range s.Labels {
+			values[i] = l.Value
+		}
+		return strings.Join(values, "\xff")
+	}
+	sort.Slice(samples, func(i, j int) bool { return key(samples[i]) < key(samples[j]) })
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
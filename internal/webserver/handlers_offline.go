@@ -0,0 +1,132 @@
+package webserver
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+)
+
+// OfflineChange is a change made while a server was unreachable
+type OfflineChange struct {
+	Method   string    `json:"method"`
+	Path     string    `json:"path"` // REST path, e.g. /workspaces/topp
+	QueuedAt time.Time `json:"queuedAt"`
+}
+
+// OfflineConnectionStatus tells whether a connection's server is reachable
+// and what is queued for it
+type OfflineConnectionStatus struct {
+	ConnectionID string          `json:"connectionId"`
+	Offline      bool            `json:"offline"`
+	Since        *time.Time      `json:"since,omitempty"`    // When requests started failing
+	CachedAt     *time.Time      `json:"cachedAt,omitempty"` // Age of the catalog shown while offline
+	Queued       []OfflineChange `json:"queued"`
+}
+
+// OfflineReplayResponse reports a replay of queued changes
+type OfflineReplayResponse struct {
+	Replayed  int    `json:"replayed"`
+	Remaining int    `json:"remaining"`
+	Error     string `json:"error,omitempty"` // Why the first remaining change failed
+}
+
+// offlineStatus describes a client's offline store
+func offlineStatus(connID string, store *api.OfflineStore) OfflineConnectionStatus {
+	status := store.Status()
+	resp := OfflineConnectionStatus{ConnectionID: connID, Offline: status.Offline, Queued: []OfflineChange{}}
+	if status.Offline {
+		resp.Since = &status.Since
+		if !status.CachedAt.IsZero() {
+			resp.CachedAt = &status.CachedAt
+		}
+	}
+	if queued, err := store.Queued(); err == nil {
+		for _, c := range queued {
+			resp.Queued = append(resp.Queued, OfflineChange{Method: c.Method, Path: c.Path, QueuedAt: c.QueuedAt})
+		}
+	}
+	return resp
+}
+
+// handleOffline handles /api/offline (status of every connection),
+// /api/offline/{connId}/replay and /api/offline/{connId} (discard the queue)
+func (s *Server) handleOffline(w http.ResponseWriter, r *http.Request) {
+	connID, action, _ := parsePathParams(r.URL.Path, "/api/offline")
+
+	if connID == "" {
+		if r.Method != http.MethodGet {
+			s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		s.jsonResponse(w, s.offlineStatuses())
+		return
+	}
+
+	s.clientsMu.RLock()
+	client := s.clients[connID]
+	s.clientsMu.RUnlock()
+	if client == nil || client.Offline() == nil {
+		s.jsonError(w, "Connection not found", http.StatusNotFound)
+		return
+	}
+	store := client.Offline()
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		s.jsonResponse(w, offlineStatus(connID, store))
+	case action == "replay" && r.Method == http.MethodPost:
+		client = s.getClient(r, connID)
+		replayed, err := client.ReplayQueued()
+		resp := OfflineReplayResponse{Replayed: replayed}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if queued, qerr := store.Queued(); qerr == nil {
+			resp.Remaining = len(queued)
+		}
+		s.jsonResponse(w, resp)
+	case action == "" && r.Method == http.MethodDelete:
+		if err := store.DiscardQueued(); err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// offlineStatuses reports every connection, first checking whether the
+// servers known to be unreachable are back
+func (s *Server) offlineStatuses() []OfflineConnectionStatus {
+	s.clientsMu.RLock()
+	clients := make(map[string]*api.Client, len(s.clients))
+	for id, c := range s.clients {
+		if c.Offline() != nil {
+			clients[id] = c
+		}
+	}
+	s.clientsMu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		if c.Offline().Status().Offline {
+			wg.Add(1)
+			go func(c *api.Client) {
+				defer wg.Done()
+				c.TestConnection()
+			}(c)
+		}
+	}
+	wg.Wait()
+
+	statuses := make([]OfflineConnectionStatus, 0, len(clients))
+	for id, c := range clients {
+		statuses = append(statuses, offlineStatus(id, c.Offline()))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ConnectionID < statuses[j].ConnectionID })
+	return statuses
+}
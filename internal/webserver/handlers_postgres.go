@@ -2,9 +2,11 @@ package webserver
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
 	"github.com/kartoza/kartoza-cloudbench/internal/postgres"
 )
 
@@ -90,7 +92,7 @@ func (s *Server) handleCreatePGService(w http.ResponseWriter, r *http.Request) {
 		Options:  make(map[string]string),
 	}
 
-	if err := postgres.SaveServiceEntry(entry); err != nil {
+	if err := postgres.SaveServiceEntry(entry, s.auditActor(r, entry.Name)); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -254,9 +256,10 @@ func (s *Server) handleParsePGServiceByName(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Mark as parsed in config
-	s.config.SetPGServiceParsed(name, true)
-	if err := s.config.Save(); err != nil {
+	err = s.config.Update(func(c *config.Config) { c.SetPGServiceParsed(name, true) })
+	if err != nil {
 		// Log but don't fail - schema was harvested successfully
+		log.Printf("Failed to save parsed state of service %s: %v", name, err)
 	}
 
 	json.NewEncoder(w).Encode(cache)
@@ -438,14 +441,15 @@ func (s *Server) handleGetPGSchemasForCompletion(w http.ResponseWriter, r *http.
 
 // handleDeletePGServiceByName deletes a PostgreSQL service entry
 func (s *Server) handleDeletePGServiceByName(w http.ResponseWriter, r *http.Request, name string) {
-	if err := postgres.DeleteServiceEntry(name); err != nil {
+	if err := postgres.DeleteServiceEntry(name, s.auditActor(r, name)); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Remove from config state
-	s.config.RemovePGServiceState(name)
-	s.config.Save()
+	// Remove from config state; the service entry itself is already gone
+	if err := s.config.Update(func(c *config.Config) { c.RemovePGServiceState(name) }); err != nil {
+		log.Printf("Failed to save state of deleted service %s: %v", name, err)
+	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -463,7 +467,7 @@ func (s *Server) handleSetPGServiceHidden(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := postgres.SetServiceHidden(name, req.Hidden); err != nil {
+	if err := postgres.SetServiceHidden(name, req.Hidden, s.auditActor(r, name)); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
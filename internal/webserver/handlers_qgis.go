@@ -162,7 +162,7 @@ func (s *Server) createQGISProject(w http.ResponseWriter, r *http.Request) {
 
 	// Add to config and save
 	s.config.QGISProjects = append(s.config.QGISProjects, project)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		os.Remove(destPath) // Clean up on error
 		http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -228,7 +228,7 @@ func (s *Server) updateQGISProject(w http.ResponseWriter, r *http.Request, proje
 				s.config.QGISProjects[i].Size = info.Size()
 			}
 
-			if err := s.config.Save(); err != nil {
+			if err := s.saveConfig(); err != nil {
 				http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -259,7 +259,7 @@ func (s *Server) deleteQGISProject(w http.ResponseWriter, r *http.Request, proje
 			// Remove from slice
 			s.config.QGISProjects = append(s.config.QGISProjects[:i], s.config.QGISProjects[i+1:]...)
 
-			if err := s.config.Save(); err != nil {
+			if err := s.saveConfig(); err != nil {
 				http.Error(w, "Failed to save config: "+err.Error(), http.StatusInternalServerError)
 				return
 			}
@@ -39,6 +39,15 @@ func (s *Server) handleQueryBuilder(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// QueryExecuteRequest is the request body for executing a raw or visual query
+type QueryExecuteRequest struct {
+	SQL         string                `json:"sql"`        // Raw SQL query (optional)
+	Definition  query.QueryDefinition `json:"definition"` // Visual query definition (optional)
+	ServiceName string                `json:"service_name"`
+	MaxRows     int                   `json:"max_rows"`
+	Offset      int                   `json:"offset"`
+}
+
 // handleQueryExecute handles /api/query/execute - execute a visual query or raw SQL
 func (s *Server) handleQueryExecute(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -48,13 +57,7 @@ func (s *Server) handleQueryExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		SQL         string                `json:"sql"`         // Raw SQL query (optional)
-		Definition  query.QueryDefinition `json:"definition"`  // Visual query definition (optional)
-		ServiceName string                `json:"service_name"`
-		MaxRows     int                   `json:"max_rows"`
-		Offset      int                   `json:"offset"`
-	}
+	var req QueryExecuteRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
@@ -123,6 +126,13 @@ func (s *Server) handleQueryExecute(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// QuerySaveRequest is the request body for saving a visual query definition
+type QuerySaveRequest struct {
+	Name        string                `json:"name"`
+	ServiceName string                `json:"service_name"`
+	Definition  query.QueryDefinition `json:"definition"`
+}
+
 // handleQuerySave handles /api/query/save - save a query definition
 func (s *Server) handleQuerySave(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -132,11 +142,7 @@ func (s *Server) handleQuerySave(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req struct {
-		Name        string                `json:"name"`
-		ServiceName string                `json:"service_name"`
-		Definition  query.QueryDefinition `json:"definition"`
-	}
+	var req QuerySaveRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
@@ -151,7 +157,7 @@ func (s *Server) handleQuerySave(w http.ResponseWriter, r *http.Request) {
 	// Store the query definition in config
 	req.Definition.Name = req.Name
 	s.config.SaveQuery(req.ServiceName, req.Name, req.Definition)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		s.jsonError(w, "Failed to save query", http.StatusInternalServerError)
 		return
 	}
@@ -198,7 +204,7 @@ func (s *Server) handleQueryDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.config.DeleteQuery(serviceName, queryName)
-	if err := s.config.Save(); err != nil {
+	if err := s.saveConfig(); err != nil {
 		s.jsonError(w, "Failed to delete query", http.StatusInternalServerError)
 		return
 	}
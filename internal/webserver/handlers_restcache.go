@@ -0,0 +1,43 @@
+package webserver
+
+import (
+	"net/http"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+)
+
+// RESTCacheResponse describes the in-memory GeoServer REST response cache
+type RESTCacheResponse struct {
+	Enabled       bool           `json:"enabled"`
+	TTLSecs       int            `json:"ttlSecs"`
+	TTLs          map[string]int `json:"ttls,omitempty"` // By REST resource
+	Hits          uint64         `json:"hits"`
+	Misses        uint64         `json:"misses"`
+	Invalidations uint64         `json:"invalidations"`
+	Entries       int            `json:"entries"`
+	HitRate       float64        `json:"hitRate"`
+}
+
+// handleRESTCache handles /api/rest-cache: GET reports the cache's hit rate,
+// DELETE drops every cached response
+func (s *Server) handleRESTCache(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		stats := api.SharedCache.Stats()
+		s.jsonResponse(w, RESTCacheResponse{
+			Enabled:       api.SharedCache.Enabled(),
+			TTLSecs:       s.config.RESTCacheTTLSecs,
+			TTLs:          s.config.RESTCacheTTLs,
+			Hits:          stats.Hits,
+			Misses:        stats.Misses,
+			Invalidations: stats.Invalidations,
+			Entries:       stats.Entries,
+			HitRate:       stats.HitRate,
+		})
+	case http.MethodDelete:
+		api.SharedCache.Clear()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
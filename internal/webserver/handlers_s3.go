@@ -324,7 +324,7 @@ func (s *Server) deleteS3Connection(w http.ResponseWriter, r *http.Request, conn
 
 // testS3Connection tests an S3 connection
 func (s *Server) testS3Connection(w http.ResponseWriter, r *http.Request, connID string) {
-	client := s.getS3Client(connID)
+	client := s.getS3Client(r, connID)
 	if client == nil {
 		s.jsonError(w, "S3 connection not found", http.StatusNotFound)
 		return
@@ -351,7 +351,7 @@ func (s *Server) testS3Connection(w http.ResponseWriter, r *http.Request, connID
 
 // handleS3Buckets handles bucket operations
 func (s *Server) handleS3Buckets(w http.ResponseWriter, r *http.Request, connID string, pathParts []string) {
-	client := s.getS3Client(connID)
+	client := s.getS3Client(r, connID)
 	if client == nil {
 		s.jsonError(w, "S3 connection not found", http.StatusNotFound)
 		return
@@ -870,7 +870,7 @@ func (s *Server) handleS3Preview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getS3Client(connID)
+	client := s.getS3Client(r, connID)
 	if client == nil {
 		s.jsonError(w, "S3 connection not found", http.StatusNotFound)
 		return
@@ -887,7 +887,7 @@ func (s *Server) handleS3Preview(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build proxy URL (data served through our backend)
-	proxyURL := "/api/s3/proxy/" + connID + "/" + bucket + "?key=" + objectKey
+	proxyURL := s.publicBase(r) + "/api/s3/proxy/" + connID + "/" + bucket + "?key=" + objectKey
 
 	// Generate presigned URL for GDAL bounds extraction only (not returned to client)
 	presignedURL, _ := client.GetPresignedURL(ctx, bucket, objectKey, 1*time.Hour)
@@ -1096,7 +1096,7 @@ func (s *Server) handleS3Proxy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getS3Client(connID)
+	client := s.getS3Client(r, connID)
 	if client == nil {
 		s.jsonError(w, "S3 connection not found", http.StatusNotFound)
 		return
@@ -77,7 +77,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		client := s.getClient(conn.ID)
+		client := s.getClient(r, conn.ID)
 		if client == nil {
 			continue
 		}
@@ -251,7 +251,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Search S3 connections
-	s3Results := s.searchS3Entities(query)
+	s3Results := s.searchS3Entities(r, query)
 	results = append(results, s3Results...)
 
 	// Search QGIS projects
@@ -259,7 +259,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	results = append(results, qgisResults...)
 
 	// Search GeoNode connections and resources
-	geonodeResults := s.searchGeoNodeEntities(query)
+	geonodeResults := s.searchGeoNodeEntities(r, query)
 	results = append(results, geonodeResults...)
 
 	// Limit results
@@ -555,7 +555,7 @@ func (s *Server) searchPGFunctions(db *sql.DB, serviceName, query string) []Sear
 }
 
 // searchS3Entities searches S3 connections and buckets
-func (s *Server) searchS3Entities(query string) []SearchResult {
+func (s *Server) searchS3Entities(r *http.Request, query string) []SearchResult {
 	var results []SearchResult
 
 	for _, conn := range s.config.S3Connections {
@@ -573,7 +573,7 @@ func (s *Server) searchS3Entities(query string) []SearchResult {
 		}
 
 		// Try to list buckets and search them
-		client := s.getS3Client(conn.ID)
+		client := s.getS3Client(r, conn.ID)
 		if client == nil {
 			continue
 		}
@@ -626,7 +626,7 @@ func (s *Server) searchQGISProjects(query string) []SearchResult {
 }
 
 // searchGeoNodeEntities searches GeoNode connections and resources
-func (s *Server) searchGeoNodeEntities(query string) []SearchResult {
+func (s *Server) searchGeoNodeEntities(r *http.Request, query string) []SearchResult {
 	var results []SearchResult
 
 	for _, conn := range s.config.GeoNodeConnections {
@@ -645,7 +645,7 @@ func (s *Server) searchGeoNodeEntities(query string) []SearchResult {
 		}
 
 		// Get GeoNode client and search resources
-		client := s.getGeoNodeClient(conn.ID)
+		client := s.getGeoNodeClient(r, conn.ID)
 		if client == nil {
 			continue
 		}
@@ -55,7 +55,7 @@ func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -0,0 +1,176 @@
+package webserver
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/manifest"
+	"github.com/kartoza/kartoza-cloudbench/internal/snapshot"
+)
+
+// SnapshotTakeResponse is the result of taking a snapshot
+type SnapshotTakeResponse struct {
+	Snapshot snapshot.Snapshot `json:"snapshot"`
+	Created  bool              `json:"created"` // False when the catalog has not changed since the latest snapshot
+}
+
+// SnapshotRestoreRequest restores one object of a snapshot
+type SnapshotRestoreRequest struct {
+	Snapshot string `json:"snapshot"`
+	Path     string `json:"path"` // Catalog tree path of the object, as listed by the diff
+}
+
+// SnapshotRestoreResponse lists the changes a restore made
+type SnapshotRestoreResponse struct {
+	Changes []manifest.Change `json:"changes"`
+}
+
+// handleSnapshots handles requests to /api/snapshots/{connId},
+// /api/snapshots/{connId}/diff, /api/snapshots/{connId}/restore and
+// /api/snapshots/{connId}/{id}
+func (s *Server) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	connID, action, _ := parsePathParams(r.URL.Path, "/api/snapshots")
+	if connID == "" {
+		s.jsonError(w, "Connection ID is required", http.StatusBadRequest)
+		return
+	}
+	client := s.getClient(r, connID)
+	if client == nil {
+		s.jsonError(w, "Connection not found", http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		list, err := s.snapshots.List(connID)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, list)
+	case action == "" && r.Method == http.MethodPost:
+		snap, created, err := snapshot.Take(s.snapshots, connID, client, snapshot.TriggerManual)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		snap.Files = nil
+		s.jsonResponse(w, SnapshotTakeResponse{Snapshot: *snap, Created: created})
+	case action == "diff" && r.Method == http.MethodGet:
+		s.diffSnapshots(w, r, connID)
+	case action == "restore" && r.Method == http.MethodPost:
+		s.restoreSnapshotObject(w, r, connID, client)
+	case action != "" && r.Method == http.MethodDelete:
+		if err := s.snapshots.Delete(connID, action); err != nil {
+			s.snapshotError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// diffSnapshots lists the changes from the snapshot given by ?from= to the
+// one given by ?to=
+func (s *Server) diffSnapshots(w http.ResponseWriter, r *http.Request, connID string) {
+	from, err := s.snapshots.Get(connID, r.URL.Query().Get("from"))
+	if err != nil {
+		s.snapshotError(w, err)
+		return
+	}
+	to, err := s.snapshots.Get(connID, r.URL.Query().Get("to"))
+	if err != nil {
+		s.snapshotError(w, err)
+		return
+	}
+	changes, err := s.snapshots.Diff(from, to)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, changes)
+}
+
+// restoreSnapshotObject makes one object on the server match a snapshot
+func (s *Server) restoreSnapshotObject(w http.ResponseWriter, r *http.Request, connID string, client *api.Client) {
+	var req SnapshotRestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	snap, err := s.snapshots.Get(connID, req.Snapshot)
+	if err != nil {
+		s.snapshotError(w, err)
+		return
+	}
+	m, err := s.snapshots.Restore(snap, req.Path)
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plan, err := m.Plan(client, manifest.Options{Secret: s.secret, KeepLiveSecrets: true})
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := plan.Apply(client, nil); err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.jsonResponse(w, SnapshotRestoreResponse{Changes: plan.Changes})
+}
+
+// secret reads a secret referenced by a restored data store
+func (s *Server) secret(name string) (string, error) {
+	backend, err := s.config.OpenSecrets()
+	if err != nil {
+		return "", err
+	}
+	return backend.Get(name)
+}
+
+func (s *Server) snapshotError(w http.ResponseWriter, err error) {
+	if errors.Is(err, snapshot.ErrNotFound) {
+		s.jsonError(w, "Snapshot not found", http.StatusNotFound)
+		return
+	}
+	s.jsonError(w, err.Error(), http.StatusInternalServerError)
+}
+
+// scheduleSnapshots snapshots every GeoServer connection each
+// snapshot_hours until stop is closed. Snapshots are only stored when a
+// catalog has changed.
+func (s *Server) scheduleSnapshots(stop <-chan struct{}) {
+	hours := s.config.SnapshotHours
+	if hours <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(hours) * time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		s.clientsMu.RLock()
+		clients := make(map[string]*api.Client, len(s.clients))
+		for id, client := range s.clients {
+			clients[id] = client
+		}
+		s.clientsMu.RUnlock()
+
+		for connID, client := range clients {
+			if _, _, err := snapshot.Take(s.snapshots, connID, client, snapshot.TriggerScheduled); err != nil {
+				log.Printf("Scheduled snapshot of connection %s failed: %v", connID, err)
+			}
+		}
+	}
+}
@@ -43,23 +43,26 @@ func (s *Server) handleSQLView(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SQLViewCreateRequest is the request body for creating a SQL View layer
+type SQLViewCreateRequest struct {
+	ConnectionID    string                 `json:"connection_id"`
+	Workspace       string                 `json:"workspace"`
+	DataStore       string                 `json:"datastore"`
+	LayerName       string                 `json:"layer_name"`
+	Title           string                 `json:"title"`
+	Abstract        string                 `json:"abstract"`
+	SQL             string                 `json:"sql,omitempty"`
+	QueryDefinition *query.QueryDefinition `json:"query_definition,omitempty"`
+	GeometryColumn  string                 `json:"geometry_column"`
+	GeometryType    string                 `json:"geometry_type"`
+	SRID            int                    `json:"srid"`
+	KeyColumn       string                 `json:"key_column,omitempty"`
+	Parameters      []api.SQLViewParameter `json:"parameters,omitempty"`
+}
+
 // handleCreateSQLView creates a new SQL View layer
 func (s *Server) handleCreateSQLView(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		ConnectionID    string                  `json:"connection_id"`
-		Workspace       string                  `json:"workspace"`
-		DataStore       string                  `json:"datastore"`
-		LayerName       string                  `json:"layer_name"`
-		Title           string                  `json:"title"`
-		Abstract        string                  `json:"abstract"`
-		SQL             string                  `json:"sql,omitempty"`
-		QueryDefinition *query.QueryDefinition `json:"query_definition,omitempty"`
-		GeometryColumn  string                  `json:"geometry_column"`
-		GeometryType    string                  `json:"geometry_type"`
-		SRID            int                     `json:"srid"`
-		KeyColumn       string                  `json:"key_column,omitempty"`
-		Parameters      []api.SQLViewParameter  `json:"parameters,omitempty"`
-	}
+	var req SQLViewCreateRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
@@ -78,7 +81,7 @@ func (s *Server) handleCreateSQLView(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get the GeoServer client
-	client := s.getClient(req.ConnectionID)
+	client := s.getClient(r, req.ConnectionID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -113,20 +116,23 @@ func (s *Server) handleCreateSQLView(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// SQLViewUpdateRequest is the request body for updating a SQL View layer
+type SQLViewUpdateRequest struct {
+	DataStore       string                 `json:"datastore"`
+	Title           string                 `json:"title"`
+	Abstract        string                 `json:"abstract"`
+	SQL             string                 `json:"sql,omitempty"`
+	QueryDefinition *query.QueryDefinition `json:"query_definition,omitempty"`
+	GeometryColumn  string                 `json:"geometry_column"`
+	GeometryType    string                 `json:"geometry_type"`
+	SRID            int                    `json:"srid"`
+	KeyColumn       string                 `json:"key_column,omitempty"`
+	Parameters      []api.SQLViewParameter `json:"parameters,omitempty"`
+}
+
 // handleUpdateSQLView updates an existing SQL View layer
 func (s *Server) handleUpdateSQLView(w http.ResponseWriter, r *http.Request, connID, workspace, layerName string) {
-	var req struct {
-		DataStore       string                  `json:"datastore"`
-		Title           string                  `json:"title"`
-		Abstract        string                  `json:"abstract"`
-		SQL             string                  `json:"sql,omitempty"`
-		QueryDefinition *query.QueryDefinition `json:"query_definition,omitempty"`
-		GeometryColumn  string                  `json:"geometry_column"`
-		GeometryType    string                  `json:"geometry_type"`
-		SRID            int                     `json:"srid"`
-		KeyColumn       string                  `json:"key_column,omitempty"`
-		Parameters      []api.SQLViewParameter  `json:"parameters,omitempty"`
-	}
+	var req SQLViewUpdateRequest
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.jsonError(w, "Invalid request body", http.StatusBadRequest)
@@ -138,7 +144,7 @@ func (s *Server) handleUpdateSQLView(w http.ResponseWriter, r *http.Request, con
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -177,7 +183,7 @@ func (s *Server) handleDeleteSQLView(w http.ResponseWriter, r *http.Request, con
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -201,7 +207,7 @@ func (s *Server) handleListPostGISDataStores(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -51,7 +51,7 @@ func (s *Server) handleDataStores(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -294,7 +294,7 @@ func (s *Server) handleCoverageStores(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
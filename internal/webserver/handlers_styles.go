@@ -46,7 +46,7 @@ func (s *Server) handleStyles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -272,7 +272,7 @@ func (s *Server) handleLayerGroups(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
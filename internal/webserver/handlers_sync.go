@@ -7,25 +7,28 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 	"github.com/kartoza/kartoza-cloudbench/internal/sync"
 )
 
 // SyncConfigRequest represents a request to save/update a sync configuration
 type SyncConfigRequest struct {
-	ID       string             `json:"id,omitempty"`
-	Name     string             `json:"name"`
-	SourceID string             `json:"source_id"`
-	DestIDs  []string           `json:"destination_ids"`
-	Options  config.SyncOptions `json:"options"`
+	ID       string                   `json:"id,omitempty"`
+	Name     string                   `json:"name"`
+	SourceID string                   `json:"source_id"`
+	DestIDs  []string                 `json:"destination_ids"`
+	Options  config.SyncOptions       `json:"options"`
+	Mappings *config.SyncMappingRules `json:"mappings,omitempty"`
 }
 
 // StartSyncRequest represents a request to start syncing
 type StartSyncRequest struct {
-	ConfigID string              `json:"configId,omitempty"`        // Use saved config
-	SourceID string              `json:"sourceId,omitempty"`        // Or specify inline
-	DestIDs  []string            `json:"destinationIds,omitempty"`
-	Options  *config.SyncOptions `json:"options,omitempty"`
+	ConfigID string                   `json:"configId,omitempty"` // Use saved config
+	SourceID string                   `json:"sourceId,omitempty"` // Or specify inline
+	DestIDs  []string                 `json:"destinationIds,omitempty"`
+	Options  *config.SyncOptions      `json:"options,omitempty"`
+	Mappings *config.SyncMappingRules `json:"mappings,omitempty"`
 }
 
 // handleSyncConfigs handles sync configuration CRUD
@@ -81,23 +84,18 @@ func (s *Server) createSyncConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
 	syncCfg := config.SyncConfiguration{
 		ID:          uuid.New().String(),
 		Name:        req.Name,
 		SourceID:    req.SourceID,
 		DestIDs:     req.DestIDs,
 		SyncOptions: req.Options,
+		Mappings:    req.Mappings,
 		CreatedAt:   time.Now().Format(time.RFC3339),
 	}
 
-	cfg.AddSyncConfig(syncCfg)
-	if err := cfg.Save(); err != nil {
+	s.config.AddSyncConfig(syncCfg)
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -119,13 +117,7 @@ func (s *Server) updateSyncConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	existing := cfg.GetSyncConfig(req.ID)
+	existing := s.config.GetSyncConfig(req.ID)
 	if existing == nil {
 		http.Error(w, "Sync configuration not found", http.StatusNotFound)
 		return
@@ -137,12 +129,13 @@ func (s *Server) updateSyncConfig(w http.ResponseWriter, r *http.Request) {
 		SourceID:     req.SourceID,
 		DestIDs:      req.DestIDs,
 		SyncOptions:  req.Options,
+		Mappings:     req.Mappings,
 		CreatedAt:    existing.CreatedAt,
 		LastSyncedAt: existing.LastSyncedAt,
 	}
 
-	cfg.UpdateSyncConfig(syncCfg)
-	if err := cfg.Save(); err != nil {
+	s.config.UpdateSyncConfig(syncCfg)
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -158,14 +151,8 @@ func (s *Server) deleteSyncConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg, err := config.Load()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	cfg.RemoveSyncConfig(path)
-	if err := cfg.Save(); err != nil {
+	s.config.RemoveSyncConfig(path)
+	if err := s.saveConfig(); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -195,6 +182,7 @@ func (s *Server) handleSyncStart(w http.ResponseWriter, r *http.Request) {
 	var sourceID string
 	var destIDs []string
 	var options config.SyncOptions
+	var mappings *config.SyncMappingRules
 
 	if req.ConfigID != "" {
 		syncCfg := cfg.GetSyncConfig(req.ConfigID)
@@ -205,6 +193,7 @@ func (s *Server) handleSyncStart(w http.ResponseWriter, r *http.Request) {
 		sourceID = syncCfg.SourceID
 		destIDs = syncCfg.DestIDs
 		options = syncCfg.SyncOptions
+		mappings = syncCfg.Mappings
 	} else {
 		sourceID = req.SourceID
 		destIDs = req.DestIDs
@@ -213,6 +202,7 @@ func (s *Server) handleSyncStart(w http.ResponseWriter, r *http.Request) {
 		} else {
 			options = config.DefaultSyncOptions()
 		}
+		mappings = req.Mappings
 	}
 
 	if sourceID == "" || len(destIDs) == 0 {
@@ -235,7 +225,7 @@ func (s *Server) handleSyncStart(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 
-		task := sync.DefaultManager.StartSync(sourceConn, destConn, options, req.ConfigID)
+		task := sync.DefaultManager.StartSync(sourceConn, destConn, options, mappings, req.ConfigID, s.auditActor(r, ""))
 		tasks = append(tasks, task)
 	}
 
@@ -297,3 +287,45 @@ func (s *Server) handleSyncStop(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{"status": "stopped"})
 }
+
+// handleSyncResume resumes a stopped or failed task from its checkpoint
+func (s *Server) handleSyncResume(w http.ResponseWriter, r *http.Request) {
+	s.restartSyncTask(w, r, "/api/sync/resume/", sync.DefaultManager.ResumeTask)
+}
+
+// handleSyncRetry re-runs only the failed items of a task
+func (s *Server) handleSyncRetry(w http.ResponseWriter, r *http.Request) {
+	s.restartSyncTask(w, r, "/api/sync/retry/", sync.DefaultManager.RetryFailedItems)
+}
+
+func (s *Server) restartSyncTask(w http.ResponseWriter, r *http.Request, prefix string, restart func(*config.Config, string, *audit.Actor) (*sync.Task, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, prefix)
+	if id == "" {
+		http.Error(w, "Task ID required", http.StatusBadRequest)
+		return
+	}
+	if sync.DefaultManager.GetTask(id) == nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		http.Error(w, "Failed to load config", http.StatusInternalServerError)
+		return
+	}
+
+	task, err := restart(cfg, id, s.auditActor(r, ""))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(task)
+}
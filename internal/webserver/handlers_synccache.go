@@ -0,0 +1,94 @@
+package webserver
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/cache"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+)
+
+// SyncCacheServer is how much of the sync cache holds one server's data
+type SyncCacheServer struct {
+	ConnectionID string `json:"connectionId"`
+	Name         string `json:"name"` // Empty when the connection was deleted
+	Size         int64  `json:"size"`
+	Entries      int    `json:"entries"`
+	Pinned       int    `json:"pinned"` // Entries kept because a sync configuration reads them
+}
+
+// SyncCacheResponse describes the sync cache and its limits
+type SyncCacheResponse struct {
+	Dir        string            `json:"dir"`
+	Size       int64             `json:"size"`
+	MaxSize    int64             `json:"maxSize"` // 0 when unlimited
+	MaxAgeDays int               `json:"maxAgeDays"`
+	TTLHours   int               `json:"ttlHours"`
+	Servers    []SyncCacheServer `json:"servers"`
+}
+
+// SyncCacheEvictResponse reports what enforcing the cache limits removed
+type SyncCacheEvictResponse struct {
+	Removed int   `json:"removed"`
+	Freed   int64 `json:"freed"`
+}
+
+// handleSyncCache handles /api/sync/cache (usage), /api/sync/cache/evict
+// and /api/sync/cache/{connId} (clear one server's data)
+func (s *Server) handleSyncCache(w http.ResponseWriter, r *http.Request) {
+	if cache.DefaultManager == nil {
+		s.jsonError(w, "Cache is not available", http.StatusServiceUnavailable)
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		s.jsonError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	policy := cache.ConfigPolicy(cfg)
+	action := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/sync/cache"), "/")
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		usage, err := cache.DefaultManager.Usage(policy)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp := SyncCacheResponse{
+			Dir:        cache.DefaultManager.CacheDir(),
+			MaxSize:    policy.MaxSize,
+			MaxAgeDays: cfg.CacheMaxAgeDays,
+			TTLHours:   cfg.CacheTTLHours,
+			Servers:    make([]SyncCacheServer, 0, len(usage)),
+		}
+		for _, u := range usage {
+			server := SyncCacheServer{ConnectionID: u.ServerID, Size: u.Size, Entries: u.Entries, Pinned: u.Pinned}
+			if conn := cfg.GetConnection(u.ServerID); conn != nil {
+				server.Name = conn.Name
+			}
+			resp.Size += u.Size
+			resp.Servers = append(resp.Servers, server)
+		}
+		s.jsonResponse(w, resp)
+	case action == "evict" && r.Method == http.MethodPost:
+		result, err := cache.DefaultManager.Evict(policy)
+		if err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.jsonResponse(w, SyncCacheEvictResponse{Removed: result.Removed, Freed: result.Freed})
+	case action != "" && action != "evict" && r.Method == http.MethodDelete:
+		if strings.Contains(action, "/") || action == ".." {
+			s.jsonError(w, "Invalid connection ID", http.StatusBadRequest)
+			return
+		}
+		if err := cache.DefaultManager.ClearCache(action); err != nil {
+			s.jsonError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -26,7 +26,7 @@ func (s *Server) handleTerriaConnection(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -35,7 +35,7 @@ func (s *Server) handleTerriaConnection(w http.ResponseWriter, r *http.Request)
 
 	exporter := terria.NewExporter(client, conn)
 	// Set proxy URL based on request host
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	catalog, err := exporter.ExportConnection()
@@ -61,7 +61,7 @@ func (s *Server) handleTerriaWorkspace(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -69,7 +69,7 @@ func (s *Server) handleTerriaWorkspace(w http.ResponseWriter, r *http.Request) {
 	}
 
 	exporter := terria.NewExporter(client, conn)
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	catalog, err := exporter.ExportWorkspace(workspace)
@@ -95,7 +95,7 @@ func (s *Server) handleTerriaLayer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -103,7 +103,7 @@ func (s *Server) handleTerriaLayer(w http.ResponseWriter, r *http.Request) {
 	}
 
 	exporter := terria.NewExporter(client, conn)
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	catalog, err := exporter.ExportLayer(workspace, layer)
@@ -129,7 +129,7 @@ func (s *Server) handleTerriaLayerGroup(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -137,7 +137,7 @@ func (s *Server) handleTerriaLayerGroup(w http.ResponseWriter, r *http.Request)
 	}
 
 	exporter := terria.NewExporter(client, conn)
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	catalog, err := exporter.ExportLayerGroup(workspace, groupName)
@@ -163,7 +163,7 @@ func (s *Server) handleTerriaStory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -171,7 +171,7 @@ func (s *Server) handleTerriaStory(w http.ResponseWriter, r *http.Request) {
 	}
 
 	exporter := terria.NewExporter(client, conn)
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	initFile, err := exporter.ExportLayerGroupAsStory(workspace, groupName)
@@ -202,7 +202,7 @@ func (s *Server) handleTerriaInit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	connID := parts[0]
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -210,7 +210,7 @@ func (s *Server) handleTerriaInit(w http.ResponseWriter, r *http.Request) {
 	}
 
 	exporter := terria.NewExporter(client, conn)
-	proxyURL := fmt.Sprintf("http://%s/api/terria/proxy", r.Host)
+	proxyURL := s.externalURL(r) + "/api/terria/proxy"
 	exporter.SetProxyURL(proxyURL)
 
 	var members []terria.CatalogMember
@@ -354,7 +354,7 @@ func (s *Server) handleTerriaURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	connID := parts[0]
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -374,7 +374,7 @@ func (s *Server) handleTerriaURL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build the full Terria URL
-	baseURL := fmt.Sprintf("http://%s", r.Host)
+	baseURL := s.externalURL(r)
 	terriaURL := fmt.Sprintf("%s/#%s%s", baseURL, baseURL, initPath)
 
 	// Also provide NationalMap URL option
@@ -417,7 +417,7 @@ func (s *Server) handleTerriaDownload(w http.ResponseWriter, r *http.Request) {
 	}
 
 	connID := parts[0]
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	conn := s.getConnectionConfig(connID)
 	if client == nil || conn == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
@@ -43,7 +43,7 @@ func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(connID)
+	client := s.getClient(r, connID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -192,7 +192,7 @@ func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	client := s.getClient(req.ConnID)
+	client := s.getClient(r, req.ConnID)
 	if client == nil {
 		s.jsonError(w, "Connection not found", http.StatusNotFound)
 		return
@@ -229,23 +229,25 @@ func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
 		Password:     conn.Password,
 	}
 
-	// Start or update preview server
+	// The preview page is served by this server, under its base path, so it
+	// works behind a reverse proxy
 	if s.previewServer == nil {
 		s.previewServer = preview.NewServer()
 	}
-
-	// Start preview returns the URL
-	url, err := s.previewServer.Start(layerInfo)
-	if err != nil {
-		s.jsonError(w, fmt.Sprintf("Failed to start preview: %v", err), http.StatusInternalServerError)
-		return
-	}
+	s.previewServer.SetLayer(layerInfo)
 
 	s.jsonResponse(w, map[string]string{
-		"url": url,
+		"url": s.externalURL(r) + "/preview/",
 	})
 }
 
+// handlePreviewPage serves the layer preview page
+// GET /preview/
+func (s *Server) handlePreviewPage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(s.withBaseHref(r, preview.GetPreviewHTML())))
+}
+
 // handleLayerInfo handles layer info requests for the preview server
 // GET /api/layer - returns current layer configuration
 func (s *Server) handleLayerInfo(w http.ResponseWriter, r *http.Request) {
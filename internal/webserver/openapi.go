@@ -0,0 +1,795 @@
+package webserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	gosync "sync"
+	"time"
+	"unicode"
+
+	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/bundle"
+	"github.com/kartoza/kartoza-cloudbench/internal/cloudnative"
+	"github.com/kartoza/kartoza-cloudbench/internal/config"
+	"github.com/kartoza/kartoza-cloudbench/internal/geonode"
+	"github.com/kartoza/kartoza-cloudbench/internal/integration"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
+	"github.com/kartoza/kartoza-cloudbench/internal/llm"
+	"github.com/kartoza/kartoza-cloudbench/internal/models"
+	"github.com/kartoza/kartoza-cloudbench/internal/ogr2ogr"
+	"github.com/kartoza/kartoza-cloudbench/internal/postgres"
+	"github.com/kartoza/kartoza-cloudbench/internal/preview"
+	"github.com/kartoza/kartoza-cloudbench/internal/query"
+	"github.com/kartoza/kartoza-cloudbench/internal/snapshot"
+	"github.com/kartoza/kartoza-cloudbench/internal/sync"
+	"github.com/kartoza/kartoza-cloudbench/internal/terria"
+)
+
+// apiOperation describes one operation of the REST API for the OpenAPI
+// document. Request and response bodies are given as values of the Go types
+// the handlers decode and encode, so their schemas follow the code.
+type apiOperation struct {
+	Method     string
+	Path       string // Path template, e.g. /api/layers/{connId}/{workspace}
+	Summary    string
+	Query      []string // Query parameters
+	Request    any      // Request body, or a multipartForm for uploads
+	Response   any      // Response body; nil when there is none or it is not JSON
+	Status     int      // Success status, 200 when zero
+	Content    string   // Response media type when not JSON
+	AltContent string   // A second, non-JSON media type the response may have
+	Public     bool     // Reachable without logging in
+	Tag        string
+}
+
+// multipartForm describes a multipart/form-data request body
+type multipartForm struct {
+	Files  []string
+	Fields []string
+}
+
+// Response bodies the handlers build from maps
+type (
+	statusBody struct {
+		Status string `json:"status"`
+	}
+	successBody struct {
+		Success bool   `json:"success"`
+		Message string `json:"message,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	layerActionBody struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Layer   string `json:"layer"`
+	}
+	seedStartedBody struct {
+		Success bool   `json:"success"`
+		Message string `json:"message"`
+		Layer   string `json:"layer"`
+		Type    string `json:"type"`
+		JobID   string `json:"jobId"`
+	}
+	seedsTerminatedBody struct {
+		Success  bool   `json:"success"`
+		Message  string `json:"message"`
+		KillType string `json:"killType"`
+	}
+	resourceRef struct {
+		Name      string `json:"name"`
+		Workspace string `json:"workspace"`
+		Store     string `json:"store"`
+	}
+	publishResultBody struct {
+		Published []string `json:"published"`
+		Errors    []string `json:"errors"`
+	}
+	importStartedBody struct {
+		JobID   string `json:"job_id"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	importUploadBody struct {
+		FilePath string `json:"file_path"`
+		Filename string `json:"filename"`
+		Message  string `json:"message"`
+	}
+	ogr2ogrStatusBody struct {
+		Available           bool              `json:"available"`
+		Version             string            `json:"version"`
+		RasterAvailable     bool              `json:"raster_available"`
+		RasterVersion       string            `json:"raster_version"`
+		SupportedFormats    []string          `json:"supported_formats"`
+		SupportedExtensions map[string]string `json:"supported_extensions"`
+		VectorExtensions    map[string]string `json:"vector_extensions"`
+	}
+	s3UploadBody struct {
+		Success         bool     `json:"success"`
+		Key             string   `json:"key,omitempty"`
+		Size            int64    `json:"size,omitempty"`
+		Converted       bool     `json:"converted"`
+		Format          string   `json:"format,omitempty"`
+		GpkgExtracted   bool     `json:"gpkgExtracted,omitempty"`
+		LayerCount      int      `json:"layerCount,omitempty"`
+		Files           []string `json:"files,omitempty"`
+		CreateSubfolder bool     `json:"createSubfolder,omitempty"`
+	}
+	presignBody struct {
+		URL     string `json:"url"`
+		Expires string `json:"expires"`
+	}
+	geoNodeConnectionBody struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Username string `json:"username"`
+		HasToken bool   `json:"has_token,omitempty"`
+		IsActive bool   `json:"is_active,omitempty"`
+	}
+	geoNodeUploadBody struct {
+		Success bool   `json:"success"`
+		ID      int    `json:"id,omitempty"`
+		Status  string `json:"status,omitempty"`
+		State   string `json:"state,omitempty"`
+		URL     string `json:"url,omitempty"`
+		Message string `json:"message,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	geometryDetectionBody struct {
+		GeometryColumn string `json:"geometry_column"`
+		GeometryType   string `json:"geometry_type"`
+		SRID           int    `json:"srid"`
+		Detected       bool   `json:"detected"`
+		Error          string `json:"error,omitempty"`
+	}
+	terriaURLBody struct {
+		LocalTerriaURL  string `json:"localTerriaUrl"`
+		NationalMapURL  string `json:"nationalMapUrl"`
+		InitURL         string `json:"initUrl"`
+		CatalogEndpoint string `json:"catalogEndpoint"`
+	}
+	errorBody struct {
+		Error string `json:"error"`
+	}
+)
+
+// Request bodies the handlers decode into anonymous structs
+type (
+	nameBody struct {
+		Name string `json:"name"`
+	}
+	storeUpdateBody struct {
+		Name        string `json:"name"`
+		Enabled     bool   `json:"enabled"`
+		Description string `json:"description"`
+	}
+	truncateBody struct {
+		GridSetID string `json:"gridSetId"`
+		Format    string `json:"format"`
+		ZoomStart int    `json:"zoomStart"`
+		ZoomStop  int    `json:"zoomStop"`
+	}
+	loginBody struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	passwordChangeBody struct {
+		CurrentPassword string `json:"current_password"`
+		NewPassword     string `json:"new_password"`
+	}
+	filePathBody struct {
+		FilePath string `json:"file_path"`
+	}
+	sqlBody struct {
+		SQL         string `json:"sql"`
+		ServiceName string `json:"service_name"`
+		MaxRows     int    `json:"max_rows,omitempty"`
+	}
+	geometryDetectBody struct {
+		PGServiceName string `json:"pg_service_name"`
+		SQL           string `json:"sql"`
+	}
+	qgisProjectUpdateBody struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+	}
+	geoNodeConnectionRequest struct {
+		Name     string `json:"name,omitempty"`
+		URL      string `json:"url"`
+		Username string `json:"username,omitempty"`
+		Password string `json:"password,omitempty"`
+		Token    string `json:"token,omitempty"`
+		IsActive *bool  `json:"is_active,omitempty"`
+	}
+)
+
+// apiOperations lists every operation of the REST API. The conformance test
+// fails when a route registered in setupRoutes has no operation here.
+var apiOperations = concatOperations(
+	tagOperations("Health",
+		apiOperation{Method: http.MethodGet, Path: "/api/health", Summary: "Report that the server is up", Response: statusBody{}, Public: true},
+	),
+	tagOperations("Authentication",
+		apiOperation{Method: http.MethodGet, Path: "/api/auth/session", Summary: "Get the current user and CSRF token", Response: AuthSessionResponse{}, Public: true},
+		apiOperation{Method: http.MethodPost, Path: "/api/auth/login", Summary: "Log in and start a session", Request: loginBody{}, Response: AuthSessionResponse{}, Public: true},
+		apiOperation{Method: http.MethodPost, Path: "/api/auth/logout", Summary: "End the current session", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/auth/password", Summary: "Change the current user's password", Request: passwordChangeBody{}, Response: AuthSessionResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/auth/tokens", Summary: "List the current user's API tokens", Response: []APITokenResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/auth/tokens", Summary: "Create an API token; the secret is only returned once", Request: nameBody{}, Response: APITokenResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodDelete, Path: "/api/auth/tokens/{id}", Summary: "Revoke an API token", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/auth/capabilities", Summary: "Get the current user's role and grants", Response: AuthCapabilitiesResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/auth/users", Summary: "List user accounts", Response: []AuthUserResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/auth/users", Summary: "Create a user account", Request: AuthUserRequest{}, Response: AuthUserResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodPut, Path: "/api/auth/users/{id}", Summary: "Update a user account", Request: AuthUserRequest{}, Response: AuthUserResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/auth/users/{id}", Summary: "Delete a user account", Status: http.StatusNoContent},
+	),
+	tagOperations("Connections",
+		apiOperation{Method: http.MethodGet, Path: "/api/connections", Summary: "List GeoServer connections", Response: []ConnectionResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/connections", Summary: "Add a GeoServer connection", Request: ConnectionRequest{}, Response: ConnectionResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodPost, Path: "/api/connections/test", Summary: "Test credentials without saving them", Request: ConnectionRequest{}, Response: TestConnectionResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/connections/{id}", Summary: "Get a connection", Response: ConnectionResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/connections/{id}", Summary: "Update a connection", Request: ConnectionRequest{}, Response: ConnectionResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/connections/{id}", Summary: "Remove a connection", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/connections/{id}/test", Summary: "Test a saved connection", Response: TestConnectionResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/connections/{id}/info", Summary: "Get GeoServer version information", Response: api.ServerInfo{}},
+	),
+	tagOperations("Workspaces",
+		apiOperation{Method: http.MethodGet, Path: "/api/workspaces/{connId}", Summary: "List workspaces", Response: []WorkspaceResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/workspaces/{connId}", Summary: "Create a workspace", Request: WorkspaceCreateRequest{}, Response: WorkspaceResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/workspaces/{connId}/{workspace}", Summary: "Get a workspace's configuration", Response: WorkspaceConfigResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/workspaces/{connId}/{workspace}", Summary: "Update a workspace", Request: WorkspaceCreateRequest{}, Response: WorkspaceConfigResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/workspaces/{connId}/{workspace}", Summary: "Delete a workspace", Status: http.StatusNoContent},
+	),
+	tagOperations("Stores",
+		apiOperation{Method: http.MethodGet, Path: "/api/datastores/{connId}/{workspace}", Summary: "List data stores", Response: []DataStoreResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/datastores/{connId}/{workspace}", Summary: "Create a data store", Request: DataStoreCreateRequest{}, Response: DataStoreResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/datastores/{connId}/{workspace}/{store}", Summary: "Get a data store", Response: DataStoreResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/datastores/{connId}/{workspace}/{store}", Summary: "Update a data store", Request: storeUpdateBody{}, Response: DataStoreResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/datastores/{connId}/{workspace}/{store}", Summary: "Delete a data store", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/datastores/{connId}/{workspace}/{store}/available", Summary: "List the store's unpublished feature types", Response: map[string][]string{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/datastores/{connId}/{workspace}/{store}/publish", Summary: "Publish feature types of the store", Request: PublishFeatureTypeRequest{}, Response: publishResultBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/coveragestores/{connId}/{workspace}", Summary: "List coverage stores", Response: []CoverageStoreResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/coveragestores/{connId}/{workspace}", Summary: "Create a coverage store", Request: CoverageStoreCreateRequest{}, Response: CoverageStoreResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/coveragestores/{connId}/{workspace}/{store}", Summary: "Get a coverage store", Response: CoverageStoreResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/coveragestores/{connId}/{workspace}/{store}", Summary: "Update a coverage store", Request: storeUpdateBody{}, Response: CoverageStoreResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/coveragestores/{connId}/{workspace}/{store}", Summary: "Delete a coverage store", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/featuretypes/{connId}/{workspace}/{store}", Summary: "List a data store's feature types", Response: []resourceRef{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/featuretypes/{connId}/{workspace}/{store}", Summary: "Publish a feature type", Request: nameBody{}, Response: resourceRef{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/coverages/{connId}/{workspace}/{store}", Summary: "List a coverage store's coverages", Response: []resourceRef{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/coverages/{connId}/{workspace}/{store}", Summary: "Publish a coverage", Request: nameBody{}, Response: resourceRef{}, Status: http.StatusCreated},
+	),
+	tagOperations("Layers",
+		apiOperation{Method: http.MethodGet, Path: "/api/layers/{connId}/{workspace}", Summary: "List layers", Response: []LayerResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/layers/{connId}/{workspace}/{layer}", Summary: "Get a layer", Response: LayerResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/layers/{connId}/{workspace}/{layer}", Summary: "Update a layer", Request: LayerUpdateRequest{}, Response: LayerResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/layers/{connId}/{workspace}/{layer}", Summary: "Delete a layer", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/layers/{connId}/{workspace}/{layer}/count", Summary: "Count a vector layer's features; -1 when unknown", Response: map[string]int64{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/layermetadata/{connId}/{workspace}/{layer}", Summary: "Get a layer's full metadata", Response: LayerMetadataResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/layermetadata/{connId}/{workspace}/{layer}", Summary: "Update a layer's metadata", Request: LayerMetadataUpdateRequest{}, Response: LayerMetadataResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/layerstyles/{connId}/{workspace}/{layer}", Summary: "Get a layer's styles", Response: LayerStylesResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/layerstyles/{connId}/{workspace}/{layer}", Summary: "Set a layer's default and alternative styles", Request: LayerStylesUpdateRequest{}, Response: LayerStylesResponse{}},
+	),
+	tagOperations("Styles",
+		apiOperation{Method: http.MethodGet, Path: "/api/styles/{connId}/{workspace}", Summary: "List styles", Response: []StyleResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/styles/{connId}/{workspace}", Summary: "Create a style", Request: StyleCreateRequest{}, Response: StyleContentResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/styles/{connId}/{workspace}/{style}", Summary: "Get a style's content", Response: StyleContentResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/styles/{connId}/{workspace}/{style}", Summary: "Update a style's content", Request: StyleContentRequest{}, Response: StyleContentResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/styles/{connId}/{workspace}/{style}", Summary: "Delete a style", Status: http.StatusNoContent},
+	),
+	tagOperations("Layer groups",
+		apiOperation{Method: http.MethodGet, Path: "/api/layergroups/{connId}/{workspace}", Summary: "List layer groups", Response: []LayerGroupResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/layergroups/{connId}/{workspace}", Summary: "Create a layer group", Request: models.LayerGroupCreate{}, Response: LayerGroupResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/layergroups/{connId}/{workspace}/{group}", Summary: "Get a layer group", Response: LayerGroupDetailsResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/layergroups/{connId}/{workspace}/{group}", Summary: "Update a layer group", Request: LayerGroupUpdateRequest{}, Response: LayerGroupDetailsResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/layergroups/{connId}/{workspace}/{group}", Summary: "Delete a layer group", Status: http.StatusNoContent},
+	),
+	tagOperations("Upload and preview",
+		apiOperation{Method: http.MethodPost, Path: "/api/upload", Summary: "Upload a file to GeoServer", Query: []string{"connId", "workspace"}, Request: multipartForm{Files: []string{"file"}}, Response: UploadResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/preview", Summary: "Start a layer preview", Request: PreviewRequest{}, Response: map[string]string{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/layer", Summary: "Get the previewed layer", Response: preview.LayerInfo{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/metadata", Summary: "Get the previewed layer's metadata", Response: preview.ExtendedMetadata{}},
+	),
+	tagOperations("GeoWebCache",
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/layers/{connId}", Summary: "List cached layers", Response: []GWCLayerResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/layers/{connId}/{layer}", Summary: "Get a layer's cache configuration", Response: GWCLayerResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/seed/{connId}/{layer}", Summary: "Get a layer's seed tasks", Response: []GWCSeedTaskResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/gwc/seed/{connId}/{layer}", Summary: "Queue a seed, reseed or truncate job", Request: SeedRequest{}, Response: seedStartedBody{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/gwc/seed/{connId}/{layer}", Summary: "Stop a layer's seed tasks", Response: layerActionBody{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/gwc/seed/{connId}", Summary: "Stop all seed tasks", Query: []string{"type"}, Response: seedsTerminatedBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/gwc/truncate/{connId}/{layer}", Summary: "Truncate a layer's cache, all grid sets and formats unless given", Request: truncateBody{}, Response: layerActionBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/gridsets/{connId}", Summary: "List grid sets", Response: []GWCGridSetResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/gridsets/{connId}/{gridset}", Summary: "Get a grid set", Response: GWCGridSetResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/gwc/diskquota/{connId}", Summary: "Get the disk quota configuration", Response: GWCDiskQuotaResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/gwc/diskquota/{connId}", Summary: "Update the disk quota configuration", Request: models.GWCDiskQuota{}, Response: successBody{}},
+	),
+	tagOperations("Settings",
+		apiOperation{Method: http.MethodGet, Path: "/api/settings/{connId}", Summary: "Get GeoServer contact information", Response: ContactResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/settings/{connId}", Summary: "Update GeoServer contact information", Request: ContactUpdateRequest{}, Response: ContactResponse{}},
+	),
+	tagOperations("Jobs",
+		apiOperation{Method: http.MethodGet, Path: "/api/jobs", Summary: "List background jobs, newest first", Query: []string{"kind", "status"}, Response: []jobs.Info{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/jobs", Summary: "Remove finished jobs", Query: []string{"kind"}, Response: map[string]int{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/jobs/{id}", Summary: "Get a job", Response: jobs.Info{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/jobs/{id}/cancel", Summary: "Cancel a pending or running job", Response: jobs.Info{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/jobs/{id}", Summary: "Remove a finished job", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/events", Summary: "Stream job progress as Server-Sent Events", Query: []string{"type", "job", "lastEventId"}, Response: Event{}, Content: "text/event-stream"},
+	),
+	tagOperations("Sync",
+		apiOperation{Method: http.MethodGet, Path: "/api/sync/configs", Summary: "List sync configurations", Response: []config.SyncConfiguration{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/configs", Summary: "Create a sync configuration", Request: SyncConfigRequest{}, Response: config.SyncConfiguration{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodPut, Path: "/api/sync/configs", Summary: "Update the sync configuration with the body's id", Request: SyncConfigRequest{}, Response: config.SyncConfiguration{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/sync/configs/{id}", Summary: "Get a sync configuration", Response: config.SyncConfiguration{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/sync/configs/{id}", Summary: "Delete a sync configuration", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/start", Summary: "Start syncing to each destination", Request: StartSyncRequest{}, Response: []sync.Task{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/sync/status", Summary: "List sync tasks", Response: []sync.Task{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/sync/status/{id}", Summary: "Get a sync task", Response: sync.Task{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/stop", Summary: "Stop all sync tasks", Response: statusBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/stop/{id}", Summary: "Stop a sync task", Response: statusBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/resume/{id}", Summary: "Resume a stopped or failed task from its checkpoint", Response: sync.Task{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/retry/{id}", Summary: "Re-run a task's failed items", Response: sync.Task{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/sync/cache", Summary: "Show the sync cache's size per server and its limits", Response: SyncCacheResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sync/cache/evict", Summary: "Remove cached data beyond the size and age limits now", Response: SyncCacheEvictResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/sync/cache/{connId}", Summary: "Remove a server's cached data", Status: http.StatusNoContent},
+	),
+	tagOperations("Bundles",
+		apiOperation{Method: http.MethodPost, Path: "/api/bundle/export", Summary: "Export connections and settings as a bundle", Request: bundle.ExportOptions{}, Response: bundle.Bundle{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/bundle/import", Summary: "Merge a bundle into the configuration", Request: BundleImportRequest{}, Response: bundle.ImportResult{}},
+	),
+	tagOperations("Snapshots",
+		apiOperation{Method: http.MethodGet, Path: "/api/snapshots/{connId}", Summary: "List a connection's catalog snapshots, newest first", Response: []snapshot.Snapshot{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/snapshots/{connId}", Summary: "Snapshot the catalog, unless it is unchanged since the latest snapshot", Response: SnapshotTakeResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/snapshots/{connId}/diff", Summary: "List the objects added, removed and modified between two snapshots", Query: []string{"from", "to"}, Response: []snapshot.Change{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/snapshots/{connId}/restore", Summary: "Make one object match a snapshot", Request: SnapshotRestoreRequest{}, Response: SnapshotRestoreResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/snapshots/{connId}/{id}", Summary: "Delete a snapshot", Status: http.StatusNoContent},
+	),
+	tagOperations("Offline",
+		apiOperation{Method: http.MethodGet, Path: "/api/offline", Summary: "Tell which servers are unreachable and list their queued changes", Response: []OfflineConnectionStatus{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/offline/{connId}", Summary: "Get one connection's offline status", Response: OfflineConnectionStatus{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/offline/{connId}/replay", Summary: "Replay the changes queued while the server was unreachable", Response: OfflineReplayResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/offline/{connId}", Summary: "Discard the queued changes", Status: http.StatusNoContent},
+	),
+	tagOperations("Audit",
+		apiOperation{Method: http.MethodGet, Path: "/api/audit", Summary: "List audit records, newest first; format=csv exports them", Query: []string{"user", "via", "service", "action", "connection", "resource", "since", "until", "limit", "format"}, Response: []audit.Record{}, AltContent: "text/csv"},
+	),
+	tagOperations("Dashboard",
+		apiOperation{Method: http.MethodGet, Path: "/api/dashboard", Summary: "Get the status of every server", Response: DashboardResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/dashboard/server", Summary: "Get one server's status", Query: []string{"id"}, Response: ServerStatusResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/rest-cache", Summary: "Get the REST response cache's hit rate and TTLs", Response: RESTCacheResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/rest-cache", Summary: "Drop every cached REST response", Status: http.StatusNoContent},
+	),
+	tagOperations("Download",
+		apiOperation{Method: http.MethodGet, Path: "/api/download/{connId}/{resourceType}/{workspace}", Summary: "Download a workspace's configuration", Content: "application/octet-stream"},
+		apiOperation{Method: http.MethodGet, Path: "/api/download/{connId}/{resourceType}/{workspace}/{name}", Summary: "Download a datastore, coveragestore, layer, style, layergroup, shapefile or geotiff", Content: "application/octet-stream"},
+		apiOperation{Method: http.MethodGet, Path: "/api/download/logs/{taskId}", Summary: "Download a sync task's log", Content: "text/plain"},
+	),
+	tagOperations("Search",
+		apiOperation{Method: http.MethodGet, Path: "/api/search", Summary: "Search all connections", Query: []string{"q", "connection"}, Response: SearchResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/search/suggestions", Summary: "Get search suggestions", Response: map[string][]string{}},
+	),
+	tagOperations("Documentation",
+		apiOperation{Method: http.MethodGet, Path: "/api/docs", Summary: "Get the user documentation", Response: DocumentationResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/openapi.json", Summary: "Get this OpenAPI document", Response: map[string]any{}},
+	),
+	tagOperations("PostgreSQL",
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/services", Summary: "List pg_service.conf entries", Response: []PGServiceResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/services", Summary: "Add a pg_service.conf entry", Request: PGServiceCreateRequest{}, Response: PGServiceResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodDelete, Path: "/api/pg/services/{name}", Summary: "Delete a service entry", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/services/{name}/test", Summary: "Test a service's connection", Response: successBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/services/{name}/parse", Summary: "Harvest a service's schema", Response: postgres.SchemaCache{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/services/{name}/hide", Summary: "Hide or show a service", Request: PGServiceHideRequest{}, Response: map[string]bool{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/services/{name}/schema", Summary: "Get a service's harvested schema", Response: postgres.SchemaCache{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/services/{name}/schemas", Summary: "Get schemas for SQL completion", Response: map[string][]SchemaInfoForCompletion{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/services/{name}/stats", Summary: "Get server statistics", Response: postgres.ServerStats{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/services/{name}/schemastats", Summary: "Get a schema's statistics", Query: []string{"schema"}, Response: postgres.SchemaStats{}},
+	),
+	tagOperations("Import",
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/import", Summary: "Queue an ogr2ogr vector import", Request: ImportRequest{}, Response: importStartedBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/import/raster", Summary: "Queue a raster2pgsql raster import", Request: RasterImportRequest{}, Response: importStartedBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/import/upload", Summary: "Upload a file to import, with shapefile sidecars", Request: multipartForm{Files: []string{"file"}}, Response: importUploadBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/import/{jobId}", Summary: "Get an import job", Response: ImportJob{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/pg/detect-layers", Summary: "List the layers of a file", Request: filePathBody{}, Response: []ogr2ogr.LayerInfo{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/pg/ogr2ogr/status", Summary: "Check ogr2ogr and raster2pgsql availability", Response: ogr2ogrStatusBody{}},
+	),
+	tagOperations("Bridge",
+		apiOperation{Method: http.MethodPost, Path: "/api/bridge", Summary: "Publish PostgreSQL tables through a GeoServer data store", Request: BridgeCreateRequest{}, Response: BridgeResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/bridge/create", Summary: "Publish PostgreSQL tables through a GeoServer data store", Request: BridgeCreateRequest{}, Response: BridgeResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/bridge/tables", Summary: "List a service's tables", Query: []string{"service"}, Response: map[string]any{}},
+	),
+	tagOperations("AI",
+		apiOperation{Method: http.MethodPost, Path: "/api/ai/query", Summary: "Generate SQL from a question and optionally run it", Request: AIQueryRequest{}, Response: AIQueryResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/ai/explain", Summary: "Explain a SQL query", Request: sqlBody{}, Response: map[string]string{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/ai/execute", Summary: "Run a read-only SQL query", Request: sqlBody{}, Response: map[string]*llm.QueryResult{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/ai/providers", Summary: "List LLM providers", Response: map[string][]llm.ProviderStatus{}},
+	),
+	tagOperations("Query",
+		apiOperation{Method: http.MethodPost, Path: "/api/query/build", Summary: "Generate SQL from a visual query", Request: query.QueryDefinition{}, Response: map[string]any{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/query/execute", Summary: "Run a visual query or raw SQL", Request: QueryExecuteRequest{}, Response: map[string]*llm.QueryResult{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/query/save", Summary: "Save a visual query", Request: QuerySaveRequest{}, Response: successBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/query/list", Summary: "List saved queries", Query: []string{"service"}, Response: map[string][]config.SavedQuery{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/query/delete", Summary: "Delete a saved query", Query: []string{"service", "name"}, Status: http.StatusNoContent},
+	),
+	tagOperations("QGIS",
+		apiOperation{Method: http.MethodGet, Path: "/api/qgis/projects", Summary: "List QGIS projects", Response: []config.QGISProject{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/qgis/projects", Summary: "Upload a .qgs or .qgz project", Request: multipartForm{Files: []string{"file"}, Fields: []string{"name"}}, Response: config.QGISProject{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/qgis/projects/{id}", Summary: "Get a project", Response: config.QGISProject{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/qgis/projects/{id}", Summary: "Rename or move a project", Request: qgisProjectUpdateBody{}, Response: config.QGISProject{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/qgis/projects/{id}", Summary: "Remove a project from the list", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/qgis/projects/{id}/file", Summary: "Get the project file", Content: "application/xml", AltContent: "application/zip"},
+		apiOperation{Method: http.MethodGet, Path: "/api/qgis/projects/{id}/metadata", Summary: "Get the project's layers and extent", Response: QGISProjectMetadata{}},
+	),
+	tagOperations("S3",
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/connections", Summary: "List S3 connections", Response: []S3ConnectionResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/connections", Summary: "Add an S3 connection", Request: S3ConnectionRequest{}, Response: S3ConnectionResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/connections/test", Summary: "Test S3 credentials without saving them", Request: S3ConnectionRequest{}, Response: S3TestConnectionResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/connections/{id}", Summary: "Get an S3 connection", Response: S3ConnectionResponse{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/s3/connections/{id}", Summary: "Update an S3 connection", Request: S3ConnectionRequest{}, Response: S3ConnectionResponse{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/s3/connections/{id}", Summary: "Remove an S3 connection", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/connections/{id}/test", Summary: "Test a saved S3 connection", Response: S3TestConnectionResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/connections/{id}/buckets", Summary: "List buckets", Response: []S3BucketResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/connections/{id}/buckets", Summary: "Create a bucket", Request: nameBody{}, Response: S3BucketResponse{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodDelete, Path: "/api/s3/connections/{id}/buckets/{bucket}", Summary: "Delete a bucket", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/connections/{id}/buckets/{bucket}/objects", Summary: "List objects under a prefix", Query: []string{"prefix"}, Response: []S3ObjectResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/connections/{id}/buckets/{bucket}/objects", Summary: "Upload an object, optionally converting it to a cloud-native format", Request: multipartForm{Files: []string{"file"}, Fields: []string{"key", "prefix", "convert", "subfolder"}}, Response: s3UploadBody{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/s3/connections/{id}/buckets/{bucket}/objects", Summary: "Delete an object, or a folder when the key ends in /", Query: []string{"key"}, Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/connections/{id}/buckets/{bucket}/presign", Summary: "Get a presigned download URL valid for an hour", Query: []string{"key"}, Response: presignBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/conversion/tools", Summary: "Check the conversion tools", Response: map[string]cloudnative.ToolInfo{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/conversion/jobs", Summary: "List conversion jobs", Response: []*cloudnative.ConversionJob{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/conversion/jobs/{id}", Summary: "Get a conversion job", Response: cloudnative.ConversionJob{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/s3/conversion/jobs/{id}", Summary: "Remove a finished conversion job", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/s3/conversion/jobs/{id}/cancel", Summary: "Cancel a conversion job", Response: statusBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/preview/{connId}/{bucket}", Summary: "Get preview metadata for an object", Query: []string{"key"}, Response: S3PreviewMetadata{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/s3/proxy/{connId}/{bucket}", Summary: "Stream an object, honouring Range requests", Query: []string{"key"}, Content: "application/octet-stream"},
+	),
+	tagOperations("GeoNode",
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections", Summary: "List GeoNode connections", Response: []geoNodeConnectionBody{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/geonode/connections", Summary: "Add a GeoNode connection", Request: geoNodeConnectionRequest{}, Response: geoNodeConnectionBody{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodPost, Path: "/api/geonode/connections/test", Summary: "Test GeoNode credentials without saving them", Request: geoNodeConnectionRequest{}, Response: successBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}", Summary: "Get a GeoNode connection", Response: geoNodeConnectionBody{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/geonode/connections/{id}", Summary: "Update a GeoNode connection", Request: geoNodeConnectionRequest{}, Response: geoNodeConnectionBody{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/geonode/connections/{id}", Summary: "Remove a GeoNode connection", Status: http.StatusNoContent},
+		apiOperation{Method: http.MethodPost, Path: "/api/geonode/connections/{id}/test", Summary: "Test a saved GeoNode connection", Response: successBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/resources", Summary: "List resources", Query: []string{"type", "page", "page_size"}, Response: geonode.ResourcesResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/datasets", Summary: "List datasets", Query: []string{"page", "page_size"}, Response: geonode.DatasetsResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/maps", Summary: "List maps", Query: []string{"page", "page_size"}, Response: geonode.MapsResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/documents", Summary: "List documents", Query: []string{"page", "page_size"}, Response: geonode.DocumentsResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/geostories", Summary: "List GeoStories", Query: []string{"page", "page_size"}, Response: geonode.GeoStoriesResponse{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/dashboards", Summary: "List dashboards", Query: []string{"page", "page_size"}, Response: geonode.DashboardsResponse{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/geonode/connections/{id}/upload", Summary: "Upload a dataset", Request: multipartForm{Files: []string{"file"}, Fields: []string{"title", "abstract"}}, Response: geoNodeUploadBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/geonode/connections/{id}/download/{pk}/{alternate}", Summary: "Download a dataset, as GeoPackage unless another format is given", Query: []string{"format"}, Content: "application/octet-stream"},
+	),
+	tagOperations("SQL views",
+		apiOperation{Method: http.MethodPost, Path: "/api/sqlview", Summary: "Publish a SQL query as a GeoServer layer", Request: SQLViewCreateRequest{}, Response: integration.SQLViewLayerResult{}, Status: http.StatusCreated},
+		apiOperation{Method: http.MethodGet, Path: "/api/sqlview/datastores", Summary: "List PostGIS data stores", Query: []string{"connection", "workspace"}, Response: map[string][]string{}},
+		apiOperation{Method: http.MethodPost, Path: "/api/sqlview/detect", Summary: "Detect a query's geometry column, type and SRID", Request: geometryDetectBody{}, Response: geometryDetectionBody{}},
+		apiOperation{Method: http.MethodPut, Path: "/api/sqlview/{connId}/{workspace}/{layer}", Summary: "Update a SQL View layer", Request: SQLViewUpdateRequest{}, Response: integration.SQLViewLayerResult{}},
+		apiOperation{Method: http.MethodDelete, Path: "/api/sqlview/{connId}/{workspace}/{layer}", Summary: "Delete a SQL View layer", Query: []string{"datastore"}, Status: http.StatusNoContent},
+	),
+	tagOperations("Terria",
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/connection/{connId}", Summary: "Export a connection as a Terria catalog group", Response: terria.CatalogGroup{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/workspace/{connId}/{workspace}", Summary: "Export a workspace as a Terria catalog group", Response: terria.CatalogGroup{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/layer/{connId}/{workspace}/{layer}", Summary: "Export a layer as a Terria catalog item", Response: terria.WMSCatalogItem{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/layergroup/{connId}/{workspace}/{group}", Summary: "Export a layer group as a Terria catalog item", Response: terria.WMSCatalogItem{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/story/{connId}/{workspace}/{group}", Summary: "Export a layer group as a Terria story", Response: terria.InitFile{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/init/{file}", Summary: "Get a connection's Terria init file, {connId}.json", Response: terria.InitFile{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/init/{connId}/{file}", Summary: "Get a workspace's Terria init file, {workspace}.json", Response: terria.InitFile{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/proxy", Summary: "Proxy a request for Terria, avoiding CORS restrictions", Query: []string{"url"}, Content: "application/octet-stream"},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/url/{connId}", Summary: "Get Terria URLs for a connection", Response: terriaURLBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/url/{connId}/{workspace}", Summary: "Get Terria URLs for a workspace", Response: terriaURLBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/url/{connId}/{workspace}/{layer}", Summary: "Get Terria URLs for a layer", Response: terriaURLBody{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/download/{connId}", Summary: "Download a connection's Terria catalog", Response: terria.InitFile{}},
+		apiOperation{Method: http.MethodGet, Path: "/api/terria/download/{connId}/{workspace}", Summary: "Download a workspace's Terria catalog", Response: terria.InitFile{}},
+	),
+)
+
+// tagOperations puts operations under a tag
+func tagOperations(tag string, ops ...apiOperation) []apiOperation {
+	for i := range ops {
+		ops[i].Tag = tag
+	}
+	return ops
+}
+
+// concatOperations joins groups of operations
+func concatOperations(groups ...[]apiOperation) []apiOperation {
+	var all []apiOperation
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+var (
+	openAPIOnce gosync.Once
+	openAPIJSON []byte
+)
+
+// handleOpenAPI serves the OpenAPI 3 document describing the REST API
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	openAPIOnce.Do(func() {
+		openAPIJSON, _ = json.MarshalIndent(buildOpenAPI(apiOperations), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPIJSON)
+}
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// buildOpenAPI builds the OpenAPI document for a list of operations
+func buildOpenAPI(ops []apiOperation) map[string]any {
+	schemas := newSchemaBuilder()
+	errorRef := schemas.schema(reflect.TypeOf(errorBody{}))
+
+	paths := map[string]map[string]any{}
+	var tags []map[string]any
+	seenTags := map[string]bool{}
+	for _, op := range ops {
+		if !seenTags[op.Tag] {
+			seenTags[op.Tag] = true
+			tags = append(tags, map[string]any{"name": op.Tag})
+		}
+
+		var params []map[string]any
+		for _, m := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+			params = append(params, map[string]any{
+				"name": m[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+			})
+		}
+		for _, q := range op.Query {
+			params = append(params, map[string]any{
+				"name": q, "in": "query", "schema": map[string]any{"type": "string"},
+			})
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := map[string]any{"description": http.StatusText(status)}
+		content := map[string]any{}
+		switch {
+		case op.Response != nil && op.Content == "":
+			content["application/json"] = map[string]any{"schema": schemas.schema(reflect.TypeOf(op.Response))}
+		case op.Response != nil:
+			content[op.Content] = map[string]any{"schema": schemas.schema(reflect.TypeOf(op.Response))}
+		case op.Content != "":
+			content[op.Content] = map[string]any{"schema": rawSchema(op.Content)}
+		}
+		if op.AltContent != "" {
+			content[op.AltContent] = map[string]any{"schema": rawSchema(op.AltContent)}
+		}
+		if len(content) > 0 {
+			success["content"] = content
+		}
+
+		operation := map[string]any{
+			"operationId": operationID(op.Method, op.Path),
+			"summary":     op.Summary,
+			"tags":        []string{op.Tag},
+			"responses": map[string]any{
+				itoa(status): success,
+				"default": map[string]any{
+					"description": "Error",
+					"content":     map[string]any{"application/json": map[string]any{"schema": errorRef}},
+				},
+			},
+		}
+		if len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Public {
+			operation["security"] = []any{}
+		}
+		switch body := op.Request.(type) {
+		case nil:
+		case multipartForm:
+			props := map[string]any{}
+			for _, f := range body.Files {
+				props[f] = map[string]any{"type": "string", "format": "binary"}
+			}
+			for _, f := range body.Fields {
+				props[f] = map[string]any{"type": "string"}
+			}
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{"multipart/form-data": map[string]any{
+					"schema": map[string]any{"type": "object", "properties": props, "required": body.Files},
+				}},
+			}
+		default:
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": schemas.schema(reflect.TypeOf(body))}},
+			}
+		}
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Kartoza CloudBench API",
+			"version":     "1",
+			"description": "REST API of the CloudBench web server. When authentication is enabled, requests need a session cookie (plus the " + csrfHeaderName + " header on changes) or a personal API token.",
+		},
+		"tags":  tags,
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth":    map[string]any{"type": "http", "scheme": "bearer", "description": "Personal API token"},
+				"sessionCookie": map[string]any{"type": "apiKey", "in": "cookie", "name": sessionCookieName},
+			},
+		},
+		"security": []any{
+			map[string]any{"bearerAuth": []string{}},
+			map[string]any{"sessionCookie": []string{}},
+		},
+	}
+}
+
+// rawSchema is the schema of a non-JSON response body
+func rawSchema(mediaType string) map[string]any {
+	if strings.HasPrefix(mediaType, "text/") {
+		return map[string]any{"type": "string"}
+	}
+	return map[string]any{"type": "string", "format": "binary"}
+}
+
+// operationID derives an operation ID from the method and path, e.g.
+// GET /api/layers/{connId}/{workspace} becomes getLayersByConnIdByWorkspace
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, part := range strings.Split(strings.TrimPrefix(path, "/api/"), "/") {
+		if strings.HasPrefix(part, "{") {
+			b.WriteString("By")
+			part = strings.Trim(part, "{}")
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			b.WriteString(upperFirst(word))
+		}
+	}
+	return b.String()
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func itoa(n int) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
+
+// schemaBuilder derives JSON schemas from Go types the way encoding/json
+// encodes them. Named struct types become shared components.
+type schemaBuilder struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	rawJSONType   = reflect.TypeOf(json.RawMessage{})
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema returns the schema of a type, or a reference to its component
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == rawJSONType:
+		return map[string]any{}
+	case t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType):
+		return map[string]any{"description": "Custom JSON encoding"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		name, ok := b.names[t]
+		if !ok {
+			name = b.componentName(t)
+			b.names[t] = name
+			b.components[name] = map[string]any{} // Placeholder for recursive types
+			b.components[name] = b.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		return map[string]any{} // Interfaces hold any value
+	}
+}
+
+// componentName names a type's component, qualifying it with its package
+// when another package has a type of the same name
+func (b *schemaBuilder) componentName(t reflect.Type) string {
+	name := upperFirst(t.Name())
+	if _, taken := b.components[name]; !taken {
+		return name
+	}
+	pkg := t.PkgPath()
+	return upperFirst(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+}
+
+// structSchema returns the object schema of a struct's JSON fields
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	b.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				b.addFields(ft, props) // Embedded fields are promoted
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if strings.Contains(opts, "string") {
+			props[name] = map[string]any{"type": "string"}
+			continue
+		}
+		props[name] = b.schema(f.Type)
+	}
+}
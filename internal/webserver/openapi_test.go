@@ -0,0 +1,137 @@
+package webserver
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// prefixRoutes are the API routes registered with a trailing slash on
+// purpose: their handlers parse the rest of the path, which the document
+// describes as the longer paths beneath them
+var prefixRoutes = []string{
+	"/api/auth/tokens/", "/api/auth/users/", "/api/connections/", "/api/workspaces/",
+	"/api/datastores/", "/api/coveragestores/", "/api/layers/", "/api/layermetadata/",
+	"/api/layerstyles/", "/api/styles/", "/api/layergroups/", "/api/featuretypes/",
+	"/api/coverages/", "/api/gwc/layers/", "/api/gwc/seed/", "/api/gwc/truncate/",
+	"/api/gwc/gridsets/", "/api/gwc/diskquota/", "/api/settings/", "/api/jobs/",
+	"/api/sync/configs/", "/api/sync/status/", "/api/sync/stop/", "/api/sync/resume/",
+	"/api/sync/retry/", "/api/sync/cache/", "/api/snapshots/", "/api/offline/",
+	"/api/download/logs/", "/api/download/", "/api/pg/services/", "/api/s3/connections/",
+	"/api/s3/conversion/jobs/", "/api/s3/preview/", "/api/s3/proxy/", "/api/pg/import/",
+	"/api/bridge/", "/api/ai/", "/api/query/", "/api/qgis/projects/", "/api/geonode/connections/",
+	"/api/sqlview/", "/api/terria/connection/", "/api/terria/workspace/", "/api/terria/layer/",
+	"/api/terria/layergroup/", "/api/terria/story/", "/api/terria/init/", "/api/terria/url/",
+	"/api/terria/download/",
+}
+
+// TestOpenAPICoversRoutes fails when a registered API route, or an exported
+// request or response type, is missing from the OpenAPI document
+func TestOpenAPICoversRoutes(t *testing.T) {
+	s := &Server{}
+	s.setupRoutes(http.NewServeMux())
+
+	rec := httptest.NewRecorder()
+	s.handleOpenAPI(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI    string                     `json:"openapi"`
+		Paths      map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("unexpected OpenAPI version %q", doc.OpenAPI)
+	}
+
+	// Every registered route is a documented path, except for the prefix
+	// routes, which need a documented path beneath them
+	prefixes := map[string]bool{}
+	for _, pattern := range prefixRoutes {
+		prefixes[pattern] = true
+	}
+	registered := map[string]bool{}
+	for _, pattern := range s.routes {
+		if !strings.HasPrefix(pattern, "/api/") {
+			continue
+		}
+		registered[pattern] = true
+		if _, ok := doc.Paths[pattern]; ok {
+			continue
+		}
+		if !prefixes[pattern] {
+			t.Errorf("route %s is not in the OpenAPI document", pattern)
+			continue
+		}
+		covered := false
+		for path := range doc.Paths {
+			if strings.HasPrefix(path, pattern) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			t.Errorf("prefix route %s has no documented path beneath it", pattern)
+		}
+	}
+	for _, pattern := range prefixRoutes {
+		if !registered[pattern] {
+			t.Errorf("prefix route %s is not registered", pattern)
+		}
+	}
+
+	// Every path in the spec is served by a registered route
+	for path := range doc.Paths {
+		served := registered[path]
+		for _, pattern := range prefixRoutes {
+			if strings.HasPrefix(path, pattern) {
+				served = true
+			}
+		}
+		if !served {
+			t.Errorf("documented path %s has no registered route", path)
+		}
+	}
+
+	// Every exported request and response type is a schema component
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		t.Fatalf("parsing package: %v", err)
+	}
+	bodyType := regexp.MustCompile(`^[A-Z]\w*(Request|Response)$`)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if _, isStruct := ts.Type.(*ast.StructType); !isStruct || !bodyType.MatchString(ts.Name.Name) {
+						continue
+					}
+					if _, ok := doc.Components.Schemas[ts.Name.Name]; !ok {
+						t.Errorf("type %s is not in the OpenAPI document", ts.Name.Name)
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package webserver
+
+import (
+	"html"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// SetBasePath serves the UI and API under a URL prefix such as /cloudbench,
+// for running behind a reverse proxy that forwards that prefix unchanged
+func (s *Server) SetBasePath(basePath string) {
+	s.basePath = normalizeBasePath(basePath)
+}
+
+// SetTrustProxy honours the X-Forwarded-For, -Proto, -Host and -Prefix
+// headers. Only enable it when every request arrives through a proxy that
+// sets them, since clients can send them too.
+func (s *Server) SetTrustProxy(trust bool) {
+	s.trustProxy = trust
+}
+
+// normalizeBasePath turns "cloudbench/" or "/cloudbench/" into "/cloudbench",
+// and "/" into ""
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(strings.TrimSpace(basePath), "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// basePathMiddleware strips the base path so routes see paths from /.
+// The base path itself redirects to its trailing-slash form, and requests
+// outside it are not found.
+func (s *Server) basePathMiddleware(next http.Handler) http.Handler {
+	if s.basePath == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == s.basePath {
+			http.Redirect(w, r, s.publicBase(r)+"/", http.StatusMovedPermanently)
+			return
+		}
+		rest, ok := strings.CutPrefix(r.URL.Path, s.basePath+"/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		r2 := r.Clone(r.Context())
+		r2.URL.Path = "/" + rest
+		r2.URL.RawPath = ""
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// forwardedMiddleware takes the client address from X-Forwarded-For when
+// proxy headers are trusted
+func (s *Server) forwardedMiddleware(next http.Handler) http.Handler {
+	if !s.trustProxy {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			client, _, _ := strings.Cut(forwarded, ",")
+			if ip := net.ParseIP(strings.TrimSpace(client)); ip != nil {
+				r.RemoteAddr = net.JoinHostPort(ip.String(), "0")
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedHeader returns the first value of a trusted X-Forwarded-* header
+func (s *Server) forwardedHeader(r *http.Request, name string) string {
+	if !s.trustProxy {
+		return ""
+	}
+	value, _, _ := strings.Cut(r.Header.Get(name), ",")
+	return strings.TrimSpace(value)
+}
+
+// requestScheme returns the scheme the client used: https when the request
+// came over TLS or a trusted proxy says so
+func (s *Server) requestScheme(r *http.Request) string {
+	if proto := s.forwardedHeader(r, "X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		return proto
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// requestHost returns the host the client used
+func (s *Server) requestHost(r *http.Request) string {
+	if host := s.forwardedHeader(r, "X-Forwarded-Host"); host != "" {
+		return host
+	}
+	return r.Host
+}
+
+// publicBase returns the path prefix under which the client sees the
+// server: a trusted X-Forwarded-Prefix, for proxies that strip it, or the
+// configured base path
+func (s *Server) publicBase(r *http.Request) string {
+	if prefix := s.forwardedHeader(r, "X-Forwarded-Prefix"); prefix != "" {
+		return normalizeBasePath(prefix) + s.basePath
+	}
+	return s.basePath
+}
+
+// externalURL returns the URL of the server's root as the client sees it,
+// without a trailing slash, e.g. https://example.org/cloudbench
+func (s *Server) externalURL(r *http.Request) string {
+	return s.requestScheme(r) + "://" + s.requestHost(r) + s.publicBase(r)
+}
+
+// rootRelativeURL matches src and href attributes starting with a single /
+var rootRelativeURL = regexp.MustCompile(`\b(src|href)="/([^/])`)
+
+// withBaseHref points a page's root-relative URLs at the public base path,
+// adding a <base> element for its relative URLs and a meta tag the web UI
+// reads to prefix its API requests
+func (s *Server) withBaseHref(r *http.Request, page string) string {
+	base := html.EscapeString(s.publicBase(r))
+	if base != "" {
+		page = rootRelativeURL.ReplaceAllString(page, `$1="`+base+`/$2`)
+	}
+	head := `<base href="` + base + `/"><meta name="cloudbench-base" content="` + base + `">`
+	return strings.Replace(page, "<head>", "<head>"+head, 1)
+}
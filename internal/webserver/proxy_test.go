@@ -0,0 +1,54 @@
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBasePathAndForwardedHeaders(t *testing.T) {
+	s := &Server{}
+	s.SetBasePath("cloudbench/")
+
+	var seen string
+	handler := s.basePathMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.URL.Path
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cloudbench/api/jobs", nil))
+	if seen != "/api/jobs" {
+		t.Errorf("expected the base path to be stripped, got %q", seen)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cloudbench", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/cloudbench/" {
+		t.Errorf("expected a redirect to /cloudbench/, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected paths outside the base path to be not found, got %d", rec.Code)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/cloudbench/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	r.Header.Set("X-Forwarded-Host", "maps.example.org")
+	if got := s.externalURL(r); got != "http://example.com/cloudbench" {
+		t.Errorf("untrusted forwarded headers were used: %s", got)
+	}
+	s.SetTrustProxy(true)
+	if got := s.externalURL(r); got != "https://maps.example.org/cloudbench" {
+		t.Errorf("unexpected external URL %s", got)
+	}
+
+	page := s.withBaseHref(r, `<html><head><script src="/assets/app.js"></script><link href="//cdn.example.org/x.css"></head></html>`)
+	for _, want := range []string{`<base href="/cloudbench/">`, `src="/cloudbench/assets/app.js"`, `href="//cdn.example.org/x.css"`} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page lacks %s: %s", want, page)
+		}
+	}
+}
@@ -1,21 +1,29 @@
 package webserver
 
 import (
+	"context"
 	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"log"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/kartoza/kartoza-cloudbench/internal/api"
+	"github.com/kartoza/kartoza-cloudbench/internal/audit"
+	"github.com/kartoza/kartoza-cloudbench/internal/auth"
+	"github.com/kartoza/kartoza-cloudbench/internal/cache"
 	"github.com/kartoza/kartoza-cloudbench/internal/cloudnative"
 	"github.com/kartoza/kartoza-cloudbench/internal/config"
 	"github.com/kartoza/kartoza-cloudbench/internal/geonode"
+	"github.com/kartoza/kartoza-cloudbench/internal/jobs"
 	"github.com/kartoza/kartoza-cloudbench/internal/preview"
 	"github.com/kartoza/kartoza-cloudbench/internal/s3client"
+	"github.com/kartoza/kartoza-cloudbench/internal/snapshot"
 )
 
 //go:embed static/*
@@ -24,15 +32,30 @@ var staticFiles embed.FS
 // Server represents the web server
 type Server struct {
 	config           *config.Config
-	clients          map[string]*api.Client        // GeoServer Connection ID -> Client
-	s3Clients        map[string]*s3client.Client   // S3 Connection ID -> Client
-	geonodeClients   map[string]*geonode.Client    // GeoNode Connection ID -> Client
+	clients          map[string]*api.Client      // GeoServer Connection ID -> Client
+	s3Clients        map[string]*s3client.Client // S3 Connection ID -> Client
+	geonodeClients   map[string]*geonode.Client  // GeoNode Connection ID -> Client
 	clientsMu        sync.RWMutex
 	s3ClientsMu      sync.RWMutex
 	geonodeClientsMu sync.RWMutex
 	previewServer    *preview.Server
 	conversionMgr    *cloudnative.Manager
 	addr             string
+	auth             *auth.Store     // nil when authentication is disabled
+	sessions         *auth.Sessions  // Browser sessions, when authentication is enabled
+	audit            *audit.Log      // Where changes are recorded; nil disables auditing
+	jobs             *jobs.Manager   // Background jobs of every kind
+	events           *eventHub       // Job progress streamed by /api/events
+	metrics          *serverMetrics  // Request and job durations served at /metrics
+	snapshots        *snapshot.Store // Catalog snapshots for the change timeline
+	stopBackground   chan struct{}   // Closed by Shutdown to stop scheduled snapshots and the cache janitor
+	routes           []string        // Patterns registered by setupRoutes
+	basePath         string          // URL prefix the server is reached under, e.g. /cloudbench
+	trustProxy       bool            // Whether X-Forwarded-* headers are honoured
+	tlsCert          string          // PEM certificate file; empty serves plain HTTP
+	tlsKey           string          // PEM key file
+	httpServer       *http.Server
+	httpServerMu     sync.Mutex
 }
 
 // New creates a new web server
@@ -42,13 +65,24 @@ func New(cfg *config.Config) *Server {
 		clients:        make(map[string]*api.Client),
 		s3Clients:      make(map[string]*s3client.Client),
 		geonodeClients: make(map[string]*geonode.Client),
-		conversionMgr:  cloudnative.NewManager(),
+		conversionMgr:  cloudnative.NewManager(jobs.Default),
+		audit:          audit.Default,
+		jobs:           jobs.Default,
+		events:         newEventHub(),
+		metrics:        newServerMetrics(),
+		snapshots:      snapshot.Default,
 	}
+	s.jobs.SetLimits(cfg.JobLimits)
+	if cache.DefaultManager != nil {
+		cache.DefaultManager.SetTTL(time.Duration(cfg.CacheTTLHours) * time.Hour)
+	}
+	api.SharedCache.Configure(cfg)
+	s.publishJobEvents()
+	s.metrics.observeJobs(s.jobs)
 
 	// Initialize clients for existing GeoServer connections
 	for _, conn := range cfg.Connections {
-		client := api.NewClient(&conn)
-		s.clients[conn.ID] = client
+		s.clients[conn.ID] = newAPIClient(&conn)
 	}
 
 	// Initialize clients for existing S3 connections
@@ -67,18 +101,71 @@ func New(cfg *config.Config) *Server {
 	return s
 }
 
-// Start starts the web server
+// Start starts the web server and blocks until it fails or Shutdown is
+// called, in which case it returns nil
 func (s *Server) Start(addr string) error {
 	s.addr = addr
 
 	mux := http.NewServeMux()
 	s.setupRoutes(mux)
 
+	var handler http.Handler = mux
+	if s.auth != nil {
+		handler = s.authMiddleware(handler)
+	}
+	handler = s.metricsMiddleware(mux, handler)
+	handler = s.basePathMiddleware(handler)
+	handler = s.forwardedMiddleware(handler)
+
 	// Wrap with CORS isolation headers middleware for SharedArrayBuffer support (qgis-js)
-	handler := corsIsolationMiddleware(mux)
+	handler = corsIsolationMiddleware(handler)
+
+	server := &http.Server{Addr: addr, Handler: handler}
+	server.RegisterOnShutdown(s.events.closeAll)
+	stop := make(chan struct{})
+	s.httpServerMu.Lock()
+	s.httpServer = server
+	s.stopBackground = stop
+	s.httpServerMu.Unlock()
+	go s.scheduleSnapshots(stop)
+	if cache.DefaultManager != nil {
+		go cache.DefaultManager.RunJanitor(cache.JanitorInterval, config.Load, log.Printf, stop)
+	}
 
-	log.Printf("Starting web server on %s", addr)
-	return http.ListenAndServe(addr, handler)
+	var err error
+	if s.tlsCert != "" {
+		log.Printf("Starting web server on %s (HTTPS)", addr)
+		err = server.ListenAndServeTLS(s.tlsCert, s.tlsKey)
+	} else {
+		log.Printf("Starting web server on %s", addr)
+		err = server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting requests and waits for open requests and then
+// running background jobs to finish. Jobs still running when ctx ends are
+// cancelled.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.httpServerMu.Lock()
+	server := s.httpServer
+	if s.stopBackground != nil {
+		close(s.stopBackground)
+		s.stopBackground = nil
+	}
+	s.httpServerMu.Unlock()
+
+	var err error
+	if server != nil {
+		err = server.Shutdown(ctx)
+	}
+	if jobsErr := s.jobs.Shutdown(ctx); err == nil {
+		err = jobsErr
+	}
+	return err
 }
 
 // corsIsolationMiddleware adds COOP and COEP headers required for SharedArrayBuffer
@@ -93,147 +180,221 @@ func corsIsolationMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-// setupRoutes sets up all HTTP routes
+// setupRoutes sets up all HTTP routes. Each API route has a permission
+// policy (see authz.go) that is enforced when authentication is enabled.
 func (s *Server) setupRoutes(mux *http.ServeMux) {
+	route := func(pattern string, h http.HandlerFunc) {
+		s.routes = append(s.routes, pattern)
+		mux.HandleFunc(pattern, h)
+	}
+	handle := func(pattern string, a access, h http.HandlerFunc) {
+		route(pattern, s.authorize(pattern, a, h))
+	}
+
+	// API routes - health and authentication
+	route("/api/health", s.handleHealth)
+	route("/api/auth/session", s.handleAuthSession)
+	route("/api/auth/login", s.handleAuthLogin)
+	route("/api/auth/logout", s.handleAuthLogout)
+	route("/api/auth/password", s.handleAuthPassword)
+	route("/api/auth/tokens", s.handleAuthTokens)
+	route("/api/auth/tokens/", s.handleAuthTokens)
+	route("/api/auth/capabilities", s.handleAuthCapabilities)
+	handle("/api/auth/users", adminAccess, s.handleAuthUsers)
+	handle("/api/auth/users/", adminAccess, s.handleAuthUsers)
+
 	// API routes - connections
-	mux.HandleFunc("/api/connections", s.handleConnections)
-	mux.HandleFunc("/api/connections/test", s.handleTestConnectionDirect) // Test without saving
-	mux.HandleFunc("/api/connections/", s.handleConnectionByID)
+	handle("/api/connections", manageAccess, s.handleConnections)
+	handle("/api/connections/test", manageAccess, s.handleTestConnectionDirect) // Test without saving
+	handle("/api/connections/", connectionAccess, s.handleConnectionByID)
 
 	// API routes - workspaces (pattern: /api/connections/{connId}/workspaces)
-	mux.HandleFunc("/api/workspaces/", s.handleWorkspaces)
+	handle("/api/workspaces/", catalogAccess, s.handleWorkspaces)
 
 	// API routes - data stores
-	mux.HandleFunc("/api/datastores/", s.handleDataStores)
+	handle("/api/datastores/", catalogAccess, s.handleDataStores)
 
 	// API routes - coverage stores
-	mux.HandleFunc("/api/coveragestores/", s.handleCoverageStores)
+	handle("/api/coveragestores/", catalogAccess, s.handleCoverageStores)
 
 	// API routes - layers
-	mux.HandleFunc("/api/layers/", s.handleLayers)
+	handle("/api/layers/", catalogAccess, s.handleLayers)
 
 	// API routes - layer metadata (comprehensive)
-	mux.HandleFunc("/api/layermetadata/", s.handleLayerMetadata)
+	handle("/api/layermetadata/", catalogAccess, s.handleLayerMetadata)
 
 	// API routes - layer styles association
-	mux.HandleFunc("/api/layerstyles/", s.handleLayerStyles)
+	handle("/api/layerstyles/", catalogAccess, s.handleLayerStyles)
 
 	// API routes - styles
-	mux.HandleFunc("/api/styles/", s.handleStyles)
+	handle("/api/styles/", catalogAccess, s.handleStyles)
 
 	// API routes - layer groups
-	mux.HandleFunc("/api/layergroups/", s.handleLayerGroups)
+	handle("/api/layergroups/", catalogAccess, s.handleLayerGroups)
 
 	// API routes - feature types
-	mux.HandleFunc("/api/featuretypes/", s.handleFeatureTypes)
+	handle("/api/featuretypes/", catalogAccess, s.handleFeatureTypes)
 
 	// API routes - coverages
-	mux.HandleFunc("/api/coverages/", s.handleCoverages)
+	handle("/api/coverages/", catalogAccess, s.handleCoverages)
 
 	// API routes - upload
-	mux.HandleFunc("/api/upload", s.handleUpload)
+	uploadAccess := catalogAccess
+	uploadAccess.query = true
+	handle("/api/upload", uploadAccess, s.handleUpload)
 
 	// API routes - preview
-	mux.HandleFunc("/api/preview", s.handlePreview)
-	mux.HandleFunc("/api/layer", s.handleLayerInfo)
-	mux.HandleFunc("/api/metadata", s.handleMetadata)
-
-	// API routes - GeoWebCache (GWC)
-	mux.HandleFunc("/api/gwc/layers/", s.handleGWCLayers)
-	mux.HandleFunc("/api/gwc/seed/", s.handleGWCSeed)
-	mux.HandleFunc("/api/gwc/truncate/", s.handleGWCTruncate)
-	mux.HandleFunc("/api/gwc/gridsets/", s.handleGWCGridSets)
-	mux.HandleFunc("/api/gwc/diskquota/", s.handleGWCDiskQuota)
+	handle("/api/preview", readAccess, s.handlePreview)
+	handle("/api/layer", readAccess, s.handleLayerInfo)
+	handle("/api/metadata", readAccess, s.handleMetadata)
+
+	// API routes - GeoWebCache (GWC), keyed by connection and layer name
+	gwcAccess := catalogAccess
+	gwcAccess.scope = scopeConnection
+	handle("/api/gwc/layers/", gwcAccess, s.handleGWCLayers)
+	handle("/api/gwc/seed/", gwcAccess, s.handleGWCSeed)
+	handle("/api/gwc/truncate/", gwcAccess, s.handleGWCTruncate)
+	handle("/api/gwc/gridsets/", connectionAccess, s.handleGWCGridSets)
+	handle("/api/gwc/diskquota/", connectionAccess, s.handleGWCDiskQuota)
 
 	// API routes - Settings
-	mux.HandleFunc("/api/settings/", s.handleSettings)
+	handle("/api/settings/", connectionAccess, s.handleSettings)
+
+	// API routes - background jobs and their progress events (Server-Sent Events)
+	handle("/api/jobs", editAccess, s.handleJobs)
+	handle("/api/jobs/", editAccess, s.handleJobs)
+	handle("/api/events", readAccess, s.handleEvents)
 
 	// API routes - Sync (server replication)
-	mux.HandleFunc("/api/sync/configs", s.handleSyncConfigs)
-	mux.HandleFunc("/api/sync/configs/", s.handleSyncConfigs)
-	mux.HandleFunc("/api/sync/start", s.handleSyncStart)
-	mux.HandleFunc("/api/sync/status", s.handleSyncStatus)
-	mux.HandleFunc("/api/sync/status/", s.handleSyncStatus)
-	mux.HandleFunc("/api/sync/stop", s.handleSyncStop)
-	mux.HandleFunc("/api/sync/stop/", s.handleSyncStop)
+	handle("/api/sync/configs", manageAccess, s.handleSyncConfigs)
+	handle("/api/sync/configs/", manageAccess, s.handleSyncConfigs)
+	handle("/api/sync/start", manageAccess, s.handleSyncStart)
+	handle("/api/sync/status", readAccess, s.handleSyncStatus)
+	handle("/api/sync/status/", readAccess, s.handleSyncStatus)
+	handle("/api/sync/stop", manageAccess, s.handleSyncStop)
+	handle("/api/sync/stop/", manageAccess, s.handleSyncStop)
+	handle("/api/sync/resume/", manageAccess, s.handleSyncResume)
+	handle("/api/sync/retry/", manageAccess, s.handleSyncRetry)
+	handle("/api/sync/cache", manageAccess, s.handleSyncCache)
+	handle("/api/sync/cache/", manageAccess, s.handleSyncCache)
+
+	// API routes - Bundles (connection import/export)
+	handle("/api/bundle/export", adminAccess, s.handleBundleExport)
+	handle("/api/bundle/import", adminAccess, s.handleBundleImport)
+
+	// API routes - catalog snapshots and the change timeline between them
+	snapshotAccess := catalogAccess
+	snapshotAccess.scope = scopeConnection
+	handle("/api/snapshots/", snapshotAccess, s.handleSnapshots)
+
+	// API routes - browsing unreachable servers and their queued changes
+	offlineAccess := catalogAccess
+	offlineAccess.scope = scopeConnection
+	handle("/api/offline", readAccess, s.handleOffline)
+	handle("/api/offline/", offlineAccess, s.handleOffline)
+
+	// API routes - Audit log of changes
+	handle("/api/audit", adminAccess, s.handleAudit)
 
 	// API routes - Dashboard (server status overview)
-	mux.HandleFunc("/api/dashboard", s.handleDashboard)
-	mux.HandleFunc("/api/dashboard/server", s.handleServerStatus)
+	handle("/api/dashboard", readAccess, s.handleDashboard)
+	handle("/api/dashboard/server", readAccess, s.handleServerStatus)
+	handle("/api/rest-cache", manageAccess, s.handleRESTCache)
 
 	// API routes - Download (export resources)
-	mux.HandleFunc("/api/download/logs/", s.handleDownloadLogs)
-	mux.HandleFunc("/api/download/", s.handleDownload)
+	handle("/api/download/logs/", adminAccess, s.handleDownloadLogs)
+	handle("/api/download/", readAccess, s.handleDownload)
 
 	// API routes - Universal Search
-	mux.HandleFunc("/api/search", s.handleSearch)
-	mux.HandleFunc("/api/search/suggestions", s.handleSearchSuggestions)
+	handle("/api/search", readAccess, s.handleSearch)
+	handle("/api/search/suggestions", readAccess, s.handleSearchSuggestions)
 
 	// API routes - Documentation
-	mux.HandleFunc("/api/docs", s.handleDocumentation)
+	handle("/api/docs", readAccess, s.handleDocumentation)
+	handle("/api/openapi.json", readAccess, s.handleOpenAPI)
+	handle("/metrics", readAccess, s.handleMetrics)
 
 	// API routes - PostgreSQL Services
-	mux.HandleFunc("/api/pg/services", s.handlePGServices)
-	mux.HandleFunc("/api/pg/services/", s.handlePGServiceByName)
+	handle("/api/pg/services", manageAccess, s.handlePGServices)
+	handle("/api/pg/services/", manageAccess, s.handlePGServiceByName)
 
 	// API routes - S3 Storage
-	mux.HandleFunc("/api/s3/connections", s.handleS3Connections)
-	mux.HandleFunc("/api/s3/connections/test", s.handleTestS3ConnectionDirect)
-	mux.HandleFunc("/api/s3/connections/", s.handleS3ConnectionByID)
-	mux.HandleFunc("/api/s3/conversion/tools", s.handleConversionToolStatus)
-	mux.HandleFunc("/api/s3/conversion/jobs", s.handleConversionJobs)
-	mux.HandleFunc("/api/s3/conversion/jobs/", s.handleConversionJobByID)
-	mux.HandleFunc("/api/s3/preview/", s.handleS3Preview)
-	mux.HandleFunc("/api/s3/proxy/", s.handleS3Proxy)
+	s3Access := connectionAccess
+	s3Access.sub = map[string]access{"buckets": {read: auth.RoleViewer, write: auth.RoleEditor, remove: auth.RoleAdmin, scope: scopeConnection}}
+	handle("/api/s3/connections", manageAccess, s.handleS3Connections)
+	handle("/api/s3/connections/test", manageAccess, s.handleTestS3ConnectionDirect)
+	handle("/api/s3/connections/", s3Access, s.handleS3ConnectionByID)
+	handle("/api/s3/conversion/tools", readAccess, s.handleConversionToolStatus)
+	handle("/api/s3/conversion/jobs", editAccess, s.handleConversionJobs)
+	handle("/api/s3/conversion/jobs/", editAccess, s.handleConversionJobByID)
+	handle("/api/s3/preview/", readAccess, s.handleS3Preview)
+	handle("/api/s3/proxy/", readAccess, s.handleS3Proxy)
 
 	// API routes - Data Import (ogr2ogr and raster2pgsql)
-	mux.HandleFunc("/api/pg/import", s.handlePGImport)
-	mux.HandleFunc("/api/pg/import/raster", s.handlePGRasterImport)
-	mux.HandleFunc("/api/pg/import/upload", s.handlePGImportUpload)
-	mux.HandleFunc("/api/pg/import/", s.handlePGImportStatus)
-	mux.HandleFunc("/api/pg/detect-layers", s.handlePGDetectLayers)
-	mux.HandleFunc("/api/pg/ogr2ogr/status", s.handleOgr2ogrStatus)
+	handle("/api/pg/import", editAccess, s.handlePGImport)
+	handle("/api/pg/import/raster", editAccess, s.handlePGRasterImport)
+	handle("/api/pg/import/upload", editAccess, s.handlePGImportUpload)
+	handle("/api/pg/import/", editAccess, s.handlePGImportStatus)
+	handle("/api/pg/detect-layers", readAccess, s.handlePGDetectLayers)
+	handle("/api/pg/ogr2ogr/status", readAccess, s.handleOgr2ogrStatus)
 
 	// API routes - PostgreSQL to GeoServer Bridge
-	mux.HandleFunc("/api/bridge", s.handleBridge)
-	mux.HandleFunc("/api/bridge/", s.handleBridge)
+	handle("/api/bridge", editAccess, s.handleBridge)
+	handle("/api/bridge/", editAccess, s.handleBridge)
 
 	// API routes - AI Query Engine
-	mux.HandleFunc("/api/ai/", s.handleAI)
+	handle("/api/ai/", readAccess, s.handleAI)
 
-	// API routes - Visual Query Designer
-	mux.HandleFunc("/api/query/", s.handleQuery)
+	// API routes - Visual Query Designer (saving and deleting change the config)
+	handle("/api/query/", readAccess, s.handleQuery)
+	handle("/api/query/save", editAccess, s.handleQuery)
+	handle("/api/query/delete", editAccess, s.handleQuery)
 
 	// API routes - QGIS Projects
-	mux.HandleFunc("/api/qgis/projects", s.handleQGISProjects)
-	mux.HandleFunc("/api/qgis/projects/", s.handleQGISProjectByID)
+	handle("/api/qgis/projects", editAccess, s.handleQGISProjects)
+	handle("/api/qgis/projects/", editAccess, s.handleQGISProjectByID)
 
 	// API routes - GeoNode
-	mux.HandleFunc("/api/geonode/connections", s.handleGeoNodeConnections)
-	mux.HandleFunc("/api/geonode/connections/test", s.handleGeoNodeTestConnection)
-	mux.HandleFunc("/api/geonode/connections/", s.handleGeoNodeConnectionByID)
+	geonodeAccess := connectionAccess
+	geonodeAccess.sub = map[string]access{
+		"datasets":   readAccess,
+		"maps":       readAccess,
+		"documents":  readAccess,
+		"geostories": readAccess,
+		"dashboards": readAccess,
+		"resources":  readAccess,
+		"download":   readAccess,
+		"upload":     {read: auth.RoleViewer, write: auth.RoleEditor, remove: auth.RoleAdmin, scope: scopeConnection},
+	}
+	handle("/api/geonode/connections", manageAccess, s.handleGeoNodeConnections)
+	handle("/api/geonode/connections/test", manageAccess, s.handleGeoNodeTestConnection)
+	handle("/api/geonode/connections/", geonodeAccess, s.handleGeoNodeConnectionByID)
 
 	// API routes - SQL View Layers (publish queries as GeoServer layers)
-	mux.HandleFunc("/api/sqlview/", s.handleSQLView)
-	mux.HandleFunc("/api/sqlview", s.handleSQLView)
+	handle("/api/sqlview/", editAccess, s.handleSQLView)
+	handle("/api/sqlview", editAccess, s.handleSQLView)
+	handle("/api/sqlview/detect", readAccess, s.handleSQLView)
 
 	// API routes - Terria Integration (3D globe viewer, catalog export)
-	mux.HandleFunc("/api/terria/connection/", s.handleTerriaConnection)
-	mux.HandleFunc("/api/terria/workspace/", s.handleTerriaWorkspace)
-	mux.HandleFunc("/api/terria/layer/", s.handleTerriaLayer)
-	mux.HandleFunc("/api/terria/layergroup/", s.handleTerriaLayerGroup)
-	mux.HandleFunc("/api/terria/story/", s.handleTerriaStory)
-	mux.HandleFunc("/api/terria/init/", s.handleTerriaInit)
-	mux.HandleFunc("/api/terria/proxy", s.handleTerriaProxy)
-	mux.HandleFunc("/api/terria/url/", s.handleTerriaURL)
-	mux.HandleFunc("/api/terria/download/", s.handleTerriaDownload)
+	handle("/api/terria/connection/", readAccess, s.handleTerriaConnection)
+	handle("/api/terria/workspace/", readAccess, s.handleTerriaWorkspace)
+	handle("/api/terria/layer/", readAccess, s.handleTerriaLayer)
+	handle("/api/terria/layergroup/", readAccess, s.handleTerriaLayerGroup)
+	handle("/api/terria/story/", readAccess, s.handleTerriaStory)
+	handle("/api/terria/init/", readAccess, s.handleTerriaInit)
+	handle("/api/terria/proxy", readAccess, s.handleTerriaProxy)
+	handle("/api/terria/url/", readAccess, s.handleTerriaURL)
+	handle("/api/terria/download/", readAccess, s.handleTerriaDownload)
 
 	// 3D Viewer - embedded Cesium-based viewer
-	mux.HandleFunc("/viewer/", s.handleTerriaViewer)
-	mux.HandleFunc("/viewer", s.handleTerriaViewer)
+	route("/viewer/", s.handleTerriaViewer)
+	route("/viewer", s.handleTerriaViewer)
+
+	// Layer preview page, reading the layer from /api/layer and /api/metadata
+	route("/preview/", s.handlePreviewPage)
 
 	// Serve static files (React app)
-	mux.HandleFunc("/", s.serveStatic)
+	route("/", s.serveStatic)
 }
 
 // serveStatic serves the React app and static files
@@ -296,6 +457,10 @@ func (s *Server) serveStatic(w http.ResponseWriter, r *http.Request) {
 		contentType = "application/octet-stream"
 	}
 
+	if path == "/index.html" {
+		content = []byte(s.withBaseHref(r, string(content)))
+	}
+
 	w.Header().Set("Content-Type", contentType)
 	w.Write(content)
 }
@@ -319,11 +484,16 @@ func (s *Server) jsonError(w http.ResponseWriter, message string, status int) {
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
-// getClient returns the API client for a connection ID
-func (s *Server) getClient(connID string) *api.Client {
+// getClient returns the API client for a connection ID, recording changes
+// in the audit log as the request's user
+func (s *Server) getClient(r *http.Request, connID string) *api.Client {
 	s.clientsMu.RLock()
-	defer s.clientsMu.RUnlock()
-	return s.clients[connID]
+	client := s.clients[connID]
+	s.clientsMu.RUnlock()
+	if client == nil {
+		return nil
+	}
+	return client.WithAudit(s.auditActor(r, connID))
 }
 
 // getConnectionConfig returns the connection config for an ID
@@ -340,7 +510,18 @@ func (s *Server) getConnectionConfig(connID string) *config.Connection {
 func (s *Server) addClient(conn *config.Connection) {
 	s.clientsMu.Lock()
 	defer s.clientsMu.Unlock()
-	s.clients[conn.ID] = api.NewClient(conn)
+	s.clients[conn.ID] = newAPIClient(conn)
+}
+
+// newAPIClient creates a client that shares the response cache and keeps
+// the connection's catalog for browsing while the server is unreachable
+func newAPIClient(conn *config.Connection) *api.Client {
+	client := api.NewClient(conn)
+	client.SetResponseCache(api.SharedCache)
+	if store, err := api.OpenOfflineStore(conn.ID); err == nil {
+		client.SetOffline(store)
+	}
+	return client
 }
 
 // removeClient removes an API client
@@ -398,9 +579,19 @@ func parseStorePathParams(path, prefix string) (connID, workspace, store, resour
 	return
 }
 
-// saveConfig saves the configuration to disk
+// saveConfig saves the configuration to disk. If another process saved it
+// first, the server picks up that version instead and the change must be
+// retried.
 func (s *Server) saveConfig() error {
-	return s.config.Save()
+	err := s.config.Save()
+	if errors.Is(err, config.ErrConfigChanged) {
+		if reloadErr := s.config.Reload(); reloadErr != nil {
+			return reloadErr
+		}
+		s.refreshClients()
+		return fmt.Errorf("configuration was changed by another CloudBench process and has been reloaded; please retry")
+	}
+	return err
 }
 
 // generateConnectionID generates a unique connection ID
@@ -410,11 +601,16 @@ func generateConnectionID() string {
 
 // S3 client management methods
 
-// getS3Client returns the S3 client for a connection ID
-func (s *Server) getS3Client(connID string) *s3client.Client {
+// getS3Client returns the S3 client for a connection ID, recording changes
+// in the audit log as the request's user
+func (s *Server) getS3Client(r *http.Request, connID string) *s3client.Client {
 	s.s3ClientsMu.RLock()
-	defer s.s3ClientsMu.RUnlock()
-	return s.s3Clients[connID]
+	client := s.s3Clients[connID]
+	s.s3ClientsMu.RUnlock()
+	if client == nil {
+		return nil
+	}
+	return client.WithAudit(s.auditActor(r, connID))
 }
 
 // addS3Client adds a new S3 client for a connection
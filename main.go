@@ -16,6 +16,6 @@ var (
 func main() {
 	if err := cmd.Execute(version); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(cmd.ExitCode(err))
 	}
 }
@@ -1,9 +1,13 @@
-import { useState, useCallback } from 'react'
+import { useState, useCallback, useEffect } from 'react'
+import { Center, Spinner } from '@chakra-ui/react'
 import Layout from './components/Layout'
 import MainContent from './components/MainContent'
 import Dialogs from './components/dialogs'
 import { SearchModal, useSearchShortcut } from './components/SearchModal'
 import { HelpPanel, useHelpShortcut } from './components/HelpPanel'
+import LoginPage from './components/LoginPage'
+import { useAuthStore } from './stores/authStore'
+import { UNAUTHORIZED_EVENT } from './api/auth'
 
 function App() {
   const [isSearchOpen, setIsSearchOpen] = useState(false)
@@ -16,6 +20,26 @@ function App() {
   const toggleHelp = useCallback(() => setIsHelpOpen(prev => !prev), [])
   useHelpShortcut(toggleHelp)
 
+  // Check for an existing session, and again whenever a request is rejected
+  const authStatus = useAuthStore((state) => state.status)
+  const checkSession = useAuthStore((state) => state.checkSession)
+  useEffect(() => {
+    checkSession()
+    window.addEventListener(UNAUTHORIZED_EVENT, checkSession)
+    return () => window.removeEventListener(UNAUTHORIZED_EVENT, checkSession)
+  }, [checkSession])
+
+  if (authStatus === 'loading') {
+    return (
+      <Center minH="100vh">
+        <Spinner size="xl" color="kartoza.500" />
+      </Center>
+    )
+  }
+  if (authStatus === 'anonymous') {
+    return <LoginPage />
+  }
+
   return (
     <>
       <Layout
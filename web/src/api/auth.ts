@@ -0,0 +1,199 @@
+// Authentication API: sessions, login and personal API tokens
+
+import { BASE_PATH, withBase } from './base'
+
+export type Role = 'viewer' | 'editor' | 'admin'
+
+// Roles from least to most powerful; each includes the ones before it
+export const ROLES: Role[] = ['viewer', 'editor', 'admin']
+
+export interface Grant {
+  connection: string
+  workspace?: string
+  role: Role
+}
+
+export interface AuthUser {
+  id: string
+  username: string
+  role: Role
+  grants: Grant[]
+  created_at: string
+}
+
+export interface AuthCapabilities {
+  auth_enabled: boolean
+  role: Role
+  grants: Grant[]
+}
+
+export interface AuthUserRequest {
+  username?: string
+  password?: string
+  role: Role
+  grants: Grant[]
+}
+
+export interface AuthSession {
+  auth_enabled: boolean
+  user: AuthUser | null
+  csrf_token?: string
+}
+
+export interface APIToken {
+  id: string
+  name: string
+  prefix: string
+  created_at: string
+  token?: string // Only present right after creation
+}
+
+// Fired when an API request is rejected because the session has ended
+export const UNAUTHORIZED_EVENT = 'cloudbench:unauthorized'
+
+let csrfToken: string | null = null
+
+// setCSRFToken remembers the token sent with state-changing requests
+export function setCSRFToken(token: string | null) {
+  csrfToken = token
+}
+
+const safeMethods = ['GET', 'HEAD', 'OPTIONS']
+
+// installAuthFetch wraps window.fetch so API paths such as /api/jobs are
+// requested under the base path, every same-origin API request that changes
+// state carries the CSRF token, and expired sessions are noticed
+export function installAuthFetch() {
+  const originalFetch = window.fetch.bind(window)
+
+  window.fetch = async (input: RequestInfo | URL, init?: RequestInit) => {
+    if (typeof input === 'string' && input.startsWith('/api/')) {
+      input = withBase(input)
+    }
+    const url = new URL(input instanceof Request ? input.url : input.toString(), window.location.href)
+    const apiPrefix = BASE_PATH + '/api/'
+    const isAPI = url.origin === window.location.origin && url.pathname.startsWith(apiPrefix)
+    const method = (init?.method || (input instanceof Request ? input.method : 'GET')).toUpperCase()
+
+    if (isAPI && csrfToken && !safeMethods.includes(method)) {
+      const headers = new Headers(init?.headers || (input instanceof Request ? input.headers : undefined))
+      headers.set('X-CSRF-Token', csrfToken)
+      init = { ...init, headers }
+    }
+
+    const response = await originalFetch(input, init)
+    if (isAPI && response.status === 401 && !url.pathname.startsWith(apiPrefix + 'auth/')) {
+      window.dispatchEvent(new Event(UNAUTHORIZED_EVENT))
+    }
+    return response
+  }
+}
+
+async function handleResponse<T>(response: Response): Promise<T> {
+  if (!response.ok) {
+    const error = await response.json().catch(() => ({ error: 'Unknown error' }))
+    throw new Error(error.error || `HTTP ${response.status}`)
+  }
+  if (response.status === 204) {
+    return undefined as T
+  }
+  return response.json()
+}
+
+export async function getSession(): Promise<AuthSession> {
+  const response = await fetch('/api/auth/session')
+  return handleResponse<AuthSession>(response)
+}
+
+export async function login(username: string, password: string): Promise<AuthSession> {
+  const response = await fetch('/api/auth/login', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ username, password }),
+  })
+  return handleResponse<AuthSession>(response)
+}
+
+export async function logout(): Promise<void> {
+  const response = await fetch('/api/auth/logout', { method: 'POST' })
+  return handleResponse<void>(response)
+}
+
+export async function changePassword(currentPassword: string, newPassword: string): Promise<AuthSession> {
+  const response = await fetch('/api/auth/password', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ current_password: currentPassword, new_password: newPassword }),
+  })
+  return handleResponse<AuthSession>(response)
+}
+
+export async function getTokens(): Promise<APIToken[]> {
+  const response = await fetch('/api/auth/tokens')
+  return handleResponse<APIToken[]>(response)
+}
+
+export async function createToken(name: string): Promise<APIToken> {
+  const response = await fetch('/api/auth/tokens', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ name }),
+  })
+  return handleResponse<APIToken>(response)
+}
+
+export async function deleteToken(id: string): Promise<void> {
+  const response = await fetch(`/api/auth/tokens/${id}`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
+export async function getCapabilities(): Promise<AuthCapabilities> {
+  const response = await fetch('/api/auth/capabilities')
+  return handleResponse<AuthCapabilities>(response)
+}
+
+export async function getUsers(): Promise<AuthUser[]> {
+  const response = await fetch('/api/auth/users')
+  return handleResponse<AuthUser[]>(response)
+}
+
+export async function createUser(req: AuthUserRequest): Promise<AuthUser> {
+  const response = await fetch('/api/auth/users', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(req),
+  })
+  return handleResponse<AuthUser>(response)
+}
+
+export async function updateUser(id: string, req: AuthUserRequest): Promise<AuthUser> {
+  const response = await fetch(`/api/auth/users/${id}`, {
+    method: 'PUT',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(req),
+  })
+  return handleResponse<AuthUser>(response)
+}
+
+export async function deleteUser(id: string): Promise<void> {
+  const response = await fetch(`/api/auth/users/${id}`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
+// roleFor mirrors the server's rule: the highest of the global role and any
+// grant matching the connection and workspace
+export function roleFor(caps: AuthCapabilities, connId?: string, workspace?: string): Role {
+  let role = caps.role
+  if (!connId) {
+    return role
+  }
+  for (const g of caps.grants) {
+    if (g.connection !== connId || (g.workspace && g.workspace !== workspace)) {
+      continue
+    }
+    if (ROLES.indexOf(g.role) > ROLES.indexOf(role)) {
+      role = g.role
+    }
+  }
+  return role
+}
@@ -0,0 +1,11 @@
+// URL base path: the prefix the server is reached under, e.g. /cloudbench
+// behind a reverse proxy. The server announces it in a meta tag it adds to
+// index.html; it is empty when served from the root or by the dev server.
+
+export const BASE_PATH =
+  document.querySelector<HTMLMetaElement>('meta[name="cloudbench-base"]')?.content ?? ''
+
+// withBase prefixes a root-relative path, such as /api/jobs, with the base path
+export function withBase(path: string): string {
+  return path.startsWith('/') && !path.startsWith('//') ? BASE_PATH + path : path
+}
@@ -54,8 +54,9 @@ import type {
   GeoNodeDashboardsResponse,
   GeoNodeResourcesResponse,
 } from '../types'
+import { withBase } from './base'
 
-const API_BASE = '/api'
+const API_BASE = withBase('/api')
 
 async function handleResponse<T>(response: Response): Promise<T> {
   if (!response.ok) {
@@ -732,6 +733,58 @@ export async function stopAllSyncs(): Promise<{ success: boolean; message: strin
   return handleResponse<{ success: boolean; message: string }>(response)
 }
 
+// Resume a stopped or failed sync task from its checkpoint
+export async function resumeSyncTask(taskId: string): Promise<SyncTask> {
+  const response = await fetch(`${API_BASE}/sync/resume/${taskId}`, {
+    method: 'POST',
+  })
+  return handleResponse<SyncTask>(response)
+}
+
+// Re-run only the failed items of a sync task
+export async function retrySyncTask(taskId: string): Promise<SyncTask> {
+  const response = await fetch(`${API_BASE}/sync/retry/${taskId}`, {
+    method: 'POST',
+  })
+  return handleResponse<SyncTask>(response)
+}
+
+// One server's share of the sync cache
+export interface SyncCacheServer {
+  connectionId: string
+  name: string
+  size: number
+  entries: number
+  pinned: number
+}
+
+export interface SyncCacheUsage {
+  dir: string
+  size: number
+  maxSize: number
+  maxAgeDays: number
+  ttlHours: number
+  servers: SyncCacheServer[]
+}
+
+// Get the sync cache's size per server and its limits
+export async function getSyncCache(): Promise<SyncCacheUsage> {
+  const response = await fetch(`${API_BASE}/sync/cache`)
+  return handleResponse<SyncCacheUsage>(response)
+}
+
+// Remove cached data beyond the configured size and age limits
+export async function evictSyncCache(): Promise<{ removed: number; freed: number }> {
+  const response = await fetch(`${API_BASE}/sync/cache/evict`, { method: 'POST' })
+  return handleResponse<{ removed: number; freed: number }>(response)
+}
+
+// Remove a server's cached data
+export async function clearSyncCache(connId: string): Promise<void> {
+  const response = await fetch(`${API_BASE}/sync/cache/${connId}`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
 // ============================================================================
 // Dashboard API
 // ============================================================================
@@ -748,6 +801,29 @@ export async function getServerStatus(connectionId: string): Promise<ServerStatu
   return handleResponse<ServerStatus>(response)
 }
 
+export interface RestCacheStats {
+  enabled: boolean
+  ttlSecs: number
+  ttls?: Record<string, number>
+  hits: number
+  misses: number
+  invalidations: number
+  entries: number
+  hitRate: number
+}
+
+// Get the GeoServer REST response cache's hit rate
+export async function getRestCache(): Promise<RestCacheStats> {
+  const response = await fetch(`${API_BASE}/rest-cache`)
+  return handleResponse<RestCacheStats>(response)
+}
+
+// Drop every cached GeoServer REST response
+export async function clearRestCache(): Promise<void> {
+  const response = await fetch(`${API_BASE}/rest-cache`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
 // ============================================================================
 // Download API - Export resource configurations
 // ============================================================================
@@ -796,6 +872,165 @@ export function downloadSyncLogs(taskId: string): void {
   window.open(url, '_blank')
 }
 
+// ============================================================================
+// Connection Bundle API
+// ============================================================================
+
+export type BundleKind = 'geoserver' | 's3' | 'geonode' | 'pgservice' | 'sync' | 'query'
+
+export interface BundleExportOptions {
+  kinds?: BundleKind[]
+  ids?: string[]
+  passphrase?: string
+}
+
+// A bundle is passed through unchanged; only the fields the UI shows are typed
+export interface ConnectionBundle {
+  version: number
+  created_at: string
+  secrets_mode: 'redacted' | 'encrypted'
+  [key: string]: unknown
+}
+
+export interface BundleImportResult {
+  added: string[]
+  replaced: string[]
+  skipped: string[]
+  warnings?: string[]
+}
+
+// Export connections and settings as a bundle
+export async function exportBundle(options: BundleExportOptions): Promise<ConnectionBundle> {
+  const response = await fetch(`${API_BASE}/bundle/export`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(options),
+  })
+  return handleResponse<ConnectionBundle>(response)
+}
+
+// Import a bundle, resolving conflicts with existing objects
+export async function importBundle(
+  bundle: ConnectionBundle,
+  passphrase: string,
+  onConflict: 'skip' | 'replace' | 'duplicate'
+): Promise<BundleImportResult> {
+  const response = await fetch(`${API_BASE}/bundle/import`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ bundle, passphrase, on_conflict: onConflict }),
+  })
+  return handleResponse<BundleImportResult>(response)
+}
+
+// ============================================================================
+// Catalog Snapshots API
+// ============================================================================
+
+export interface CatalogSnapshot {
+  id: string
+  connection: string
+  time: string
+  trigger: 'manual' | 'scheduled'
+  objects: number
+}
+
+export interface SnapshotFieldDiff {
+  field: string
+  from: string
+  to: string
+}
+
+export interface SnapshotChange {
+  action: 'added' | 'removed' | 'modified'
+  kind: string
+  workspace?: string
+  name: string
+  path: string
+  fields?: SnapshotFieldDiff[]
+}
+
+// List a connection's catalog snapshots, newest first
+export async function getSnapshots(connId: string): Promise<CatalogSnapshot[]> {
+  const response = await fetch(`${API_BASE}/snapshots/${connId}`)
+  return handleResponse<CatalogSnapshot[]>(response)
+}
+
+// Snapshot a connection's catalog; created is false when nothing changed
+export async function takeSnapshot(connId: string): Promise<{ snapshot: CatalogSnapshot; created: boolean }> {
+  const response = await fetch(`${API_BASE}/snapshots/${connId}`, { method: 'POST' })
+  return handleResponse<{ snapshot: CatalogSnapshot; created: boolean }>(response)
+}
+
+// List the objects that changed from one snapshot to another
+export async function diffSnapshots(connId: string, from: string, to: string): Promise<SnapshotChange[]> {
+  const params = new URLSearchParams({ from, to })
+  const response = await fetch(`${API_BASE}/snapshots/${connId}/diff?${params}`)
+  return handleResponse<SnapshotChange[]>(response)
+}
+
+// Make one object match a snapshot
+export async function restoreSnapshotObject(
+  connId: string,
+  snapshot: string,
+  path: string
+): Promise<{ changes: { action: string; kind: string; name: string }[] }> {
+  const response = await fetch(`${API_BASE}/snapshots/${connId}/restore`, {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify({ snapshot, path }),
+  })
+  return handleResponse<{ changes: { action: string; kind: string; name: string }[] }>(response)
+}
+
+// Delete a snapshot
+export async function deleteSnapshot(connId: string, id: string): Promise<void> {
+  const response = await fetch(`${API_BASE}/snapshots/${connId}/${encodeURIComponent(id)}`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
+// ============================================================================
+// Offline API
+// ============================================================================
+
+export interface OfflineChange {
+  method: string
+  path: string
+  queuedAt: string
+}
+
+export interface OfflineStatus {
+  connectionId: string
+  offline: boolean
+  since?: string
+  cachedAt?: string
+  queued: OfflineChange[]
+}
+
+export interface OfflineReplayResult {
+  replayed: number
+  remaining: number
+  error?: string
+}
+
+// Tell which servers are unreachable, checking whether they are back
+export async function getOfflineStatus(): Promise<OfflineStatus[]> {
+  const response = await fetch(`${API_BASE}/offline`)
+  return handleResponse<OfflineStatus[]>(response)
+}
+
+// Replay the changes queued while a server was unreachable
+export async function replayOfflineChanges(connId: string): Promise<OfflineReplayResult> {
+  const response = await fetch(`${API_BASE}/offline/${connId}/replay`, { method: 'POST' })
+  return handleResponse<OfflineReplayResult>(response)
+}
+
+// Discard the changes queued for a server
+export async function discardOfflineChanges(connId: string): Promise<void> {
+  const response = await fetch(`${API_BASE}/offline/${connId}`, { method: 'DELETE' })
+  return handleResponse<void>(response)
+}
+
 // ============================================================================
 // Universal Search API
 // ============================================================================
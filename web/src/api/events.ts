@@ -0,0 +1,67 @@
+// Job progress events streamed by the server (Server-Sent Events)
+
+import { useEffect, useRef } from 'react'
+import { withBase } from './base'
+
+export type JobType = 'sync' | 'import' | 'conversion' | 'seed'
+
+export type JobEventType = 'progress' | 'log' | 'complete'
+
+export interface JobEvent<T = unknown> {
+  id: number
+  type: JobEventType
+  jobType: JobType
+  jobId: string
+  message?: string
+  job?: T // The job's state, as returned by its status endpoint
+  time: string
+}
+
+export interface JobEventFilter {
+  types?: JobType[]
+  jobId?: string
+}
+
+// Open an event stream for the given job types or job. Recent events are
+// replayed first. The browser reconnects by itself and sends the last event
+// ID, so nothing is missed. Returns a function that closes the stream.
+export function subscribeJobEvents(
+  filter: JobEventFilter,
+  onEvent: (event: JobEvent) => void
+): () => void {
+  const params = new URLSearchParams()
+  if (filter.types?.length) params.set('type', filter.types.join(','))
+  if (filter.jobId) params.set('job', filter.jobId)
+
+  const source = new EventSource(withBase(`/api/events?${params}`))
+  source.onmessage = (message) => {
+    try {
+      onEvent(JSON.parse(message.data) as JobEvent)
+    } catch {
+      // Ignore malformed events
+    }
+  }
+  return () => source.close()
+}
+
+// Subscribe to job events while enabled. onEvent may change between renders
+// without reopening the stream.
+export function useJobEvents(
+  filter: JobEventFilter,
+  onEvent: (event: JobEvent) => void,
+  enabled = true
+) {
+  const handler = useRef(onEvent)
+  handler.current = onEvent
+
+  const types = filter.types?.join(',') ?? ''
+  const jobId = filter.jobId
+
+  useEffect(() => {
+    if (!enabled) return
+    return subscribeJobEvents(
+      { types: types ? (types.split(',') as JobType[]) : undefined, jobId },
+      (event) => handler.current(event)
+    )
+  }, [enabled, types, jobId])
+}
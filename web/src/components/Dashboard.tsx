@@ -38,6 +38,7 @@ import {
   FiEye,
   FiEyeOff,
   FiSettings,
+  FiZap,
 } from 'react-icons/fi'
 import { SiPostgresql } from 'react-icons/si'
 import * as api from '../api/client'
@@ -505,6 +506,12 @@ export default function Dashboard() {
     refetchInterval: () => pingIntervalRef.current * 1000, // Use dynamic interval
   })
 
+  const { data: restCache } = useQuery({
+    queryKey: ['rest-cache'],
+    queryFn: api.getRestCache,
+    refetchInterval: () => pingIntervalRef.current * 1000,
+  })
+
   // Fetch PostgreSQL services
   const { data: pgServices } = useQuery({
     queryKey: ['pgservices'],
@@ -653,6 +660,15 @@ export default function Dashboard() {
                 <Text color="whiteAlpha.800">PG Services</Text>
               </HStack>
             )}
+            {restCache?.enabled && (
+              <Tooltip label={`${restCache.hits} hits, ${restCache.misses} misses, ${restCache.invalidations} invalidated, ${restCache.entries} cached`}>
+                <HStack spacing={2}>
+                  <Icon as={FiZap} color="yellow.200" />
+                  <Text fontWeight="bold" color="white">{Math.round(restCache.hitRate * 100)}%</Text>
+                  <Text color="whiteAlpha.800">Cache hits</Text>
+                </HStack>
+              </Tooltip>
+            )}
             <HStack spacing={2}>
               <Icon as={FiClock} color="whiteAlpha.700" />
               <Text color="whiteAlpha.800">{data?.pingIntervalSecs || 60}s</Text>
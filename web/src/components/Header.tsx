@@ -18,10 +18,11 @@ import {
   Image,
   Text,
 } from '@chakra-ui/react'
-import { FiSettings, FiRefreshCw, FiHelpCircle, FiRefreshCcw, FiSearch, FiChevronDown, FiUpload } from 'react-icons/fi'
+import { FiSettings, FiRefreshCw, FiHelpCircle, FiRefreshCcw, FiSearch, FiChevronDown, FiUpload, FiPackage, FiUser, FiLogOut } from 'react-icons/fi'
 import { useUIStore } from '../stores/uiStore'
 import { useConnectionStore } from '../stores/connectionStore'
 import { useTreeStore } from '../stores/treeStore'
+import { useAuthStore, useCan } from '../stores/authStore'
 
 interface HeaderProps {
   onSearchClick?: () => void
@@ -32,6 +33,10 @@ export default function Header({ onSearchClick, onHelpClick }: HeaderProps) {
   const openDialog = useUIStore((state) => state.openDialog)
   const fetchConnections = useConnectionStore((state) => state.fetchConnections)
   const selectedNode = useTreeStore((state) => state.selectedNode)
+  const user = useAuthStore((state) => state.user)
+  const logout = useAuthStore((state) => state.logout)
+  const isAdmin = useCan('admin')
+  const canUpload = useCan('editor', selectedNode?.connectionId, selectedNode?.workspace)
 
   const handleUpload = () => {
     if (!selectedNode) {
@@ -110,7 +115,7 @@ export default function Header({ onSearchClick, onHelpClick }: HeaderProps) {
             mr={8}
           >
             <Image
-              src="/kartoza-logo.svg"
+              src="kartoza-logo.svg"
               alt="Kartoza"
               h="36px"
             />
@@ -142,18 +147,30 @@ export default function Header({ onSearchClick, onHelpClick }: HeaderProps) {
                 </HStack>
               </MenuButton>
               <MenuList>
-                <MenuItem
-                  icon={<FiRefreshCcw />}
-                  onClick={() => openDialog('sync', { mode: 'create' })}
-                >
-                  Sync GeoServer(s)
-                </MenuItem>
-                <MenuItem
-                  icon={<FiUpload />}
-                  onClick={handleUpload}
-                >
-                  Upload Data
-                </MenuItem>
+                {isAdmin && (
+                  <MenuItem
+                    icon={<FiRefreshCcw />}
+                    onClick={() => openDialog('sync', { mode: 'create' })}
+                  >
+                    Sync GeoServer(s)
+                  </MenuItem>
+                )}
+                {canUpload && (
+                  <MenuItem
+                    icon={<FiUpload />}
+                    onClick={handleUpload}
+                  >
+                    Upload Data
+                  </MenuItem>
+                )}
+                {isAdmin && (
+                  <MenuItem
+                    icon={<FiPackage />}
+                    onClick={() => openDialog('bundle', { mode: 'view' })}
+                  >
+                    Import / Export Connections
+                  </MenuItem>
+                )}
               </MenuList>
             </Menu>
           </HStack>
@@ -232,6 +249,32 @@ export default function Header({ onSearchClick, onHelpClick }: HeaderProps) {
                 size="sm"
               />
             </Tooltip>
+            {user && (
+              <Menu>
+                <Tooltip label={user.username} placement="bottom">
+                  <MenuButton
+                    as={IconButton}
+                    aria-label="Account"
+                    icon={<FiUser size={18} />}
+                    variant="ghost"
+                    color="gray.600"
+                    _hover={{ bg: 'gray.100', color: 'kartoza.500' }}
+                    size="sm"
+                  />
+                </Tooltip>
+                <MenuList>
+                  <MenuItem
+                    icon={<FiUser />}
+                    onClick={() => openDialog('account', { mode: 'view' })}
+                  >
+                    Account & API Tokens
+                  </MenuItem>
+                  <MenuItem icon={<FiLogOut />} onClick={() => logout()}>
+                    Sign Out
+                  </MenuItem>
+                </MenuList>
+              </Menu>
+            )}
           </HStack>
         </Flex>
       </Box>
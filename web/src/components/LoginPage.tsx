@@ -0,0 +1,87 @@
+import {
+  Box,
+  Button,
+  Flex,
+  FormControl,
+  FormLabel,
+  Heading,
+  Input,
+  Alert,
+  AlertIcon,
+  VStack,
+  Image,
+  Text,
+} from '@chakra-ui/react'
+import { useState } from 'react'
+import { useAuthStore } from '../stores/authStore'
+
+export default function LoginPage() {
+  const login = useAuthStore((state) => state.login)
+  const error = useAuthStore((state) => state.error)
+  const [username, setUsername] = useState('')
+  const [password, setPassword] = useState('')
+  const [isSubmitting, setIsSubmitting] = useState(false)
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setIsSubmitting(true)
+    try {
+      await login(username, password)
+    } catch {
+      setPassword('')
+    } finally {
+      setIsSubmitting(false)
+    }
+  }
+
+  return (
+    <Flex minH="100vh" align="center" justify="center" bg="gray.50">
+      <Box
+        as="form"
+        onSubmit={handleSubmit}
+        bg="white"
+        p={8}
+        borderRadius="xl"
+        boxShadow="lg"
+        w="360px"
+      >
+        <VStack spacing={5} align="stretch">
+          <VStack spacing={2}>
+            <Image src="kartoza-logo.svg" alt="Kartoza" h="40px" fallback={<Box />} />
+            <Heading size="md" color="gray.800">Cloudbench</Heading>
+            <Text fontSize="sm" color="gray.500">Sign in to continue</Text>
+          </VStack>
+
+          {error && (
+            <Alert status="error" borderRadius="md" fontSize="sm">
+              <AlertIcon />
+              {error}
+            </Alert>
+          )}
+
+          <FormControl isRequired>
+            <FormLabel>Username</FormLabel>
+            <Input
+              value={username}
+              onChange={(e) => setUsername(e.target.value)}
+              autoComplete="username"
+              autoFocus
+            />
+          </FormControl>
+          <FormControl isRequired>
+            <FormLabel>Password</FormLabel>
+            <Input
+              type="password"
+              value={password}
+              onChange={(e) => setPassword(e.target.value)}
+              autoComplete="current-password"
+            />
+          </FormControl>
+          <Button type="submit" colorScheme="blue" isLoading={isSubmitting}>
+            Sign in
+          </Button>
+        </VStack>
+      </Box>
+    </Flex>
+  )
+}
@@ -29,9 +29,32 @@ import {
   FiCode,
   FiRefreshCw,
   FiPlus,
+  FiClock,
 } from 'react-icons/fi'
+import { useQuery } from '@tanstack/react-query'
 import { getNodeIconComponent, getNodeColor } from './utils'
 import type { TreeNodeRowProps } from './types'
+import type { NodeType } from '../../types'
+import { useCan } from '../../stores/authStore'
+import * as api from '../../api/client'
+
+// Containers and connections hold server-wide settings, which only admins manage
+const managedNodeTypes: NodeType[] = [
+  'root', 'cloudbench', 'geoserver', 'postgresql', 's3storage', 'qgisprojects', 'geonode',
+  'connection', 'pgservice', 's3connection', 'geonodeconnection',
+]
+
+// useOffline returns whether a connection's server is unreachable and what is
+// queued for it. Every row shares one query.
+export function useOffline(connectionId?: string) {
+  const { data } = useQuery({
+    queryKey: ['offline'],
+    queryFn: api.getOfflineStatus,
+    refetchInterval: 30000,
+    enabled: !!connectionId,
+  })
+  return data?.find((s) => s.connectionId === connectionId)
+}
 
 export function TreeNodeRow({
   node,
@@ -39,15 +62,16 @@ export function TreeNodeRow({
   isSelected,
   isLoading,
   onClick,
-  onAdd,
-  onEdit,
-  onDelete,
+  onAdd: onAddAction,
+  onEdit: onEditAction,
+  onDelete: onDeleteAction,
   onPreview,
   onTerria,
   onOpenAdmin,
+  onTimeline,
   onQuery,
   onShowData,
-  onUpload,
+  onUpload: onUploadAction,
   onRefresh,
   onDownloadConfig,
   onDownloadData,
@@ -55,6 +79,7 @@ export function TreeNodeRow({
   level,
   isLeaf,
   count,
+  status,
 }: TreeNodeRowProps) {
   const bgColor = useColorModeValue(
     isSelected ? 'kartoza.50' : 'transparent',
@@ -68,6 +93,19 @@ export function TreeNodeRow({
   const nodeColor = getNodeColor(node.type)
   const NodeIcon = getNodeIconComponent(node.type)
 
+  // Hide actions the server would refuse for this user
+  const isManaged = managedNodeTypes.includes(node.type)
+  const canEdit = useCan(isManaged ? 'admin' : 'editor', node.connectionId, node.workspace)
+  const canDelete = useCan('admin', node.connectionId, node.workspace)
+  const canUpload = useCan('editor', node.connectionId, node.workspace)
+  const onAdd = canEdit ? onAddAction : undefined
+  const onEdit = canEdit ? onEditAction : undefined
+  const onDelete = canDelete ? onDeleteAction : undefined
+  const onUpload = canUpload ? onUploadAction : undefined
+
+  // Rows of an unreachable server show the last-fetched catalog
+  const isStale = !!useOffline(node.connectionId)?.offline
+
   return (
     <Flex
       align="center"
@@ -122,9 +160,27 @@ export function TreeNodeRow({
         fontWeight={isSelected ? '600' : 'normal'}
         noOfLines={1}
         letterSpacing={isSelected ? '-0.01em' : 'normal'}
+        fontStyle={isStale ? 'italic' : 'normal'}
+        opacity={isStale ? 0.7 : 1}
       >
         {node.name}
       </Text>
+      {status && (
+        <Tooltip label={status.tooltip} fontSize="xs">
+          <Badge
+            colorScheme={status.colorScheme}
+            variant="subtle"
+            fontSize="xs"
+            borderRadius="full"
+            px={2}
+            mr={2}
+            cursor={status.onClick ? 'pointer' : 'default'}
+            onClick={status.onClick}
+          >
+            {status.label}
+          </Badge>
+        </Tooltip>
+      )}
       {count !== undefined && count >= 0 && (
         <Badge
           colorScheme={nodeColor.split('.')[0]}
@@ -267,6 +323,19 @@ export function TreeNodeRow({
             />
           </Tooltip>
         )}
+        {onTimeline && (
+          <Tooltip label="Catalog Timeline" fontSize="xs">
+            <IconButton
+              aria-label="Catalog Timeline"
+              icon={<FiClock size={14} />}
+              size="xs"
+              variant="ghost"
+              colorScheme="purple"
+              onClick={onTimeline}
+              _hover={{ bg: 'purple.50' }}
+            />
+          </Tooltip>
+        )}
         {onTerria && (
           <Tooltip label="Open in Terria 3D" fontSize="xs">
             <IconButton
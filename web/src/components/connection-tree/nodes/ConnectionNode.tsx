@@ -4,9 +4,9 @@ import { useTreeStore, generateNodeId } from '../../../stores/treeStore'
 import { useUIStore } from '../../../stores/uiStore'
 import type { TreeNode } from '../../../types'
 import * as api from '../../../api/client'
-import { TreeNodeRow } from '../TreeNodeRow'
+import { TreeNodeRow, useOffline } from '../TreeNodeRow'
 import { WorkspaceNode } from './WorkspaceNode'
-import type { ConnectionNodeProps } from '../types'
+import type { ConnectionNodeProps, TreeNodeStatus } from '../types'
 
 export function ConnectionNode({ connectionId, name, url }: ConnectionNodeProps) {
   const nodeId = generateNodeId('connection', connectionId)
@@ -58,6 +58,36 @@ export function ConnectionNode({ connectionId, name, url }: ConnectionNodeProps)
     window.open(adminUrl, '_blank', 'noopener,noreferrer')
   }
 
+  const handleTimeline = (e: React.MouseEvent) => {
+    e.stopPropagation()
+    openDialog('snapshots', { mode: 'view', data: { connectionId, name } })
+  }
+
+  // Flag an unreachable server and changes waiting for it
+  const offline = useOffline(connectionId)
+  const queued = offline?.queued.length || 0
+  const handleOffline = (e: React.MouseEvent) => {
+    e.stopPropagation()
+    openDialog('offline', { mode: 'view', data: { connectionId, name } })
+  }
+  let status: TreeNodeStatus | undefined
+  if (offline?.offline) {
+    const cachedAt = offline.cachedAt ? new Date(offline.cachedAt).toLocaleString() : 'earlier'
+    status = {
+      label: queued ? `offline, ${queued} queued` : 'offline',
+      tooltip: `Server unreachable; showing the catalog fetched ${cachedAt}. Changes are queued until it is back.`,
+      colorScheme: 'orange',
+      onClick: handleOffline,
+    }
+  } else if (queued) {
+    status = {
+      label: `${queued} queued`,
+      tooltip: 'The server is back. Review and replay the changes made while it was unreachable.',
+      colorScheme: 'yellow',
+      onClick: handleOffline,
+    }
+  }
+
   return (
     <Box>
       <TreeNodeRow
@@ -69,8 +99,10 @@ export function ConnectionNode({ connectionId, name, url }: ConnectionNodeProps)
         onEdit={handleEdit}
         onDelete={handleDelete}
         onOpenAdmin={handleOpenAdmin}
+        onTimeline={handleTimeline}
         level={2}
         count={workspaces?.length}
+        status={status}
       />
       {isExpanded && workspaces && (
         <Box pl={4}>
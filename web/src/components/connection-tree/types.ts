@@ -100,6 +100,7 @@ export interface TreeNodeRowProps {
   onPreview?: (e: React.MouseEvent) => void
   onTerria?: (e: React.MouseEvent) => void
   onOpenAdmin?: (e: React.MouseEvent) => void
+  onTimeline?: (e: React.MouseEvent) => void
   onQuery?: (e: React.MouseEvent) => void
   onShowData?: (e: React.MouseEvent) => void
   onUpload?: (e: React.MouseEvent) => void
@@ -110,6 +111,15 @@ export interface TreeNodeRowProps {
   level: number
   isLeaf?: boolean
   count?: number
+  status?: TreeNodeStatus
+}
+
+// A badge next to a node's name, e.g. an unreachable server
+export interface TreeNodeStatus {
+  label: string
+  tooltip: string
+  colorScheme: string
+  onClick?: (e: React.MouseEvent) => void
 }
 
 // S3 Storage types
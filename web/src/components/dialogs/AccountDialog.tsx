@@ -0,0 +1,374 @@
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  VStack,
+  HStack,
+  Text,
+  FormControl,
+  FormLabel,
+  FormHelperText,
+  Input,
+  Box,
+  Icon,
+  IconButton,
+  Tabs,
+  TabList,
+  Tab,
+  TabPanels,
+  TabPanel,
+  Alert,
+  AlertIcon,
+  Code,
+  Select,
+  Badge,
+  useToast,
+} from '@chakra-ui/react'
+import { useState } from 'react'
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
+import { FiUser, FiKey, FiLock, FiTrash2, FiUsers } from 'react-icons/fi'
+import { useUIStore } from '../../stores/uiStore'
+import { useAuthStore, useCan } from '../../stores/authStore'
+import * as authApi from '../../api/auth'
+
+export default function AccountDialog() {
+  const activeDialog = useUIStore((state) => state.activeDialog)
+  const closeDialog = useUIStore((state) => state.closeDialog)
+  const user = useAuthStore((state) => state.user)
+  const applySession = useAuthStore((state) => state.applySession)
+  const authEnabled = useAuthStore((state) => state.authEnabled)
+  const isAdmin = useCan('admin')
+  const queryClient = useQueryClient()
+  const toast = useToast()
+
+  const [tokenName, setTokenName] = useState('')
+  const [newToken, setNewToken] = useState<string | null>(null)
+  const [currentPassword, setCurrentPassword] = useState('')
+  const [newPassword, setNewPassword] = useState('')
+  const [confirmPassword, setConfirmPassword] = useState('')
+  const [newUsername, setNewUsername] = useState('')
+  const [newUserPassword, setNewUserPassword] = useState('')
+  const [newUserRole, setNewUserRole] = useState<authApi.Role>('viewer')
+
+  const isOpen = activeDialog === 'account'
+
+  const { data: tokens = [] } = useQuery({
+    queryKey: ['apiTokens'],
+    queryFn: authApi.getTokens,
+    enabled: isOpen,
+  })
+
+  const createTokenMutation = useMutation({
+    mutationFn: (name: string) => authApi.createToken(name),
+    onSuccess: (token) => {
+      setNewToken(token.token ?? null)
+      setTokenName('')
+      queryClient.invalidateQueries({ queryKey: ['apiTokens'] })
+    },
+    onError: (err: Error) => {
+      toast({ title: 'Failed to create token', description: err.message, status: 'error', duration: 5000 })
+    },
+  })
+
+  const deleteTokenMutation = useMutation({
+    mutationFn: (id: string) => authApi.deleteToken(id),
+    onSuccess: () => queryClient.invalidateQueries({ queryKey: ['apiTokens'] }),
+  })
+
+  const passwordMutation = useMutation({
+    mutationFn: () => authApi.changePassword(currentPassword, newPassword),
+    onSuccess: (session) => {
+      applySession(session)
+      setCurrentPassword('')
+      setNewPassword('')
+      setConfirmPassword('')
+      toast({ title: 'Password changed', description: 'Other sessions were signed out', status: 'success', duration: 3000 })
+    },
+    onError: (err: Error) => {
+      toast({ title: 'Failed to change password', description: err.message, status: 'error', duration: 5000 })
+    },
+  })
+
+  const showUsers = isAdmin && authEnabled
+
+  const { data: users = [] } = useQuery({
+    queryKey: ['authUsers'],
+    queryFn: authApi.getUsers,
+    enabled: isOpen && showUsers,
+  })
+
+  const createUserMutation = useMutation({
+    mutationFn: () => authApi.createUser({
+      username: newUsername,
+      password: newUserPassword,
+      role: newUserRole,
+      grants: [],
+    }),
+    onSuccess: () => {
+      setNewUsername('')
+      setNewUserPassword('')
+      setNewUserRole('viewer')
+      queryClient.invalidateQueries({ queryKey: ['authUsers'] })
+    },
+    onError: (err: Error) => {
+      toast({ title: 'Failed to create user', description: err.message, status: 'error', duration: 5000 })
+    },
+  })
+
+  const updateUserMutation = useMutation({
+    mutationFn: ({ id, req }: { id: string; req: authApi.AuthUserRequest }) => authApi.updateUser(id, req),
+    onSuccess: () => queryClient.invalidateQueries({ queryKey: ['authUsers'] }),
+    onError: (err: Error) => {
+      toast({ title: 'Failed to update user', description: err.message, status: 'error', duration: 5000 })
+    },
+  })
+
+  const deleteUserMutation = useMutation({
+    mutationFn: (id: string) => authApi.deleteUser(id),
+    onSuccess: () => queryClient.invalidateQueries({ queryKey: ['authUsers'] }),
+    onError: (err: Error) => {
+      toast({ title: 'Failed to delete user', description: err.message, status: 'error', duration: 5000 })
+    },
+  })
+
+  const handleClose = () => {
+    setNewToken(null)
+    closeDialog()
+  }
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="lg" isCentered>
+      <ModalOverlay bg="blackAlpha.600" backdropFilter="blur(4px)" />
+      <ModalContent borderRadius="xl" overflow="hidden">
+        {/* Header */}
+        <Box
+          bg="linear-gradient(135deg, #0a3a50 0%, #175a77 50%, #2d7d9b 100%)"
+          px={6}
+          py={4}
+        >
+          <HStack spacing={3}>
+            <Box bg="whiteAlpha.200" p={2} borderRadius="lg">
+              <Icon as={FiUser} boxSize={5} color="white" />
+            </Box>
+            <Box>
+              <Text color="white" fontWeight="600" fontSize="lg">
+                {user?.username}
+              </Text>
+              <Text color="whiteAlpha.800" fontSize="sm">
+                Password and API tokens
+              </Text>
+            </Box>
+          </HStack>
+        </Box>
+        <ModalCloseButton color="white" />
+
+        <ModalBody py={4}>
+          <Tabs colorScheme="blue" isFitted>
+            <TabList>
+              <Tab><Icon as={FiKey} mr={2} />API Tokens</Tab>
+              <Tab><Icon as={FiLock} mr={2} />Password</Tab>
+              {showUsers && <Tab><Icon as={FiUsers} mr={2} />Users</Tab>}
+            </TabList>
+            <TabPanels>
+              <TabPanel px={0}>
+                <VStack spacing={4} align="stretch">
+                  {newToken && (
+                    <Alert status="success" borderRadius="md" flexDirection="column" alignItems="start" gap={2}>
+                      <HStack><AlertIcon /><Text fontSize="sm">Copy this token now, it will not be shown again:</Text></HStack>
+                      <Code p={2} w="100%" wordBreak="break-all">{newToken}</Code>
+                    </Alert>
+                  )}
+                  <FormControl>
+                    <FormLabel>New token</FormLabel>
+                    <HStack>
+                      <Input
+                        value={tokenName}
+                        onChange={(e) => setTokenName(e.target.value)}
+                        placeholder="e.g. CI deploy script"
+                      />
+                      <Button
+                        colorScheme="blue"
+                        onClick={() => createTokenMutation.mutate(tokenName)}
+                        isLoading={createTokenMutation.isPending}
+                        isDisabled={!tokenName.trim()}
+                      >
+                        Create
+                      </Button>
+                    </HStack>
+                    <FormHelperText>
+                      Send it as <Code fontSize="xs">Authorization: Bearer &lt;token&gt;</Code> to use the API from scripts.
+                    </FormHelperText>
+                  </FormControl>
+                  {tokens.length === 0 ? (
+                    <Text fontSize="sm" color="gray.500">No tokens yet</Text>
+                  ) : (
+                    tokens.map((token) => (
+                      <HStack key={token.id} p={2} borderWidth="1px" borderRadius="md" justify="space-between">
+                        <Box>
+                          <Text fontSize="sm" fontWeight="500">{token.name}</Text>
+                          <Text fontSize="xs" color="gray.500">
+                            {token.prefix}… created {new Date(token.created_at).toLocaleDateString()}
+                          </Text>
+                        </Box>
+                        <IconButton
+                          aria-label="Revoke token"
+                          icon={<FiTrash2 />}
+                          size="sm"
+                          variant="ghost"
+                          colorScheme="red"
+                          onClick={() => deleteTokenMutation.mutate(token.id)}
+                        />
+                      </HStack>
+                    ))
+                  )}
+                </VStack>
+              </TabPanel>
+
+              <TabPanel px={0}>
+                <VStack spacing={4} align="stretch">
+                  <FormControl>
+                    <FormLabel>Current password</FormLabel>
+                    <Input
+                      type="password"
+                      value={currentPassword}
+                      onChange={(e) => setCurrentPassword(e.target.value)}
+                      autoComplete="current-password"
+                    />
+                  </FormControl>
+                  <FormControl>
+                    <FormLabel>New password</FormLabel>
+                    <Input
+                      type="password"
+                      value={newPassword}
+                      onChange={(e) => setNewPassword(e.target.value)}
+                      autoComplete="new-password"
+                    />
+                    <FormHelperText>At least 8 characters</FormHelperText>
+                  </FormControl>
+                  <FormControl isInvalid={confirmPassword !== '' && confirmPassword !== newPassword}>
+                    <FormLabel>Repeat new password</FormLabel>
+                    <Input
+                      type="password"
+                      value={confirmPassword}
+                      onChange={(e) => setConfirmPassword(e.target.value)}
+                      autoComplete="new-password"
+                    />
+                  </FormControl>
+                  <Button
+                    colorScheme="blue"
+                    onClick={() => passwordMutation.mutate()}
+                    isLoading={passwordMutation.isPending}
+                    isDisabled={!currentPassword || newPassword.length < 8 || newPassword !== confirmPassword}
+                  >
+                    Change Password
+                  </Button>
+                </VStack>
+              </TabPanel>
+
+              {showUsers && (
+                <TabPanel px={0}>
+                  <VStack spacing={4} align="stretch">
+                    <FormControl>
+                      <FormLabel>New user</FormLabel>
+                      <VStack spacing={2} align="stretch">
+                        <HStack>
+                          <Input
+                            value={newUsername}
+                            onChange={(e) => setNewUsername(e.target.value)}
+                            placeholder="Username"
+                          />
+                          <Select
+                            value={newUserRole}
+                            onChange={(e) => setNewUserRole(e.target.value as authApi.Role)}
+                            w="140px"
+                          >
+                            {authApi.ROLES.map((role) => (
+                              <option key={role} value={role}>{role}</option>
+                            ))}
+                          </Select>
+                        </HStack>
+                        <HStack>
+                          <Input
+                            type="password"
+                            value={newUserPassword}
+                            onChange={(e) => setNewUserPassword(e.target.value)}
+                            placeholder="Initial password"
+                            autoComplete="new-password"
+                          />
+                          <Button
+                            colorScheme="blue"
+                            onClick={() => createUserMutation.mutate()}
+                            isLoading={createUserMutation.isPending}
+                            isDisabled={!newUsername.trim() || newUserPassword.length < 8}
+                          >
+                            Add
+                          </Button>
+                        </HStack>
+                      </VStack>
+                      <FormHelperText>
+                        Viewers browse and preview, editors change layers and styles, admins manage connections, sync and deletes.
+                      </FormHelperText>
+                    </FormControl>
+                    {users.map((u) => (
+                      <HStack key={u.id} p={2} borderWidth="1px" borderRadius="md" justify="space-between">
+                        <Box>
+                          <HStack spacing={2}>
+                            <Text fontSize="sm" fontWeight="500">{u.username}</Text>
+                            {u.id === user?.id && <Badge fontSize="2xs">you</Badge>}
+                          </HStack>
+                          <Text fontSize="xs" color="gray.500">
+                            {u.grants.length > 0
+                              ? u.grants.map((g) => `${g.role} on ${g.connection}${g.workspace ? '/' + g.workspace : ''}`).join(', ')
+                              : `created ${new Date(u.created_at).toLocaleDateString()}`}
+                          </Text>
+                        </Box>
+                        <HStack>
+                          <Select
+                            size="sm"
+                            w="110px"
+                            value={u.role}
+                            onChange={(e) => updateUserMutation.mutate({
+                              id: u.id,
+                              req: { role: e.target.value as authApi.Role, grants: u.grants },
+                            })}
+                          >
+                            {authApi.ROLES.map((role) => (
+                              <option key={role} value={role}>{role}</option>
+                            ))}
+                          </Select>
+                          <IconButton
+                            aria-label="Delete user"
+                            icon={<FiTrash2 />}
+                            size="sm"
+                            variant="ghost"
+                            colorScheme="red"
+                            isDisabled={u.id === user?.id}
+                            onClick={() => deleteUserMutation.mutate(u.id)}
+                          />
+                        </HStack>
+                      </HStack>
+                    ))}
+                  </VStack>
+                </TabPanel>
+              )}
+            </TabPanels>
+          </Tabs>
+        </ModalBody>
+
+        <ModalFooter
+          borderTop="1px solid"
+          borderTopColor="gray.100"
+          bg="gray.50"
+        >
+          <Button onClick={handleClose} borderRadius="lg">
+            Close
+          </Button>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  )
+}
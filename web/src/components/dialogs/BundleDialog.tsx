@@ -0,0 +1,295 @@
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  VStack,
+  HStack,
+  Text,
+  FormControl,
+  FormLabel,
+  FormHelperText,
+  Input,
+  Checkbox,
+  CheckboxGroup,
+  SimpleGrid,
+  RadioGroup,
+  Radio,
+  Box,
+  Icon,
+  Tabs,
+  TabList,
+  Tab,
+  TabPanels,
+  TabPanel,
+  Alert,
+  AlertIcon,
+  List,
+  ListItem,
+  useToast,
+} from '@chakra-ui/react'
+import { useState } from 'react'
+import { useQueryClient } from '@tanstack/react-query'
+import { FiPackage, FiDownload, FiUpload } from 'react-icons/fi'
+import { useUIStore } from '../../stores/uiStore'
+import { useConnectionStore } from '../../stores/connectionStore'
+import * as api from '../../api/client'
+
+const kindLabels: { kind: api.BundleKind; label: string }[] = [
+  { kind: 'geoserver', label: 'GeoServer connections' },
+  { kind: 's3', label: 'S3 connections' },
+  { kind: 'geonode', label: 'GeoNode connections' },
+  { kind: 'pgservice', label: 'PostgreSQL services' },
+  { kind: 'sync', label: 'Sync configurations' },
+  { kind: 'query', label: 'Saved queries' },
+]
+
+export default function BundleDialog() {
+  const activeDialog = useUIStore((state) => state.activeDialog)
+  const closeDialog = useUIStore((state) => state.closeDialog)
+  const fetchConnections = useConnectionStore((state) => state.fetchConnections)
+  const queryClient = useQueryClient()
+  const toast = useToast()
+
+  // Export state
+  const [kinds, setKinds] = useState<api.BundleKind[]>(kindLabels.map((k) => k.kind))
+  const [exportPassphrase, setExportPassphrase] = useState('')
+  const [isExporting, setIsExporting] = useState(false)
+
+  // Import state
+  const [bundle, setBundle] = useState<api.ConnectionBundle | null>(null)
+  const [importPassphrase, setImportPassphrase] = useState('')
+  const [onConflict, setOnConflict] = useState<'skip' | 'replace' | 'duplicate'>('skip')
+  const [isImporting, setIsImporting] = useState(false)
+  const [result, setResult] = useState<api.BundleImportResult | null>(null)
+
+  const isOpen = activeDialog === 'bundle'
+
+  const handleClose = () => {
+    setBundle(null)
+    setResult(null)
+    setExportPassphrase('')
+    setImportPassphrase('')
+    closeDialog()
+  }
+
+  const handleExport = async () => {
+    setIsExporting(true)
+    try {
+      const exported = await api.exportBundle({
+        kinds,
+        passphrase: exportPassphrase || undefined,
+      })
+      const blob = new Blob([JSON.stringify(exported, null, 2)], { type: 'application/json' })
+      const url = URL.createObjectURL(blob)
+      const link = document.createElement('a')
+      link.href = url
+      link.download = `cloudbench-bundle-${new Date().toISOString().slice(0, 10)}.json`
+      link.click()
+      URL.revokeObjectURL(url)
+      toast({
+        title: 'Bundle exported',
+        description: exported.secrets_mode === 'encrypted' ? 'Secrets are encrypted' : 'Secrets are redacted',
+        status: 'success',
+        duration: 3000,
+      })
+    } catch (err) {
+      toast({
+        title: 'Export failed',
+        description: (err as Error).message,
+        status: 'error',
+        duration: 5000,
+      })
+    } finally {
+      setIsExporting(false)
+    }
+  }
+
+  const handleFileChange = async (e: React.ChangeEvent<HTMLInputElement>) => {
+    const file = e.target.files?.[0]
+    setResult(null)
+    if (!file) {
+      setBundle(null)
+      return
+    }
+    try {
+      setBundle(JSON.parse(await file.text()))
+    } catch {
+      setBundle(null)
+      toast({ title: 'Not a valid bundle file', status: 'error', duration: 3000 })
+    }
+  }
+
+  const handleImport = async () => {
+    if (!bundle) return
+    setIsImporting(true)
+    try {
+      const imported = await api.importBundle(bundle, importPassphrase, onConflict)
+      setResult(imported)
+      fetchConnections()
+      queryClient.invalidateQueries({ queryKey: ['s3connections'] })
+      queryClient.invalidateQueries({ queryKey: ['geonodeconnections'] })
+      queryClient.invalidateQueries({ queryKey: ['pgservices'] })
+      queryClient.invalidateQueries({ queryKey: ['syncConfigs'] })
+    } catch (err) {
+      toast({
+        title: 'Import failed',
+        description: (err as Error).message,
+        status: 'error',
+        duration: 5000,
+      })
+    } finally {
+      setIsImporting(false)
+    }
+  }
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="lg" isCentered>
+      <ModalOverlay bg="blackAlpha.600" backdropFilter="blur(4px)" />
+      <ModalContent borderRadius="xl" overflow="hidden">
+        {/* Header */}
+        <Box
+          bg="linear-gradient(135deg, #0a3a50 0%, #175a77 50%, #2d7d9b 100%)"
+          px={6}
+          py={4}
+        >
+          <HStack spacing={3}>
+            <Box bg="whiteAlpha.200" p={2} borderRadius="lg">
+              <Icon as={FiPackage} boxSize={5} color="white" />
+            </Box>
+            <Box>
+              <Text color="white" fontWeight="600" fontSize="lg">
+                Connection Bundles
+              </Text>
+              <Text color="whiteAlpha.800" fontSize="sm">
+                Share connections and settings with your team
+              </Text>
+            </Box>
+          </HStack>
+        </Box>
+        <ModalCloseButton color="white" />
+
+        <ModalBody py={4}>
+          <Tabs colorScheme="blue" isFitted>
+            <TabList>
+              <Tab><Icon as={FiDownload} mr={2} />Export</Tab>
+              <Tab><Icon as={FiUpload} mr={2} />Import</Tab>
+            </TabList>
+            <TabPanels>
+              <TabPanel px={0}>
+                <VStack spacing={5} align="stretch">
+                  <FormControl>
+                    <FormLabel>Include</FormLabel>
+                    <CheckboxGroup value={kinds} onChange={(v) => setKinds(v as api.BundleKind[])}>
+                      <SimpleGrid columns={2} spacing={2}>
+                        {kindLabels.map(({ kind, label }) => (
+                          <Checkbox key={kind} value={kind}>{label}</Checkbox>
+                        ))}
+                      </SimpleGrid>
+                    </CheckboxGroup>
+                    <FormHelperText>
+                      Sync configurations and saved queries also include the connections they use.
+                    </FormHelperText>
+                  </FormControl>
+                  <FormControl>
+                    <FormLabel>Shared passphrase</FormLabel>
+                    <Input
+                      type="password"
+                      value={exportPassphrase}
+                      onChange={(e) => setExportPassphrase(e.target.value)}
+                      placeholder="Leave empty to redact secrets"
+                    />
+                    <FormHelperText>
+                      Passwords, keys and tokens are encrypted with this passphrase, or left out without one.
+                    </FormHelperText>
+                  </FormControl>
+                  <Button
+                    leftIcon={<FiDownload />}
+                    colorScheme="blue"
+                    onClick={handleExport}
+                    isLoading={isExporting}
+                    isDisabled={kinds.length === 0}
+                  >
+                    Export Bundle
+                  </Button>
+                </VStack>
+              </TabPanel>
+
+              <TabPanel px={0}>
+                <VStack spacing={5} align="stretch">
+                  <FormControl>
+                    <FormLabel>Bundle file</FormLabel>
+                    <Input type="file" accept=".json,application/json" onChange={handleFileChange} pt={1} />
+                  </FormControl>
+                  {bundle?.secrets_mode === 'encrypted' && (
+                    <FormControl>
+                      <FormLabel>Bundle passphrase</FormLabel>
+                      <Input
+                        type="password"
+                        value={importPassphrase}
+                        onChange={(e) => setImportPassphrase(e.target.value)}
+                        placeholder="Leave empty to import without secrets"
+                      />
+                    </FormControl>
+                  )}
+                  <FormControl>
+                    <FormLabel>Existing objects</FormLabel>
+                    <RadioGroup value={onConflict} onChange={(v) => setOnConflict(v as typeof onConflict)}>
+                      <VStack align="start" spacing={1}>
+                        <Radio value="skip">Keep existing</Radio>
+                        <Radio value="replace">Replace (keeps existing secrets the bundle lacks)</Radio>
+                        <Radio value="duplicate">Import as copies</Radio>
+                      </VStack>
+                    </RadioGroup>
+                  </FormControl>
+                  <Button
+                    leftIcon={<FiUpload />}
+                    colorScheme="blue"
+                    onClick={handleImport}
+                    isLoading={isImporting}
+                    isDisabled={!bundle}
+                  >
+                    Import Bundle
+                  </Button>
+
+                  {result && (
+                    <Box>
+                      <Alert status="success" borderRadius="md" mb={2}>
+                        <AlertIcon />
+                        {result.added.length} added, {result.replaced.length} replaced, {result.skipped.length} skipped
+                      </Alert>
+                      {result.warnings?.map((warning) => (
+                        <Alert key={warning} status="warning" borderRadius="md" mb={2} fontSize="sm">
+                          <AlertIcon />
+                          {warning}
+                        </Alert>
+                      ))}
+                      <List spacing={1} fontSize="sm" color="gray.600" maxH="150px" overflowY="auto">
+                        {result.added.map((label) => <ListItem key={`a-${label}`}>Added {label}</ListItem>)}
+                        {result.replaced.map((label) => <ListItem key={`r-${label}`}>Replaced {label}</ListItem>)}
+                        {result.skipped.map((label) => <ListItem key={`s-${label}`}>Skipped {label}</ListItem>)}
+                      </List>
+                    </Box>
+                  )}
+                </VStack>
+              </TabPanel>
+            </TabPanels>
+          </Tabs>
+        </ModalBody>
+
+        <ModalFooter
+          borderTop="1px solid"
+          borderTopColor="gray.100"
+          bg="gray.50"
+        >
+          <Button onClick={handleClose} borderRadius="lg">
+            Close
+          </Button>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  )
+}
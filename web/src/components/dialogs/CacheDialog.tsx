@@ -43,10 +43,21 @@ import {
 import { useQuery, useQueryClient } from '@tanstack/react-query'
 import { FiDatabase, FiPlay, FiTrash2, FiStopCircle, FiRefreshCw } from 'react-icons/fi'
 import { useUIStore } from '../../stores/uiStore'
+import { useCan } from '../../stores/authStore'
 import { useTreeStore } from '../../stores/treeStore'
 import * as api from '../../api/client'
+import { useJobEvents } from '../../api/events'
 import type { GWCSeedRequest, GWCSeedTask } from '../../types'
 
+// Format bytes to human readable
+function formatBytes(bytes: number): string {
+  if (bytes === 0) return '0 B'
+  const k = 1024
+  const sizes = ['B', 'KB', 'MB', 'GB', 'TB']
+  const i = Math.floor(Math.log(bytes) / Math.log(k))
+  return parseFloat((bytes / Math.pow(k, i)).toFixed(1)) + ' ' + sizes[i]
+}
+
 export default function CacheDialog() {
   const activeDialog = useUIStore((state) => state.activeDialog)
   const dialogData = useUIStore((state) => state.dialogData)
@@ -78,14 +89,62 @@ export default function CacheDialog() {
     enabled: isOpen && !!connectionId && !!fullLayerName,
   })
 
-  // Fetch seed status (polling while seeding)
+  // Fetch seed status. Seeds started here are pushed as events; the slow
+  // poll on the progress tab picks up seeds started elsewhere.
   const { data: seedStatus, refetch: refetchSeedStatus } = useQuery({
     queryKey: ['gwc-seed-status', connectionId, fullLayerName],
     queryFn: () => api.getGWCSeedStatus(connectionId, fullLayerName),
     enabled: isOpen && !!connectionId && !!fullLayerName,
-    refetchInterval: activeTab === 1 ? 2000 : false, // Poll every 2 seconds on progress tab
+    refetchInterval: activeTab === 1 ? 15000 : false,
   })
 
+  // Local cache of layer data downloaded by syncs
+  const canManageCache = useCan('admin')
+  const { data: syncCache, refetch: refetchSyncCache } = useQuery({
+    queryKey: ['sync-cache'],
+    queryFn: api.getSyncCache,
+    enabled: isOpen && activeTab === 3,
+  })
+
+  const handleEvictSyncCache = async () => {
+    try {
+      const result = await api.evictSyncCache()
+      toast({
+        title: result.removed ? `Removed ${result.removed} cached resources (${formatBytes(result.freed)})` : 'The cache is within its limits',
+        status: 'success',
+        duration: 3000,
+      })
+      refetchSyncCache()
+    } catch (err) {
+      toast({ title: 'Eviction failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    }
+  }
+
+  const handleClearSyncCache = async (connId: string) => {
+    try {
+      await api.clearSyncCache(connId)
+      refetchSyncCache()
+    } catch (err) {
+      toast({ title: 'Clearing the cache failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    }
+  }
+
+  useJobEvents({ types: ['seed'], jobId: `${connectionId}/${fullLayerName}` }, (event) => {
+    if (event.job) {
+      queryClient.setQueryData(['gwc-seed-status', connectionId, fullLayerName], event.job as GWCSeedTask[])
+    } else {
+      if (event.type === 'complete' && event.message && event.message !== 'Seeding cancelled') {
+        toast({
+          title: 'Seeding failed',
+          description: event.message,
+          status: 'error',
+          duration: 5000,
+        })
+      }
+      refetchSeedStatus()
+    }
+  }, isOpen && !!connectionId && !!fullLayerName)
+
   // Set default grid set when data loads
   useEffect(() => {
     if (layerCache?.gridSubsets?.[0] && !selectedGridSet) {
@@ -124,7 +183,7 @@ export default function CacheDialog() {
       await api.seedLayer(connectionId, fullLayerName, request)
 
       toast({
-        title: `${seedType === 'seed' ? 'Seeding' : 'Reseeding'} started`,
+        title: `${seedType === 'seed' ? 'Seeding' : 'Reseeding'} queued`,
         description: `Layer: ${layerName}`,
         status: 'success',
         duration: 3000,
@@ -269,6 +328,7 @@ export default function CacheDialog() {
                   )}
                 </Tab>
                 <Tab>Info</Tab>
+                <Tab>Local Cache</Tab>
               </TabList>
 
               <TabPanels>
@@ -509,6 +569,67 @@ export default function CacheDialog() {
                     </Box>
                   </VStack>
                 </TabPanel>
+
+                {/* Local Cache Tab */}
+                <TabPanel px={0}>
+                  {!syncCache ? (
+                    <Spinner size="sm" />
+                  ) : (
+                    <VStack spacing={3} align="stretch">
+                      <Text fontSize="sm" color="gray.600">
+                        Layer data downloaded by syncs: {formatBytes(syncCache.size)}
+                        {syncCache.maxSize > 0 && ` of ${formatBytes(syncCache.maxSize)}`}
+                        {syncCache.maxAgeDays > 0 && `, kept up to ${syncCache.maxAgeDays} days`}
+                      </Text>
+                      {syncCache.servers.length === 0 ? (
+                        <Text fontSize="sm" color="gray.500">Nothing is cached.</Text>
+                      ) : (
+                        <Table size="sm">
+                          <Thead>
+                            <Tr>
+                              <Th>Server</Th>
+                              <Th isNumeric>Size</Th>
+                              <Th isNumeric>Resources</Th>
+                              <Th isNumeric>Pinned</Th>
+                              <Th />
+                            </Tr>
+                          </Thead>
+                          <Tbody>
+                            {syncCache.servers.map((server) => (
+                              <Tr key={server.connectionId}>
+                                <Td>{server.name || server.connectionId}</Td>
+                                <Td isNumeric>{formatBytes(server.size)}</Td>
+                                <Td isNumeric>{server.entries}</Td>
+                                <Td isNumeric>
+                                  <Tooltip label="Kept regardless of the limits because a sync configuration reads them" fontSize="xs">
+                                    <span>{server.pinned}</span>
+                                  </Tooltip>
+                                </Td>
+                                <Td>
+                                  {canManageCache && (
+                                    <IconButton
+                                      aria-label="Clear"
+                                      icon={<FiTrash2 />}
+                                      size="xs"
+                                      variant="ghost"
+                                      colorScheme="red"
+                                      onClick={() => handleClearSyncCache(server.connectionId)}
+                                    />
+                                  )}
+                                </Td>
+                              </Tr>
+                            ))}
+                          </Tbody>
+                        </Table>
+                      )}
+                      {canManageCache && (syncCache.maxSize > 0 || syncCache.maxAgeDays > 0) && (
+                        <Button size="sm" leftIcon={<FiRefreshCw />} onClick={handleEvictSyncCache} alignSelf="start">
+                          Enforce Limits Now
+                        </Button>
+                      )}
+                    </VStack>
+                  )}
+                </TabPanel>
               </TabPanels>
             </Tabs>
           )}
@@ -100,7 +100,7 @@ export default function ConnectionDialog() {
         setName(conn.name)
         setUrl(conn.url)
         setUsername(conn.username)
-        setPassword(conn.password || '')
+        setPassword('')
         setShowPassword(false)
       }
     } else if (isOpen && !isEditMode) {
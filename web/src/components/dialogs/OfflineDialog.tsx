@@ -0,0 +1,180 @@
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalHeader,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  VStack,
+  HStack,
+  Text,
+  Icon,
+  Badge,
+  Alert,
+  AlertIcon,
+  Table,
+  Thead,
+  Tbody,
+  Tr,
+  Th,
+  Td,
+  Code,
+  useToast,
+} from '@chakra-ui/react'
+import { useState } from 'react'
+import { useQueryClient } from '@tanstack/react-query'
+import { FiWifiOff, FiPlay, FiTrash2 } from 'react-icons/fi'
+import { useUIStore } from '../../stores/uiStore'
+import { useCan } from '../../stores/authStore'
+import { useOffline } from '../connection-tree/TreeNodeRow'
+import * as api from '../../api/client'
+
+const methodColors: Record<string, string> = {
+  POST: 'green',
+  PUT: 'orange',
+  DELETE: 'red',
+}
+
+export default function OfflineDialog() {
+  const activeDialog = useUIStore((state) => state.activeDialog)
+  const dialogData = useUIStore((state) => state.dialogData)
+  const closeDialog = useUIStore((state) => state.closeDialog)
+  const queryClient = useQueryClient()
+  const toast = useToast()
+
+  const isOpen = activeDialog === 'offline'
+  const connectionId = (dialogData?.data?.connectionId as string) || ''
+  const name = (dialogData?.data?.name as string) || connectionId
+  const canReplay = useCan('editor', connectionId)
+  const canDiscard = useCan('admin', connectionId)
+  const status = useOffline(isOpen ? connectionId : undefined)
+  const queued = status?.queued || []
+
+  const [isReplaying, setIsReplaying] = useState(false)
+  const [replayError, setReplayError] = useState('')
+
+  const refresh = () => {
+    queryClient.invalidateQueries({ queryKey: ['offline'] })
+    queryClient.invalidateQueries({ queryKey: ['workspaces', connectionId] })
+  }
+
+  const handleClose = () => {
+    setReplayError('')
+    closeDialog()
+  }
+
+  const handleReplay = async () => {
+    setIsReplaying(true)
+    setReplayError('')
+    try {
+      const result = await api.replayOfflineChanges(connectionId)
+      if (result.error) {
+        setReplayError(result.error)
+      } else {
+        toast({ title: `Replayed ${result.replayed} changes`, status: 'success', duration: 3000 })
+      }
+      refresh()
+    } catch (err) {
+      setReplayError((err as Error).message)
+    } finally {
+      setIsReplaying(false)
+    }
+  }
+
+  const handleDiscard = async () => {
+    try {
+      await api.discardOfflineChanges(connectionId)
+      toast({ title: 'Queued changes discarded', status: 'info', duration: 3000 })
+      refresh()
+    } catch (err) {
+      toast({ title: 'Discard failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    }
+  }
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="3xl" scrollBehavior="inside">
+      <ModalOverlay />
+      <ModalContent>
+        <ModalHeader>
+          <HStack>
+            <Icon as={FiWifiOff} />
+            <Text>Offline Changes: {name}</Text>
+          </HStack>
+        </ModalHeader>
+        <ModalCloseButton />
+        <ModalBody>
+          <VStack align="stretch" spacing={4}>
+            {status?.offline ? (
+              <Alert status="warning">
+                <AlertIcon />
+                The server has been unreachable since {status.since ? new Date(status.since).toLocaleString() : 'recently'}.
+                The tree shows the catalog fetched {status.cachedAt ? new Date(status.cachedAt).toLocaleString() : 'earlier'},
+                and changes are queued until the server is back.
+              </Alert>
+            ) : (
+              queued.length > 0 && (
+                <Alert status="info">
+                  <AlertIcon />
+                  The server is reachable again. Review these changes before replaying them, oldest first.
+                </Alert>
+              )
+            )}
+            {replayError && (
+              <Alert status="error">
+                <AlertIcon />
+                {replayError}
+              </Alert>
+            )}
+            {queued.length === 0 ? (
+              <Text color="gray.500">No changes are queued.</Text>
+            ) : (
+              <Table size="sm">
+                <Thead>
+                  <Tr>
+                    <Th>Change</Th>
+                    <Th>Resource</Th>
+                    <Th>Queued</Th>
+                  </Tr>
+                </Thead>
+                <Tbody>
+                  {queued.map((c, i) => (
+                    <Tr key={i}>
+                      <Td><Badge colorScheme={methodColors[c.method] || 'gray'}>{c.method}</Badge></Td>
+                      <Td><Code fontSize="xs">{c.path}</Code></Td>
+                      <Td fontSize="xs">{new Date(c.queuedAt).toLocaleString()}</Td>
+                    </Tr>
+                  ))}
+                </Tbody>
+              </Table>
+            )}
+          </VStack>
+        </ModalBody>
+        <ModalFooter>
+          <HStack spacing={3}>
+            {canDiscard && queued.length > 0 && (
+              <Button leftIcon={<FiTrash2 />} variant="ghost" colorScheme="red" onClick={handleDiscard}>
+                Discard
+              </Button>
+            )}
+            {canReplay && queued.length > 0 && (
+              <Button
+                leftIcon={<FiPlay />}
+                colorScheme="kartoza"
+                onClick={handleReplay}
+                isLoading={isReplaying}
+                isDisabled={status?.offline}
+              >
+                Replay {queued.length} Changes
+              </Button>
+            )}
+            <Button variant="ghost" onClick={handleClose}>
+              Close
+            </Button>
+          </HStack>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  )
+}
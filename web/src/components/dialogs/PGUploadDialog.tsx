@@ -43,6 +43,7 @@ import {
 import { useQueryClient } from '@tanstack/react-query'
 import { useUIStore } from '../../stores/uiStore'
 import * as api from '../../api/client'
+import { subscribeJobEvents } from '../../api/events'
 
 interface FileUpload {
   file: File
@@ -350,9 +351,9 @@ export default function PGUploadDialog() {
 
     setImporting(false)
 
-    // Poll for job completion
+    // Watch for job completion
     if (jobIds.length > 0) {
-      pollJobStatus(jobIds)
+      watchJobs(jobIds)
     }
 
     // Invalidate queries to refresh the tree
@@ -369,42 +370,37 @@ export default function PGUploadDialog() {
     }
   }
 
-  const pollJobStatus = async (jobIds: string[]) => {
+  const watchJobs = (jobIds: string[]) => {
     const pendingJobs = new Set(jobIds)
 
-    while (pendingJobs.size > 0) {
-      await new Promise((resolve) => setTimeout(resolve, 2000))
-
-      for (const jobId of Array.from(pendingJobs)) {
-        try {
-          const status = await api.getImportJobStatus(jobId)
-          if (status.status === 'completed' || status.status === 'failed') {
-            pendingJobs.delete(jobId)
-
-            if (status.status === 'completed') {
-              toast({
-                title: 'Import completed',
-                description: `Table ${status.target_table} created successfully`,
-                status: 'success',
-                duration: 3000,
-              })
-            } else {
-              toast({
-                title: 'Import failed',
-                description: status.error || 'Unknown error',
-                status: 'error',
-                duration: 5000,
-              })
-            }
-          }
-        } catch (err) {
-          pendingJobs.delete(jobId)
-        }
+    // Completion events are replayed if a job finishes before the stream opens
+    const close = subscribeJobEvents({ types: ['import'] }, (event) => {
+      if (event.type !== 'complete' || !pendingJobs.has(event.jobId)) return
+      pendingJobs.delete(event.jobId)
+
+      const job = event.job as api.ImportJob
+      if (job.status === 'completed') {
+        toast({
+          title: 'Import completed',
+          description: `Table ${job.target_table} created successfully`,
+          status: 'success',
+          duration: 3000,
+        })
+      } else {
+        toast({
+          title: 'Import failed',
+          description: job.error || 'Unknown error',
+          status: 'error',
+          duration: 5000,
+        })
       }
-    }
 
-    // Refresh after all jobs complete
-    queryClient.invalidateQueries({ queryKey: ['pgschemas', serviceName] })
+      // Refresh after all jobs complete
+      if (pendingJobs.size === 0) {
+        close()
+        queryClient.invalidateQueries({ queryKey: ['pgschemas', serviceName] })
+      }
+    })
   }
 
   const toggleLayerSelection = (layerName: string) => {
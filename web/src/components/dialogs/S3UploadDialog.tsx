@@ -29,6 +29,8 @@ import { FiUpload, FiFile, FiCheckCircle, FiAlertCircle, FiRefreshCw } from 'rea
 import { useQuery, useQueryClient } from '@tanstack/react-query'
 import { useUIStore } from '../../stores/uiStore'
 import * as api from '../../api/client'
+import { useJobEvents } from '../../api/events'
+import type { ConversionJob } from '../../types'
 
 // Helper to format file size
 function formatFileSize(bytes: number): string {
@@ -103,14 +105,19 @@ export default function S3UploadDialog() {
     enabled: isOpen,
   })
 
-  // Poll for conversion job status
+  // Conversion job status, kept up to date by events from the server
   const { data: conversionJob } = useQuery({
     queryKey: ['conversionJob', conversionJobId],
     queryFn: () => conversionJobId ? api.getConversionJob(conversionJobId) : null,
     enabled: !!conversionJobId,
-    refetchInterval: conversionJobId ? 2000 : false,
   })
 
+  useJobEvents({ types: ['conversion'], jobId: conversionJobId ?? undefined }, (event) => {
+    if (event.job) {
+      queryClient.setQueryData(['conversionJob', event.jobId], event.job as ConversionJob)
+    }
+  }, !!conversionJobId)
+
   // Reset form when dialog opens
   useEffect(() => {
     if (isOpen) {
@@ -0,0 +1,269 @@
+import {
+  Modal,
+  ModalOverlay,
+  ModalContent,
+  ModalHeader,
+  ModalFooter,
+  ModalBody,
+  ModalCloseButton,
+  Button,
+  VStack,
+  HStack,
+  Text,
+  Select,
+  Box,
+  Icon,
+  Badge,
+  Spinner,
+  Alert,
+  AlertIcon,
+  Accordion,
+  AccordionItem,
+  AccordionButton,
+  AccordionPanel,
+  AccordionIcon,
+  Table,
+  Thead,
+  Tbody,
+  Tr,
+  Th,
+  Td,
+  Code,
+  IconButton,
+  Tooltip,
+  useToast,
+} from '@chakra-ui/react'
+import { useEffect, useState } from 'react'
+import { useQuery, useQueryClient } from '@tanstack/react-query'
+import { FiClock, FiCamera, FiRotateCcw, FiTrash2 } from 'react-icons/fi'
+import { useUIStore } from '../../stores/uiStore'
+import { useCan } from '../../stores/authStore'
+import * as api from '../../api/client'
+
+const actionColors: Record<api.SnapshotChange['action'], string> = {
+  added: 'green',
+  removed: 'red',
+  modified: 'orange',
+}
+
+function snapshotLabel(s: api.CatalogSnapshot) {
+  return `${new Date(s.time).toLocaleString()} (${s.trigger}, ${s.objects} objects)`
+}
+
+export default function SnapshotsDialog() {
+  const activeDialog = useUIStore((state) => state.activeDialog)
+  const dialogData = useUIStore((state) => state.dialogData)
+  const closeDialog = useUIStore((state) => state.closeDialog)
+  const queryClient = useQueryClient()
+  const toast = useToast()
+
+  const isOpen = activeDialog === 'snapshots'
+  const connectionId = (dialogData?.data?.connectionId as string) || ''
+  const name = (dialogData?.data?.name as string) || connectionId
+  const canEdit = useCan('editor', connectionId)
+  const canDelete = useCan('admin', connectionId)
+
+  const [from, setFrom] = useState('')
+  const [to, setTo] = useState('')
+  const [isTaking, setIsTaking] = useState(false)
+  const [restoring, setRestoring] = useState('')
+
+  const { data: snapshots, isLoading } = useQuery({
+    queryKey: ['snapshots', connectionId],
+    queryFn: () => api.getSnapshots(connectionId),
+    enabled: isOpen && !!connectionId,
+  })
+
+  // Compare the two latest snapshots until others are picked
+  useEffect(() => {
+    if (!snapshots) return
+    if (!snapshots.some((s) => s.id === to)) setTo(snapshots[0]?.id || '')
+    if (!snapshots.some((s) => s.id === from)) setFrom(snapshots[1]?.id || '')
+  }, [snapshots, from, to])
+
+  const { data: changes, isLoading: isDiffing, error: diffError } = useQuery({
+    queryKey: ['snapshot-diff', connectionId, from, to],
+    queryFn: () => api.diffSnapshots(connectionId, from, to),
+    enabled: isOpen && !!from && !!to && from !== to,
+  })
+
+  const handleClose = () => {
+    setFrom('')
+    setTo('')
+    closeDialog()
+  }
+
+  const handleTake = async () => {
+    setIsTaking(true)
+    try {
+      const result = await api.takeSnapshot(connectionId)
+      toast({
+        title: result.created ? 'Snapshot taken' : 'No changes since the latest snapshot',
+        status: result.created ? 'success' : 'info',
+        duration: 3000,
+      })
+      queryClient.invalidateQueries({ queryKey: ['snapshots', connectionId] })
+    } catch (err) {
+      toast({ title: 'Snapshot failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    } finally {
+      setIsTaking(false)
+    }
+  }
+
+  const handleRestore = async (change: api.SnapshotChange) => {
+    setRestoring(change.path)
+    try {
+      const result = await api.restoreSnapshotObject(connectionId, from, change.path)
+      toast({
+        title: result.changes.length ? `Restored ${change.kind} ${change.name}` : `${change.kind} ${change.name} already matches`,
+        status: 'success',
+        duration: 3000,
+      })
+      queryClient.invalidateQueries({ queryKey: ['workspaces', connectionId] })
+    } catch (err) {
+      toast({ title: 'Restore failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    } finally {
+      setRestoring('')
+    }
+  }
+
+  const handleDelete = async (id: string) => {
+    try {
+      await api.deleteSnapshot(connectionId, id)
+      queryClient.invalidateQueries({ queryKey: ['snapshots', connectionId] })
+    } catch (err) {
+      toast({ title: 'Delete failed', description: (err as Error).message, status: 'error', duration: 5000 })
+    }
+  }
+
+  const fromSnapshot = snapshots?.find((s) => s.id === from)
+
+  return (
+    <Modal isOpen={isOpen} onClose={handleClose} size="4xl" scrollBehavior="inside">
+      <ModalOverlay />
+      <ModalContent>
+        <ModalHeader>
+          <HStack>
+            <Icon as={FiClock} />
+            <Text>Catalog Timeline: {name}</Text>
+          </HStack>
+        </ModalHeader>
+        <ModalCloseButton />
+        <ModalBody>
+          <VStack align="stretch" spacing={4}>
+            {isLoading && <Spinner />}
+            {snapshots && snapshots.length < 2 && (
+              <Alert status="info">
+                <AlertIcon />
+                {snapshots.length === 0
+                  ? 'No snapshots yet. Take one now, and another after the catalog changes, to compare them.'
+                  : 'Take another snapshot after the catalog changes to compare the two.'}
+              </Alert>
+            )}
+            {snapshots && snapshots.length > 0 && (
+              <HStack align="end">
+                <Box flex={1}>
+                  <Text fontSize="sm" color="gray.500">From</Text>
+                  <HStack>
+                    <Select size="sm" value={from} onChange={(e) => setFrom(e.target.value)}>
+                      {snapshots.map((s) => (
+                        <option key={s.id} value={s.id}>{snapshotLabel(s)}</option>
+                      ))}
+                    </Select>
+                    {canDelete && from && (
+                      <Tooltip label="Delete this snapshot" fontSize="xs">
+                        <IconButton aria-label="Delete snapshot" icon={<FiTrash2 />} size="sm" variant="ghost" colorScheme="red" onClick={() => handleDelete(from)} />
+                      </Tooltip>
+                    )}
+                  </HStack>
+                </Box>
+                <Box flex={1}>
+                  <Text fontSize="sm" color="gray.500">To</Text>
+                  <Select size="sm" value={to} onChange={(e) => setTo(e.target.value)}>
+                    {snapshots.map((s) => (
+                      <option key={s.id} value={s.id}>{snapshotLabel(s)}</option>
+                    ))}
+                  </Select>
+                </Box>
+              </HStack>
+            )}
+
+            {isDiffing && <Spinner />}
+            {diffError && (
+              <Alert status="error">
+                <AlertIcon />
+                {(diffError as Error).message}
+              </Alert>
+            )}
+            {changes && changes.length === 0 && <Text color="gray.500">No changes between these snapshots.</Text>}
+            {changes && changes.length > 0 && (
+              <Accordion allowMultiple>
+                {changes.map((c) => (
+                  <AccordionItem key={c.path}>
+                    <HStack>
+                      <AccordionButton flex={1}>
+                        <HStack flex={1} spacing={3}>
+                          <Badge colorScheme={actionColors[c.action]}>{c.action}</Badge>
+                          <Text fontSize="sm" color="gray.500">{c.kind}</Text>
+                          <Text fontSize="sm" fontWeight="medium">
+                            {c.workspace && c.kind !== 'workspace' ? `${c.workspace}:${c.name}` : c.name}
+                          </Text>
+                        </HStack>
+                        <AccordionIcon />
+                      </AccordionButton>
+                      {canEdit && c.action !== 'added' && (
+                        <Tooltip label={`Make ${c.name} match the ${fromSnapshot ? new Date(fromSnapshot.time).toLocaleString() : 'From'} snapshot`} fontSize="xs">
+                          <IconButton
+                            aria-label="Restore"
+                            icon={<FiRotateCcw />}
+                            size="xs"
+                            variant="ghost"
+                            colorScheme="blue"
+                            isLoading={restoring === c.path}
+                            onClick={() => handleRestore(c)}
+                          />
+                        </Tooltip>
+                      )}
+                    </HStack>
+                    <AccordionPanel>
+                      <Table size="sm">
+                        <Thead>
+                          <Tr>
+                            <Th>Field</Th>
+                            <Th>From</Th>
+                            <Th>To</Th>
+                          </Tr>
+                        </Thead>
+                        <Tbody>
+                          {(c.fields || []).map((f) => (
+                            <Tr key={f.field}>
+                              <Td fontSize="xs">{f.field}</Td>
+                              <Td><Code fontSize="xs" whiteSpace="pre-wrap" colorScheme="red">{f.from}</Code></Td>
+                              <Td><Code fontSize="xs" whiteSpace="pre-wrap" colorScheme="green">{f.to}</Code></Td>
+                            </Tr>
+                          ))}
+                        </Tbody>
+                      </Table>
+                    </AccordionPanel>
+                  </AccordionItem>
+                ))}
+              </Accordion>
+            )}
+          </VStack>
+        </ModalBody>
+        <ModalFooter>
+          <HStack spacing={3}>
+            {canEdit && (
+              <Button leftIcon={<FiCamera />} colorScheme="kartoza" onClick={handleTake} isLoading={isTaking}>
+                Take Snapshot
+              </Button>
+            )}
+            <Button variant="ghost" onClick={handleClose}>
+              Close
+            </Button>
+          </HStack>
+        </ModalFooter>
+      </ModalContent>
+    </Modal>
+  )
+}
@@ -30,7 +30,7 @@ import {
   useDisclosure,
 } from '@chakra-ui/react'
 import { keyframes, css } from '@emotion/react'
-import { useQuery, useMutation } from '@tanstack/react-query'
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
 import { useState, useEffect, useRef } from 'react'
 import {
   FiServer,
@@ -56,8 +56,10 @@ import {
   FiActivity,
   FiDownload,
   FiX,
+  FiRotateCcw,
 } from 'react-icons/fi'
 import * as api from '../../api/client'
+import { useJobEvents } from '../../api/events'
 import type { Connection, SyncConfiguration, SyncTask, SyncOptions, StartSyncRequest } from '../../types'
 import { useUIStore } from '../../stores/uiStore'
 import { useConnectionStore } from '../../stores/connectionStore'
@@ -184,9 +186,11 @@ interface DestinationServerProps {
   task?: SyncTask
   onRemove: () => void
   onStop: () => void
+  onResume: () => void
+  onRetry: () => void
 }
 
-function DestinationServer({ connection, isRunning, task, onRemove, onStop }: DestinationServerProps) {
+function DestinationServer({ connection, isRunning, task, onRemove, onStop, onResume, onRetry }: DestinationServerProps) {
   const progress = task?.progress || 0
 
   return (
@@ -290,16 +294,42 @@ function DestinationServer({ connection, isRunning, task, onRemove, onStop }: De
               />
             </Tooltip>
           ) : (
-            <Tooltip label="Remove">
-              <IconButton
-                aria-label="Remove destination"
-                icon={<FiTrash2 />}
-                size="xs"
-                colorScheme="red"
-                variant="ghost"
-                onClick={(e) => { e.stopPropagation(); onRemove(); }}
-              />
-            </Tooltip>
+            <>
+              {task?.resumable && (
+                <Tooltip label="Resume sync">
+                  <IconButton
+                    aria-label="Resume sync"
+                    icon={<FiPlay />}
+                    size="xs"
+                    colorScheme="blue"
+                    variant="ghost"
+                    onClick={(e) => { e.stopPropagation(); onResume(); }}
+                  />
+                </Tooltip>
+              )}
+              {task?.resumable && task.itemsFailed > 0 && (
+                <Tooltip label="Retry failed items">
+                  <IconButton
+                    aria-label="Retry failed items"
+                    icon={<FiRotateCcw />}
+                    size="xs"
+                    colorScheme="orange"
+                    variant="ghost"
+                    onClick={(e) => { e.stopPropagation(); onRetry(); }}
+                  />
+                </Tooltip>
+              )}
+              <Tooltip label="Remove">
+                <IconButton
+                  aria-label="Remove destination"
+                  icon={<FiTrash2 />}
+                  size="xs"
+                  colorScheme="red"
+                  variant="ghost"
+                  onClick={(e) => { e.stopPropagation(); onRemove(); }}
+                />
+              </Tooltip>
+            </>
           )}
         </HStack>
       </VStack>
@@ -443,6 +473,29 @@ function SyncOptionsPanel({ options, onChange }: SyncOptionsProps) {
               <Text fontSize="sm">Layer Groups</Text>
             </HStack>
           </Checkbox>
+
+          <Checkbox
+            isChecked={!!options.gwc}
+            onChange={() => handleToggle('gwc')}
+            colorScheme="kartoza"
+          >
+            <HStack spacing={1}>
+              <Icon as={FiPackage} color="teal.500" boxSize={3} />
+              <Text fontSize="sm">Tile Caching</Text>
+            </HStack>
+          </Checkbox>
+
+          <Checkbox
+            isChecked={!!options.gwc_seed}
+            onChange={() => handleToggle('gwc_seed')}
+            isDisabled={!options.gwc}
+            colorScheme="kartoza"
+          >
+            <HStack spacing={1}>
+              <Icon as={FiActivity} color="teal.500" boxSize={3} />
+              <Text fontSize="sm">Seed New Layers</Text>
+            </HStack>
+          </Checkbox>
         </SimpleGrid>
       </Collapse>
     </Box>
@@ -554,6 +607,8 @@ export function SyncDialog() {
     layers: true,
     styles: true,
     layergroups: true,
+    gwc: false,
+    gwc_seed: false,
   })
   const [hoveredSource, setHoveredSource] = useState(false)
   const [configName, setConfigName] = useState('')
@@ -570,11 +625,26 @@ export function SyncDialog() {
     queryKey: ['syncStatus'],
     queryFn: api.getSyncStatus,
     enabled: isOpen,
-    refetchInterval: 1000, // Poll every second when dialog is open
   })
 
+  // Progress is pushed by the server; log lines and completions refetch the
+  // tasks, batched so a burst of events causes one request
+  const queryClient = useQueryClient()
+  const refetchTimer = useRef<ReturnType<typeof setTimeout>>()
+  useJobEvents({ types: ['sync'] }, (event) => {
+    const update = event.job as Partial<SyncTask> | undefined
+    if (event.type === 'progress' && update) {
+      queryClient.setQueryData<SyncTask[]>(['syncStatus'], (tasks) =>
+        tasks?.map((t) => (t.id === event.jobId ? { ...t, ...update } : t))
+      )
+      return
+    }
+    clearTimeout(refetchTimer.current)
+    refetchTimer.current = setTimeout(() => refetchTasks(), 300)
+  }, isOpen)
+
   // Check if any syncs are running
-  const isAnyRunning = runningTasks.some(t => t.status === 'running')
+  const isAnyRunning = runningTasks.some(t => t.status === 'running' || t.status === 'pending')
 
   // Mutations
   const startSyncMutation = useMutation({
@@ -610,6 +680,27 @@ export function SyncDialog() {
     },
   })
 
+  const resumeSyncMutation = useMutation({
+    mutationFn: ({ taskId, retryOnly }: { taskId: string; retryOnly: boolean }) =>
+      retryOnly ? api.retrySyncTask(taskId) : api.resumeSyncTask(taskId),
+    onSuccess: (_, { retryOnly }) => {
+      toast({
+        title: retryOnly ? 'Retrying failed items' : 'Sync resumed',
+        status: 'info',
+        duration: 2000,
+      })
+      refetchTasks()
+    },
+    onError: (error: Error) => {
+      toast({
+        title: 'Failed to restart sync',
+        description: error.message,
+        status: 'error',
+        duration: 5000,
+      })
+    },
+  })
+
   const stopAllMutation = useMutation({
     mutationFn: api.stopAllSyncs,
     onSuccess: () => {
@@ -714,6 +805,8 @@ export function SyncDialog() {
       layers: true,
       styles: true,
       layergroups: true,
+      gwc: false,
+      gwc_seed: false,
     }
     setOptions({ ...defaultOptions, ...(config.options || {}) })
     setSelectedConfigId(config.id)
@@ -911,7 +1004,7 @@ export function SyncDialog() {
                       const conn = getConnection(destId)
                       if (!conn) return null
                       const task = getTaskForDest(destId)
-                      const isRunning = task?.status === 'running'
+                      const isRunning = task?.status === 'running' || task?.status === 'pending'
 
                       return (
                         <DestinationServer
@@ -921,6 +1014,8 @@ export function SyncDialog() {
                           task={task}
                           onRemove={() => handleRemoveDestination(destId)}
                           onStop={() => task && stopSyncMutation.mutate(task.id)}
+                          onResume={() => task && resumeSyncMutation.mutate({ taskId: task.id, retryOnly: false })}
+                          onRetry={() => task && resumeSyncMutation.mutate({ taskId: task.id, retryOnly: true })}
                         />
                       )
                     })}
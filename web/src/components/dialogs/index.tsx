@@ -17,6 +17,10 @@ import QGISProjectDialog from './QGISProjectDialog'
 import QGISPreviewDialog from './QGISPreviewDialog'
 import GeoNodeConnectionDialog from './GeoNodeConnectionDialog'
 import GeoNodeUploadDialog from './GeoNodeUploadDialog'
+import BundleDialog from './BundleDialog'
+import AccountDialog from './AccountDialog'
+import SnapshotsDialog from './SnapshotsDialog'
+import OfflineDialog from './OfflineDialog'
 import { SettingsDialog } from './SettingsDialog'
 import { SyncDialog } from './SyncDialog'
 import { StyleDialog } from './StyleDialog'
@@ -47,6 +51,10 @@ export default function Dialogs() {
       <QGISPreviewDialog />
       <GeoNodeConnectionDialog />
       <GeoNodeUploadDialog />
+      <BundleDialog />
+      <AccountDialog />
+      <SnapshotsDialog />
+      <OfflineDialog />
     </>
   )
 }
@@ -4,6 +4,10 @@ import { ChakraProvider } from '@chakra-ui/react'
 import { QueryClient, QueryClientProvider } from '@tanstack/react-query'
 import App from './App'
 import theme from './theme'
+import { installAuthFetch } from './api/auth'
+
+// Send the CSRF token with every API request that changes state
+installAuthFetch()
 
 const queryClient = new QueryClient({
   defaultOptions: {
@@ -0,0 +1,85 @@
+import { create } from 'zustand'
+import * as authApi from '../api/auth'
+import type { AuthSession, AuthUser, AuthCapabilities, Role } from '../api/auth'
+
+interface AuthState {
+  status: 'loading' | 'authenticated' | 'anonymous'
+  authEnabled: boolean
+  user: AuthUser | null
+  capabilities: AuthCapabilities
+  error: string | null
+
+  // Actions
+  checkSession: () => Promise<void>
+  login: (username: string, password: string) => Promise<void>
+  logout: () => Promise<void>
+  applySession: (session: AuthSession) => void
+  loadCapabilities: () => Promise<void>
+}
+
+// Until capabilities load, offer only what a viewer may do
+const viewerCapabilities: AuthCapabilities = { auth_enabled: true, role: 'viewer', grants: [] }
+
+export const useAuthStore = create<AuthState>((set, get) => ({
+  status: 'loading',
+  authEnabled: true,
+  user: null,
+  capabilities: viewerCapabilities,
+  error: null,
+
+  applySession: (session) => {
+    authApi.setCSRFToken(session.csrf_token ?? null)
+    set({
+      authEnabled: session.auth_enabled,
+      user: session.user,
+      status: !session.auth_enabled || session.user ? 'authenticated' : 'anonymous',
+    })
+    if (!session.auth_enabled || session.user) {
+      get().loadCapabilities()
+    }
+  },
+
+  loadCapabilities: async () => {
+    try {
+      set({ capabilities: await authApi.getCapabilities() })
+    } catch {
+      set({ capabilities: viewerCapabilities })
+    }
+  },
+
+  checkSession: async () => {
+    try {
+      get().applySession(await authApi.getSession())
+    } catch (err) {
+      set({ status: 'anonymous', error: (err as Error).message })
+    }
+  },
+
+  login: async (username, password) => {
+    set({ error: null })
+    try {
+      get().applySession(await authApi.login(username, password))
+    } catch (err) {
+      set({ error: (err as Error).message })
+      throw err
+    }
+  },
+
+  logout: async () => {
+    try {
+      await authApi.logout()
+    } finally {
+      authApi.setCSRFToken(null)
+      set({ user: null, status: 'anonymous', capabilities: viewerCapabilities })
+    }
+  },
+}))
+
+// useCan reports whether the current user has at least the given role,
+// optionally on a connection and workspace. The server enforces the same
+// rules; this only hides actions that would be refused.
+export function useCan(need: Role, connId?: string, workspace?: string): boolean {
+  const capabilities = useAuthStore((state) => state.capabilities)
+  const role = authApi.roleFor(capabilities, connId, workspace)
+  return authApi.ROLES.indexOf(role) >= authApi.ROLES.indexOf(need)
+}
@@ -33,6 +33,10 @@ export type DialogType =
   | 'qgispreview'
   | 'geonode'
   | 'geonodeupload'
+  | 'bundle'
+  | 'account'
+  | 'snapshots'
+  | 'offline'
   | null
 
 export type DialogMode = 'create' | 'edit' | 'delete' | 'view'
@@ -4,7 +4,6 @@ export interface Connection {
   name: string
   url: string
   username: string
-  password: string
   isActive: boolean
 }
 
@@ -406,8 +405,35 @@ export interface SyncOptions {
   layers: boolean
   styles: boolean
   layergroups: boolean
+  gwc?: boolean
+  gwc_seed?: boolean
   workspace_filter?: string[]
   datastore_strategy?: DataStoreSyncStrategy
+  workers?: number
+  rate_limit?: number
+}
+
+export interface NameRewriteRule {
+  kinds?: string[]
+  strip_prefix?: string
+  add_prefix?: string
+  strip_suffix?: string
+  add_suffix?: string
+}
+
+export interface DataStoreParamOverride {
+  host?: string
+  port?: string
+  database?: string
+  schema?: string
+  params?: Record<string, string>
+}
+
+export interface SyncMappingRules {
+  workspace_renames?: Record<string, string>
+  style_renames?: Record<string, string>
+  name_rewrites?: NameRewriteRule[]
+  datastore_overrides?: Record<string, DataStoreParamOverride>
 }
 
 export interface SyncConfiguration {
@@ -416,6 +442,7 @@ export interface SyncConfiguration {
   source_id: string
   destination_ids: string[]
   options: SyncOptions
+  mappings?: SyncMappingRules
   created_at: string
   last_synced_at?: string
 }
@@ -425,7 +452,7 @@ export interface SyncTask {
   configId: string
   sourceId: string
   destId: string
-  status: 'running' | 'completed' | 'failed' | 'stopped'
+  status: 'pending' | 'running' | 'completed' | 'failed' | 'stopped'
   progress: number
   currentItem: string
   itemsTotal: number
@@ -436,6 +463,7 @@ export interface SyncTask {
   completedAt?: string
   error?: string
   log: string[]
+  resumable?: boolean
 }
 
 export interface StartSyncRequest {
@@ -443,6 +471,7 @@ export interface StartSyncRequest {
   sourceId?: string
   destinationIds?: string[]
   options?: SyncOptions
+  mappings?: SyncMappingRules
 }
 
 // Dashboard types
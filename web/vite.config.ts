@@ -5,6 +5,8 @@ import cesium from 'vite-plugin-cesium'
 // https://vitejs.dev/config/
 export default defineConfig({
   plugins: [react(), cesium()],
+  // Relative asset URLs, so the app works under any base path (see -base-path)
+  base: './',
   build: {
     outDir: '../internal/webserver/static',
     emptyOutDir: true,